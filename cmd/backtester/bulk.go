@@ -0,0 +1,109 @@
+// bulk.go — прогон всех стратегий по каталогу инструментов и сводный рейтинг обобщающей способности
+package main
+
+import (
+	"fmt"
+	"log"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"bt/internal"
+	"bt/internal/app/backtester"
+)
+
+// runBulk — запускает полный набор стратегий на каждом файле каталога и сохраняет сводный рейтинг в Markdown
+func runBulk(config backtester.Config) error {
+	extension := config.Source
+	if extension == "" {
+		extension = "json"
+	}
+	files, err := filepath.Glob(filepath.Join(config.Dir, "*."+extension))
+	if err != nil {
+		return fmt.Errorf("ошибка чтения каталога %s: %w", config.Dir, err)
+	}
+	sort.Strings(files)
+	barSpec, err := internal.ParseBarSpec(config.Bars)
+	if err != nil {
+		return err
+	}
+	if len(files) == 0 {
+		return fmt.Errorf("в каталоге %s нет файлов *.%s со свечами", config.Dir, extension)
+	}
+
+	log.Printf("📂 Сводный рейтинг: %d инструментов в %s", len(files), config.Dir)
+
+	var runs []backtester.InstrumentRun
+	for i, file := range files {
+		instrument := strings.TrimSuffix(filepath.Base(file), filepath.Ext(file))
+		log.Printf("📈 [%d/%d] Инструмент %s", i+1, len(files), instrument)
+
+		source, err := internal.NewCandleSource(config.Source, file)
+		if err != nil {
+			return err
+		}
+		candles, err := source.Load()
+		if err != nil {
+			log.Printf("⚠️ %s: %v — пропущен", file, err)
+			continue
+		}
+		if len(candles) == 0 {
+			log.Printf("⚠️ %s: нет свечей — пропущен", file)
+			continue
+		}
+		if err := ensureCandleTimes(candles, config.AllowBadTime); err != nil {
+			log.Printf("⚠️ %s: %v — пропущен", file, err)
+			continue
+		}
+		candles = resolveZeroTimes(candles, config.ZeroTime)
+
+		candles = barSpec.Apply(candles)
+
+		// Индикаторы прошлого инструмента больше не понадобятся — сбрасываем кэш перед каждым
+		internal.ResetCache()
+
+		// Отчеты по отдельным инструментам не печатаются: итог — только сводный рейтинг
+		runner := backtester.NewParallelStrategyRunnerWithConfig(config.Debug, nil, config)
+		results, err := runner.RunAllStrategies(candles)
+		if err != nil {
+			log.Printf("❌ %s: %v — пропущен", instrument, err)
+			continue
+		}
+
+		runs = append(runs, backtester.InstrumentRun{
+			Instrument: instrument,
+			Candles:    len(candles),
+			Results:    results,
+		})
+	}
+
+	if len(runs) == 0 {
+		return fmt.Errorf("ни один файл в %s не удалось протестировать", config.Dir)
+	}
+
+	leaderboard := backtester.BuildLeaderboard(runs)
+
+	f, err := backtester.CreateTimestampedFile(config.OutDir, "leaderboard", ".md")
+	if err != nil {
+		return fmt.Errorf("ошибка создания отчета: %w", err)
+	}
+	defer f.Close()
+
+	if err := backtester.WriteLeaderboardMarkdown(f, runs, leaderboard); err != nil {
+		return fmt.Errorf("ошибка записи отчета: %w", err)
+	}
+
+	fmt.Println("\n" + strings.Repeat("═", 80))
+	fmt.Println("🏆 ТОП-10 СТРАТЕГИЙ ПО СРЕДНЕМУ РАНГУ")
+	fmt.Println(strings.Repeat("═", 80))
+	for i, entry := range leaderboard {
+		if i == 10 {
+			break
+		}
+		fmt.Printf("%2d. %-30s │ Ср. ранг: %5.1f │ Медианная прибыль: %+7.2f%% │ Инструментов: %d/%d\n",
+			i+1, entry.Name, entry.AverageRank, entry.MedianProfit*100, entry.Instruments, len(runs))
+	}
+	fmt.Printf("📄 Сводный рейтинг сохранен: %s\n", f.Name())
+
+	return nil
+}
@@ -0,0 +1,40 @@
+// crossval.go — k-fold кросс-валидация оптимизации стратегий
+package main
+
+import (
+	"fmt"
+	"log"
+
+	"bt/internal"
+	"bt/internal/app/backtester"
+)
+
+// runCrossValidation — кросс-валидация выбранной стратегии или всех стратегий по очереди
+func runCrossValidation(config backtester.Config, candles []internal.Candle) error {
+	runner := backtester.NewSingleStrategyRunnerWithConfig(config.Debug, config)
+
+	if config.Strategy != "all" {
+		result, err := backtester.RunCrossValidation(config.Strategy, candles, config.CVFolds, runner.GetSlipping())
+		if err != nil {
+			return err
+		}
+		backtester.PrintCrossValidation(result)
+		return nil
+	}
+
+	names := append(internal.GetStrategyNames(), internal.GetStrategyNamesV2()...)
+	var results []backtester.CrossValidationResult
+	for i, name := range names {
+		log.Printf("🧪 [%d/%d] %s: %d фолдов", i+1, len(names), name, config.CVFolds)
+		result, err := backtester.RunCrossValidation(name, candles, config.CVFolds, runner.GetSlipping())
+		if err != nil {
+			// Как при обычном запуске всех стратегий: ошибка одной стратегии не прерывает остальные
+			fmt.Printf("❌ Ошибка при запуске стратегии %s: %v\n", name, err)
+			continue
+		}
+		results = append(results, *result)
+	}
+
+	backtester.PrintCrossValidationSummary(results)
+	return nil
+}
@@ -2,7 +2,6 @@
 package main
 
 import (
-	"encoding/json"
 	"flag"
 	"fmt"
 	"log"
@@ -10,7 +9,6 @@ import (
 	_ "net/http/pprof"
 	"os"
 	"runtime/pprof"
-	"sort"
 	"strings"
 	"time"
 
@@ -33,61 +31,141 @@ import (
 	_ "bt/strategies/v1/volume"
 
 	_ "bt/strategies/v2/lines"
+	_ "bt/strategies/v2/meta"
 	_ "bt/strategies/v2/oscillators"
 	_ "bt/strategies/v2/trend"
+	_ "bt/strategies/v2/volatility"
 	_ "bt/strategies/v2/wave"
 )
 
 func LoadCandlesFromFile(filename string) []internal.Candle {
-	data, err := os.ReadFile(filename)
+	return loadCandles(&internal.JSONFileSource{Path: filename}, filename)
+}
+
+// loadCandles — загружает свечи из источника; ошибка загрузки завершает программу
+func loadCandles(source internal.CandleSource, description string) []internal.Candle {
+	if description == internal.StdinPath {
+		description = "stdin"
+	}
+	candles, err := source.Load()
 	if err != nil {
-		log.Fatal("❌ Не удалось прочитать файл:", err)
+		log.Fatal("❌ Не удалось загрузить свечи из ", description, ": ", err)
 	}
 
-	var wrapper struct {
-		Candles []internal.Candle `json:"candles"`
+	fmt.Printf("✅ Загружено %d свечей из %s\n", len(candles), description)
+	return candles
+}
+
+// syntheticCandleInterval — шаг синтетических меток времени для --allow-bad-time
+const syntheticCandleInterval = time.Minute
+
+// ensureCandleTimes — проверяет, что свечи не имеют одинакового (в т.ч. пустого) времени.
+// Такой файл нельзя отсортировать и по нему нельзя вычислить интервал свечей, поэтому
+// без allowBadTime возвращается ошибка, а с allowBadTime свечам присваиваются
+// последовательные синтетические метки времени в порядке следования в файле.
+func ensureCandleTimes(candles []internal.Candle, allowBadTime bool) error {
+	if len(candles) < 2 {
+		return nil
 	}
 
-	err = json.Unmarshal(data, &wrapper)
-	if err != nil {
-		log.Fatal("❌ Ошибка парсинга JSON:", err)
+	first := candles[0].ParsedTime
+	for _, c := range candles[1:] {
+		if !c.ParsedTime.Equal(first) {
+			return nil
+		}
 	}
 
-	// Precompute ParsedTime to optimize ToTime() calls for better performance
-	// Handle empty time strings gracefully to avoid parsing errors
-	for i := range wrapper.Candles {
-		if wrapper.Candles[i].Time != "" {
-			// Compute and store time.Time with multiple format fallbacks
-			t, err := time.Parse(time.RFC3339, wrapper.Candles[i].Time)
-			if err != nil {
-				// Try RFC3339Nano format
-				t, err = time.Parse(time.RFC3339Nano, wrapper.Candles[i].Time)
-				if err != nil {
-					// Try format without timezone
-					t, err = time.Parse("2006-01-02T15:04:05", wrapper.Candles[i].Time)
-					if err != nil {
-						log.Printf("❌ Все форматы времени провалились для: '%s', используем zero time", wrapper.Candles[i].Time)
-						t = time.Time{} // Use zero time for invalid formats
-					}
-				}
-			}
-			wrapper.Candles[i].ParsedTime = t
+	if !allowBadTime {
+		if first.IsZero() {
+			return fmt.Errorf("у всех %d свечей пустое или некорректное время — порядок и интервал свечей не определены (используйте --allow-bad-time для синтетических меток времени)", len(candles))
+		}
+		return fmt.Errorf("у всех %d свечей одинаковое время %s — порядок и интервал свечей не определены (используйте --allow-bad-time для синтетических меток времени)", len(candles), first.Format(time.RFC3339))
+	}
+
+	base := first
+	if base.IsZero() {
+		base = time.Unix(0, 0).UTC()
+	}
+	for i := range candles {
+		candles[i].ParsedTime = base.Add(time.Duration(i) * syntheticCandleInterval)
+		candles[i].Time = candles[i].ParsedTime.Format(time.RFC3339)
+	}
+
+	log.Printf("⚠️  У всех свечей одинаковое время — назначены синтетические метки с шагом %v", syntheticCandleInterval)
+	return nil
+}
+
+// resolveZeroTimes — удаляет свечи без времени или назначает им время по соседям (--zero-time)
+func resolveZeroTimes(candles []internal.Candle, zeroTime string) []internal.Candle {
+	policy, _ := internal.ParseZeroTimePolicy(zeroTime)
+	candles, count := internal.ResolveZeroTimeCandles(candles, policy)
+	if count > 0 {
+		if policy == internal.ZeroTimeDrop {
+			log.Printf("⚠️  Удалено %d свечей без времени (--zero-time interpolate назначит им время по соседним свечам)", count)
+		} else {
+			log.Printf("⚠️  %d свечам без времени назначено время по соседним свечам", count)
 		}
-		// If Time is empty, ParsedTime remains as zero time (already set by UnmarshalJSON)
+	}
+	return candles
+}
+
+// maxReportedGaps — сколько нарушений ряда свечей перечисляется в сводке --debug
+const maxReportedGaps = 10
+
+// checkCandleSeries — сводка ValidateCandleSeries: с debug выводится в лог, с failOnGaps любое нарушение — ошибка
+func checkCandleSeries(candles []internal.Candle, debug, failOnGaps bool) error {
+	intervalSeconds, gaps := internal.ValidateCandleSeries(candles)
+	counts := make(map[internal.GapKind]int)
+	var longest time.Duration
+	for _, gap := range gaps {
+		counts[gap.Kind]++
+		longest = max(longest, gap.Duration())
 	}
 
-	sort.Slice(wrapper.Candles, func(i, j int) bool {
-		return wrapper.Candles[i].ParsedTime.Before(wrapper.Candles[j].ParsedTime)
-	})
+	if debug {
+		interval := time.Duration(intervalSeconds) * time.Second
+		log.Printf("🕳️ Ряд свечей: интервал %v, пропусков %d (самый длинный %v), повторов времени %d, без времени %d",
+			interval, counts[internal.GapMissing], longest, counts[internal.GapDuplicate], counts[internal.GapZeroTime])
+		for i, gap := range gaps {
+			if i == maxReportedGaps {
+				log.Printf("   … и еще %d", len(gaps)-maxReportedGaps)
+				break
+			}
+			if gap.Kind == internal.GapZeroTime {
+				log.Printf("   %-9s свеча %d", gap.Kind, gap.Index)
+				continue
+			}
+			log.Printf("   %-9s свеча %d: %s → %s", gap.Kind, gap.Index, gap.From.Format(time.RFC3339), gap.To.Format(time.RFC3339))
+		}
+	}
 
-	fmt.Printf("✅ Загружено %d свечей из %s\n", len(wrapper.Candles), filename)
-	return wrapper.Candles
+	if failOnGaps && len(gaps) > 0 {
+		return fmt.Errorf("ряд свечей неравномерный: пропусков %d, повторов времени %d, свечей без времени %d (--fail-on-gaps)",
+			counts[internal.GapMissing], counts[internal.GapDuplicate], counts[internal.GapZeroTime])
+	}
+	return nil
 }
 
 func main() {
 	// Парсинг командной строки
 	config := parseFlags()
 
+	// Принтер создается до загрузки данных: в режимах --oneline, --format=csv и --output=json:- служебный вывод перенаправляется в stderr
+	printer, err := createPrinter(config)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	// Уровень логов стратегий: info/debug по умолчанию подавляются, чтобы не засорять вывод параллельного прогона
+	logLevel, err := internal.ParseLogLevel(config.LogLevel)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	if config.LogLevel == "" && config.Debug {
+		logLevel = internal.LogDebug
+	}
+	internal.SetLogLevel(logLevel)
+
 	// Запуск realtime профилирования если указано
 	if config.ProfPort > 0 {
 		go func() {
@@ -112,25 +190,326 @@ func main() {
 		defer pprof.StopCPUProfile()
 	}
 
+	// Параметры движка, общие для всех стратегий
+	engineOptions, err := createEngineOptions(config)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	internal.SetDefaultBacktestOptions(engineOptions)
+	priceSource, err := internal.ParsePriceSource(config.PriceSource)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	internal.SetDefaultPriceSource(priceSource)
+	if priceSource != internal.PriceClose {
+		log.Printf("💲 Источник цены для стратегий: %s", priceSource)
+	}
+	if config.TrendGate < 0 || config.TrendGate >= 100 {
+		log.Fatalf("❌ --trend-gate должен быть в диапазоне [0, 100), получено %.1f", config.TrendGate)
+	}
+	if config.TrendGate > 0 {
+		log.Printf("📶 Фильтр флэта: трендовые стратегии не входят в позицию при ADX(%d) < %.0f", internal.DefaultTrendGatePeriod, config.TrendGate)
+	}
+	if config.OutDir == "" {
+		log.Fatalf("❌ --out_dir не может быть пустым (текущий каталог — \".\")")
+	}
+	if config.Workers < 0 {
+		log.Fatalf("❌ --workers должен быть неотрицательным, получено %d", config.Workers)
+	}
+	if config.MonteCarlo < 0 {
+		log.Fatalf("❌ --monte_carlo должен быть неотрицательным, получено %d", config.MonteCarlo)
+	}
+	if _, err := internal.ParseZeroTimePolicy(config.ZeroTime); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	sessionFilter, err := internal.NewSessionFilter(config.Session, config.SessionTZ)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	if sessionFilter != nil {
+		log.Printf("🕘 Торговая сессия %s: сигналы вне нее не исполняются, выходы переносятся на начало сессии", sessionFilter)
+	}
+	if _, err := backtester.ParseSaveConfigs(config.SaveConfigs); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	objectiveFunc, err := internal.ParseObjective(config.Objective)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	internal.SetDefaultObjective(objectiveFunc)
+	if objectiveFunc != internal.ObjectiveProfit {
+		log.Printf("🎯 Целевая функция оптимизации: %s", objectiveFunc)
+	}
+	if config.RecencyHalfLife < 0 {
+		log.Fatalf("❌ --recency-half-life не может быть отрицательным, получено %d", config.RecencyHalfLife)
+	}
+	internal.SetDefaultRecencyHalfLife(config.RecencyHalfLife)
+	if config.RecencyHalfLife > 0 {
+		log.Printf("⏳ Взвешивание по давности: вес доходности бара уменьшается вдвое каждые %d баров", config.RecencyHalfLife)
+	}
+	if config.MaxConfigs < 0 {
+		log.Fatalf("❌ --max-configs не может быть отрицательным, получено %d", config.MaxConfigs)
+	}
+	internal.SetDefaultGridLimit(internal.GridLimit{MaxConfigs: config.MaxConfigs, Seed: config.GridSeed})
+	if config.WFWindow < 0 || config.WFStep < 0 {
+		log.Fatalf("❌ --wf-window и --wf-step не могут быть отрицательными, получено %d и %d", config.WFWindow, config.WFStep)
+	}
+	if config.WFWindow > 0 {
+		step := config.WFStep
+		if step == 0 {
+			step = max(config.WFWindow/4, 1)
+		}
+		internal.SetDefaultWalkForward(internal.WalkForward{Window: config.WFWindow, Step: step})
+		log.Printf("🔁 Walk-forward: подбор на %d свечах, проверка на следующих %d, сдвиг на %d (стратегии V2)", config.WFWindow, step, step)
+	} else if config.WFStep > 0 {
+		log.Fatal("❌ --wf-step работает только с --wf-window")
+	}
+	if config.MaxConfigs > 0 {
+		log.Printf("🎲 Перебор сетки: не больше %d конфигураций на стратегию (случайная подвыборка, seed %d)", config.MaxConfigs, config.GridSeed)
+	}
+	barSpec, err := internal.ParseBarSpec(config.Bars)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	if engineOptions.ExecutionDelay > 0 || engineOptions.Fill != internal.FillClose {
+		log.Printf("⏱️ Исполнение сделок: задержка %d бар(ов), цена %s", engineOptions.ExecutionDelay, engineOptions.Fill)
+	}
+	if engineOptions.SlippagePercent > 0 || engineOptions.Commission > 0 {
+		log.Printf("💸 Издержки: проскальзывание %.3f%%, комиссия %.3f%% на сторону", engineOptions.SlippagePercent*100, engineOptions.Commission*100)
+	}
+	if engineOptions.CommissionModel != nil {
+		log.Printf("💸 Комиссия брокера на каждую сторону: %s", engineOptions.CommissionModel)
+	}
+	if engineOptions.BreakerTrip > 0 {
+		log.Printf("🛑 Прерыватель по просадке: срабатывает на %.1f%%, возобновление после отыгрыша %.0f%% просадки",
+			engineOptions.BreakerTrip*100, engineOptions.BreakerReset*100)
+	}
+	if engineOptions.KellyMultiplier > 0 {
+		log.Printf("🎲 Размер позиции по Келли: %.2f × f*, не более %.0f%% капитала (второй прогон по статистике сделок)",
+			engineOptions.KellyMultiplier, engineOptions.KellyCap*100)
+	}
+	switch config.BenchmarkAnchor {
+	case backtester.BenchmarkAnchorFirst:
+	case backtester.BenchmarkAnchorTrade:
+		log.Printf("⚓ Buy & Hold покупает на баре первого входа стратегии")
+	default:
+		log.Fatalf("❌ неизвестная привязка Buy & Hold '%s' (ожидается first или trade)", config.BenchmarkAnchor)
+	}
+	if engineOptions.RegimeFilter != "" {
+		log.Printf("🌡️ Фильтр режима волатильности: входы в режиме %s запрещены (окно %d баров)", engineOptions.RegimeFilter, engineOptions.RegimeWindow)
+	}
+	if engineOptions.ScaleIn > 1 {
+		log.Printf("🪜 Докупка: до %d входов в позицию равными частями капитала, вход по средневзвешенной цене", engineOptions.ScaleIn)
+	}
+	if engineOptions.AllowShorts {
+		log.Println("🔻 Короткие позиции: SELL без позиции открывает шорт, BUY его закрывает")
+	}
+	if engineOptions.Exits.StopLossPercent > 0 || engineOptions.Exits.TakeProfitPercent > 0 {
+		trailing := ""
+		if engineOptions.Exits.TrailingStop {
+			trailing = " (трейлинг)"
+		}
+		log.Printf("🛡️ Выходы движка: стоп-лосс %.2f%%%s, тейк-профит %.2f%% (0 = выключено)",
+			engineOptions.Exits.StopLossPercent*100, trailing, engineOptions.Exits.TakeProfitPercent*100)
+	}
+	if engineOptions.Capital() != internal.DefaultInitialCapital {
+		log.Printf("💰 Начальный капитал: $%.2f", engineOptions.Capital())
+	}
+	switch engineOptions.PositionSizing {
+	case internal.SizingWholeShares:
+		log.Println("🧮 Размер позиции: целые бумаги, остаток капитала остается на счете")
+	case internal.SizingFixedFraction:
+		log.Printf("🧮 Размер позиции: %.0f%% капитала счета на каждый вход", engineOptions.RiskFraction*100)
+	}
+	if engineOptions.TrackExcursions {
+		log.Println("📐 MFE/MAE: лучшее и худшее движение цены за время каждой сделки")
+	}
+	if engineOptions.ReturnMode == internal.ReturnFixed {
+		log.Println("📏 Режим доходности fixed: каждая сделка на начальный капитал, прибыль не реинвестируется")
+	}
+	if engineOptions.MinHoldBars > 0 || engineOptions.CooldownBars > 0 {
+		log.Printf("⏳ Ограничение частоты сделок: удержание не меньше %d баров, пауза после выхода %d баров", engineOptions.MinHoldBars, engineOptions.CooldownBars)
+	}
+	if engineOptions.MinTradeMove > 0 {
+		log.Printf("🧹 Фильтр слабых сделок: выход только при движении цены от входа не менее %.3f%%", engineOptions.MinTradeMove*100)
+	}
+
+	if config.Explain && (config.Strategy == "all" || config.Dir != "") {
+		log.Fatal("❌ --explain работает только для одной стратегии: укажите --strategy")
+	}
+	if config.MonteCarlo > 0 && (config.Strategy == "all" || config.Dir != "") {
+		log.Fatal("❌ --monte_carlo работает только для одной стратегии: укажите --strategy")
+	}
+	if config.SaveEquity && config.SaveSignals <= 0 {
+		log.Fatal("❌ --save-equity сохраняет кривые капитала топ-N стратегий: укажите --save_signals=N")
+	}
+	if config.Forward && config.ConfigFile == "" {
+		log.Fatal("❌ --forward проверяет сохраненные конфигурации: укажите --config (например, optimized_configs.json)")
+	}
+	if config.Forward && config.Dir != "" {
+		log.Fatal("❌ --forward несовместим с --dir")
+	}
+	if config.Calibration && (config.Strategy == "all" || config.Dir != "") {
+		log.Fatal("❌ --calibration работает только для одной стратегии: укажите --strategy")
+	}
+	if config.DumpSearch != "" {
+		if config.Strategy == "all" || config.Dir != "" {
+			log.Fatal("❌ --dump-search работает только для одной стратегии: укажите --strategy")
+		}
+		internal.EnableSearchRecording()
+	}
+
+	// Самопроверка стратегий не требует файла со свечами
+	if config.SelfCheck {
+		runSelfCheck()
+		return
+	}
+
+	if config.NonFinitePolicy != "na" && config.NonFinitePolicy != "fail" {
+		log.Fatalf("❌ неизвестное значение --non-finite '%s' (ожидается na или fail)", config.NonFinitePolicy)
+	}
+
+	// Базовый отчет читается до прогона, чтобы ошибка в пути не стоила полного бэктеста
+	var baseline *backtester.JSONReport
+	if config.CompareTo != "" {
+		if config.Dir != "" || config.Timeframes != "" {
+			log.Fatal("❌ --compare-to сравнивает обычный прогон по --file и несовместим с --dir и --timeframes")
+		}
+		if config.MaxRegression < 0 {
+			log.Fatalf("❌ --regression-tolerance не может быть отрицательным, получено %.4f", config.MaxRegression)
+		}
+		baseline, err = backtester.LoadJSONReport(config.CompareTo)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		log.Printf("📐 Сравнение с %s: %d стратегий, допуск %.2f п.п.", config.CompareTo, len(baseline.Results), config.MaxRegression*100)
+	}
+
+	// Сводный рейтинг по каталогу инструментов
+	if config.Dir != "" {
+		if err := runBulk(config); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
 	// Загрузка данных
-	candles := LoadCandlesFromFile(config.Filename)
+	source, err := internal.NewCandleSource(config.Source, config.Filename)
+	if err != nil {
+		log.Fatal("❌ ", err)
+	}
+	candles := loadCandles(source, config.Filename)
 	if len(candles) == 0 {
 		log.Fatal("Нет данных для анализа")
 	}
+	if err := ensureCandleTimes(candles, config.AllowBadTime); err != nil {
+		log.Fatal("❌ ", err)
+	}
+	candles = resolveZeroTimes(candles, config.ZeroTime)
+	if config.Debug || config.FailOnGaps {
+		if err := checkCandleSeries(candles, config.Debug, config.FailOnGaps); err != nil {
+			log.Fatal("❌ ", err)
+		}
+	}
+
+	if barSpec.Kind != internal.TimeBars {
+		sourceCount := len(candles)
+		candles = barSpec.Apply(candles)
+		log.Printf("📊 Бары %s: %d свечей → %d баров", barSpec, sourceCount, len(candles))
+	}
+
+	if err := backtester.ValidateHoldout(config.Holdout, len(candles)); err != nil {
+		log.Fatal("❌ ", err)
+	}
+
+	log.Printf("📅 Аннуализация метрик: %.0f баров в году (рынок: %s)", engineOptions.PeriodsPerYearFor(candles), engineOptions.Market)
+
+	// Статистика данных перед запуском
+	if config.Stats || config.StatsOnly {
+		printCandleStats(candles, engineOptions.PeriodsPerYearFor(candles))
+		if config.StatsOnly {
+			return
+		}
+	}
+
+	// Калибровка уверенности предсказаний
+	if config.Calibration {
+		result, err := backtester.RunConfidenceCalibration(config.Strategy, candles, config.CalibHorizon, config.CalibBins)
+		if err != nil {
+			log.Fatal("❌ ", err)
+		}
+		backtester.WriteConfidenceCalibration(os.Stdout, result)
+		return
+	}
+
+	// K-fold кросс-валидация оптимизации
+	if config.CVFolds != 0 {
+		if err := runCrossValidation(config, candles); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
+
+	// Проверка стабильности стратегии по таймфреймам
+	if config.Timeframes != "" {
+		if err := runTimeframeStability(config, candles); err != nil {
+			log.Fatal("❌ ", err)
+		}
+		return
+	}
 
 	// Инициализация компонентов
-	printer := backtester.NewCombinedPrinter() // Используем комбинированный принтер для автоматической генерации MD отчетов
 	runner := createRunner(config, printer)
-	saver := backtester.NewFileSaver()
+	saver := backtester.NewFileSaverWithConfig(config)
 
 	// Запуск стратегий
-	results, err := runStrategies(config, runner, candles)
+	results, err := runStrategies(config, runner, printer, candles)
 	if err != nil {
 		log.Fatalf("Ошибка при запуске стратегий: %v", err)
 	}
 
 	// Результаты уже выведены через принтер в runner
 
+	// Нечисловые результаты и сравнение с базовым отчетом: код выхода выставляется в самом конце,
+	// после сохранения и профилей
+	var nonFiniteNames []string
+	for _, r := range results {
+		if r.NonFinite {
+			nonFiniteNames = append(nonFiniteNames, r.Name)
+		}
+	}
+	if len(nonFiniteNames) > 0 {
+		log.Printf("⚫ Нечисловой результат (NaN/Inf) у стратегий: %s", strings.Join(nonFiniteNames, ", "))
+	}
+
+	regressions := 0
+	if baseline != nil {
+		// Для одной стратегии остальные записи базы не считаются пропавшими
+		if config.Strategy != "all" {
+			baseline = baseline.Only(results)
+		}
+		deltas := backtester.CompareToBaseline(results, baseline, config.MaxRegression)
+		backtester.WriteBaselineComparison(os.Stdout, deltas, config.MaxRegression)
+		regressions = backtester.CountRegressions(deltas)
+	}
+
+	// Форвард-проверка сохраненных конфигураций
+	if config.Forward {
+		backtester.WriteForwardValidation(os.Stdout, backtester.ForwardValidation(results), config.ConfigFile)
+	}
+
+	// Поверхность поиска оптимизатора
+	if config.DumpSearch != "" {
+		points := internal.TakeSearchSurface()
+		if len(points) == 0 {
+			log.Printf("⚠️ --dump-search: оптимизатор не проверил ни одной конфигурации (загружена готовая конфигурация или у стратегии собственный оптимизатор)")
+		} else if err := backtester.SaveSearchSurface(config.DumpSearch, points); err != nil {
+			log.Printf("❌ %v", err)
+		} else {
+			fmt.Printf("🗺️ Поверхность поиска (%d конфигураций) сохранена: %s\n", len(points), config.DumpSearch)
+		}
+	}
+
 	// Сохранение данных для графиков
 	if config.SaveSignals > 0 {
 		fmt.Printf("%s", "\n"+strings.Repeat("=", 100)+"\n")
@@ -140,6 +519,11 @@ func main() {
 		if err := saver.SaveTopStrategies(candles, results, config.Filename, config.SaveSignals); err != nil {
 			log.Printf("❌ Ошибка при сохранении данных: %v", err)
 		}
+		if config.SaveEquity {
+			if err := saver.SaveEquityCurves(candles, results, config.Filename, config.SaveSignals); err != nil {
+				log.Printf("❌ Ошибка при сохранении кривых капитала: %v", err)
+			}
+		}
 	} else if config.Debug {
 		fmt.Println("\n💡 Сохранение сигналов отключено флагом --save_signals=0")
 	}
@@ -155,48 +539,468 @@ func main() {
 		}
 		f.Close()
 	}
+
+	if config.NonFinitePolicy == "fail" && len(nonFiniteNames) > 0 {
+		pprof.StopCPUProfile()
+		log.Fatalf("❌ --non-finite=fail: %d стратегий с нечисловым результатом", len(nonFiniteNames))
+	}
+	if regressions > 0 {
+		pprof.StopCPUProfile()
+		log.Fatalf("❌ Регрессия относительно %s: %d стратегий", config.CompareTo, regressions)
+	}
 }
 
 // parseFlags — парсит командную строку и возвращает конфигурацию
 func parseFlags() backtester.Config {
-	filename := flag.String("file", "candles.json", "Путь к JSON-файлу со свечами")
+	filename := flag.String("file", "candles.json", "Путь к JSON-файлу со свечами (\"-\" — читать из stdin)")
+	source := flag.String("source", "json", "Источник данных для --file/--dir: json (формат API) или csv (time,open,high,low,close[,volume])")
+	dir := flag.String("dir", "", "Каталог с файлами свечей (по файлу на инструмент): прогон всех стратегий и сводный рейтинг")
 	strategyName := flag.String("strategy", "all", "Стратегия: all (все стратегии) или "+strings.Join(internal.GetStrategyNames(), ", "))
 	debug := flag.Bool("debug", false, "Включить детальное логирование")
 	saveSignals := flag.Int("save_signals", 0, "Сохранить топ-N стратегий с сигналами (0 = не сохранять)")
+	saveEquity := flag.Bool("save-equity", false, "Вместе с --save_signals сохранить кривую капитала топ-N стратегий в <файл>_<стратегия>_equity.json")
+	outDir := flag.String("out_dir", backtester.DefaultOutDir, "Каталог для отчетов, оптимизированных конфигураций и сохраненных сигналов (создается при необходимости)")
 	cpuProfile := flag.String("cpu_profile", "", "Файл для CPU профилирования (пусто = отключено)")
 	memProfile := flag.String("mem_profile", "", "Файл для памяти профилирования (пусто = отключено)")
+	strategyTimeout := flag.Duration("strategy_timeout", 0, "Предельное время на одну стратегию, например 30s или 5m: по истечении оптимизация прерывается, стратегия пропускается (0 = без ограничения)")
+	workers := flag.Int("workers", 0, "Сколько стратегий выполнять одновременно (0 = число ядер)")
+	memStats := flag.Bool("mem-stats", false, "Замерять память, выделенную каждой стратегией (стратегии выполняются по одной)")
 	configFile := flag.String("config", "", "Путь к JSON-файлу с конфигурациями стратегий (пусто = оптимизация)")
 	profPort := flag.Int("prof_port", 0, "Порт для realtime профилирования (0 = отключено)")
+	allowBadTime := flag.Bool("allow-bad-time", false, "Разрешить файлы, где у всех свечей одинаковое время (назначить синтетические метки)")
+	zeroTime := flag.String("zero-time", "drop", "Свечи без времени: drop (удалить, количество выводится в лог) или interpolate (время по соседним свечам с шагом основного интервала)")
+	failOnGaps := flag.Bool("fail-on-gaps", false, "Завершаться с ошибкой при пропусках свечей длиннее 1.5 интервала (включая ночи и выходные), повторах и пустом времени")
+	oneline := flag.Bool("oneline", false, "Вывести по одной строке name=...;profit=...;trades=...;sharpe=... на стратегию (без таблиц и Markdown)")
+	periodsPerYear := flag.Float64("periods-per-year", 0, "Число баров в году для аннуализации метрик (0 = определить по интервалу свечей)")
+	market := flag.String("market", "", "Рынок для аннуализации: crypto (24/7) или equity (торговые сессии); пусто = календарное время")
+	executionDelay := flag.Int("exec-delay", 0, "Исполнять сделку через N баров после сигнала (0 = на баре сигнала)")
+	fill := flag.String("fill", "close", "Цена исполнения на баре исполнения: close, open, vwap или worst")
+	returnMode := flag.String("return-mode", "compound", "Учет доходности: compound (прибыль реинвестируется) или fixed (каждая сделка на начальный капитал, результаты суммируются)")
+	fillModel := flag.String("fill-model", "close", "Модель исполнения: close (закрытие бара сигнала), next-open (открытие следующего бара) или worst (High/Low следующего бара)")
+	breakerTrip := flag.Float64("breaker-trip", 0, "Просадка от пика (0..1), после которой не открываются новые позиции (0 = выключено)")
+	breakerReset := flag.Float64("breaker-reset", 0.5, "Доля отыгранной просадки (0..1), после которой входы снова разрешены")
+	monteCarlo := flag.Int("monte_carlo", 0, "Число перевыборок сделок (бутстреп) для доверительных полос 5–95% итога и просадки одиночной стратегии, например 1000 (0 = выключено)")
+	holdout := flag.Float64("holdout", 0, "Доля последних свечей, отложенная от оптимизации, например 0.2: параметры подбираются на первых 80%, прибыль на хвосте выводится отдельно (0 = выключено)")
+	cvFolds := flag.Int("cv-folds", 0, "K-fold кросс-валидация: оптимизация на k-1 непрерывных фолдах и проверка на отложенном (0 = выключено)")
+	timeframes := flag.String("timeframes", "", "Проверка стабильности стратегии на таймфреймах через запятую, например 30m,1h,4h,1d (пусто = отключено)")
+	slippagePercent := flag.Float64("slippage-pct", 0, "Проскальзывание в долях цены, например 0.0005 = 0.05% (добавляется к абсолютному)")
+	commission := flag.Float64("commission", 0, "Комиссия на каждую сторону: доля от суммы сделки (0.0005 = 0.05%) для percent или сумма за сделку для fixed")
+	commissionType := flag.String("commission-type", "percent", "Модель комиссии: percent (доля от суммы), fixed (сумма за сделку) или tiered (ставка по ступеням --commission-tiers)")
+	commissionTiers := flag.String("commission-tiers", "", "Ступени комиссии для --commission-type tiered: сумма:ставка через запятую, например 0:0.001,100000:0.0005")
+	sizing := flag.String("sizing", "fractional", "Размер позиции: fractional (дробное число бумаг), whole (целые бумаги, остаток остается деньгами) или fixed-fraction (доля капитала --risk-fraction на вход)")
+	riskFraction := flag.Float64("risk-fraction", 0, "Доля капитала счета на один вход при --sizing fixed-fraction, например 0.25 = 25%")
+	capital := flag.Float64("capital", internal.DefaultInitialCapital, "Начальный капитал счета для всех стратегий и Buy & Hold")
+	mfeMae := flag.Bool("mfe-mae", false, "Считать для каждой сделки лучшее и худшее движение цены (MFE/MAE) по High/Low и вывести средние в Markdown-отчет")
+	forceClose := flag.Bool("force-close", false, "Закрывать открытую позицию по последней цене с учетом издержек")
+	realistic := flag.Bool("realistic", false, "Пресет реалистичных условий: проскальзывание, комиссия, исполнение на следующем баре и закрытие в конце")
+	minHoldBars := flag.Int("min-hold", 0, "Минимальное удержание позиции в барах для всех стратегий: более ранний выход по сигналу отклоняется, стоп-лосс и тейк-профит не ограничиваются (0 = выключено)")
+	cooldownBars := flag.Int("cooldown", 0, "Пауза в барах после закрытия позиции, в течение которой новый вход отклоняется (0 = выключено)")
+	minTradeMove := flag.Float64("min-move", 0, "Минимальное движение цены от входа в долях для выхода из позиции, например 2× slippage-pct (0 = выключено)")
+	profitFloor := flag.Float64("profit-floor", 0, "Порог чистой доходности сделки в долях: в сводке считаются сделки ниже порога")
+	explain := flag.Bool("explain", false, "Для одиночной стратегии вывести по барам, какое условие заблокировало сигнал (qstick_oscillator_v2, predictive_spline_v2)")
+	forward := flag.Bool("forward", false, "Форвард-проверка: прогнать конфигурации из --config на новых данных и отметить стратегии, которые перестали зарабатывать")
+	calibration := flag.Bool("calibration", false, "Для одиночной V2-стратегии с предсказанием (golden_cross_v2, cci_oscillator_v2, elliott_wave_v2, predictive_linear_spline_v2) сверить заявленную уверенность предсказаний с ценами на истории")
+	calibHorizon := flag.Int("calibration-horizon", 5, "Через сколько баров после предсказанного сигнала проверять направление цены для --calibration")
+	calibBins := flag.Int("calibration-bins", 5, "Число интервалов уверенности в таблице --calibration")
+	session := flag.String("session", "", "Торговая сессия ЧЧ:ММ-ЧЧ:ММ, например 10:00-18:45: сигналы на свечах вне нее заменяются на HOLD, SELL переносится на начало сессии (пусто = без ограничения)")
+	sessionTZ := flag.String("session-tz", "", "Часовой пояс времени --session, например Europe/Moscow (пусто = пояс времени свечей)")
+	trendGate := flag.Float64("trend-gate", 0, "Порог ADX: трендовые стратегии не открывают позиции во флэте, пока ADX ниже порога (0 = выключено)")
+	saveConfigs := flag.String("save-configs", "combined", "Сохранение оптимизированных конфигураций через запятую: combined (общий файл), split (файл на стратегию), top:N[:profit|sharpe] (только N лучших)")
+	recencyHalfLife := flag.Int("recency-half-life", 0, "Период полураспада (в барах) веса доходностей в целевой функции оптимизации (0 = все бары равноценны)")
+	dumpSearch := flag.String("dump-search", "", "Сохранить в CSV все проверенные оптимизатором конфигурации с метриками (только для одной стратегии)")
+	wfWindow := flag.Int("wf-window", 0, "Walk-forward оптимизация стратегий V2: окно подбора параметров в свечах (0 = подбор по всем свечам)")
+	wfStep := flag.Int("wf-step", 0, "Walk-forward: проверочный отрезок и сдвиг окна в свечах (0 = четверть окна)")
+	maxConfigs := flag.Int("max-configs", 0, "Максимум конфигураций сетки на стратегию V2; больше — случайная подвыборка (0 = все)")
+	gridSeed := flag.Int64("grid-seed", internal.DefaultGridSeed, "Зерно случайной подвыборки конфигураций для --max-configs")
+	objective := flag.String("objective", string(internal.ObjectiveProfit), "Целевая функция оптимизации: profit (прибыль) или upi (прибыль / Ulcer Index кривой капитала)")
+	bars := flag.String("bars", "time", "Нарезка баров: time (исходные свечи), volume:N (равный объем) или dollar:N (равный оборот)")
+	priceSource := flag.String("price-source", "close", "Цена свечи для ценового ряда стратегий: close, open, hl2, hlc3 или ohlc4")
+	kelly := flag.Float64("kelly", 0, "Размер позиции по дробному Келли: множитель к f* по статистике сделок, например 0.5 = половина Келли (0 = выключено)")
+	kellyCap := flag.Float64("kelly-cap", 0.5, "Максимальная доля капитала на сделку при размере по Келли")
+	allowShorts := flag.Bool("allow-shorts", false, "Короткие позиции: SELL без позиции открывает шорт, следующий BUY его закрывает")
+	stopLoss := flag.Float64("stop-loss", 0, "Стоп-лосс движка в долях от цены входа, например 0.03 = 3%: позиция закрывается между сигналами стратегии (0 = выключено)")
+	takeProfit := flag.Float64("take-profit", 0, "Тейк-профит движка в долях от цены входа, например 0.05 = 5% (0 = выключено)")
+	trailingStop := flag.Bool("trailing-stop", false, "Стоп-лосс отсчитывается от лучшей цены с момента входа, а не от цены входа (нужен --stop-loss)")
+	scaleIn := flag.Int("scale-in", 0, "Докупка по повторным BUY: до N входов в позицию равными частями капитала по средневзвешенной цене (0 = выключено)")
+	bnhAnchor := flag.String("bnh-anchor", backtester.BenchmarkAnchorFirst, "Покупка Buy & Hold: first (первая свеча) или trade (бар первого входа стратегии, без форы за прогрев)")
+	regimeFilter := flag.String("regime-filter", "", "Не открывать позиции в режиме волатильности: low, normal или high (пусто = выключено)")
+	regimeWindow := flag.Int("regime-window", internal.DefaultRegimeWindow, "Окно доходностей для определения режима волатильности")
+	output := flag.String("output", "combined", "Куда выводить итоги: combined (консоль + Markdown), console, markdown, json (файл results_<время>.json) или json:- (JSON в stdout, база для --compare-to)")
+	format := flag.String("format", "table", "Формат итоговой таблицы: table (вывод по --output) или csv (в stdout, для Excel/Sheets); json устарел — используйте --output=json:-")
+	nonFinite := flag.String("non-finite", "na", "Стратегии с NaN/Inf в результате: na (показать N/A в конце таблицы) или fail (код выхода 1)")
+	compareTo := flag.String("compare-to", "", "JSON-отчет прошлого прогона (--output=json или json:-): вывести изменения по стратегиям и завершиться с кодом 1 при регрессии")
+	regressionTolerance := flag.Float64("regression-tolerance", 0.01, "Допустимое падение доходности стратегии относительно --compare-to в долях, например 0.01 = 1 п.п.")
+	normTrades := flag.Int("norm-trades", backtester.DefaultReferenceTrades, "Эталонное число сделок для нормированной прибыли в таблице эффективности отчета")
+	logLevel := flag.String("log-level", "", "Уровень логов стратегий: error, warn, info или debug (по умолчанию warn, с --debug — debug)")
+	stats := flag.Bool("stats", false, "Вывести статистику загруженных свечей (период, интервал, цены, объем, волатильность, пропуски) перед запуском стратегий")
+	statsOnly := flag.Bool("stats-only", false, "Вывести статистику загруженных свечей и выйти, не запуская стратегии")
+	selfCheck := flag.Bool("selfcheck", false, "Прогнать все стратегии на детерминированных синтетических свечах; код выхода 1 при панике или NaN/Inf")
 	flag.Parse()
 
 	return backtester.Config{
-		Filename:    *filename,
-		Strategy:    *strategyName,
-		Debug:       *debug,
-		SaveSignals: *saveSignals,
-		CpuProfile:  *cpuProfile,
-		MemProfile:  *memProfile,
-		ConfigFile:  *configFile,
-		ProfPort:    *profPort,
+		Filename:        *filename,
+		Dir:             *dir,
+		Source:          *source,
+		Strategy:        *strategyName,
+		Debug:           *debug,
+		SaveSignals:     *saveSignals,
+		SaveEquity:      *saveEquity,
+		OutDir:          *outDir,
+		CpuProfile:      *cpuProfile,
+		MemProfile:      *memProfile,
+		ConfigFile:      *configFile,
+		ProfPort:        *profPort,
+		AllowBadTime:    *allowBadTime,
+		ZeroTime:        *zeroTime,
+		FailOnGaps:      *failOnGaps,
+		Oneline:         *oneline,
+		PeriodsPerYear:  *periodsPerYear,
+		Market:          *market,
+		ExecutionDelay:  *executionDelay,
+		Fill:            *fill,
+		FillModel:       *fillModel,
+		ReturnMode:      *returnMode,
+		BreakerTrip:     *breakerTrip,
+		BreakerReset:    *breakerReset,
+		Timeframes:      *timeframes,
+		SlippagePercent: *slippagePercent,
+		Commission:      *commission,
+		CommissionType:  *commissionType,
+		CommissionTiers: *commissionTiers,
+		ForceClose:      *forceClose,
+		TrackExcursions: *mfeMae,
+		InitialCapital:  *capital,
+		PositionSizing:  *sizing,
+		RiskFraction:    *riskFraction,
+		Realistic:       *realistic,
+		MinTradeMove:    *minTradeMove,
+		MinHoldBars:     *minHoldBars,
+		CooldownBars:    *cooldownBars,
+		ProfitFloor:     *profitFloor,
+		Explain:         *explain,
+		Forward:         *forward,
+		Calibration:     *calibration,
+		CalibHorizon:    *calibHorizon,
+		CalibBins:       *calibBins,
+		PriceSource:     *priceSource,
+		Bars:            *bars,
+		Objective:       *objective,
+		MaxConfigs:      *maxConfigs,
+		WFWindow:        *wfWindow,
+		WFStep:          *wfStep,
+		DumpSearch:      *dumpSearch,
+		RecencyHalfLife: *recencyHalfLife,
+		GridSeed:        *gridSeed,
+		SaveConfigs:     *saveConfigs,
+		TrendGate:       *trendGate,
+		Session:         *session,
+		SessionTZ:       *sessionTZ,
+		KellyMultiplier: *kelly,
+		KellyCap:        *kellyCap,
+		ScaleIn:         *scaleIn,
+		AllowShorts:     *allowShorts,
+		StopLoss:        *stopLoss,
+		TakeProfit:      *takeProfit,
+		TrailingStop:    *trailingStop,
+		RegimeFilter:    *regimeFilter,
+		BenchmarkAnchor: *bnhAnchor,
+		RegimeWindow:    *regimeWindow,
+		Format:          *format,
+		Output:          *output,
+		SelfCheck:       *selfCheck,
+		Stats:           *stats,
+		StatsOnly:       *statsOnly,
+		ReferenceTrades: *normTrades,
+		LogLevel:        *logLevel,
+		CompareTo:       *compareTo,
+		MaxRegression:   *regressionTolerance,
+		NonFinitePolicy: *nonFinite,
+		MemStats:        *memStats,
+		StrategyTimeout: *strategyTimeout,
+		Workers:         *workers,
+		CVFolds:         *cvFolds,
+		Holdout:         *holdout,
+		MonteCarlo:      *monteCarlo,
+	}
+}
+
+// createEngineOptions — собирает параметры движка бэктеста из флагов командной строки
+func createEngineOptions(config backtester.Config) (internal.BacktestOptions, error) {
+	market, err := internal.ParseMarket(config.Market)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	fill, err := internal.ParseFillPrice(config.Fill)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	returnMode, err := internal.ParseReturnMode(config.ReturnMode)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	fillModel, err := internal.ParseFillModel(config.FillModel)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	if fillModel != internal.FillModelClose && fill != internal.FillClose {
+		return internal.BacktestOptions{}, fmt.Errorf("--fill-model %s нельзя сочетать с --fill %s", fillModel, fill)
+	}
+	if config.ExecutionDelay < 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--exec-delay не может быть отрицательным, получено %d", config.ExecutionDelay)
+	}
+	if config.BreakerTrip < 0 || config.BreakerTrip >= 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--breaker-trip должен быть в диапазоне [0, 1), получено %.2f", config.BreakerTrip)
+	}
+	if config.BreakerReset < 0 || config.BreakerReset > 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--breaker-reset должен быть в диапазоне [0, 1], получено %.2f", config.BreakerReset)
+	}
+	if config.PeriodsPerYear < 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--periods-per-year должен быть положительным, получено %.2f", config.PeriodsPerYear)
+	}
+	if config.SlippagePercent < 0 || config.SlippagePercent >= 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--slippage-pct должен быть в диапазоне [0, 1), получено %.4f", config.SlippagePercent)
+	}
+	commission, commissionModel, err := parseCommission(config)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	if config.KellyMultiplier < 0 || config.KellyMultiplier > 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--kelly должен быть в диапазоне [0, 1], получено %.2f", config.KellyMultiplier)
+	}
+	if config.KellyCap <= 0 || config.KellyCap > 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--kelly-cap должен быть в диапазоне (0, 1], получено %.2f", config.KellyCap)
+	}
+	if config.MinTradeMove < 0 || config.MinTradeMove >= 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--min-move должен быть в диапазоне [0, 1), получено %.4f", config.MinTradeMove)
+	}
+	if config.MinHoldBars < 0 || config.CooldownBars < 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--min-hold и --cooldown не могут быть отрицательными, получено %d и %d", config.MinHoldBars, config.CooldownBars)
+	}
+	if config.ScaleIn < 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--scale-in не может быть отрицательным, получено %d", config.ScaleIn)
+	}
+	if config.InitialCapital <= 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--capital должен быть положительным, получено %.2f", config.InitialCapital)
+	}
+	positionSizing, err := internal.ParsePositionSizing(config.PositionSizing)
+	if err != nil {
+		return internal.BacktestOptions{}, err
+	}
+	if positionSizing == internal.SizingFixedFraction {
+		if config.RiskFraction <= 0 || config.RiskFraction > 1 {
+			return internal.BacktestOptions{}, fmt.Errorf("--risk-fraction должен быть в диапазоне (0, 1], получено %.2f", config.RiskFraction)
+		}
+		if config.KellyMultiplier > 0 {
+			return internal.BacktestOptions{}, fmt.Errorf("--sizing fixed-fraction нельзя сочетать с --kelly: оба задают долю капитала на сделку")
+		}
+	} else if config.RiskFraction != 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--risk-fraction действует только с --sizing fixed-fraction")
+	}
+	if config.StopLoss < 0 || config.StopLoss >= 1 {
+		return internal.BacktestOptions{}, fmt.Errorf("--stop-loss должен быть в диапазоне [0, 1), получено %.4f", config.StopLoss)
+	}
+	if config.TakeProfit < 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--take-profit не может быть отрицательным, получено %.4f", config.TakeProfit)
+	}
+	if config.TrailingStop && config.StopLoss == 0 {
+		return internal.BacktestOptions{}, fmt.Errorf("--trailing-stop задает способ отсчета стоп-лосса: укажите --stop-loss")
+	}
+	regimeFilter := ""
+	if config.RegimeFilter != "" {
+		if regimeFilter, err = internal.ParseVolatilityRegime(config.RegimeFilter); err != nil {
+			return internal.BacktestOptions{}, err
+		}
+		if config.RegimeWindow < 2 {
+			return internal.BacktestOptions{}, fmt.Errorf("--regime-window должен быть не меньше 2, получено %d", config.RegimeWindow)
+		}
+	}
+
+	opts := internal.BacktestOptions{
+		InitialCapital:  config.InitialCapital,
+		PositionSizing:  positionSizing,
+		RiskFraction:    config.RiskFraction,
+		PeriodsPerYear:  config.PeriodsPerYear,
+		Market:          market,
+		ExecutionDelay:  config.ExecutionDelay,
+		Fill:            fill,
+		BreakerTrip:     config.BreakerTrip,
+		BreakerReset:    config.BreakerReset,
+		SlippagePercent: config.SlippagePercent,
+		Commission:      commission,
+		CommissionModel: commissionModel,
+		ForceClose:      config.ForceClose,
+		TrackExcursions: config.TrackExcursions,
+		MinTradeMove:    config.MinTradeMove,
+		MinHoldBars:     config.MinHoldBars,
+		CooldownBars:    config.CooldownBars,
+		ProfitFloor:     config.ProfitFloor,
+		KellyMultiplier: config.KellyMultiplier,
+		KellyCap:        config.KellyCap,
+		ScaleIn:         config.ScaleIn,
+		AllowShorts:     config.AllowShorts,
+		RegimeFilter:    regimeFilter,
+		RegimeWindow:    config.RegimeWindow,
+		ReturnMode:      returnMode,
+		Exits: internal.ExitRules{
+			StopLossPercent:   config.StopLoss,
+			TakeProfitPercent: config.TakeProfit,
+			TrailingStop:      config.TrailingStop,
+		},
+	}
+	opts = fillModel.Apply(opts)
+	if config.Realistic {
+		applyRealisticPreset(&opts)
+	}
+	return opts, nil
+}
+
+// parseCommission — комиссия из --commission-type: percent учитывается в цене сделки (Commission),
+// fixed и tiered списываются со счета моделью комиссии
+func parseCommission(config backtester.Config) (float64, internal.CommissionModel, error) {
+	if config.CommissionTiers != "" && config.CommissionType != "tiered" {
+		return 0, nil, fmt.Errorf("--commission-tiers работает только с --commission-type tiered")
+	}
+	switch config.CommissionType {
+	case "", "percent":
+		if config.Commission < 0 || config.Commission >= 1 {
+			return 0, nil, fmt.Errorf("--commission должен быть в диапазоне [0, 1), получено %.4f", config.Commission)
+		}
+		return config.Commission, nil, nil
+	case "fixed":
+		if config.Commission < 0 {
+			return 0, nil, fmt.Errorf("--commission не может быть отрицательным, получено %.4f", config.Commission)
+		}
+		if config.Commission == 0 {
+			return 0, nil, nil
+		}
+		return 0, internal.FixedCommission{PerTrade: config.Commission}, nil
+	case "tiered":
+		if config.Commission != 0 {
+			return 0, nil, fmt.Errorf("при --commission-type tiered ставки задаются в --commission-tiers, а не в --commission")
+		}
+		tiers, err := internal.ParseCommissionTiers(config.CommissionTiers)
+		if err != nil {
+			return 0, nil, err
+		}
+		return 0, tiers, nil
+	default:
+		return 0, nil, fmt.Errorf("неизвестная модель комиссии '%s' (ожидается percent, fixed или tiered)", config.CommissionType)
+	}
+}
+
+// Значения пресета --realistic: типичные издержки розничного брокера на ликвидных инструментах
+const (
+	realisticSlippagePercent = 0.0005 // 0.05% от цены
+	realisticCommission      = 0.0005 // 0.05% от суммы сделки на каждую сторону
+	realisticExecutionDelay  = 1      // сделка на открытии следующего бара
+)
+
+// applyRealisticPreset — заполняет значениями пресета параметры, оставленные по умолчанию, и выводит примененные
+func applyRealisticPreset(opts *internal.BacktestOptions) {
+	var applied []string
+	if opts.SlippagePercent == 0 {
+		opts.SlippagePercent = realisticSlippagePercent
+		applied = append(applied, fmt.Sprintf("проскальзывание %.2f%%", realisticSlippagePercent*100))
+	}
+	if opts.Commission == 0 && opts.CommissionModel == nil {
+		opts.Commission = realisticCommission
+		applied = append(applied, fmt.Sprintf("комиссия %.2f%% на сторону", realisticCommission*100))
+	}
+	if opts.ExecutionDelay == 0 {
+		opts.ExecutionDelay = realisticExecutionDelay
+		applied = append(applied, fmt.Sprintf("исполнение через %d бар", realisticExecutionDelay))
+	}
+	if opts.Fill == internal.FillClose {
+		opts.Fill = internal.FillOpen
+		applied = append(applied, "цена исполнения open")
+	}
+	if !opts.ForceClose {
+		opts.ForceClose = true
+		applied = append(applied, "закрытие позиции в конце периода")
+	}
+
+	if len(applied) == 0 {
+		log.Println("🧾 Реалистичный режим: все параметры заданы явно, пресет ничего не изменил")
+		return
+	}
+	log.Printf("🧾 Реалистичный режим: %s", strings.Join(applied, ", "))
+}
+
+// jsonStdoutOutput — значение --output для JSON-отчета в stdout (--output=json сохраняет его в файл)
+const jsonStdoutOutput = "json:-"
+
+// createPrinter — создает принтер результатов в зависимости от режима вывода
+func createPrinter(config backtester.Config) (backtester.ResultPrinter, error) {
+	if config.ReferenceTrades <= 0 {
+		return nil, fmt.Errorf("--norm-trades должен быть положительным, получено %d", config.ReferenceTrades)
+	}
+
+	// --format=json — прежний способ вывести JSON в stdout, теперь это --output=json:-
+	if config.Format == "json" {
+		if config.Output != "" && config.Output != "combined" {
+			return nil, fmt.Errorf("--output=%s и --format=json нельзя использовать вместе", config.Output)
+		}
+		log.Println("⚠️ --format=json устарел, используйте --output=json:-")
+		config.Format, config.Output = "table", jsonStdoutOutput
+	}
+
+	switch config.Format {
+	case "", "table":
+	case "csv":
+		if config.Oneline {
+			return nil, fmt.Errorf("--oneline и --format=csv нельзя использовать вместе")
+		}
+		if config.Output != "" && config.Output != "combined" {
+			return nil, fmt.Errorf("--output=%s и --format=csv нельзя использовать вместе", config.Output)
+		}
+		// Как и в --oneline: служебный вывод в stderr, в stdout только отчет
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		return backtester.NewCSVPrinter(stdout), nil
+	default:
+		return nil, fmt.Errorf("неизвестный формат вывода '%s' (ожидается table или csv)", config.Format)
+	}
+
+	if config.Oneline {
+		if config.Output != "" && config.Output != "combined" {
+			return nil, fmt.Errorf("--oneline и --output=%s нельзя использовать вместе", config.Output)
+		}
+		// Весь служебный вывод уходит в stderr, в stdout остаются только итоговые строки
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		return backtester.NewOneLinePrinter(stdout), nil
+	}
+
+	switch config.Output {
+	case "", "combined":
+		return backtester.NewCombinedPrinterWithConfig(config), nil // Используем комбинированный принтер для автоматической генерации MD отчетов
+	case "console":
+		return backtester.NewConsolePrinter(), nil
+	case "markdown":
+		return backtester.NewMarkdownPrinterWithConfig(config), nil
+	case jsonStdoutOutput:
+		// Как и в --oneline: служебный вывод в stderr, в stdout только отчет
+		stdout := os.Stdout
+		os.Stdout = os.Stderr
+		return backtester.NewJSONPrinter(stdout), nil
+	case "json":
+		return backtester.NewJSONFilePrinter(config.OutDir), nil
+	default:
+		return nil, fmt.Errorf("неизвестный вывод '%s' (ожидается combined, console, markdown, json или json:-)", config.Output)
 	}
 }
 
 // createRunner — создает подходящий runner в зависимости от стратегии
 func createRunner(config backtester.Config, printer backtester.ResultPrinter) backtester.StrategyRunner {
 	if config.Strategy == "all" {
-		if config.ConfigFile != "" {
-			return backtester.NewParallelStrategyRunnerWithConfig(config.Debug, printer, config)
-		}
-		return backtester.NewParallelStrategyRunnerWithPrinter(config.Debug, printer)
+		// Конфигурация нужна раннеру и без файла конфигураций (например, для --mem-stats)
+		return backtester.NewParallelStrategyRunnerWithConfig(config.Debug, printer, config)
 	}
-	if config.ConfigFile != "" {
-		return backtester.NewSingleStrategyRunnerWithConfig(config.Debug, config)
-	}
-	return backtester.NewSingleStrategyRunner(config.Debug)
+	return backtester.NewSingleStrategyRunnerWithConfig(config.Debug, config)
 }
 
 // runStrategies — запускает стратегии с помощью runner
-func runStrategies(config backtester.Config, runner backtester.StrategyRunner, candles []internal.Candle) ([]backtester.BenchmarkResult, error) {
+func runStrategies(config backtester.Config, runner backtester.StrategyRunner, printer backtester.ResultPrinter, candles []internal.Candle) ([]backtester.BenchmarkResult, error) {
 	if config.Strategy == "all" {
 		return runner.RunAllStrategies(candles)
 	}
@@ -207,37 +1011,16 @@ func runStrategies(config backtester.Config, runner backtester.StrategyRunner, c
 		return nil, err
 	}
 
-	// Добавляем Buy & Hold как бенчмарк
-	bnhStrategy := internal.GetStrategy("buy_and_hold")
-	var bnhResult internal.BacktestResult
-
-	bnhConfig := bnhStrategy.DefaultConfig()
-	bnhSignals := bnhStrategy.GenerateSignalsWithConfig(candles, bnhConfig)
-
-	// Получаем значение проскальзывания из runner
-	slipping := 0.01
-	if parallelRunner, ok := runner.(*backtester.ParallelStrategyRunner); ok {
-		slipping = parallelRunner.GetSlipping()
-	} else if singleRunner, ok := runner.(*backtester.SingleStrategyRunner); ok {
-		slipping = singleRunner.GetSlipping()
+	// Добавляем Buy & Hold как бенчмарк; с --bnh-anchor=trade он покупает на баре первого входа стратегии
+	anchor := 0
+	if config.BenchmarkAnchor == backtester.BenchmarkAnchorTrade && mainResult.FirstEntryIndex > 0 {
+		anchor = mainResult.FirstEntryIndex
 	}
+	bnhResult := runner.BuyAndHold(candles, anchor)
 
-	bnhResult = internal.Backtest(candles, bnhSignals, slipping)
-
-	results := []backtester.BenchmarkResult{
-		*mainResult,
-		{
-			Name:           bnhStrategy.Name(),
-			TotalProfit:    bnhResult.TotalProfit,
-			TradeCount:     bnhResult.TradeCount,
-			FinalPortfolio: bnhResult.FinalPortfolio,
-			ExecutionTime:  mainResult.ExecutionTime, // Используем то же время для простоты
-			NextSignal:     nil,                      // Buy & Hold не предсказывает сигналы
-		},
-	}
+	results := []backtester.BenchmarkResult{*mainResult, bnhResult}
 
 	// Выводим результаты через принтер для одиночной стратегии
-	printer := backtester.NewCombinedPrinter()
 	printer.PrintComparison(results)
 
 	return results, nil
@@ -0,0 +1,185 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bt/internal"
+	"bt/internal/app/backtester"
+)
+
+func TestRegisteredStrategies_HaveDeclaredCategory(t *testing.T) {
+	// Пакет main импортирует все стратегии, поэтому здесь доступны оба реестра
+	for _, name := range internal.GetStrategyNames() {
+		strategy := internal.GetStrategy(name)
+		categorized, ok := strategy.(internal.CategorizedStrategy)
+		if !ok || categorized.Category() == "" {
+			t.Errorf("V1 strategy %s does not declare a category", name)
+			continue
+		}
+		if category := internal.GetStrategyCategory(name); category == internal.FallbackCategory {
+			t.Errorf("V1 strategy %s falls back to %q", name, category)
+		}
+	}
+
+	for _, name := range internal.GetStrategyNamesV2() {
+		strategy, _ := internal.GetStrategyV2(name)
+		categorized, ok := strategy.(internal.CategorizedStrategy)
+		if !ok || categorized.Category() == "" {
+			t.Errorf("V2 strategy %s does not declare a category", name)
+			continue
+		}
+		if category := internal.GetStrategyCategory(name); category == internal.FallbackCategory {
+			t.Errorf("V2 strategy %s falls back to %q", name, category)
+		}
+	}
+}
+
+func TestRegisteredStrategies_AreDeterministic(t *testing.T) {
+	candles := internal.GenerateSyntheticCandles(600, 1)
+	names := append(internal.GetStrategyNames(), internal.GetStrategyNamesV2()...)
+	for _, name := range names {
+		if err := internal.AssertDeterministic(name, candles); err != nil {
+			t.Error(err)
+		}
+	}
+}
+
+// knownLookAhead — стратегии, чьи сигналы зависят от будущих свечей (internal.CheckNoLookAhead):
+// сплайны и экстремумы строятся по всей истории, monthly_rebalance смотрит на свечи следующего месяца,
+// elliott_wave_v2 переразмечает волны задним числом. Их результаты бэктеста завышены
+var knownLookAhead = map[string]bool{
+	"linear_alternating_spline":       true,
+	"quadratic_variable_trend_spline": true,
+	"optimal_extrema_strategy":        true,
+	"monthly_rebalance":               true,
+	"linear_spline_v2":                true,
+	"elliott_wave_v2":                 true,
+}
+
+// unverifiedLookAhead — стратегии без единого BUY/SELL на ряде проверки с конфигурацией по умолчанию:
+// CheckNoLookAhead для них ничего не доказывает (internal.ErrNoSignals)
+var unverifiedLookAhead = map[string]bool{
+	"extrema_strategy": true,
+}
+
+func TestRegisteredStrategies_NoLookAhead(t *testing.T) {
+	// Длинный и резкий ряд, чтобы сделки совершали и стратегии с высокими порогами входа
+	candles := internal.GenerateSyntheticCandlesWithVolatility(1000, 1, 0.015)
+	names := append(internal.GetStrategyNames(), internal.GetStrategyNamesV2()...)
+	for _, name := range names {
+		err := internal.CheckNoLookAhead(name, candles)
+		idle := errors.Is(err, internal.ErrNoSignals)
+		switch {
+		case idle && !unverifiedLookAhead[name]:
+			t.Errorf("%v; add it to unverifiedLookAhead or make the harness series trigger it", err)
+		case !idle && unverifiedLookAhead[name]:
+			t.Errorf("%s now trades on the harness series, remove it from unverifiedLookAhead", name)
+		case idle:
+		case err != nil && !knownLookAhead[name]:
+			t.Error(err)
+		case err == nil && knownLookAhead[name]:
+			t.Errorf("%s no longer looks ahead, remove it from knownLookAhead", name)
+		}
+	}
+}
+
+func TestRunAllStrategies_SingleWorkerProducesAllResults(t *testing.T) {
+	// Оптимизированные конфигурации сохраняются в текущий каталог
+	t.Chdir(t.TempDir())
+
+	candles := internal.GenerateSyntheticCandles(300, 1)
+	runner := backtester.NewParallelStrategyRunnerWithConfig(false, nil, backtester.Config{Workers: 1})
+	results, err := runner.RunAllStrategies(candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Имя в результате — Name() стратегии, оно не всегда совпадает с ключом реестра, поэтому сверяется число
+	total := len(internal.GetStrategyNames()) + len(internal.GetStrategyNamesV2())
+	if len(results) != total {
+		t.Fatalf("Expected %d results with one worker, got %d", total, len(results))
+	}
+}
+
+func TestEnsureCandleTimes_EmptyTimeFile(t *testing.T) {
+	// Файл, где у всех свечей пустое время
+	data := `{"candles": [
+		{"open": {"units": "100", "nano": 0}, "high": {"units": "101", "nano": 0}, "low": {"units": "99", "nano": 0}, "close": {"units": "100", "nano": 0}, "volume": "10", "time": ""},
+		{"open": {"units": "100", "nano": 0}, "high": {"units": "102", "nano": 0}, "low": {"units": "99", "nano": 0}, "close": {"units": "101", "nano": 0}, "volume": "10", "time": ""},
+		{"open": {"units": "101", "nano": 0}, "high": {"units": "103", "nano": 0}, "low": {"units": "100", "nano": 0}, "close": {"units": "102", "nano": 0}, "volume": "10", "time": ""}
+	]}`
+	filename := filepath.Join(t.TempDir(), "empty_time.json")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	candles := LoadCandlesFromFile(filename)
+	if len(candles) != 3 {
+		t.Fatalf("Expected 3 candles, got %d", len(candles))
+	}
+
+	// Без флага загрузка должна быть отклонена
+	if err := ensureCandleTimes(candles, false); err == nil {
+		t.Error("Expected error for candles with identical empty time")
+	}
+
+	// С флагом свечам назначаются последовательные метки в исходном порядке
+	if err := ensureCandleTimes(candles, true); err != nil {
+		t.Fatalf("Unexpected error with allowBadTime: %v", err)
+	}
+	for i := 1; i < len(candles); i++ {
+		step := candles[i].ParsedTime.Sub(candles[i-1].ParsedTime)
+		if step != syntheticCandleInterval {
+			t.Errorf("Expected step %v between candles %d and %d, got %v", syntheticCandleInterval, i-1, i, step)
+		}
+	}
+	if candles[2].Close.ToFloat64() != 102 {
+		t.Errorf("Expected original order to be preserved, last close = %.2f", candles[2].Close.ToFloat64())
+	}
+}
+
+func TestEnsureCandleTimes_DistinctTimes(t *testing.T) {
+	base := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	candles := []internal.Candle{
+		{ParsedTime: base},
+		{ParsedTime: base.Add(time.Hour)},
+	}
+
+	if err := ensureCandleTimes(candles, false); err != nil {
+		t.Errorf("Unexpected error for distinct times: %v", err)
+	}
+	if !candles[1].ParsedTime.Equal(base.Add(time.Hour)) {
+		t.Error("Distinct times must not be rewritten")
+	}
+}
+
+func TestCreatePrinter_JSONThroughOutput(t *testing.T) {
+	stdout := os.Stdout
+	defer func() { os.Stdout = stdout }()
+
+	for _, config := range []backtester.Config{
+		{ReferenceTrades: 1, Output: "json:-"},
+		{ReferenceTrades: 1, Format: "json"}, // устаревший синоним
+	} {
+		printer, err := createPrinter(config)
+		if _, ok := printer.(*backtester.JSONPrinter); err != nil || !ok {
+			t.Errorf("%+v: expected JSON printer, got %T, err %v", config, printer, err)
+		}
+		os.Stdout = stdout
+	}
+
+	for _, config := range []backtester.Config{
+		{ReferenceTrades: 1, Format: "json", Output: "markdown"},
+		{ReferenceTrades: 1, Format: "xml"},
+		{ReferenceTrades: 1, Oneline: true, Output: "json:-"},
+	} {
+		if _, err := createPrinter(config); err == nil {
+			t.Errorf("%+v: expected an error", config)
+		}
+		os.Stdout = stdout
+	}
+}
@@ -0,0 +1,29 @@
+// selfcheck.go — самопроверка всех стратегий на синтетических данных
+package main
+
+import (
+	"log"
+	"os"
+
+	"bt/internal"
+	"bt/internal/app/backtester"
+)
+
+const (
+	selfCheckCandles = 600 // несколько смен режима, достаточно для самых длинных периодов
+	selfCheckSeed    = 42
+)
+
+// runSelfCheck — генерирует синтетические свечи, прогоняет на них все стратегии и завершает процесс
+// с кодом 1, если хотя бы одна стратегия упала или вернула нечисловой результат
+func runSelfCheck() {
+	candles := internal.GenerateSyntheticCandles(selfCheckCandles, selfCheckSeed)
+	internal.ResetCache()
+
+	results := backtester.RunSelfCheck(candles)
+	if failed := backtester.SelfCheckFailures(results); failed > 0 {
+		log.Printf("❌ Самопроверка не пройдена: %d из %d стратегий с ошибками", failed, len(results))
+		os.Exit(1)
+	}
+	log.Printf("✅ Самопроверка пройдена: %d стратегий", len(results))
+}
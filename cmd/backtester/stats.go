@@ -0,0 +1,53 @@
+// stats.go — сводка по загруженным свечам перед запуском стратегий (--stats)
+package main
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"bt/internal"
+)
+
+// printCandleStats — выводит сводку по свечам; periodsPerYear нужен для годовой волатильности
+func printCandleStats(candles []internal.Candle, periodsPerYear float64) {
+	stats := internal.ComputeCandleStats(candles)
+
+	fmt.Println("\n" + strings.Repeat("═", 60))
+	fmt.Println("🔎 СТАТИСТИКА ДАННЫХ")
+	fmt.Println(strings.Repeat("═", 60))
+	fmt.Printf("🕯️ Свечей:               %d\n", stats.Count)
+	if !stats.First.IsZero() {
+		fmt.Printf("📅 Период:               %s — %s (%s)\n",
+			stats.First.Format("2006-01-02 15:04"), stats.Last.Format("2006-01-02 15:04"), formatStatsDuration(stats.Last.Sub(stats.First)))
+	}
+	if stats.Interval > 0 {
+		fmt.Printf("⏱️ Интервал:             %s (медиана)\n", formatStatsDuration(stats.Interval))
+	}
+	fmt.Printf("💰 Цена закрытия:        мин %.4f, макс %.4f, средняя %.4f\n", stats.MinPrice, stats.MaxPrice, stats.MeanPrice)
+	if stats.AvgDailyVolume > 0 {
+		fmt.Printf("📦 Средний объем за день: %.0f\n", stats.AvgDailyVolume)
+	}
+	fmt.Printf("🌊 Волатильность:        %.3f%% за бар, %.1f%% годовых\n",
+		stats.Volatility*100, stats.Volatility*math.Sqrt(periodsPerYear)*100)
+	if stats.Interval > 0 {
+		fmt.Printf("🕳️ Пропусков:            %d (интервал больше двух медианных), самый длинный %s\n",
+			stats.Gaps, formatStatsDuration(stats.MaxGap))
+	}
+	fmt.Println(strings.Repeat("═", 60))
+}
+
+// formatStatsDuration — длительность в днях и часах/минутах без лишних нулей
+func formatStatsDuration(d time.Duration) string {
+	days := int(d / (24 * time.Hour))
+	rest := d % (24 * time.Hour)
+	switch {
+	case days > 0 && rest == 0:
+		return fmt.Sprintf("%dд", days)
+	case days > 0:
+		return fmt.Sprintf("%dд %s", days, rest.Round(time.Minute))
+	default:
+		return d.String()
+	}
+}
@@ -0,0 +1,69 @@
+// timeframes.go — проверка устойчивости стратегии на нескольких таймфреймах одних и тех же данных
+package main
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"bt/internal"
+	"bt/internal/app/backtester"
+)
+
+// runTimeframeStability — агрегирует свечи в каждый из таймфреймов и сравнивает стратегию с Buy & Hold
+func runTimeframeStability(config backtester.Config, candles []internal.Candle) error {
+	if config.Strategy == "all" {
+		return fmt.Errorf("--timeframes требует конкретную стратегию (--strategy)")
+	}
+
+	timeframes, err := parseTimeframes(config.Timeframes)
+	if err != nil {
+		return err
+	}
+
+	runner := backtester.NewParallelStrategyRunnerWithConfig(config.Debug, nil, config)
+	results, err := backtester.RunTimeframeStability(runner, config.Strategy, candles, timeframes, runner.GetSlipping())
+	if err != nil {
+		return err
+	}
+
+	backtester.PrintTimeframeStability(config.Strategy, results)
+	return nil
+}
+
+// parseTimeframes — разбирает список таймфреймов; кроме формата time.ParseDuration поддерживаются дни ("1d")
+func parseTimeframes(s string) ([]time.Duration, error) {
+	var timeframes []time.Duration
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		var timeframe time.Duration
+		if days, ok := strings.CutSuffix(part, "d"); ok {
+			n, err := strconv.Atoi(days)
+			if err != nil {
+				return nil, fmt.Errorf("некорректный таймфрейм '%s': %w", part, err)
+			}
+			timeframe = time.Duration(n) * 24 * time.Hour
+		} else {
+			d, err := time.ParseDuration(part)
+			if err != nil {
+				return nil, fmt.Errorf("некорректный таймфрейм '%s': %w", part, err)
+			}
+			timeframe = d
+		}
+
+		if timeframe <= 0 {
+			return nil, fmt.Errorf("таймфрейм '%s' должен быть положительным", part)
+		}
+		timeframes = append(timeframes, timeframe)
+	}
+
+	if len(timeframes) == 0 {
+		return nil, fmt.Errorf("не задано ни одного таймфрейма")
+	}
+	return timeframes, nil
+}
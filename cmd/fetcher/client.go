@@ -0,0 +1,181 @@
+// client.go — запросы к Tinkoff API с ограничением частоты и повтором при временных сбоях
+package main
+
+import (
+	"bt/internal"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const maxBackoff = time.Minute // потолок паузы между повторами
+
+// sleepContext — пауза d, прерываемая отменой ctx (Ctrl+C)
+func sleepContext(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// rateLimiter — не больше заданного числа запросов в минуту: между запросами выдерживается равный интервал
+type rateLimiter struct {
+	mu       sync.Mutex
+	interval time.Duration
+	last     time.Time
+	sleep    func(context.Context, time.Duration) error
+}
+
+func newRateLimiter(requestsPerMinute int) *rateLimiter {
+	limiter := &rateLimiter{sleep: sleepContext}
+	if requestsPerMinute > 0 {
+		limiter.interval = time.Minute / time.Duration(requestsPerMinute)
+	}
+	return limiter
+}
+
+// wait — ждет, пока с предыдущего запроса не пройдет интервал; при отмене ctx возвращает ее ошибку
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if wait := l.interval - time.Since(l.last); !l.last.IsZero() && wait > 0 {
+		if err := l.sleep(ctx, wait); err != nil {
+			return err
+		}
+	}
+	l.last = time.Now()
+	return nil
+}
+
+// candleClient — клиент GetCandles: лимит частоты и экспоненциальный backoff с джиттером
+// на сетевых ошибках, 429 и 5xx
+type candleClient struct {
+	http     *http.Client
+	endpoint string
+	token    string
+	limiter  *rateLimiter
+	retries  int           // повторов после первой попытки
+	backoff  time.Duration // пауза перед первым повтором, дальше удваивается
+	sleep    func(context.Context, time.Duration) error
+}
+
+// apiResponse — ответ API, который не требует повтора: 200 или окончательная ошибка клиента
+type apiResponse struct {
+	status int
+	body   []byte
+}
+
+// fetch — запрашивает окно свечей; ошибка означает, что все попытки исчерпаны и окно нужно запросить снова позже,
+// или что сбор прерван отменой ctx — тогда повторы не выполняются
+func (c *candleClient) fetch(ctx context.Context, reqBody internal.RequestBody) (*apiResponse, error) {
+	jsonBody, err := json.Marshal(reqBody)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка сериализации запроса: %w", err)
+	}
+
+	var lastErr error
+	for attempt := 0; attempt <= c.retries; attempt++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		if attempt > 0 {
+			pause := c.backoffFor(attempt, lastErr)
+			log.Printf("🔁 Повтор %d/%d через %s: %v", attempt, c.retries, pause.Round(time.Millisecond), lastErr)
+			if err := c.sleep(ctx, pause); err != nil {
+				return nil, err
+			}
+		}
+
+		if err := c.limiter.wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := c.do(ctx, jsonBody)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		if resp.status == http.StatusTooManyRequests || resp.status >= 500 {
+			lastErr = &retryableStatusError{status: resp.status, body: resp.body, retryAfter: resp.retryAfter}
+			continue
+		}
+		return &apiResponse{status: resp.status, body: resp.body}, nil
+	}
+	return nil, fmt.Errorf("после %d попыток: %w", c.retries+1, lastErr)
+}
+
+// httpResult — ответ сервера вместе с заголовком Retry-After
+type httpResult struct {
+	status     int
+	body       []byte
+	retryAfter time.Duration
+}
+
+func (c *candleClient) do(ctx context.Context, jsonBody []byte) (*httpResult, error) {
+	req, err := http.NewRequestWithContext(ctx, "POST", c.endpoint, bytes.NewReader(jsonBody))
+	if err != nil {
+		return nil, fmt.Errorf("ошибка создания запроса: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	resp, err := c.http.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("HTTP ошибка: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения тела ответа: %w", err)
+	}
+
+	result := &httpResult{status: resp.StatusCode, body: body}
+	if seconds, err := strconv.Atoi(resp.Header.Get("Retry-After")); err == nil && seconds > 0 {
+		result.retryAfter = time.Duration(seconds) * time.Second
+	}
+	return result, nil
+}
+
+// backoffFor — backoff·2^(attempt-1) со случайным джиттером до половины паузы, но не меньше Retry-After;
+// нулевой backoff — повтор без паузы
+func (c *candleClient) backoffFor(attempt int, lastErr error) time.Duration {
+	var pause time.Duration
+	if c.backoff > 0 {
+		pause = c.backoff << (attempt - 1)
+		// Переполнение сдвига на большом числе повторов тоже упирается в потолок
+		if pause <= 0 || pause > maxBackoff {
+			pause = maxBackoff
+		}
+	}
+	if half := int64(pause / 2); half > 0 {
+		pause += time.Duration(rand.Int63n(half))
+	}
+	if statusErr, ok := lastErr.(*retryableStatusError); ok && statusErr.retryAfter > pause {
+		pause = statusErr.retryAfter
+	}
+	return pause
+}
+
+// retryableStatusError — временный отказ сервера (429 или 5xx)
+type retryableStatusError struct {
+	status     int
+	body       []byte
+	retryAfter time.Duration
+}
+
+func (e *retryableStatusError) Error() string {
+	return fmt.Sprintf("HTTP %d: %s", e.status, string(e.body))
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bt/internal"
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestCandleClient_RetriesTransientFailures(t *testing.T) {
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("Expected token from flag in Authorization header, got %q", r.Header.Get("Authorization"))
+		}
+		switch attempts {
+		case 1:
+			w.Header().Set("Retry-After", "3")
+			w.WriteHeader(http.StatusTooManyRequests)
+		case 2:
+			w.WriteHeader(http.StatusBadGateway)
+		default:
+			w.Write([]byte(`{"candles":[]}`))
+		}
+	}))
+	defer server.Close()
+
+	var pauses []time.Duration
+	api := &candleClient{
+		http:     server.Client(),
+		endpoint: server.URL,
+		token:    "secret",
+		limiter:  newRateLimiter(0),
+		retries:  3,
+		backoff:  100 * time.Millisecond,
+		sleep: func(_ context.Context, d time.Duration) error {
+			pauses = append(pauses, d)
+			return nil
+		},
+	}
+
+	resp, err := api.fetch(context.Background(), internal.RequestBody{})
+	if err != nil {
+		t.Fatalf("Expected success after retries, got %v", err)
+	}
+	if resp.status != http.StatusOK || attempts != 3 {
+		t.Errorf("Expected 200 on the third attempt, got %d after %d attempts", resp.status, attempts)
+	}
+	if len(pauses) != 2 {
+		t.Fatalf("Expected 2 backoff pauses, got %v", pauses)
+	}
+	// После 429 выдерживается Retry-After, второй повтор — удвоенный backoff с джиттером до половины
+	if pauses[0] != 3*time.Second {
+		t.Errorf("Expected Retry-After pause of 3s, got %s", pauses[0])
+	}
+	if pauses[1] < 200*time.Millisecond || pauses[1] >= 300*time.Millisecond {
+		t.Errorf("Expected second pause in [200ms, 300ms), got %s", pauses[1])
+	}
+}
+
+func TestCandleClient_GivesUpAndPassesClientErrors(t *testing.T) {
+	status := http.StatusServiceUnavailable
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		w.WriteHeader(status)
+	}))
+	defer server.Close()
+
+	api := &candleClient{
+		http:     server.Client(),
+		endpoint: server.URL,
+		limiter:  newRateLimiter(0),
+		retries:  2,
+		backoff:  time.Millisecond,
+		sleep:    func(context.Context, time.Duration) error { return nil },
+	}
+
+	if _, err := api.fetch(context.Background(), internal.RequestBody{}); err == nil || attempts != 3 {
+		t.Errorf("Expected failure after 3 attempts, got err=%v after %d attempts", err, attempts)
+	}
+
+	// Ошибка клиента не повторяется — ее обрабатывает цикл сбора
+	status, attempts = http.StatusBadRequest, 0
+	resp, err := api.fetch(context.Background(), internal.RequestBody{})
+	if err != nil || resp.status != http.StatusBadRequest || attempts != 1 {
+		t.Errorf("Expected single 400 response, got %+v, err=%v after %d attempts", resp, err, attempts)
+	}
+}
+
+func TestCandleClient_StopsRetryingOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	attempts := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempts++
+		cancel() // Ctrl+C во время первого запроса
+		w.WriteHeader(http.StatusServiceUnavailable)
+	}))
+	defer server.Close()
+
+	api := &candleClient{
+		http:     server.Client(),
+		endpoint: server.URL,
+		limiter:  newRateLimiter(0),
+		retries:  5,
+		backoff:  time.Hour,
+		sleep:    sleepContext,
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := api.fetch(ctx, internal.RequestBody{})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, context.Canceled) || attempts != 1 {
+			t.Errorf("Expected context.Canceled after 1 attempt, got err=%v after %d attempts", err, attempts)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("fetch kept backing off after cancellation")
+	}
+}
+
+func TestRateLimiter_SpacesRequests(t *testing.T) {
+	var slept time.Duration
+	limiter := newRateLimiter(60)
+	limiter.sleep = func(_ context.Context, d time.Duration) error {
+		slept += d
+		return nil
+	}
+
+	limiter.wait(context.Background())
+	if slept != 0 {
+		t.Errorf("First request should not wait, slept %s", slept)
+	}
+	limiter.wait(context.Background())
+	if slept < 900*time.Millisecond || slept > time.Second {
+		t.Errorf("Expected about 1s between requests at 60 rpm, slept %s", slept)
+	}
+}
+
+func TestCandleClient_ZeroBackoffRetriesWithoutPause(t *testing.T) {
+	api := &candleClient{backoff: 0}
+	for attempt := 1; attempt <= 3; attempt++ {
+		if pause := api.backoffFor(attempt, errors.New("HTTP ошибка")); pause != 0 {
+			t.Errorf("attempt %d: expected no pause with --backoff 0, got %s", attempt, pause)
+		}
+	}
+	// Retry-After сервера выдерживается и без backoff
+	if pause := api.backoffFor(1, &retryableStatusError{status: http.StatusTooManyRequests, retryAfter: 2 * time.Second}); pause != 2*time.Second {
+		t.Errorf("expected Retry-After pause of 2s, got %s", pause)
+	}
+}
@@ -3,27 +3,25 @@ package main
 
 import (
 	"bt/internal"
-	"bytes"
 	"context"
 	"crypto/tls"
 	"encoding/json"
+	"flag"
 	"fmt"
-	"io"
 	"log"
 	"net/http"
 	"os"
+	"os/signal"
 	"strings"
 	"time"
 )
 
 const (
-	API_TOKEN     = "NEW_TOKEN"
-	INSTRUMENT_ID = "TCS60A101X76"
-	INTERVAL      = "CANDLE_INTERVAL_30_MIN"
-	LIMIT         = 1000
-	API_ENDPOINT  = "https://invest-public-api.tbank.ru/rest/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles"
-	OUTPUT_FILE   = "tmos_big.json"
-	MONTH_STEP    = 30 * 24 * time.Hour // ~1 месяц (без учёта точного количества дней — достаточно)
+	INTERVAL     = "CANDLE_INTERVAL_30_MIN"
+	LIMIT        = 1000
+	API_ENDPOINT = "https://invest-public-api.tbank.ru/rest/tinkoff.public.invest.api.contract.v1.MarketDataService/GetCandles"
+	OUTPUT_FILE  = "tmos_big.json"     // для одного --instrument; с --instruments — <id>.json
+	MONTH_STEP   = 30 * 24 * time.Hour // ~1 месяц (без учёта точного количества дней — достаточно)
 )
 
 var client = &http.Client{
@@ -36,174 +34,220 @@ var client = &http.Client{
 }
 
 func main() {
+	compactOnly := flag.Bool("compact", false, "Только собрать итоговый JSON из уже скачанных блоков и выйти")
+	token := flag.String("token", os.Getenv("TINKOFF_TOKEN"), "Токен Tinkoff Invest API (по умолчанию из переменной окружения TINKOFF_TOKEN)")
+	instrument := flag.String("instrument", "TCS60A101X76", "Идентификатор инструмента (FIGI или instrument_uid)")
+	instruments := flag.String("instruments", "", "Список инструментов через запятую; каждый сохраняется в <id>.json (заменяет --instrument)")
+	from := flag.String("from", "", "Собирать историю до этой даты (YYYY-MM-DD); инструменты, уже собранные до нее, пропускаются (пусто = пока есть данные)")
+	requestsPerMinute := flag.Int("rpm", 120, "Максимум запросов к API в минуту (0 = без ограничения)")
+	retries := flag.Int("retries", 5, "Повторов запроса при сетевой ошибке, 429 и 5xx, прежде чем остановиться")
+	backoff := flag.Duration("backoff", time.Second, "Пауза перед первым повтором; дальше удваивается, со случайным джиттером (0 = без паузы)")
+	flag.Parse()
+
+	// Один --instrument пишется в OUTPUT_FILE, как раньше; список — по файлу на инструмент
+	targets := map[string]string{*instrument: OUTPUT_FILE}
+	order := []string{*instrument}
+	if *instruments != "" {
+		targets, order = map[string]string{}, nil
+		for _, id := range strings.Split(*instruments, ",") {
+			if id = strings.TrimSpace(id); id != "" && targets[id] == "" {
+				targets[id] = id + ".json"
+				order = append(order, id)
+			}
+		}
+		if len(order) == 0 {
+			log.Fatal("❌ --instruments не содержит ни одного идентификатора")
+		}
+	}
+
+	if *compactOnly {
+		for _, id := range order {
+			storage, err := openBlockStorage(targets[id], id, INTERVAL)
+			if err != nil {
+				log.Fatalf("❌ %s: не удалось открыть хранилище блоков: %v", id, err)
+			}
+			compact(storage, targets[id])
+		}
+		return
+	}
+
+	var fromDate time.Time
+	if *from != "" {
+		var err error
+		if fromDate, err = time.Parse("2006-01-02", *from); err != nil {
+			log.Fatalf("❌ Некорректная дата --from '%s' (ожидается YYYY-MM-DD)", *from)
+		}
+	}
+	if *token == "" {
+		log.Fatal("❌ Не задан токен API: укажите --token или переменную окружения TINKOFF_TOKEN")
+	}
+	if *retries < 0 || *requestsPerMinute < 0 {
+		log.Fatalf("❌ --retries и --rpm не могут быть отрицательными, получено %d и %d", *retries, *requestsPerMinute)
+	}
+	if *backoff < 0 {
+		log.Fatalf("❌ --backoff не может быть отрицательным, получено %s", *backoff)
+	}
+	f := &fetcher{
+		api: &candleClient{
+			http:     client,
+			endpoint: API_ENDPOINT,
+			token:    *token,
+			limiter:  newRateLimiter(*requestsPerMinute),
+			retries:  *retries,
+			backoff:  *backoff,
+			sleep:    sleepContext,
+		},
+		from: fromDate,
+	}
+
+	// Ctrl+C прерывает сбор между запросами; скачанные блоки остаются в манифесте
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
 	log.Println("🚀 Запуск сборщика свечей Tinkoff Invest (месячные блоки + автосохранение)")
+	log.Printf("🚦 Инструментов: %d, не больше %d запросов в минуту, до %d повторов при сбоях", len(order), *requestsPerMinute, *retries)
+
+	failed := 0
+	for _, id := range order {
+		if err := f.fetchInstrument(ctx, id, INTERVAL, targets[id]); err != nil {
+			failed++
+			log.Printf("🛑 %s: %v — скачанные блоки сохранены, сбор можно продолжить повторным запуском", id, err)
+			if ctx.Err() != nil {
+				break
+			}
+		}
+	}
+	if failed > 0 {
+		log.Printf("⚠️ Не собрано инструментов: %d из %d", failed, len(order))
+		os.Exit(1)
+	}
+}
+
+// fetcher — общие для всех инструментов клиент API и нижняя граница истории
+type fetcher struct {
+	api  *candleClient
+	from time.Time // собирать историю до этой даты (нулевая — пока API отдает данные)
+}
+
+// fetchInstrument — собирает свечи инструмента помесячно от текущего момента в прошлое с автосохранением
+// после каждого запроса и продолжением по манифесту; в конце (и при ошибке) собирает outFile из блоков
+func (f *fetcher) fetchInstrument(ctx context.Context, id, interval, outFile string) error {
+	storage, err := openBlockStorage(outFile, id, interval)
+	if err != nil {
+		return fmt.Errorf("не удалось открыть хранилище блоков: %w", err)
+	}
 
-	// Начинаем с текущего времени
+	// Начинаем с текущего времени или продолжаем с места остановки по манифесту
 	toTime := time.Now().UTC()
-	var allCandles []internal.Candle
+	if !storage.manifest.NextTo.IsZero() {
+		toTime = storage.manifest.NextTo
+		if storage.manifest.Exhausted || (!f.from.IsZero() && !toTime.After(f.from)) {
+			log.Printf("⏭️ %s: уже собран (%d свечей) — пропущен", id, storage.candleCount())
+			compact(storage, outFile)
+			return nil
+		}
+		log.Printf("🔄 %s: продолжаем сбор: %d блоков (%d свечей) уже скачано, следующий запрос до %s",
+			id, len(storage.manifest.Blocks), storage.candleCount(), toTime.Format("2006-01-02"))
+	}
+	defer compact(storage, outFile)
 	daysSkipped := 0
 
-	// Пытаемся загрузить уже сохранённые данные
-	if err := loadExistingCandles(&allCandles); err != nil {
-		log.Printf("⚠️ Не удалось загрузить существующие данные из %s: %v", OUTPUT_FILE, err)
-	} else {
-		log.Printf("🔄 Загружено %d свечей из предыдущего сеанса", len(allCandles))
-	}
+	for f.from.IsZero() || toTime.After(f.from) {
+		if err := ctx.Err(); err != nil {
+			return fmt.Errorf("сбор прерван: %w", err)
+		}
 
-	for {
 		fromTime := toTime.Add(-MONTH_STEP)
+		if !f.from.IsZero() && fromTime.Before(f.from) {
+			fromTime = f.from
+		}
 
 		reqBody := internal.RequestBody{
 			From:             fromTime.Format(time.RFC3339),
 			To:               toTime.Format(time.RFC3339),
-			Interval:         INTERVAL,
-			InstrumentId:     INSTRUMENT_ID,
+			Interval:         interval,
+			InstrumentId:     id,
 			CandleSourceType: "CANDLE_SOURCE_UNSPECIFIED",
 			Limit:            LIMIT,
 		}
 
-		jsonBody, err := json.Marshal(reqBody)
-		if err != nil {
-			log.Fatal("❌ Ошибка сериализации запроса:", err)
-		}
+		log.Printf("📥 %s: запрос from=%s, to=%s, limit=%d", id, reqBody.From, reqBody.To, reqBody.Limit)
 
-		req, err := http.NewRequestWithContext(context.Background(), "POST", API_ENDPOINT, bytes.NewBuffer(jsonBody))
+		// Временные сбои повторяются для того же окна; если попытки кончились, toTime не сдвигается,
+		// и повторный запуск продолжит с этого же окна
+		resp, err := f.api.fetch(ctx, reqBody)
 		if err != nil {
-			log.Fatal("❌ Ошибка создания запроса:", err)
+			return fmt.Errorf("запрос не удался: %w", err)
 		}
+		body := resp.body
 
-		req.Header.Set("Content-Type", "application/json")
-		req.Header.Set("Accept", "application/json")
-		req.Header.Set("Authorization", "Bearer "+API_TOKEN)
-
-		log.Printf("📥 Запрос: from=%s, to=%s, limit=%d", reqBody.From, reqBody.To, reqBody.Limit)
-
-		resp, err := client.Do(req)
-		if err != nil {
-			log.Printf("❌ HTTP ошибка при запросе: %v", err)
-			log.Println("💾 Сохраняю накопленные данные перед выходом...")
-			err = saveCandlesToFile(allCandles)
-			if err != nil {
-				log.Fatal("❌ Невозможно сохранить свечи в файл")
-			}
-			log.Fatal("🛑 Прервано из-за сетевой ошибки")
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("❌ Ошибка чтения тела ответа: %v", err)
-			log.Println("💾 Сохраняю накопленные данные перед выходом...")
-			err = saveCandlesToFile(allCandles)
-			if err != nil {
-				log.Fatal("❌ Невозможно сохранить свечи в файл")
-			}
-			log.Fatal("🛑 Прервано из-за ошибки чтения ответа")
-		}
-
-		if resp.StatusCode != 200 {
-			log.Printf("⚠️ HTTP %d: %s", resp.StatusCode, string(body))
+		if resp.status != http.StatusOK {
+			log.Printf("⚠️ HTTP %d: %s", resp.status, string(body))
 			if strings.Contains(string(body), "not found") || strings.Contains(string(body), "no data") {
-				log.Println("✅ Данные закончились — завершаем сбор")
-				break
+				log.Printf("✅ %s: данные закончились — завершаем сбор", id)
+				return storage.markExhausted()
 			}
-			log.Printf("⚠️ Неожиданный статус: %d — пробуем продолжить...", resp.StatusCode)
-			// Не останавливаемся — возможно, временный сбой
-			toTime = fromTime
-			time.Sleep(2 * time.Second)
-			continue
+			// Ошибка клиента (неверный или просроченный токен, 401/403, некорректный запрос) не исправится
+			// ни повтором, ни следующим окном; манифест не сдвигается, чтобы после исправления сбор
+			// продолжился с этого же окна, а не с пропущенной истории
+			return fmt.Errorf("HTTP %d — сбор инструмента остановлен: %s", resp.status, string(body))
 		}
 
 		var response internal.GetCandlesResponse
 		if err := json.Unmarshal(body, &response); err != nil {
-			log.Printf("❌ Ошибка парсинга JSON: %v", err)
-			log.Println("💾 Сохраняю накопленные данные перед выходом...")
-			err = saveCandlesToFile(allCandles)
-			if err != nil {
-				log.Fatal("❌ Невозможно сохранить свечи в файл")
-			}
-			log.Fatal("🛑 Прервано из-за ошибки парсинга ответа")
+			return fmt.Errorf("ошибка парсинга ответа: %w", err)
 		}
 
 		candles := response.Candles
 
 		if len(candles) == 0 {
 			daysSkipped++
-			log.Printf("ℹ️ Месяц %s–%s: 0 свечей (выходные/праздники?) — пропущено (%d всего)",
-				fromTime.Format("2006-01"), toTime.Format("2006-01"), daysSkipped)
+			log.Printf("ℹ️ %s: месяц %s–%s: 0 свечей (выходные/праздники?) — пропущено (%d всего)",
+				id, fromTime.Format("2006-01"), toTime.Format("2006-01"), daysSkipped)
+			if err := storage.recordGap(fromTime, toTime); err != nil {
+				return fmt.Errorf("невозможно обновить манифест: %w", err)
+			}
 			toTime = fromTime
-			time.Sleep(100 * time.Millisecond)
 			continue
 		}
 
-		// Добавляем новые свечи в начало списка (хронологический порядок: старые → новые)
-		allCandles = append(candles, allCandles...)
-		processedCount := len(allCandles)
-
-		// 🚨 КЛЮЧЕВОЙ ШАГ: сохраняем ВСЁ в файл сразу после успешного запроса
-		err = saveCandlesToFile(allCandles)
-		if err != nil {
-			log.Fatal("❌ Невозможно сохранить свечи в файл")
+		// Сдвигаем верхнюю границу на самую старую свечу
+		oldestCandleTime := candles[0].ToTime()
+		if oldestCandleTime.IsZero() {
+			return fmt.Errorf("невозможно распарсить время самой старой свечи")
+		}
+		// Самая старая свеча на начале окна: сдвиг на нее зациклил бы запросы на той же границе
+		if !oldestCandleTime.After(fromTime) {
+			oldestCandleTime = fromTime
 		}
 
-		// Сдвигаем верхнюю границу на самую старую свечу
-		oldestCandleTime, err := time.Parse(time.RFC3339, candles[0].Time)
-		if err != nil {
-			log.Fatal("❌ Невозможно распарсить время самой старой свечи:", candles[0].Time)
+		// 🚨 КЛЮЧЕВОЙ ШАГ: сохраняем блок сразу после успешного запроса (дописывается только новый блок)
+		if err := storage.appendBlock(fromTime, toTime, oldestCandleTime, body, len(candles)); err != nil {
+			return fmt.Errorf("невозможно сохранить блок свечей: %w", err)
 		}
 		toTime = oldestCandleTime
+		processedCount := storage.candleCount()
 
-		log.Printf("✅ Получено %d свечей (всего: %d). Следующий запрос до %s",
-			len(candles), processedCount, toTime.Format("2006-01-02"))
+		log.Printf("✅ %s: получено %d свечей (всего: %d). Следующий запрос до %s",
+			id, len(candles), processedCount, toTime.Format("2006-01-02"))
 
 		// Защита от бесконечности
 		if processedCount > 500000 {
-			log.Println("⚠️ Достигнут лимит в 500k свечей — остановка для защиты")
-			break
+			log.Printf("⚠️ %s: достигнут лимит в 500k свечей — остановка для защиты", id)
+			return nil
 		}
-
-		time.Sleep(100 * time.Millisecond)
-	}
-
-	log.Printf("🎉 Успешно собрано и сохранено %d свечей в файл %s", len(allCandles), OUTPUT_FILE)
-}
-
-// saveCandlesToFile сохраняет свечи в JSON-файл
-func saveCandlesToFile(candles []internal.Candle) error {
-	outputData := struct {
-		Candles []internal.Candle `json:"candles"`
-	}{
-		Candles: candles,
 	}
 
-	outputJSON, err := json.MarshalIndent(outputData, "", "  ")
-	if err != nil {
-		return fmt.Errorf("ошибка сериализации: %w", err)
-	}
-
-	if err := os.WriteFile(OUTPUT_FILE, outputJSON, 0644); err != nil {
-		return fmt.Errorf("ошибка записи в файл: %w", err)
-	}
-
-	log.Printf("💾 Сохранено %d свечей в %s", len(candles), OUTPUT_FILE)
+	log.Printf("✅ %s: история собрана до %s", id, f.from.Format("2006-01-02"))
 	return nil
 }
 
-// loadExistingCandles загружает уже сохранённые свечи из файла
-func loadExistingCandles(candles *[]internal.Candle) error {
-	data, err := os.ReadFile(OUTPUT_FILE)
+// compact — собирает итоговый файл инструмента из уже скачанных блоков
+func compact(storage *blockStorage, outFile string) {
+	count, err := storage.compact(outFile)
 	if err != nil {
-		return err
-	}
-
-	var wrapper struct {
-		Candles []internal.Candle `json:"candles"`
+		log.Printf("❌ Ошибка сборки итогового файла %s: %v", outFile, err)
+		return
 	}
-
-	if err := json.Unmarshal(data, &wrapper); err != nil {
-		return fmt.Errorf("ошибка парсинга существующих данных: %w", err)
-	}
-
-	*candles = wrapper.Candles
-	return nil
+	log.Printf("💾 Собрано %d свечей из %d блоков в %s", count, len(storage.manifest.Blocks), outFile)
 }
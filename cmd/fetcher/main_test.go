@@ -0,0 +1,167 @@
+package main
+
+import (
+	"bt/internal"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestFetchInstrument_StopsAtFromAndSkipsCompleted(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req internal.RequestBody
+		json.NewDecoder(r.Body).Decode(&req)
+		requests[req.InstrumentId]++
+		// Одна свеча в начале окна: следующее окно начинается ровно там, где кончилось это
+		w.Write([]byte(`{"candles":[{"volume":"1","time":"` + req.From + `"}]}`))
+	}))
+	defer server.Close()
+
+	f := &fetcher{
+		api: &candleClient{
+			http:     server.Client(),
+			endpoint: server.URL,
+			limiter:  newRateLimiter(0),
+			sleep:    func(context.Context, time.Duration) error { return nil },
+		},
+		from: time.Now().UTC().Add(-45 * 24 * time.Hour),
+	}
+
+	dir := t.TempDir()
+	for _, id := range []string{"AAA", "BBB"} {
+		if err := f.fetchInstrument(context.Background(), id, INTERVAL, filepath.Join(dir, id+".json")); err != nil {
+			t.Fatalf("%s: %v", id, err)
+		}
+		// 45 дней — два месячных окна, второе обрезано по --from
+		if requests[id] != 2 {
+			t.Fatalf("%s: expected 2 requests, got %d", id, requests[id])
+		}
+		if _, err := os.Stat(filepath.Join(dir, id+".json")); err != nil {
+			t.Fatalf("%s: output file not written: %v", id, err)
+		}
+	}
+
+	// Повторный запуск не трогает API: история уже собрана до --from
+	if err := f.fetchInstrument(context.Background(), "AAA", INTERVAL, filepath.Join(dir, "AAA.json")); err != nil {
+		t.Fatal(err)
+	}
+	if requests["AAA"] != 2 {
+		t.Fatalf("completed instrument was fetched again: %d requests", requests["AAA"])
+	}
+}
+
+func TestFetchInstrument_ResumesFromExistingFileWithoutManifest(t *testing.T) {
+	requests := map[string]int{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req internal.RequestBody
+		json.NewDecoder(r.Body).Decode(&req)
+		requests[req.InstrumentId]++
+		w.Write([]byte(`{"candles":[{"volume":"1","time":"` + req.From + `"}]}`))
+	}))
+	defer server.Close()
+
+	now := time.Now().UTC()
+	f := &fetcher{
+		api: &candleClient{
+			http:     server.Client(),
+			endpoint: server.URL,
+			limiter:  newRateLimiter(0),
+			sleep:    func(context.Context, time.Duration) error { return nil },
+		},
+		from: now.Add(-45 * 24 * time.Hour),
+	}
+
+	// Файл от сборщика до манифестов: собран только за последние 20 дней
+	dir := t.TempDir()
+	seed := func(id string, days ...int) string {
+		var candles []string
+		for _, d := range days {
+			candles = append(candles, `{"volume":"1","time":"`+now.Add(-time.Duration(d)*24*time.Hour).Format(time.RFC3339)+`"}`)
+		}
+		path := filepath.Join(dir, id+".json")
+		if err := os.WriteFile(path, []byte(`{"candles":[`+strings.Join(candles, ",")+`]}`), 0644); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+	partial := seed("BBB", 20, 1)
+
+	if err := f.fetchInstrument(context.Background(), "BBB", INTERVAL, partial); err != nil {
+		t.Fatal(err)
+	}
+	// Сбор продолжается от самой старой свечи файла (20 дней) до --from (45 дней) — одно окно
+	if requests["BBB"] != 1 {
+		t.Errorf("expected one request for the missing range, got %d", requests["BBB"])
+	}
+
+	if n := countCandles(t, partial); n != 3 {
+		t.Errorf("expected existing 2 candles plus 1 fetched, got %d", n)
+	}
+
+	// Файл, который не разбирается, не перезаписывается
+	broken := filepath.Join(dir, "CCC.json")
+	os.WriteFile(broken, []byte("not json"), 0644)
+	if err := f.fetchInstrument(context.Background(), "CCC", INTERVAL, broken); err == nil || requests["CCC"] != 0 {
+		t.Errorf("expected refusal without requests for an unreadable output file, got err=%v, %d requests", err, requests["CCC"])
+	}
+	if data, _ := os.ReadFile(broken); string(data) != "not json" {
+		t.Errorf("unreadable output file was overwritten: %q", data)
+	}
+}
+
+// countCandles — число свечей в итоговом файле сборщика
+func countCandles(t *testing.T, path string) int {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out struct {
+		Candles []json.RawMessage `json:"candles"`
+	}
+	if err := json.Unmarshal(data, &out); err != nil {
+		t.Fatal(err)
+	}
+	return len(out.Candles)
+}
+
+func TestFetchInstrument_StopsOnClientErrorWithoutGap(t *testing.T) {
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.WriteHeader(http.StatusUnauthorized)
+		w.Write([]byte(`{"message":"authentication token is missing or invalid"}`))
+	}))
+	defer server.Close()
+
+	f := &fetcher{
+		api: &candleClient{
+			http:     server.Client(),
+			endpoint: server.URL,
+			limiter:  newRateLimiter(0),
+			sleep:    func(context.Context, time.Duration) error { return nil },
+		},
+	}
+
+	// Без --from неверный токен иначе уводил бы сбор в прошлое, записывая пропуск за каждый месяц
+	path := filepath.Join(t.TempDir(), "AAA.json")
+	if err := f.fetchInstrument(context.Background(), "AAA", INTERVAL, path); err == nil || requests != 1 {
+		t.Fatalf("expected a fatal error after one request, got err=%v after %d requests", err, requests)
+	}
+
+	storage, err := openBlockStorage(path, "AAA", INTERVAL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(storage.manifest.Gaps) != 0 || !storage.manifest.NextTo.IsZero() {
+		t.Errorf("expected manifest untouched by a client error, got %d gaps and next_to %s",
+			len(storage.manifest.Gaps), storage.manifest.NextTo)
+	}
+}
@@ -0,0 +1,239 @@
+// storage.go — блочное хранилище свечей с манифестом покрытых диапазонов
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const manifestFile = "manifest.json"
+
+// fetchManifest — манифест загрузки: какие диапазоны уже скачаны, где лежат блоки и где пропуски
+type fetchManifest struct {
+	Instrument string          `json:"instrument"`
+	Interval   string          `json:"interval"`
+	NextTo     time.Time       `json:"nextTo"` // верхняя граница следующего запроса (для продолжения)
+	Blocks     []manifestBlock `json:"blocks"`
+	Gaps       []manifestRange `json:"gaps"`                // диапазоны без данных (выходные, праздники)
+	Exhausted  bool            `json:"exhausted,omitempty"` // API больше не отдает более старых данных
+}
+
+type manifestBlock struct {
+	From  time.Time `json:"from"`
+	To    time.Time `json:"to"`
+	File  string    `json:"file"`
+	Count int       `json:"count"`
+}
+
+type manifestRange struct {
+	From time.Time `json:"from"`
+	To   time.Time `json:"to"`
+}
+
+// blockStorage — хранит каждый ответ API отдельным файлом вместо перезаписи всего массива
+type blockStorage struct {
+	dir      string
+	manifest fetchManifest
+}
+
+// openBlockStorage — открывает (или создает) каталог блоков рядом с итоговым файлом.
+// Если манифеста нет, а итоговый файл уже собран раньше, он становится первым блоком (importExisting)
+func openBlockStorage(outputFile, instrument, interval string) (*blockStorage, error) {
+	dir := outputFile + ".blocks"
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, fmt.Errorf("ошибка создания каталога блоков: %w", err)
+	}
+
+	s := &blockStorage{
+		dir: dir,
+		manifest: fetchManifest{
+			Instrument: instrument,
+			Interval:   interval,
+		},
+	}
+
+	data, err := os.ReadFile(filepath.Join(dir, manifestFile))
+	if os.IsNotExist(err) {
+		if err := s.importExisting(outputFile); err != nil {
+			return nil, err
+		}
+		return s, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения манифеста: %w", err)
+	}
+	if err := json.Unmarshal(data, &s.manifest); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга манифеста: %w", err)
+	}
+	if s.manifest.Instrument != instrument || s.manifest.Interval != interval {
+		return nil, fmt.Errorf("манифест в %s относится к %s/%s, а не к %s/%s",
+			dir, s.manifest.Instrument, s.manifest.Interval, instrument, interval)
+	}
+
+	return s, nil
+}
+
+// importExisting — переносит ранее собранный итоговый файл без манифеста в хранилище первым блоком
+// и продолжает сбор от его самой старой свечи: иначе compact перезаписал бы файл только новыми блоками.
+// Файл, который не удается разобрать, не трогаем — сбор отказывается, а не теряет историю
+func (s *blockStorage) importExisting(outputFile string) error {
+	data, err := os.ReadFile(outputFile)
+	if os.IsNotExist(err) || (err == nil && len(data) == 0) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("ошибка чтения %s: %w", outputFile, err)
+	}
+
+	var existing struct {
+		Candles []struct {
+			Time string `json:"time"`
+		} `json:"candles"`
+	}
+	if err := json.Unmarshal(data, &existing); err != nil {
+		return fmt.Errorf("%s уже существует, но не разбирается как файл свечей (%v) — переместите его, чтобы не потерять", outputFile, err)
+	}
+	// Пустой файл остается от прогона, остановленного до первого блока (например, с неверным токеном)
+	if len(existing.Candles) == 0 {
+		return nil
+	}
+
+	var oldest, newest time.Time
+	for _, c := range existing.Candles {
+		t, err := time.Parse(time.RFC3339, c.Time)
+		if err != nil {
+			continue
+		}
+		if oldest.IsZero() || t.Before(oldest) {
+			oldest = t
+		}
+		if t.After(newest) {
+			newest = t
+		}
+	}
+	if oldest.IsZero() {
+		return fmt.Errorf("%s уже существует, но в нем нет свечей со временем — переместите его, чтобы не потерять", outputFile)
+	}
+
+	log.Printf("📦 %s: найден файл без манифеста (%d свечей, %s – %s) — продолжаем сбор от его начала",
+		outputFile, len(existing.Candles), oldest.Format("2006-01-02"), newest.Format("2006-01-02"))
+	return s.appendBlock(oldest, newest, oldest, data, len(existing.Candles))
+}
+
+// candleCount — число свечей во всех сохраненных блоках
+func (s *blockStorage) candleCount() int {
+	total := 0
+	for _, b := range s.manifest.Blocks {
+		total += b.Count
+	}
+	return total
+}
+
+// appendBlock — сохраняет сырой ответ API отдельным файлом и дописывает его в манифест
+func (s *blockStorage) appendBlock(from, to, nextTo time.Time, body []byte, count int) error {
+	name := fmt.Sprintf("block_%s_%s.json", from.UTC().Format("20060102T150405"), to.UTC().Format("20060102T150405"))
+	if err := writeFileAtomic(filepath.Join(s.dir, name), body); err != nil {
+		return fmt.Errorf("ошибка записи блока: %w", err)
+	}
+
+	s.manifest.Blocks = append(s.manifest.Blocks, manifestBlock{From: from, To: to, File: name, Count: count})
+	s.manifest.NextTo = nextTo
+	return s.saveManifest()
+}
+
+// recordGap — отмечает диапазон без данных
+func (s *blockStorage) recordGap(from, to time.Time) error {
+	s.manifest.Gaps = append(s.manifest.Gaps, manifestRange{From: from, To: to})
+	s.manifest.NextTo = from
+	return s.saveManifest()
+}
+
+// markExhausted — отмечает, что история инструмента собрана полностью
+func (s *blockStorage) markExhausted() error {
+	s.manifest.Exhausted = true
+	return s.saveManifest()
+}
+
+func (s *blockStorage) saveManifest() error {
+	data, err := json.MarshalIndent(s.manifest, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации манифеста: %w", err)
+	}
+	return writeFileAtomic(filepath.Join(s.dir, manifestFile), data)
+}
+
+// compact — собирает все блоки в один JSON-файл {"candles": [...]} в хронологическом порядке.
+// Свечи с одинаковым временем (перекрытие соседних запросов) сохраняются один раз — из более позднего блока.
+func (s *blockStorage) compact(outputFile string) (int, error) {
+	byTime := make(map[string]json.RawMessage)
+	times := make(map[string]time.Time)
+
+	for _, b := range s.manifest.Blocks {
+		data, err := os.ReadFile(filepath.Join(s.dir, b.File))
+		if err != nil {
+			return 0, fmt.Errorf("ошибка чтения блока %s: %w", b.File, err)
+		}
+
+		var block struct {
+			Candles []json.RawMessage `json:"candles"`
+		}
+		if err := json.Unmarshal(data, &block); err != nil {
+			return 0, fmt.Errorf("ошибка парсинга блока %s: %w", b.File, err)
+		}
+
+		for _, raw := range block.Candles {
+			var c struct {
+				Time string `json:"time"`
+			}
+			if err := json.Unmarshal(raw, &c); err != nil {
+				return 0, fmt.Errorf("ошибка парсинга свечи в блоке %s: %w", b.File, err)
+			}
+			t, err := time.Parse(time.RFC3339, c.Time)
+			if err != nil {
+				log.Printf("⚠️ Пропущена свеча с некорректным временем '%s' в блоке %s", c.Time, b.File)
+				continue
+			}
+			byTime[c.Time] = raw
+			times[c.Time] = t
+		}
+	}
+
+	keys := make([]string, 0, len(byTime))
+	for k := range byTime {
+		keys = append(keys, k)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		return times[keys[i]].Before(times[keys[j]])
+	})
+
+	candles := make([]json.RawMessage, len(keys))
+	for i, k := range keys {
+		candles[i] = byTime[k]
+	}
+
+	outputJSON, err := json.MarshalIndent(struct {
+		Candles []json.RawMessage `json:"candles"`
+	}{Candles: candles}, "", "  ")
+	if err != nil {
+		return 0, fmt.Errorf("ошибка сериализации: %w", err)
+	}
+	if err := writeFileAtomic(outputFile, outputJSON); err != nil {
+		return 0, fmt.Errorf("ошибка записи в файл: %w", err)
+	}
+
+	return len(candles), nil
+}
+
+// writeFileAtomic — запись через временный файл, чтобы прерванный процесс не оставлял битый файл
+func writeFileAtomic(filename string, data []byte) error {
+	tmp := filename + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, filename)
+}
@@ -0,0 +1,213 @@
+// annualization.go
+// Число баров в году для аннуализации метрик (Sharpe и др.) с учетом типа рынка
+package internal
+
+import (
+	"fmt"
+	"math"
+	"sort"
+	"sync"
+	"time"
+)
+
+const (
+	// defaultPeriodsPerYear — число баров в году, если интервал свечей определить не удалось
+	defaultPeriodsPerYear = 252.0
+	// tradingDaysPerYear — число торговых сессий в году на фондовом рынке
+	tradingDaysPerYear = 252.0
+)
+
+// Market — тип рынка: определяет, как считать число баров в году
+type Market int
+
+const (
+	// MarketAuto — календарное время по медианному интервалу между свечами (поведение по умолчанию)
+	MarketAuto Market = iota
+	// MarketCrypto — круглосуточная торговля 365 дней в году
+	MarketCrypto
+	// MarketEquity — торговые сессии: 252 дня в году × число баров в сессии
+	MarketEquity
+)
+
+func (m Market) String() string {
+	switch m {
+	case MarketCrypto:
+		return "crypto"
+	case MarketEquity:
+		return "equity"
+	default:
+		return "auto"
+	}
+}
+
+// ParseMarket — разбирает значение флага --market
+func ParseMarket(s string) (Market, error) {
+	switch s {
+	case "", "auto":
+		return MarketAuto, nil
+	case "crypto":
+		return MarketCrypto, nil
+	case "equity":
+		return MarketEquity, nil
+	default:
+		return MarketAuto, fmt.Errorf("неизвестный рынок '%s' (ожидается crypto или equity)", s)
+	}
+}
+
+// PeriodsPerYearFor — число баров в году для аннуализации: явное значение или оценка по свечам
+func (o BacktestOptions) PeriodsPerYearFor(candles []Candle) float64 {
+	if o.PeriodsPerYear > 0 {
+		return o.PeriodsPerYear
+	}
+	return InferPeriodsPerYearForMarket(candles, o.Market)
+}
+
+// periodsPerYearCache — оптимизаторы прогоняют один и тот же срез свечей тысячи раз,
+// поэтому интервал вычисляется один раз на ряд времен свечей (ключ — отпечаток времен и рынок).
+// Кэш не держит ссылок на свечи, ограничен по размеру и очищается вместе с ResetCache
+var periodsPerYearCache = struct {
+	sync.Mutex
+	values map[periodsPerYearKey]float64
+}{values: make(map[periodsPerYearKey]float64)}
+
+// maxPeriodsPerYearEntries — предел числа рядов в кэше: walk-forward и кросс-валидация дают много окон
+const maxPeriodsPerYearEntries = 256
+
+type periodsPerYearKey struct {
+	times  uint64
+	market Market
+}
+
+// resetPeriodsPerYearCache — очищает кэш числа баров в году
+func resetPeriodsPerYearCache() {
+	periodsPerYearCache.Lock()
+	defer periodsPerYearCache.Unlock()
+	clear(periodsPerYearCache.values)
+}
+
+// fingerprintTimes — отпечаток длины и времени всех свечей: число баров в году зависит только от них
+func fingerprintTimes(candles []Candle) uint64 {
+	h := fnvMix(fnvOffset64, uint64(len(candles)))
+	for _, c := range candles {
+		h = fnvMix(h, uint64(c.ParsedTime.UnixNano()))
+	}
+	return h
+}
+
+// InferPeriodsPerYear — оценивает число баров в году по медианному интервалу между свечами (календарное время)
+func InferPeriodsPerYear(candles []Candle) float64 {
+	return InferPeriodsPerYearForMarket(candles, MarketAuto)
+}
+
+// InferPeriodsPerYearForMarket — оценивает число баров в году с учетом часов работы рынка
+func InferPeriodsPerYearForMarket(candles []Candle, market Market) float64 {
+	if len(candles) == 0 {
+		return defaultPeriodsPerYear
+	}
+	key := periodsPerYearKey{times: fingerprintTimes(candles), market: market}
+	periodsPerYearCache.Lock()
+	cached, ok := periodsPerYearCache.values[key]
+	periodsPerYearCache.Unlock()
+	if ok {
+		return cached
+	}
+
+	periods := inferPeriodsPerYearForMarket(candles, market)
+	periodsPerYearCache.Lock()
+	if len(periodsPerYearCache.values) >= maxPeriodsPerYearEntries {
+		clear(periodsPerYearCache.values)
+	}
+	periodsPerYearCache.values[key] = periods
+	periodsPerYearCache.Unlock()
+	return periods
+}
+
+// inferPeriodsPerYearForMarket — InferPeriodsPerYearForMarket без кэша (для растущих срезов потокового бэктеста)
+func inferPeriodsPerYearForMarket(candles []Candle, market Market) float64 {
+	if len(candles) == 0 {
+		return defaultPeriodsPerYear
+	}
+	if market == MarketEquity {
+		return inferEquityPeriodsPerYear(candles)
+	}
+	return inferPeriodsPerYear(candles)
+}
+
+// medianInterval — медианный положительный интервал между соседними свечами (0, если времени нет)
+func medianInterval(candles []Candle) time.Duration {
+	intervals := make([]time.Duration, 0, len(candles))
+	for i := 1; i < len(candles); i++ {
+		prev, curr := candles[i-1].ParsedTime, candles[i].ParsedTime
+		if prev.IsZero() || curr.IsZero() {
+			continue
+		}
+		if d := curr.Sub(prev); d > 0 {
+			intervals = append(intervals, d)
+		}
+	}
+	if len(intervals) == 0 {
+		return 0
+	}
+
+	sort.Slice(intervals, func(i, j int) bool { return intervals[i] < intervals[j] })
+	return intervals[len(intervals)/2]
+}
+
+func inferPeriodsPerYear(candles []Candle) float64 {
+	median := medianInterval(candles)
+	if median == 0 {
+		return defaultPeriodsPerYear
+	}
+
+	year := 365.25 * 24 * time.Hour
+	return year.Seconds() / median.Seconds()
+}
+
+// inferEquityPeriodsPerYear — внутридневные бары: 252 сессии × медианное число баров за день;
+// дневные и более крупные бары: 252 / число торговых дней в баре (5 из 7 календарных)
+func inferEquityPeriodsPerYear(candles []Candle) float64 {
+	median := medianInterval(candles)
+	if median == 0 {
+		return tradingDaysPerYear
+	}
+
+	day := 24 * time.Hour
+	if median >= day {
+		tradingDays := math.Max(1, math.Round(median.Hours()/24*5/7))
+		return tradingDaysPerYear / tradingDays
+	}
+
+	barsPerDay := make(map[string]int)
+	for _, c := range candles {
+		if c.ParsedTime.IsZero() {
+			continue
+		}
+		barsPerDay[c.ParsedTime.UTC().Format("2006-01-02")]++
+	}
+
+	counts := make([]int, 0, len(barsPerDay))
+	for _, n := range barsPerDay {
+		counts = append(counts, n)
+	}
+	sort.Ints(counts)
+	return tradingDaysPerYear * float64(counts[len(counts)/2])
+}
+
+// minCAGRSpan — данные короче этого периода не аннуализируются: возведение в степень 365/дни
+// превратило бы случайное движение за несколько дней в тысячи процентов годовых
+const minCAGRSpan = 30 * 24 * time.Hour
+
+// CAGR — среднегодовая доходность (1 + totalProfit)^(1/лет) − 1 за период с first по last.
+// Для периода короче minCAGRSpan и свечей без времени возвращается доходность за период как есть;
+// потеря всего капитала — −1
+func CAGR(totalProfit float64, first, last time.Time) float64 {
+	span := last.Sub(first)
+	if first.IsZero() || last.IsZero() || span < minCAGRSpan {
+		return totalProfit
+	}
+	if totalProfit <= -1 {
+		return -1
+	}
+	years := span.Hours() / (365.25 * 24)
+	return math.Pow(1+totalProfit, 1/years) - 1
+}
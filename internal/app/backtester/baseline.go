@@ -0,0 +1,146 @@
+package backtester
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// BaselineDelta — изменение результата стратегии относительно сохраненного отчета
+type BaselineDelta struct {
+	Name            string
+	BaselineProfit  float64
+	CurrentProfit   float64
+	BaselineSharpe  float64
+	CurrentSharpe   float64
+	BaselineTrades  int
+	CurrentTrades   int
+	MissingCurrent  bool // стратегия есть в базе, но не дала результата в текущем прогоне
+	MissingBaseline bool // новая стратегия, сравнивать не с чем
+	Regressed       bool
+}
+
+// ProfitDelta — изменение доходности (в долях)
+func (d BaselineDelta) ProfitDelta() float64 {
+	return d.CurrentProfit - d.BaselineProfit
+}
+
+// Only — отчет, ограниченный стратегиями текущего прогона (для прогона с --strategy)
+func (r *JSONReport) Only(results []BenchmarkResult) *JSONReport {
+	names := make(map[string]bool, len(results))
+	for _, res := range results {
+		names[res.Name] = true
+	}
+
+	filtered := &JSONReport{}
+	for _, e := range r.Results {
+		if names[e.Strategy] {
+			filtered.Results = append(filtered.Results, e)
+		}
+	}
+	return filtered
+}
+
+// CompareToBaseline — сопоставляет текущие результаты с базовым отчетом по имени стратегии.
+// Регрессия — падение доходности больше tolerance (в долях: 0.01 = 1 п.п.), NaN/Inf в доходности
+// или исчезновение стратегии из прогона. Новые стратегии в регрессию не засчитываются
+func CompareToBaseline(current []BenchmarkResult, baseline *JSONReport, tolerance float64) []BaselineDelta {
+	byName := make(map[string]BenchmarkResult, len(current))
+	for _, r := range current {
+		byName[r.Name] = r
+	}
+
+	var deltas []BaselineDelta
+	seen := make(map[string]bool, len(baseline.Results))
+	for _, base := range baseline.Results {
+		seen[base.Strategy] = true
+		delta := BaselineDelta{
+			Name:           base.Strategy,
+			BaselineProfit: base.Profit,
+			BaselineSharpe: base.Sharpe,
+			BaselineTrades: base.Trades,
+		}
+
+		r, ok := byName[base.Strategy]
+		if !ok {
+			delta.MissingCurrent = true
+			delta.Regressed = true
+			deltas = append(deltas, delta)
+			continue
+		}
+
+		delta.CurrentProfit = r.TotalProfit
+		delta.CurrentSharpe = r.SharpeRatio
+		delta.CurrentTrades = r.TradeCount
+		delta.Regressed = r.NonFinite || math.IsNaN(r.TotalProfit) || math.IsInf(r.TotalProfit, 0) ||
+			delta.ProfitDelta() < -tolerance
+		deltas = append(deltas, delta)
+	}
+
+	for _, r := range current {
+		if !seen[r.Name] {
+			deltas = append(deltas, BaselineDelta{
+				Name:            r.Name,
+				CurrentProfit:   r.TotalProfit,
+				CurrentSharpe:   r.SharpeRatio,
+				CurrentTrades:   r.TradeCount,
+				MissingBaseline: true,
+			})
+		}
+	}
+
+	// Сначала худшие изменения, чтобы регрессии были наверху
+	sort.SliceStable(deltas, func(i, j int) bool {
+		if deltas[i].Regressed != deltas[j].Regressed {
+			return deltas[i].Regressed
+		}
+		return deltas[i].ProfitDelta() < deltas[j].ProfitDelta()
+	})
+
+	return deltas
+}
+
+// CountRegressions — число стратегий с регрессией
+func CountRegressions(deltas []BaselineDelta) int {
+	count := 0
+	for _, d := range deltas {
+		if d.Regressed {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteBaselineComparison — таблица изменений относительно базового отчета
+func WriteBaselineComparison(w io.Writer, deltas []BaselineDelta, tolerance float64) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("═", 100))
+	fmt.Fprintf(w, "📐 СРАВНЕНИЕ С БАЗОВЫМ ОТЧЕТОМ (допуск падения доходности: %.2f п.п.)\n", tolerance*100)
+	fmt.Fprintln(w, strings.Repeat("═", 100))
+	fmt.Fprintf(w, "   %-32s │ %9s │ %9s │ %9s │ %7s │ %s\n", "Стратегия", "База", "Сейчас", "Δ", "Δ Sharpe", "Сделки")
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+
+	for _, d := range deltas {
+		status := "✅"
+		if d.Regressed {
+			status = "❌"
+		}
+
+		switch {
+		case d.MissingCurrent:
+			fmt.Fprintf(w, "%s %-32s │ %+8.2f%% │ %9s │ %9s │ %7s │ нет в текущем прогоне\n",
+				status, d.Name, d.BaselineProfit*100, "—", "—", "—")
+		case d.MissingBaseline:
+			fmt.Fprintf(w, "🆕 %-32s │ %9s │ %+8.2f%% │ %9s │ %7s │ %d (новая стратегия)\n",
+				d.Name, "—", d.CurrentProfit*100, "—", "—", d.CurrentTrades)
+		default:
+			fmt.Fprintf(w, "%s %-32s │ %+8.2f%% │ %+8.2f%% │ %+8.2f%% │ %+7.2f │ %d → %d\n",
+				status, d.Name, d.BaselineProfit*100, d.CurrentProfit*100, d.ProfitDelta()*100,
+				d.CurrentSharpe-d.BaselineSharpe, d.BaselineTrades, d.CurrentTrades)
+		}
+	}
+
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+	fmt.Fprintf(w, "📐 Регрессий: %d из %d стратегий\n", CountRegressions(deltas), len(deltas))
+}
@@ -0,0 +1,49 @@
+package backtester
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompareToBaseline_FlagsRegressions(t *testing.T) {
+	baseline := []BenchmarkResult{
+		{Name: "stable", TotalProfit: 0.10, TradeCount: 5},
+		{Name: "worse", TotalProfit: 0.10, TradeCount: 5},
+		{Name: "gone", TotalProfit: 0.05, TradeCount: 2},
+	}
+
+	// Отчет проходит полный цикл: запись через JSONPrinter и чтение через LoadJSONReport
+	var buf bytes.Buffer
+	NewJSONPrinter(&buf).PrintComparison(baseline)
+	filename := filepath.Join(t.TempDir(), "baseline.json")
+	if err := os.WriteFile(filename, buf.Bytes(), 0644); err != nil {
+		t.Fatal(err)
+	}
+	report, err := LoadJSONReport(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	current := []BenchmarkResult{
+		{Name: "stable", TotalProfit: 0.095, TradeCount: 5},
+		{Name: "worse", TotalProfit: 0.07, TradeCount: 9},
+		{Name: "fresh", TotalProfit: -0.2, TradeCount: 1},
+	}
+	deltas := CompareToBaseline(current, report, 0.01)
+
+	regressed := map[string]bool{}
+	for _, d := range deltas {
+		regressed[d.Name] = d.Regressed
+	}
+	if regressed["stable"] || regressed["fresh"] {
+		t.Errorf("expected stable (within tolerance) and fresh (new) not to regress: %+v", deltas)
+	}
+	if !regressed["worse"] || !regressed["gone"] {
+		t.Errorf("expected worse and gone to regress: %+v", deltas)
+	}
+	if n := CountRegressions(deltas); n != 2 {
+		t.Errorf("expected 2 regressions, got %d", n)
+	}
+}
@@ -0,0 +1,118 @@
+package backtester
+
+import (
+	"fmt"
+	"time"
+
+	"bt/internal"
+)
+
+const (
+	// BenchmarkAnchorFirst — Buy & Hold покупает на первой свече
+	BenchmarkAnchorFirst = "first"
+	// BenchmarkAnchorTrade — Buy & Hold покупает на баре первого входа сравниваемой стратегии,
+	// чтобы бенчмарк не получал форы за время прогрева индикаторов
+	BenchmarkAnchorTrade = "trade"
+
+	buyAndHoldName = "buy_and_hold"
+)
+
+// BuyAndHoldBenchmark — Buy & Hold с покупкой на баре anchor (индекс исполнения, как FirstEntryIndex)
+func BuyAndHoldBenchmark(candles []internal.Candle, anchor int, slippage float64) BenchmarkResult {
+	signals := make([]internal.SignalType, len(candles))
+	// Сигнал ставится с учетом задержки исполнения, чтобы покупка пришлась именно на бар anchor
+	opts := benchmarkOptions(slippage)
+	signalIndex := anchor - opts.ExecutionDelay
+	if signalIndex < 0 {
+		signalIndex = 0
+	}
+	if signalIndex < len(signals) {
+		signals[signalIndex] = internal.BUY
+	}
+
+	result := internal.BacktestWithOptions(candles, signals, opts)
+	parameters := "BuyAndHold()"
+	if anchor > 0 {
+		parameters = fmt.Sprintf("BuyAndHold(anchor=%d)", anchor)
+	}
+
+	return BenchmarkResult{
+		Name:            buyAndHoldName,
+		TotalProfit:     result.TotalProfit,
+		CAGR:            result.CAGR,
+		TradeCount:      result.TradeCount,
+		FinalPortfolio:  result.FinalPortfolio,
+		SharpeRatio:     result.SharpeRatio,
+		SortinoRatio:    result.SortinoRatio,
+		MaxDrawdown:     result.MaxDrawdown,
+		WinningTrades:   result.WinningTrades,
+		LosingTrades:    result.LosingTrades,
+		AvgWin:          result.AvgWin,
+		AvgLoss:         result.AvgLoss,
+		ProfitFactor:    result.ProfitFactor,
+		Expectancy:      result.Expectancy,
+		AvgMFE:          result.AvgMFE,
+		AvgMAE:          result.AvgMAE,
+		TimeInMarket:    result.TimeInMarket,
+		Parameters:      parameters,
+		Slippage:        slippage,
+		FirstEntryIndex: result.FirstEntryIndex,
+		NonFinite:       result.NonFinite,
+		Signals:         NewSignalStats(signals),
+		Equity:          result.PortfolioValues, // одна кривая — линия сравнения на графике роста
+	}
+}
+
+// benchmarkOptions — параметры движка для Buy & Hold: издержки, капитал и задержка исполнения
+// как у стратегий, но без фильтров входа и принудительных выходов, которые превратили бы бенчмарк
+// в еще одну стратегию. Режимный фильтр на баре 0 всегда видит NORMAL (окно еще не заполнено),
+// прерыватель и пауза после выхода отклоняют или откладывают единственную покупку, а стоп-лосс
+// и тейк-профит закрывают позицию, которую Buy & Hold держит до конца
+func benchmarkOptions(slippage float64) internal.BacktestOptions {
+	opts := internal.DefaultBacktestOptions()
+	opts.Slippage = slippage
+	opts.RegimeFilter = ""
+	opts.BreakerTrip = 0
+	opts.BreakerReset = 0
+	opts.MinHoldBars = 0
+	opts.CooldownBars = 0
+	opts.Exits = internal.ExitRules{}
+	return opts
+}
+
+// benchmarkAnchor — бар, с которого Buy & Hold сравним со стратегиями: самый ранний первый вход среди них
+// (в режиме all у стратегий разный прогрев, и одна общая строка бенчмарка не может совпасть с каждой)
+func benchmarkAnchor(results []BenchmarkResult) int {
+	anchor := -1
+	for _, r := range results {
+		if r.Name == buyAndHoldName || r.FirstEntryIndex < 0 {
+			continue
+		}
+		if anchor < 0 || r.FirstEntryIndex < anchor {
+			anchor = r.FirstEntryIndex
+		}
+	}
+	if anchor < 0 {
+		return 0
+	}
+	return anchor
+}
+
+// computeBuyAndHold — бенчмарк Buy & Hold для сравнения со стратегиями; единственная точка расчета
+// для одиночного и параллельного запуска, со временем собственного прогона
+func computeBuyAndHold(candles []internal.Candle, anchor int, slippage float64) BenchmarkResult {
+	startTime := time.Now()
+	result := BuyAndHoldBenchmark(candles, anchor, slippage)
+	result.ExecutionTime = time.Since(startTime)
+	return result
+}
+
+// replaceBuyAndHold — заменяет результат buy_and_hold из реестра стратегий бенчмарком computeBuyAndHold
+func replaceBuyAndHold(results []BenchmarkResult, benchmark BenchmarkResult) {
+	for i := range results {
+		if results[i].Name == buyAndHoldName {
+			results[i] = benchmark
+			return
+		}
+	}
+}
@@ -0,0 +1,112 @@
+package backtester
+
+import (
+	"math"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestBuyAndHoldBenchmark_AnchorSkipsWarmUp(t *testing.T) {
+	// Рост первые 50 баров, пока стратегия прогревается, затем боковик
+	candles := make([]internal.Candle, 100)
+	for i := range candles {
+		price := 100.0 + float64(min(i, 50))
+		candles[i] = internal.Candle{Close: internal.Price(price)}
+	}
+
+	// Стратегия с прогревом 50 баров: первый вход на баре 50
+	results := []BenchmarkResult{
+		{Name: "warm_up_heavy", FirstEntryIndex: 50},
+		{Name: "never_trades", FirstEntryIndex: -1},
+		BuyAndHoldBenchmark(candles, 0, 0),
+	}
+	fromFirst := results[2].TotalProfit
+
+	replaceBuyAndHold(results, computeBuyAndHold(candles, benchmarkAnchor(results), 0))
+	anchored := results[2]
+	if anchored.FirstEntryIndex != 50 {
+		t.Fatalf("Expected anchored benchmark to buy at bar 50, got %d", anchored.FirstEntryIndex)
+	}
+	if fromFirst < 0.49 || anchored.TotalProfit > 1e-9 || anchored.TotalProfit < -1e-9 {
+		t.Errorf("Expected ~+50%% from the first candle and ~0%% from bar 50, got %.4f and %.4f", fromFirst, anchored.TotalProfit)
+	}
+}
+
+func TestBuyAndHold_SameBenchmarkInSingleAndParallelRunners(t *testing.T) {
+	candles := []internal.Candle{{Close: 100}, {Close: 90}, {Close: 125}}
+
+	// Без проскальзывания бенчмарк — чистое изменение цены от первой свечи до последней
+	if profit := computeBuyAndHold(candles, 0, 0).TotalProfit; math.Abs(profit-(125.0-100)/100) > 1e-9 {
+		t.Errorf("Expected (last-first)/first = 0.25, got %.6f", profit)
+	}
+
+	// Собственное проскальзывание buy_and_hold из файла конфигурации учитывается обоими раннерами
+	base := BaseStrategyRunner{slipping: 0.01, slippages: map[string]float64{buyAndHoldName: 0.5}}
+	single := &SingleStrategyRunner{BaseStrategyRunner: base}
+	parallel := &ParallelStrategyRunner{BaseStrategyRunner: base}
+
+	expected := 125/(100+0.5) - 1 // покупка по первой цене с проскальзыванием, позиция открыта до конца
+	for _, runner := range []StrategyRunner{single, parallel} {
+		result := runner.BuyAndHold(candles, 0)
+		if math.Abs(result.TotalProfit-expected) > 1e-9 {
+			t.Errorf("%T: expected benchmark profit %.6f, got %.6f", runner, expected, result.TotalProfit)
+		}
+		if result.ExecutionTime <= 0 {
+			t.Errorf("%T: expected benchmark to measure its own execution time", runner)
+		}
+	}
+}
+
+func TestBuyAndHoldBenchmark_AppliesEngineCommission(t *testing.T) {
+	candles := []internal.Candle{{Close: 100}, {Close: 110}, {Close: 120}}
+
+	defaults := internal.DefaultBacktestOptions()
+	defer internal.SetDefaultBacktestOptions(defaults)
+
+	free := BuyAndHoldBenchmark(candles, 0, 0)
+	internal.SetDefaultBacktestOptions(internal.BacktestOptions{CommissionModel: internal.FixedCommission{PerTrade: 100}})
+	charged := BuyAndHoldBenchmark(candles, 0, 0)
+
+	// Позиция не закрывается, поэтому списана только комиссия входа: 1% капитала
+	if math.Abs(charged.FinalPortfolio-free.FinalPortfolio*0.99) > 1e-6 {
+		t.Errorf("Expected benchmark to pay the entry fee, got %.4f vs %.4f without commission", charged.FinalPortfolio, free.FinalPortfolio)
+	}
+}
+
+func TestBuyAndHoldBenchmark_IgnoresRegimeFilter(t *testing.T) {
+	candles := make([]internal.Candle, 60)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100 + float64(i))}
+	}
+
+	defaults := internal.DefaultBacktestOptions()
+	defer internal.SetDefaultBacktestOptions(defaults)
+
+	plain := BuyAndHoldBenchmark(candles, 0, 0)
+	// Бар 0 всегда NORMAL, пока окно режима не заполнено: фильтр отклонил бы единственную покупку
+	internal.SetDefaultBacktestOptions(internal.BacktestOptions{RegimeFilter: internal.RegimeNormal, RegimeWindow: 10})
+	filtered := BuyAndHoldBenchmark(candles, 0, 0)
+
+	if filtered.FirstEntryIndex != 0 || math.Abs(filtered.TotalProfit-plain.TotalProfit) > 1e-9 {
+		t.Errorf("Expected regime filter not to affect benchmark, got entry %d and profit %.4f vs %.4f",
+			filtered.FirstEntryIndex, filtered.TotalProfit, plain.TotalProfit)
+	}
+}
+
+func TestBuyAndHoldBenchmark_IgnoresExitRules(t *testing.T) {
+	candles := []internal.Candle{{Close: 100}, {Close: 110}, {Close: 130}, {Close: 160}}
+
+	defaults := internal.DefaultBacktestOptions()
+	defer internal.SetDefaultBacktestOptions(defaults)
+
+	plain := BuyAndHoldBenchmark(candles, 0, 0)
+	// Тейк-профит 5% закрыл бы позицию на втором баре и превратил бенчмарк в одну сделку +5%
+	internal.SetDefaultBacktestOptions(internal.BacktestOptions{Exits: internal.ExitRules{TakeProfitPercent: 0.05, StopLossPercent: 0.01}})
+	withExits := BuyAndHoldBenchmark(candles, 0, 0)
+
+	if math.Abs(withExits.TotalProfit-plain.TotalProfit) > 1e-9 || withExits.TradeCount != plain.TradeCount {
+		t.Errorf("Expected exit rules not to affect benchmark, got profit %.4f (%d trades) vs %.4f (%d trades)",
+			withExits.TotalProfit, withExits.TradeCount, plain.TotalProfit, plain.TradeCount)
+	}
+}
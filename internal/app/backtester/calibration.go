@@ -0,0 +1,186 @@
+package backtester
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"math"
+	"strings"
+
+	"bt/internal"
+)
+
+// CalibrationBin — предсказания с уверенностью из одного интервала и доля сбывшихся
+type CalibrationBin struct {
+	Low, High      float64 // границы интервала уверенности [Low, High)
+	Count          int     // предсказаний, проверенных по ценам
+	Hits           int     // сбывшихся предсказаний
+	MeanConfidence float64 // средняя заявленная уверенность
+}
+
+// HitRate — доля сбывшихся предсказаний в интервале
+func (b CalibrationBin) HitRate() float64 {
+	if b.Count == 0 {
+		return 0
+	}
+	return float64(b.Hits) / float64(b.Count)
+}
+
+// CalibrationResult — таблица надежности предсказаний стратегии
+type CalibrationResult struct {
+	Strategy   string
+	Parameters string
+	Horizon    int // баров после предсказанного сигнала, по которым проверяется направление цены
+	Bins       []CalibrationBin
+	Predicted  int     // баров, на которых стратегия выдала предсказание
+	Unresolved int     // предсказаний, чей бар проверки лежит за концом данных
+	Brier      float64 // средний квадрат ошибки уверенности относительно исхода (0 — идеально)
+}
+
+// Resolved — число предсказаний, проверенных по ценам
+func (r CalibrationResult) Resolved() int {
+	return r.Predicted - r.Unresolved
+}
+
+// RunConfidenceCalibration — проверка уверенности предсказаний V2-стратегии на истории.
+// Конфигурация подбирается оптимизатором на всех свечах (как при обычном прогоне), затем на каждом баре
+// стратегия предсказывает следующий сигнал только по свечам до этого бара включительно
+func RunConfidenceCalibration(strategyName string, candles []internal.Candle, horizon, bins int) (*CalibrationResult, error) {
+	if horizon < 1 {
+		return nil, fmt.Errorf("горизонт проверки должен быть не меньше 1 бара, получено %d", horizon)
+	}
+	if bins < 1 {
+		return nil, fmt.Errorf("число интервалов уверенности должно быть не меньше 1, получено %d", bins)
+	}
+	strategy, ok := internal.GetStrategyV2(strategyName)
+	if !ok {
+		return nil, fmt.Errorf("стратегия %s не поддерживает --calibration (нужна V2-стратегия с предсказанием сигналов)", strategyName)
+	}
+	strategyBase, ok := strategy.(*internal.StrategyBase)
+	if !ok {
+		return nil, fmt.Errorf("стратегия %s не поддерживает --calibration", strategyName)
+	}
+
+	internal.ResetCache()
+	config := strategyBase.Optimize(context.Background(), candles, strategyBase)
+
+	// Индикаторы каждого префикса кэшируются отдельно, поэтому кэш сбрасывается перед каждым, чтобы не расти
+	predict := func(history []internal.Candle) *internal.FutureSignal {
+		internal.ResetCache()
+		return strategyBase.PredictNextSignal(history, config)
+	}
+	result := calibrateConfidence(candles, predict, horizon, bins)
+	internal.ResetCache()
+
+	if result.Predicted == 0 {
+		return nil, fmt.Errorf("стратегия %s не выдала ни одного предсказания", strategyName)
+	}
+	result.Strategy = strategyName
+	if config != nil {
+		result.Parameters = config.String()
+	}
+	return result, nil
+}
+
+// calibrateConfidence — на каждом баре i предсказание по candles[:i+1] сопоставляется с ценой:
+// BUY сбылся, если через horizon баров после предсказанного бара цена выше, чем на нем, SELL — если ниже.
+// Соседние бары часто предсказывают одно и то же событие, и каждое такое предсказание считается отдельно
+func calibrateConfidence(candles []internal.Candle, predict func([]internal.Candle) *internal.FutureSignal, horizon, bins int) *CalibrationResult {
+	result := &CalibrationResult{Horizon: horizon, Bins: make([]CalibrationBin, bins)}
+	for b := range result.Bins {
+		result.Bins[b].Low = float64(b) / float64(bins)
+		result.Bins[b].High = float64(b+1) / float64(bins)
+	}
+
+	confidenceSums := make([]float64, bins)
+	brierSum := 0.0
+	for i := 1; i < len(candles); i++ {
+		history := candles[:i+1]
+		prediction := predict(history)
+		if prediction == nil || prediction.SignalType == internal.HOLD {
+			continue
+		}
+		result.Predicted++
+
+		target := i + predictedOffset(history, prediction.Date)
+		if target+horizon >= len(candles) {
+			result.Unresolved++
+			continue
+		}
+
+		move := candles[target+horizon].Close.ToFloat64() - candles[target].Close.ToFloat64()
+		hit := (prediction.SignalType == internal.BUY && move > 0) || (prediction.SignalType == internal.SELL && move < 0)
+
+		confidence := math.Max(0, math.Min(1, prediction.Confidence))
+		b := int(confidence * float64(bins))
+		if b == bins {
+			b = bins - 1
+		}
+		result.Bins[b].Count++
+		confidenceSums[b] += confidence
+		outcome := 0.0
+		if hit {
+			result.Bins[b].Hits++
+			outcome = 1
+		}
+		brierSum += (confidence - outcome) * (confidence - outcome)
+	}
+
+	for b := range result.Bins {
+		if result.Bins[b].Count > 0 {
+			result.Bins[b].MeanConfidence = confidenceSums[b] / float64(result.Bins[b].Count)
+		}
+	}
+	if resolved := result.Resolved(); resolved > 0 {
+		result.Brier = brierSum / float64(resolved)
+	}
+	return result
+}
+
+// predictedOffset — через сколько баров после последней свечи наступит предсказанная дата
+// (стратегии считают дату по среднему интервалу свечей); не меньше одного бара
+func predictedOffset(history []internal.Candle, date int64) int {
+	last := history[len(history)-1].ToTime().Unix()
+	interval := (last - history[0].ToTime().Unix()) / int64(len(history)-1)
+	if interval <= 0 {
+		return 1
+	}
+	offset := int(math.Round(float64(date-last) / float64(interval)))
+	if offset < 1 {
+		return 1
+	}
+	return offset
+}
+
+// WriteConfidenceCalibration — таблица надежности: заявленная уверенность против доли сбывшихся предсказаний
+func WriteConfidenceCalibration(w io.Writer, result *CalibrationResult) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("═", 80))
+	fmt.Fprintf(w, "🎯 КАЛИБРОВКА УВЕРЕННОСТИ: %s (проверка через %d баров после сигнала)\n", result.Strategy, result.Horizon)
+	fmt.Fprintln(w, strings.Repeat("═", 80))
+	if result.Parameters != "" {
+		fmt.Fprintf(w, "⚙️ Параметры: %s\n", result.Parameters)
+	}
+	fmt.Fprintf(w, "%-13s │ %8s │ %10s │ %10s │ %10s\n", "Уверенность", "Прогнозы", "Заявлено", "Сбылось", "Разница")
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+
+	for _, b := range result.Bins {
+		label := fmt.Sprintf("%3.0f–%3.0f%%", b.Low*100, b.High*100)
+		if b.Count == 0 {
+			fmt.Fprintf(w, "%-13s │ %8d │ %10s │ %10s │ %10s\n", label, 0, "-", "-", "-")
+			continue
+		}
+		gap := b.HitRate() - b.MeanConfidence
+		marker := ""
+		if math.Abs(gap) > 0.1 {
+			marker = " ⚠️"
+		}
+		fmt.Fprintf(w, "%-13s │ %8d │ %9.1f%% │ %9.1f%% │ %+8.1f п.п.%s\n",
+			label, b.Count, b.MeanConfidence*100, b.HitRate()*100, gap*100, marker)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+	fmt.Fprintf(w, "📊 Предсказаний: %d, проверено: %d, за концом данных: %d\n", result.Predicted, result.Resolved(), result.Unresolved)
+	if result.Resolved() > 0 {
+		fmt.Fprintf(w, "📐 Brier score: %.4f (0 — идеальная калибровка, 0.25 — уверенность 50%% на все)\n", result.Brier)
+	}
+}
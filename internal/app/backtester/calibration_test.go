@@ -0,0 +1,97 @@
+package backtester
+
+import (
+	"bytes"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"bt/internal"
+)
+
+// zigzagCandles — часовые свечи: цена растет на четных барах и падает на нечетных
+func zigzagCandles(n int) []internal.Candle {
+	start := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	candles := make([]internal.Candle, n)
+	for i := range candles {
+		price := 100.0
+		if i%2 == 1 {
+			price = 101
+		}
+		candles[i] = internal.Candle{Close: internal.Price(price), ParsedTime: start.Add(time.Duration(i) * time.Hour)}
+	}
+	return candles
+}
+
+func TestCalibrateConfidence_BinsHitRateByConfidence(t *testing.T) {
+	candles := zigzagCandles(40)
+
+	// Предсказание на следующий бар: уверенные (0.9) всегда угадывают направление, неуверенные (0.3) всегда ошибаются
+	predict := func(history []internal.Candle) *internal.FutureSignal {
+		target := len(history) // индекс предсказанного бара
+		correct := internal.SELL
+		if target%2 == 0 {
+			correct = internal.BUY // с четного бара цена растет
+		}
+		signal := &internal.FutureSignal{
+			SignalType: correct,
+			Date:       history[len(history)-1].ParsedTime.Add(time.Hour).Unix(),
+			Confidence: 0.9,
+		}
+		if target%4 >= 2 {
+			signal.Confidence = 0.3
+			signal.SignalType = internal.BUY
+			if correct == internal.BUY {
+				signal.SignalType = internal.SELL
+			}
+		}
+		return signal
+	}
+
+	result := calibrateConfidence(candles, predict, 1, 5)
+
+	if result.Predicted != len(candles)-1 {
+		t.Fatalf("predicted: got %d, want %d", result.Predicted, len(candles)-1)
+	}
+	// Предсказания с последних двух баров не проверить: предсказанный бар или бар после него за концом данных
+	if result.Unresolved != 2 {
+		t.Errorf("unresolved: got %d, want 2", result.Unresolved)
+	}
+	low, high := result.Bins[1], result.Bins[4]
+	if low.Count == 0 || low.HitRate() != 0 {
+		t.Errorf("low-confidence bin: got %d predictions, hit rate %.2f, want all misses", low.Count, low.HitRate())
+	}
+	if high.Count == 0 || high.HitRate() != 1 {
+		t.Errorf("high-confidence bin: got %d predictions, hit rate %.2f, want all hits", high.Count, high.HitRate())
+	}
+	if math.Abs(high.MeanConfidence-0.9) > 1e-9 {
+		t.Errorf("high-confidence bin mean: got %.3f, want 0.9", high.MeanConfidence)
+	}
+	if low.Count+high.Count != result.Resolved() {
+		t.Errorf("bins hold %d predictions, resolved %d", low.Count+high.Count, result.Resolved())
+	}
+
+	wantBrier := (float64(low.Count)*0.3*0.3 + float64(high.Count)*0.1*0.1) / float64(result.Resolved())
+	if math.Abs(result.Brier-wantBrier) > 1e-9 {
+		t.Errorf("brier: got %.4f, want %.4f", result.Brier, wantBrier)
+	}
+
+	var buf bytes.Buffer
+	WriteConfidenceCalibration(&buf, result)
+	if !strings.Contains(buf.String(), "80–100%") || !strings.Contains(buf.String(), "Brier") {
+		t.Errorf("unexpected report:\n%s", buf.String())
+	}
+}
+
+func TestCalibrateConfidence_SkipsMissingPredictions(t *testing.T) {
+	candles := zigzagCandles(20)
+	predict := func(history []internal.Candle) *internal.FutureSignal {
+		return nil
+	}
+
+	result := calibrateConfidence(candles, predict, 3, 4)
+	if result.Predicted != 0 || result.Resolved() != 0 || result.Brier != 0 {
+		t.Errorf("expected empty result, got %+v", result)
+	}
+}
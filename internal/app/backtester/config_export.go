@@ -0,0 +1,138 @@
+package backtester
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"bt/internal"
+)
+
+const (
+	optimizedConfigsFile = "optimized_configs.json"
+	optimizedConfigsDir  = "optimized_configs"
+)
+
+// SaveConfigsSpec — что сохранять после оптимизации (флаг --save-configs)
+type SaveConfigsSpec struct {
+	Split     bool   // дополнительно — по файлу на стратегию в optimized_configs/
+	TopN      int    // дополнительно — файл только с N лучшими стратегиями (0 = нет)
+	TopMetric string // метрика отбора лучших: profit или sharpe
+}
+
+// ParseSaveConfigs — разбирает список через запятую: combined, split, top:N или top:N:sharpe.
+// Общий файл optimized_configs.json сохраняется всегда
+func ParseSaveConfigs(s string) (SaveConfigsSpec, error) {
+	spec := SaveConfigsSpec{TopMetric: "profit"}
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		switch {
+		case part == "" || part == "combined":
+		case part == "split":
+			spec.Split = true
+		case strings.HasPrefix(part, "top:"):
+			fields := strings.Split(strings.TrimPrefix(part, "top:"), ":")
+			n, err := strconv.Atoi(fields[0])
+			if err != nil || n <= 0 || len(fields) > 2 {
+				return spec, fmt.Errorf("неверное значение '%s' (ожидается top:N или top:N:метрика, N > 0)", part)
+			}
+			spec.TopN = n
+			if len(fields) == 2 {
+				if fields[1] != "profit" && fields[1] != "sharpe" {
+					return spec, fmt.Errorf("неизвестная метрика '%s' (ожидается profit или sharpe)", fields[1])
+				}
+				spec.TopMetric = fields[1]
+			}
+		default:
+			return spec, fmt.Errorf("неизвестный режим сохранения конфигураций '%s' (ожидается combined, split или top:N)", part)
+		}
+	}
+	return spec, nil
+}
+
+// SaveOptimizedConfigs — сохраняет оптимизированные конфигурации в каталог outDir: общий файл и,
+// по спецификации, файлы отдельных стратегий и файл лучших N. Каждый файл — в формате --config
+func SaveOptimizedConfigs(outDir string, configs map[string]internal.StrategyConfig, results []BenchmarkResult, spec SaveConfigsSpec) {
+	if filename, err := outputPath(outDir, optimizedConfigsFile); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	} else if err := writeConfigsFile(filename, configs); err != nil {
+		fmt.Printf("❌ %v\n", err)
+	} else {
+		fmt.Printf("💾 Оптимизированные конфигурации сохранены в %s\n", filename)
+	}
+
+	if spec.Split {
+		if dir, err := saveSplitConfigs(outDir, configs); err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			fmt.Printf("💾 Конфигурации сохранены по отдельности в %s/ (стратегий: %d)\n", dir, len(configs))
+		}
+	}
+
+	if spec.TopN > 0 {
+		top := make(map[string]internal.StrategyConfig, spec.TopN)
+		for _, r := range topResults(results, spec.TopN, spec.TopMetric) {
+			if config, ok := configs[r.Name]; ok {
+				top[r.Name] = config
+			}
+		}
+		if filename, err := outputPath(outDir, fmt.Sprintf("optimized_configs_top%d.json", spec.TopN)); err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else if err := writeConfigsFile(filename, top); err != nil {
+			fmt.Printf("❌ %v\n", err)
+		} else {
+			fmt.Printf("🏆 Лучшие по %s конфигурации сохранены в %s (стратегий: %d)\n", spec.TopMetric, filename, len(top))
+		}
+	}
+}
+
+// saveSplitConfigs — по файлу на стратегию в подкаталоге outDir; каждый файл можно передать в --config.
+// Возвращает путь к подкаталогу
+func saveSplitConfigs(outDir string, configs map[string]internal.StrategyConfig) (string, error) {
+	dir, err := outputPath(outDir, optimizedConfigsDir)
+	if err != nil {
+		return "", err
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("ошибка создания каталога %s: %w", dir, err)
+	}
+	for name, config := range configs {
+		filename := filepath.Join(dir, name+".json")
+		if err := writeConfigsFile(filename, map[string]internal.StrategyConfig{name: config}); err != nil {
+			return "", err
+		}
+	}
+	return dir, nil
+}
+
+// topResults — лучшие n результатов по метрике; нечисловые результаты не отбираются
+func topResults(results []BenchmarkResult, n int, metric string) []BenchmarkResult {
+	ranked := finiteResults(results)
+	if metric == "sharpe" {
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].SharpeRatio > ranked[j].SharpeRatio
+		})
+	} else {
+		sortResultsByProfit(ranked)
+	}
+	if len(ranked) > n {
+		ranked = ranked[:n]
+	}
+	return ranked
+}
+
+// writeConfigsFile — сериализует конфигурации в JSON-файл
+func writeConfigsFile(filename string, configs map[string]internal.StrategyConfig) error {
+	data, err := json.MarshalIndent(configs, "", "  ")
+	if err != nil {
+		return fmt.Errorf("ошибка сериализации конфигураций: %w", err)
+	}
+	if err := os.WriteFile(filename, data, 0644); err != nil {
+		return fmt.Errorf("ошибка сохранения файла конфигураций %s: %w", filename, err)
+	}
+	return nil
+}
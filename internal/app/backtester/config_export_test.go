@@ -0,0 +1,35 @@
+package backtester
+
+import "testing"
+
+func TestParseSaveConfigs(t *testing.T) {
+	spec, err := ParseSaveConfigs("split,top:5:sharpe")
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if !spec.Split || spec.TopN != 5 || spec.TopMetric != "sharpe" {
+		t.Errorf("Unexpected spec %+v", spec)
+	}
+
+	for _, bad := range []string{"top:0", "top:x", "top:3:drawdown", "all"} {
+		if _, err := ParseSaveConfigs(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
+
+func TestTopResults_RanksByMetricAndSkipsNonFinite(t *testing.T) {
+	results := []BenchmarkResult{
+		{Name: "steady", TotalProfit: 0.05, SharpeRatio: 2.0},
+		{Name: "lucky", TotalProfit: 0.20, SharpeRatio: 0.5},
+		{Name: "broken", TotalProfit: -1, NonFinite: true},
+		{Name: "flat", TotalProfit: 0.01, SharpeRatio: 1.0},
+	}
+
+	if top := topResults(results, 2, "profit"); top[0].Name != "lucky" || top[1].Name != "steady" {
+		t.Errorf("Unexpected top by profit: %s, %s", top[0].Name, top[1].Name)
+	}
+	if top := topResults(results, 5, "sharpe"); len(top) != 3 || top[0].Name != "steady" || top[1].Name != "flat" {
+		t.Errorf("Unexpected top by sharpe: %+v", top)
+	}
+}
@@ -0,0 +1,231 @@
+package backtester
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	"bt/internal"
+)
+
+// FoldResult — результат стратегии на одном фолде кросс-валидации
+type FoldResult struct {
+	Fold         int
+	TrainCandles int
+	TestCandles  int
+	InSample     float64 // прибыль лучшей конфигурации на обучающих фолдах
+	OutOfSample  float64 // прибыль той же конфигурации на отложенном фолде
+	Trades       int     // сделки на отложенном фолде
+	Parameters   string
+}
+
+// CrossValidationResult — результаты k-fold кросс-валидации одной стратегии
+type CrossValidationResult struct {
+	Strategy string
+	Folds    []FoldResult
+}
+
+// MeanOutOfSample — средняя прибыль на отложенных фолдах
+func (r CrossValidationResult) MeanOutOfSample() float64 {
+	return r.mean(func(f FoldResult) float64 { return f.OutOfSample })
+}
+
+// MeanInSample — средняя прибыль на обучающих данных
+func (r CrossValidationResult) MeanInSample() float64 {
+	return r.mean(func(f FoldResult) float64 { return f.InSample })
+}
+
+// StdDevOutOfSample — стандартное отклонение прибыли по отложенным фолдам
+func (r CrossValidationResult) StdDevOutOfSample() float64 {
+	if len(r.Folds) < 2 {
+		return 0
+	}
+	mean := r.MeanOutOfSample()
+	sum := 0.0
+	for _, f := range r.Folds {
+		sum += (f.OutOfSample - mean) * (f.OutOfSample - mean)
+	}
+	return math.Sqrt(sum / float64(len(r.Folds)-1))
+}
+
+// Unstable — разброс по фолдам больше средней прибыли: результат зависит от выбора периода, вероятна подгонка
+func (r CrossValidationResult) Unstable() bool {
+	return r.StdDevOutOfSample() > math.Abs(r.MeanOutOfSample())
+}
+
+func (r CrossValidationResult) mean(value func(FoldResult) float64) float64 {
+	if len(r.Folds) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, f := range r.Folds {
+		sum += value(f)
+	}
+	return sum / float64(len(r.Folds))
+}
+
+// foldBounds — границы k непрерывных фолдов; остаток от деления распределяется по первым фолдам
+func foldBounds(n, k int) [][2]int {
+	bounds := make([][2]int, k)
+	start := 0
+	for i := 0; i < k; i++ {
+		size := n / k
+		if i < n%k {
+			size++
+		}
+		bounds[i] = [2]int{start, start + size}
+		start += size
+	}
+	return bounds
+}
+
+// RunCrossValidation — k-fold кросс-валидация: свечи делятся на k непрерывных фолдов, стратегия оптимизируется
+// на остальных k-1 (склеенных по порядку) и проверяется на отложенном. Сигналы отложенного фолда считаются
+// только по его свечам, поэтому заглядывания в обучающие данные нет, но часть фолда уходит на разогрев индикаторов.
+// Индикаторы каждого шага кэшируются отдельно, поэтому кэш сбрасывается перед каждым шагом, чтобы не расти
+func RunCrossValidation(strategyName string, candles []internal.Candle, folds int, slippage float64) (*CrossValidationResult, error) {
+	if folds < 2 {
+		return nil, fmt.Errorf("число фолдов должно быть не меньше 2, получено %d", folds)
+	}
+	if len(candles) < folds*2 {
+		return nil, fmt.Errorf("%d свечей недостаточно для %d фолдов", len(candles), folds)
+	}
+
+	optimize, err := foldOptimizer(strategyName, slippage)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CrossValidationResult{Strategy: strategyName}
+	for i, bounds := range foldBounds(len(candles), folds) {
+		test := candles[bounds[0]:bounds[1]]
+		train := make([]internal.Candle, 0, len(candles)-len(test))
+		train = append(train, candles[:bounds[0]]...)
+		train = append(train, candles[bounds[1]:]...)
+
+		internal.ResetCache()
+		generate, parameters := optimize(train)
+		trainSignals := generate(train)
+		if err := internal.ValidateSignals(train, trainSignals); err != nil {
+			return nil, fmt.Errorf("%s, фолд %d: %w", strategyName, i+1, err)
+		}
+		inSample := internal.Backtest(train, trainSignals, slippage)
+
+		internal.ResetCache()
+		testSignals := generate(test)
+		if err := internal.ValidateSignals(test, testSignals); err != nil {
+			return nil, fmt.Errorf("%s, фолд %d: %w", strategyName, i+1, err)
+		}
+		outOfSample := internal.Backtest(test, testSignals, slippage)
+
+		result.Folds = append(result.Folds, FoldResult{
+			Fold:         i + 1,
+			TrainCandles: len(train),
+			TestCandles:  len(test),
+			InSample:     inSample.TotalProfit,
+			OutOfSample:  outOfSample.TotalProfit,
+			Trades:       outOfSample.TradeCount,
+			Parameters:   parameters,
+		})
+	}
+
+	return result, nil
+}
+
+// foldOptimizer — оптимизация стратегии (V1 или V2) на обучающих свечах; возвращает генератор сигналов
+// с найденной конфигурацией и ее описание
+func foldOptimizer(strategyName string, slippage float64) (func([]internal.Candle) (func([]internal.Candle) []internal.SignalType, string), error) {
+	if strategy, ok := internal.GetStrategyV2(strategyName); ok {
+		return func(train []internal.Candle) (func([]internal.Candle) []internal.SignalType, string) {
+			config := strategy.Optimize(context.Background(), train, strategy)
+			return func(candles []internal.Candle) []internal.SignalType {
+				return strategy.GenerateSignals(candles, config)
+			}, config.String()
+		}, nil
+	}
+
+	if !isRegisteredV1(strategyName) {
+		return nil, fmt.Errorf("стратегия %s не найдена", strategyName)
+	}
+	strategy := internal.GetStrategy(strategyName)
+	strategy.SetSlippage(slippage)
+	return func(train []internal.Candle) (func([]internal.Candle) []internal.SignalType, string) {
+		config := strategy.OptimizeWithConfig(context.Background(), train)
+		parameters := ""
+		if config != nil {
+			parameters = config.DefaultConfigString()
+		}
+		return func(candles []internal.Candle) []internal.SignalType {
+			return strategy.GenerateSignalsWithConfig(candles, config)
+		}, parameters
+	}, nil
+}
+
+// isRegisteredV1 — есть ли стратегия в реестре V1 (GetStrategy завершает процесс для неизвестного имени)
+func isRegisteredV1(name string) bool {
+	for _, registered := range internal.GetStrategyNames() {
+		if registered == name {
+			return true
+		}
+	}
+	return false
+}
+
+// PrintCrossValidation — таблица по фолдам для одной стратегии
+func PrintCrossValidation(result *CrossValidationResult) {
+	fmt.Println("\n" + strings.Repeat("═", 100))
+	fmt.Printf("🧪 K-FOLD КРОСС-ВАЛИДАЦИЯ: %s (фолдов: %d)\n", result.Strategy, len(result.Folds))
+	fmt.Println(strings.Repeat("═", 100))
+	fmt.Printf("%-5s │ %8s │ %8s │ %12s │ %12s │ %7s │ %s\n",
+		"Фолд", "Обучение", "Проверка", "In-sample", "Out-of-sample", "Сделки", "Параметры")
+	fmt.Println(strings.Repeat("─", 100))
+
+	for _, f := range result.Folds {
+		fmt.Printf("%-5d │ %8d │ %8d │ %+11.2f%% │ %+12.2f%% │ %7d │ %s\n",
+			f.Fold, f.TrainCandles, f.TestCandles, f.InSample*100, f.OutOfSample*100, f.Trades, f.Parameters)
+	}
+	fmt.Println(strings.Repeat("─", 100))
+	printCrossValidationVerdict(*result)
+}
+
+// PrintCrossValidationSummary — сводка по стратегиям: средняя прибыль вне выборки и ее разброс
+func PrintCrossValidationSummary(results []CrossValidationResult) {
+	fmt.Println("\n" + strings.Repeat("═", 100))
+	fmt.Println("🧪 K-FOLD КРОСС-ВАЛИДАЦИЯ: СВОДКА")
+	fmt.Println(strings.Repeat("═", 100))
+	fmt.Printf("   %-32s │ %12s │ %12s │ %9s │ %s\n", "Стратегия", "In-sample", "Out-of-sample", "σ", "Фолды")
+	fmt.Println(strings.Repeat("─", 100))
+
+	for _, r := range results {
+		marker := "✅"
+		if r.Unstable() {
+			marker = "⚠️"
+		}
+		folds := make([]string, len(r.Folds))
+		for i, f := range r.Folds {
+			folds[i] = fmt.Sprintf("%+.1f%%", f.OutOfSample*100)
+		}
+		fmt.Printf("%s %-32s │ %+11.2f%% │ %+12.2f%% │ %8.2f%% │ %s\n",
+			marker, r.Strategy, r.MeanInSample()*100, r.MeanOutOfSample()*100, r.StdDevOutOfSample()*100,
+			strings.Join(folds, " "))
+	}
+	fmt.Println(strings.Repeat("─", 100))
+	fmt.Println("⚠️ — разброс по фолдам больше средней прибыли вне выборки: вероятна подгонка под период")
+}
+
+// printCrossValidationVerdict — итог по стратегии: средняя, разброс и разрыв между обучением и проверкой
+func printCrossValidationVerdict(r CrossValidationResult) {
+	mean, std := r.MeanOutOfSample(), r.StdDevOutOfSample()
+	fmt.Printf("📊 Вне выборки: среднее %+.2f%%, σ %.2f%% (дисперсия %.6f); на обучении: %+.2f%%\n",
+		mean*100, std*100, std*std, r.MeanInSample()*100)
+
+	switch {
+	case r.Unstable():
+		fmt.Println("🔴 Разброс по фолдам больше средней прибыли — результат зависит от периода, вероятна подгонка")
+	case mean <= 0:
+		fmt.Println("🟡 Стабильно, но без преимущества: средняя прибыль вне выборки не положительна")
+	default:
+		fmt.Println("🟢 Преимущество устойчиво по фолдам")
+	}
+}
@@ -0,0 +1,57 @@
+package backtester
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestFoldBounds_CoverAllCandlesContiguously(t *testing.T) {
+	bounds := foldBounds(10, 3)
+	want := [][2]int{{0, 4}, {4, 7}, {7, 10}}
+	for i := range want {
+		if bounds[i] != want[i] {
+			t.Fatalf("fold %d: got %v, want %v", i, bounds[i], want[i])
+		}
+	}
+}
+
+func TestCrossValidationResult_FlagsHighVariance(t *testing.T) {
+	stable := CrossValidationResult{Folds: []FoldResult{{OutOfSample: 0.04}, {OutOfSample: 0.05}, {OutOfSample: 0.06}}}
+	if math.Abs(stable.MeanOutOfSample()-0.05) > 1e-12 || stable.Unstable() {
+		t.Errorf("stable folds: mean %v, unstable %v", stable.MeanOutOfSample(), stable.Unstable())
+	}
+
+	overfit := CrossValidationResult{Folds: []FoldResult{{OutOfSample: 0.30}, {OutOfSample: -0.20}, {OutOfSample: -0.04}}}
+	if !overfit.Unstable() {
+		t.Errorf("expected unstable: mean %v, σ %v", overfit.MeanOutOfSample(), overfit.StdDevOutOfSample())
+	}
+}
+
+// truncatedFoldStrategy — truncatedStrategy под своим именем: реестр не допускает повторной регистрации
+type truncatedFoldStrategy struct {
+	truncatedStrategy
+}
+
+func (s *truncatedFoldStrategy) Name() string { return "truncated_fold_signals_test" }
+
+func TestRunCrossValidation_ReportsWrongLengthSignals(t *testing.T) {
+	strategy := &truncatedFoldStrategy{}
+	strategy.Config = &truncatedConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	candles := make([]internal.Candle, 30)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + float64(i))}
+	}
+
+	result, err := RunCrossValidation(strategy.Name(), candles, 3, 0.01)
+	if err == nil || result != nil {
+		t.Fatalf("expected an error instead of a result, got result %v", result)
+	}
+	if !strings.Contains(err.Error(), strategy.Name()) || !strings.Contains(err.Error(), "фолд 1") {
+		t.Errorf("expected error naming the strategy and the fold, got: %v", err)
+	}
+}
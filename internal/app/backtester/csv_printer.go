@@ -0,0 +1,95 @@
+package backtester
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"time"
+
+	"bt/internal"
+)
+
+// csvHeader — колонки CSV-отчета (машиночитаемые имена для таблиц)
+var csvHeader = []string{
+	"rank", "strategy", "category", "profit", "trades", "time_in_market", "final_portfolio", "sharpe",
+	"suppressed_exits", "below_floor_trades", "parameters", "execution_ms",
+	"next_signal", "next_signal_time", "next_signal_price", "next_signal_confidence",
+}
+
+// CSVPrinter — сравнительная таблица в CSV для Excel/Google Sheets.
+// Числа пишутся с точкой и без знаков валют/процентов, доли — как есть (0.0123 = 1.23%)
+type CSVPrinter struct {
+	out io.Writer
+}
+
+// NewCSVPrinter — конструктор для CSVPrinter
+func NewCSVPrinter(out io.Writer) *CSVPrinter {
+	return &CSVPrinter{out: out}
+}
+
+// PrintComparison — выводит результаты, отсортированные по доходности, с заголовком
+func (p *CSVPrinter) PrintComparison(results []BenchmarkResult) {
+	sortResultsByProfit(results)
+
+	w := csv.NewWriter(p.out)
+	if err := w.Write(csvHeader); err != nil {
+		fmt.Printf("❌ Ошибка записи CSV: %v\n", err)
+		return
+	}
+
+	for i, r := range results {
+		nextSignal, nextTime, nextPrice, nextConfidence := "", "", "", ""
+		if r.NextSignal != nil {
+			nextSignal = r.NextSignal.SignalType.String()
+			nextTime = time.Unix(r.NextSignal.Date, 0).UTC().Format(time.RFC3339)
+			nextPrice = formatCSVFloat(r.NextSignal.Price, 4)
+			nextConfidence = formatCSVFloat(r.NextSignal.Confidence, 4)
+		}
+
+		record := []string{
+			strconv.Itoa(i + 1),
+			r.Name,
+			internal.GetStrategyCategory(r.Name),
+			formatCSVProfit(r),
+			strconv.Itoa(r.TradeCount),
+			formatCSVFloat(r.TimeInMarket, 4),
+			formatCSVFloat(r.FinalPortfolio, 2),
+			formatCSVFloat(r.SharpeRatio, 4),
+			strconv.Itoa(r.SuppressedExits),
+			strconv.Itoa(r.BelowFloorTrades),
+			r.Parameters,
+			strconv.FormatInt(r.ExecutionTime.Milliseconds(), 10),
+			nextSignal,
+			nextTime,
+			nextPrice,
+			nextConfidence,
+		}
+		if err := w.Write(record); err != nil {
+			fmt.Printf("❌ Ошибка записи CSV: %v\n", err)
+			return
+		}
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		fmt.Printf("❌ Ошибка записи CSV: %v\n", err)
+	}
+}
+
+// PrintProgress — заглушка: прогресс в CSV не пишется
+func (p *CSVPrinter) PrintProgress(current, total int) {
+}
+
+// formatCSVProfit — доходность или N/A для нечислового результата
+func formatCSVProfit(r BenchmarkResult) string {
+	if r.NonFinite {
+		return "N/A"
+	}
+	return formatCSVFloat(r.TotalProfit, 6)
+}
+
+// formatCSVFloat — число с точкой в качестве разделителя независимо от локали
+func formatCSVFloat(v float64, precision int) string {
+	return strconv.FormatFloat(v, 'f', precision, 64)
+}
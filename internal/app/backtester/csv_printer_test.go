@@ -0,0 +1,51 @@
+package backtester
+
+import (
+	"bytes"
+	"encoding/csv"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestCSVPrinter_QuotingAndOrder(t *testing.T) {
+	results := []BenchmarkResult{
+		{Name: "slow", TotalProfit: -0.01, TradeCount: 2, FinalPortfolio: 9900},
+		{
+			Name:           "fast",
+			TotalProfit:    0.0525,
+			TradeCount:     7,
+			FinalPortfolio: 10525.5,
+			Parameters:     `Fast(period=5, label="a,b")`,
+			NextSignal:     &internal.FutureSignal{SignalType: internal.BUY, Date: 0, Price: 1.5, Confidence: 0.8},
+		},
+	}
+
+	var buf bytes.Buffer
+	NewCSVPrinter(&buf).PrintComparison(results)
+
+	records, err := csv.NewReader(&buf).ReadAll()
+	if err != nil {
+		t.Fatalf("output is not valid CSV: %v", err)
+	}
+	if len(records) != 3 {
+		t.Fatalf("expected header + 2 rows, got %d", len(records))
+	}
+	if len(records[1]) != len(csvHeader) {
+		t.Fatalf("expected %d columns, got %d", len(csvHeader), len(records[1]))
+	}
+
+	best := records[1]
+	if best[1] != "fast" || best[3] != "0.052500" || best[6] != "10525.50" {
+		t.Errorf("unexpected first row: %v", best)
+	}
+	if best[10] != `Fast(period=5, label="a,b")` {
+		t.Errorf("parameters were not round-tripped through quoting: %q", best[10])
+	}
+	if best[12] != "BUY" || best[14] != "1.5000" {
+		t.Errorf("unexpected next signal columns: %v", best[12:])
+	}
+	if records[2][1] != "slow" || records[2][12] != "" {
+		t.Errorf("unexpected second row: %v", records[2])
+	}
+}
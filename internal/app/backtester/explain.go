@@ -0,0 +1,93 @@
+package backtester
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"bt/internal"
+)
+
+// explainableConfig — достает V2-конфиг из результата runSingleStrategy (V1 объяснения не поддерживают)
+func explainableConfig(config internal.StrategyConfig) (internal.StrategyConfigV2, bool) {
+	wrapper, ok := config.(*strategyConfigV2Wrapper)
+	if !ok || wrapper.config == nil {
+		return nil, false
+	}
+	return wrapper.config, true
+}
+
+// ExplainHolds — сигналы и причины HOLD стратегии с подобранной конфигурацией
+func ExplainHolds(strategyName string, candles []internal.Candle, config internal.StrategyConfig) ([]internal.SignalType, internal.HoldReasons, error) {
+	strategy, ok := internal.GetStrategyV2(strategyName)
+	if !ok {
+		return nil, nil, fmt.Errorf("стратегия %s не поддерживает --explain (нужна V2-стратегия с объяснением сигналов)", strategyName)
+	}
+	strategyBase, ok := strategy.(*internal.StrategyBase)
+	configV2, hasConfig := explainableConfig(config)
+	if !ok || !hasConfig {
+		return nil, nil, fmt.Errorf("стратегия %s не поддерживает --explain", strategyName)
+	}
+
+	signals, reasons := strategyBase.ExplainSignals(candles, configV2)
+	if reasons == nil {
+		return nil, nil, fmt.Errorf("стратегия %s не поддерживает --explain", strategyName)
+	}
+	return signals, reasons, nil
+}
+
+// WriteHoldExplanation — построчный журнал заблокированных сигналов и сводка по условиям
+func WriteHoldExplanation(w io.Writer, candles []internal.Candle, signals []internal.SignalType, reasons internal.HoldReasons) {
+	counts := make(map[string]int)
+	trades := 0
+
+	fmt.Fprintln(w, "\n"+strings.Repeat("═", 80))
+	fmt.Fprintln(w, "🔍 ПОЧЕМУ HOLD: бары, где стратегия могла торговать")
+	fmt.Fprintln(w, strings.Repeat("═", 80))
+
+	for i, reason := range reasons {
+		if signals[i] != internal.HOLD {
+			trades++
+		}
+		if reason.Code == "" {
+			continue
+		}
+		counts[reason.Code]++
+		fmt.Fprintf(w, "⏸️ #%-5d %s │ close %10.4f │ %-4s заблокирован: %-20s │ %s\n",
+			i, formatCandleTime(candles[i]), candles[i].Close.ToFloat64(), reason.Blocked, reason.Code, reason.Detail)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("─", 80))
+	fmt.Fprintf(w, "📊 Сигналов: %d, заблокировано: %d\n", trades, sumCounts(counts))
+
+	codes := make([]string, 0, len(counts))
+	for code := range counts {
+		codes = append(codes, code)
+	}
+	sort.Slice(codes, func(i, j int) bool {
+		if counts[codes[i]] != counts[codes[j]] {
+			return counts[codes[i]] > counts[codes[j]]
+		}
+		return codes[i] < codes[j]
+	})
+	for _, code := range codes {
+		fmt.Fprintf(w, "   %-22s %d\n", code, counts[code])
+	}
+}
+
+func formatCandleTime(candle internal.Candle) string {
+	t := candle.ToTime()
+	if t.IsZero() {
+		return strings.Repeat(" ", 16)
+	}
+	return t.Format("2006-01-02 15:04")
+}
+
+func sumCounts(counts map[string]int) int {
+	total := 0
+	for _, n := range counts {
+		total += n
+	}
+	return total
+}
@@ -0,0 +1,118 @@
+package backtester
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+)
+
+// ForwardCheck — результат сохраненной конфигурации стратегии на новых данных
+type ForwardCheck struct {
+	Name       string
+	Profit     float64
+	Sharpe     float64
+	Trades     int
+	FromConfig bool   // конфигурация загружена из файла; иначе подобрана на этих же данных и проверкой не является
+	BrokeDown  bool   // конфигурация из файла не заработала на новых данных
+	Reason     string // почему BrokeDown: нечисловой результат, нет сделок или убыток
+	BeatsHold  bool   // доходность выше Buy & Hold на тех же данных
+}
+
+// ForwardValidation — вердикт по каждой стратегии прогона с --config на новых данных. Конфигурация
+// сломалась, если дала нечисловой результат, ни одной сделки или неположительную доходность.
+// Buy & Hold служит ориентиром и в список не входит
+func ForwardValidation(results []BenchmarkResult) []ForwardCheck {
+	holdProfit := math.NaN()
+	for _, r := range results {
+		if r.Name == buyAndHoldName {
+			holdProfit = r.TotalProfit
+		}
+	}
+
+	var checks []ForwardCheck
+	for _, r := range results {
+		if r.Name == buyAndHoldName {
+			continue
+		}
+		check := ForwardCheck{
+			Name:       r.Name,
+			Profit:     r.TotalProfit,
+			Sharpe:     r.SharpeRatio,
+			Trades:     r.TradeCount,
+			FromConfig: r.FromConfig,
+			BeatsHold:  !math.IsNaN(holdProfit) && r.TotalProfit > holdProfit,
+		}
+		if r.FromConfig {
+			switch {
+			case r.NonFinite || math.IsNaN(r.TotalProfit) || math.IsInf(r.TotalProfit, 0):
+				check.Reason = "нечисловой результат"
+			case r.TotalProfit == 0 && r.TradeCount == 0:
+				check.Reason = "нет сделок"
+			case r.TotalProfit <= 0:
+				// Убыток бывает и без закрытых сделок — по позиции, открытой на конец периода
+				check.Reason = "убыток"
+			}
+			check.BrokeDown = check.Reason != ""
+		}
+		checks = append(checks, check)
+	}
+
+	// Сломавшиеся наверху, стратегии без сохраненной конфигурации — внизу
+	sort.SliceStable(checks, func(i, j int) bool {
+		if checks[i].FromConfig != checks[j].FromConfig {
+			return checks[i].FromConfig
+		}
+		if checks[i].BrokeDown != checks[j].BrokeDown {
+			return checks[i].BrokeDown
+		}
+		return checks[i].Profit < checks[j].Profit
+	})
+	return checks
+}
+
+// CountBrokeDown — число стратегий, чья конфигурация из файла не заработала на новых данных
+func CountBrokeDown(checks []ForwardCheck) int {
+	count := 0
+	for _, c := range checks {
+		if c.BrokeDown {
+			count++
+		}
+	}
+	return count
+}
+
+// WriteForwardValidation — таблица форвард-проверки конфигураций из файла на новых данных
+func WriteForwardValidation(w io.Writer, checks []ForwardCheck, configFile string) {
+	fmt.Fprintln(w, "\n"+strings.Repeat("═", 100))
+	fmt.Fprintf(w, "🔭 ФОРВАРД-ПРОВЕРКА КОНФИГУРАЦИЙ ИЗ %s НА НОВЫХ ДАННЫХ\n", configFile)
+	fmt.Fprintln(w, strings.Repeat("═", 100))
+	fmt.Fprintf(w, "   %-32s │ %9s │ %7s │ %6s │ %-8s │ %s\n", "Стратегия", "Прибыль", "Sharpe", "Сделки", "vs B&H", "Вердикт")
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+
+	checked := 0
+	for _, c := range checks {
+		versus := "хуже"
+		if c.BeatsHold {
+			versus = "лучше"
+		}
+
+		status, verdict := "✅", "держится"
+		switch {
+		case !c.FromConfig:
+			status, verdict = "➖", "нет в файле — подобрана на этих данных, не проверка"
+		case c.BrokeDown:
+			status, verdict = "❌", "сломалась: "+c.Reason
+		}
+		if c.FromConfig {
+			checked++
+		}
+
+		fmt.Fprintf(w, "%s %-32s │ %+8.2f%% │ %7.2f │ %6d │ %-8s │ %s\n",
+			status, c.Name, c.Profit*100, c.Sharpe, c.Trades, versus, verdict)
+	}
+
+	fmt.Fprintln(w, strings.Repeat("─", 100))
+	fmt.Fprintf(w, "🔭 Сломалось: %d из %d стратегий с сохраненной конфигурацией\n", CountBrokeDown(checks), checked)
+}
@@ -0,0 +1,109 @@
+package backtester
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"bt/internal"
+)
+
+// fixedTradeConfig и fixedTradeStrategy — одна сделка: покупка на баре Entry, продажа на баре Exit
+type fixedTradeConfig struct {
+	Entry int `json:"entry"`
+	Exit  int `json:"exit"`
+}
+
+func (c *fixedTradeConfig) Validate() error { return nil }
+func (c *fixedTradeConfig) DefaultConfigString() string {
+	return fmt.Sprintf("FixedTrade(entry=%d, exit=%d)", c.Entry, c.Exit)
+}
+
+type fixedTradeStrategy struct {
+	internal.BaseStrategy
+	name string
+}
+
+func (s *fixedTradeStrategy) Name() string { return s.name }
+
+func (s *fixedTradeStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	c := config.(*fixedTradeConfig)
+	signals := make([]internal.SignalType, len(candles))
+	signals[c.Entry] = internal.BUY
+	signals[c.Exit] = internal.SELL
+	return signals
+}
+
+func (s *fixedTradeStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	return &fixedTradeConfig{Entry: 0, Exit: 1}
+}
+
+func trendCandles(n int, step float64) []internal.Candle {
+	candles := make([]internal.Candle, n)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + step*float64(i))}
+	}
+	return candles
+}
+
+func TestForwardValidation_SavedConfigOnSecondDataset(t *testing.T) {
+	strategy := &fixedTradeStrategy{name: "fixed_trade_forward_test"}
+	strategy.Config = &fixedTradeConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	configFile := filepath.Join(t.TempDir(), "optimized_configs.json")
+	saved := `{"slipping": 0, "fixed_trade_forward_test": {"entry": 2, "exit": 10}}`
+	if err := os.WriteFile(configFile, []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := &BaseStrategyRunner{config: Config{ConfigFile: configFile}}
+	runner.loadConfigsFromFile()
+
+	// Период оптимизации: рост, конфигурация зарабатывает
+	train := trendCandles(20, 1)
+	result, _, err := runner.runStrategy(context.Background(), strategy.Name(), train)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.FromConfig || result.Parameters != "FixedTrade(entry=2, exit=10)" {
+		t.Fatalf("expected the saved config to be used, got %q (from config: %v)", result.Parameters, result.FromConfig)
+	}
+	checks := ForwardValidation([]BenchmarkResult{*result, BuyAndHoldBenchmark(train, 0, 0)})
+	if len(checks) != 1 || checks[0].BrokeDown {
+		t.Errorf("expected the config to hold on rising data, got %+v", checks)
+	}
+
+	// Новые данные: падение, та же конфигурация дает убыток
+	fresh := trendCandles(20, -1)
+	result, _, err = runner.runStrategy(context.Background(), strategy.Name(), fresh)
+	if err != nil {
+		t.Fatal(err)
+	}
+	checks = ForwardValidation([]BenchmarkResult{*result, BuyAndHoldBenchmark(fresh, 0, 0)})
+	if len(checks) != 1 || !checks[0].BrokeDown || checks[0].Reason != "убыток" {
+		t.Errorf("expected the config to break down on falling data, got %+v", checks)
+	}
+	if !checks[0].BeatsHold {
+		t.Errorf("a shorter losing trade should still beat Buy & Hold on the decline: %+v", checks[0])
+	}
+	if CountBrokeDown(checks) != 1 {
+		t.Errorf("CountBrokeDown = %d, want 1", CountBrokeDown(checks))
+	}
+}
+
+func TestForwardValidation_ConfigsMissingFromFileAreNotJudged(t *testing.T) {
+	results := []BenchmarkResult{
+		{Name: "optimized_here", TotalProfit: -0.1, TradeCount: 3},
+		{Name: "saved", TotalProfit: 0, TradeCount: 0, FromConfig: true},
+	}
+
+	checks := ForwardValidation(results)
+	if checks[0].Name != "saved" || !checks[0].BrokeDown || checks[0].Reason != "нет сделок" {
+		t.Errorf("expected the idle saved config first and broken down, got %+v", checks[0])
+	}
+	if checks[1].BrokeDown {
+		t.Errorf("a config optimized on the same data is not a forward check: %+v", checks[1])
+	}
+}
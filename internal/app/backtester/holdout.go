@@ -0,0 +1,58 @@
+package backtester
+
+import (
+	"fmt"
+	"math"
+
+	"bt/internal"
+)
+
+// HoldoutResult — прибыль выбранной конфигурации на свечах подбора и на отложенном хвосте (--holdout)
+type HoldoutResult struct {
+	// Split — индекс первой отложенной свечи: оптимизатор видел только candles[:Split]
+	Split          int
+	InSampleProfit float64
+	HoldoutProfit  float64
+	HoldoutTrades  int
+}
+
+// holdoutSplit — граница отложенной доли fraction последних свечей (n, если отложенной выборки нет)
+func holdoutSplit(n int, fraction float64) int {
+	if fraction <= 0 {
+		return n
+	}
+	return n - int(math.Round(float64(n)*fraction))
+}
+
+// trainingCandles — свечи, на которых подбираются параметры: без отложенного хвоста при --holdout
+func (r *BaseStrategyRunner) trainingCandles(candles []internal.Candle) []internal.Candle {
+	return candles[:holdoutSplit(len(candles), r.config.Holdout)]
+}
+
+// evaluateHoldout — бэктест сигналов, посчитанных по всем свечам, отдельно до и после границы отложенной выборки.
+// Индикаторы на хвосте прогреты свечами подбора, а сделки совершаются только на своем отрезке (nil без --holdout)
+func (r *BaseStrategyRunner) evaluateHoldout(candles []internal.Candle, signals []internal.SignalType, slippage float64) *HoldoutResult {
+	split := holdoutSplit(len(candles), r.config.Holdout)
+	if split == len(candles) {
+		return nil
+	}
+	inSample := internal.Backtest(candles[:split], signals[:split], slippage)
+	holdout := internal.Backtest(candles[split:], signals[split:], slippage)
+	return &HoldoutResult{
+		Split:          split,
+		InSampleProfit: inSample.TotalProfit,
+		HoldoutProfit:  holdout.TotalProfit,
+		HoldoutTrades:  holdout.TradeCount,
+	}
+}
+
+// ValidateHoldout — доля отложенной выборки в [0, 1), и на подбор и на проверку остается хотя бы по свече
+func ValidateHoldout(fraction float64, candles int) error {
+	if fraction < 0 || fraction >= 1 {
+		return fmt.Errorf("--holdout должен быть в диапазоне [0, 1), получено %.2f", fraction)
+	}
+	if split := holdoutSplit(candles, fraction); fraction > 0 && (split < 1 || split == candles) {
+		return fmt.Errorf("--holdout %.2f не оставляет свечей на подбор или на проверку (всего %d)", fraction, candles)
+	}
+	return nil
+}
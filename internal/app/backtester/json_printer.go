@@ -0,0 +1,206 @@
+package backtester
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"bt/internal"
+)
+
+// JSONReport — машиночитаемый отчет прогона (--output=json или json:-); служит базой для --compare-to
+type JSONReport struct {
+	// InitialCapital — начальный капитал, от которого считаются final_portfolio
+	InitialCapital float64       `json:"initial_capital,omitempty"`
+	Results        []ReportEntry `json:"results"`
+}
+
+// ReportEntry — результат одной стратегии в JSON-отчете; доли пишутся как есть (0.0123 = 1.23%)
+type ReportEntry struct {
+	Rank             int                `json:"rank"`
+	Strategy         string             `json:"strategy"`
+	Category         string             `json:"category"`
+	Profit           float64            `json:"profit"`
+	NonFinite        bool               `json:"non_finite,omitempty"` // движок получил NaN/Inf, profit — заглушка
+	Trades           int                `json:"trades"`
+	TimeInMarket     float64            `json:"time_in_market"`
+	FinalPortfolio   float64            `json:"final_portfolio"`
+	Sharpe           float64            `json:"sharpe"`
+	Sortino          float64            `json:"sortino"`
+	MaxDrawdown      float64            `json:"max_drawdown"`
+	WinningTrades    int                `json:"winning_trades"`
+	LosingTrades     int                `json:"losing_trades"`
+	WinRate          float64            `json:"win_rate"`
+	AvgWin           float64            `json:"avg_win"`
+	AvgLoss          float64            `json:"avg_loss"`
+	AvgTradeReturn   float64            `json:"avg_trade_return"`
+	SuppressedExits  int                `json:"suppressed_exits"`
+	BelowFloorTrades int                `json:"below_floor_trades"`
+	FirstEntryIndex  int                `json:"first_entry_index"`
+	FromConfig       bool               `json:"from_config"`
+	Signals          ReportSignals      `json:"signals"`
+	Parameters       string             `json:"parameters"`
+	ExecutionMs      int64              `json:"execution_ms"`
+	AllocBytes       uint64             `json:"alloc_bytes,omitempty"` // только с --mem-stats
+	Mallocs          uint64             `json:"mallocs,omitempty"`
+	WalkForward      *ReportWalkForward `json:"walk_forward,omitempty"`
+	NextSignal       *ReportNextSignal  `json:"next_signal,omitempty"`
+}
+
+// ReportSignals — распределение сигналов стратегии в JSON-отчете
+type ReportSignals struct {
+	Buy            int     `json:"buy"`
+	Sell           int     `json:"sell"`
+	Hold           int     `json:"hold"`
+	AvgBarsBetween float64 `json:"avg_bars_between"`
+}
+
+// ReportWalkForward — итоги walk-forward оптимизации в JSON-отчете
+type ReportWalkForward struct {
+	Steps             int     `json:"steps"`
+	InSampleProfit    float64 `json:"in_sample_profit"`
+	OutOfSampleProfit float64 `json:"out_of_sample_profit"`
+	Efficiency        float64 `json:"efficiency"`
+	Overfit           bool    `json:"overfit"`
+}
+
+// ReportNextSignal — предсказанный следующий сигнал в JSON-отчете
+type ReportNextSignal struct {
+	Signal     string  `json:"signal"`
+	Time       string  `json:"time"`
+	Unix       int64   `json:"unix"`
+	Price      float64 `json:"price"`
+	Confidence float64 `json:"confidence"`
+}
+
+// JSONPrinter — сравнительная таблица в JSON для скриптов, CI и дашбордов
+type JSONPrinter struct {
+	out    io.Writer // nil — отчет сохраняется в файл results_<время>.json
+	outDir string    // каталог для файла отчета
+}
+
+// NewJSONPrinter — конструктор для JSONPrinter
+func NewJSONPrinter(out io.Writer) *JSONPrinter {
+	return &JSONPrinter{out: out}
+}
+
+// NewJSONFilePrinter — JSONPrinter, сохраняющий отчет в results_<время>.json в каталоге outDir (--output=json)
+func NewJSONFilePrinter(outDir string) *JSONPrinter {
+	return &JSONPrinter{outDir: outDir}
+}
+
+// PrintComparison — выводит результаты, отсортированные по доходности
+func (p *JSONPrinter) PrintComparison(results []BenchmarkResult) {
+	sortResultsByProfit(results)
+
+	if p.out != nil {
+		if err := writeJSONReport(p.out, results); err != nil {
+			fmt.Printf("❌ Ошибка записи JSON: %v\n", err)
+		}
+		return
+	}
+
+	file, err := CreateTimestampedFile(p.outDir, "results", ".json")
+	if err != nil {
+		fmt.Printf("❌ Ошибка сохранения JSON отчета: %v\n", err)
+		return
+	}
+	defer file.Close()
+	if err := writeJSONReport(file, results); err != nil {
+		fmt.Printf("❌ Ошибка записи JSON: %v\n", err)
+		return
+	}
+	fmt.Printf("📄 JSON отчет сохранен: %s\n", file.Name())
+}
+
+func writeJSONReport(w io.Writer, results []BenchmarkResult) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	return encoder.Encode(NewJSONReport(results))
+}
+
+// PrintProgress — заглушка: прогресс в JSON не пишется
+func (p *JSONPrinter) PrintProgress(current, total int) {
+}
+
+// NewJSONReport — отчет в порядке переданных результатов
+func NewJSONReport(results []BenchmarkResult) JSONReport {
+	report := JSONReport{
+		InitialCapital: internal.DefaultBacktestOptions().Capital(),
+		Results:        make([]ReportEntry, 0, len(results)),
+	}
+	for i, r := range results {
+		entry := ReportEntry{
+			Rank:             i + 1,
+			Strategy:         r.Name,
+			Category:         internal.GetStrategyCategory(r.Name),
+			Profit:           r.TotalProfit,
+			NonFinite:        r.NonFinite,
+			Trades:           r.TradeCount,
+			TimeInMarket:     r.TimeInMarket,
+			FinalPortfolio:   r.FinalPortfolio,
+			Sharpe:           r.SharpeRatio,
+			Sortino:          r.SortinoRatio,
+			MaxDrawdown:      r.MaxDrawdown,
+			WinningTrades:    r.WinningTrades,
+			LosingTrades:     r.LosingTrades,
+			WinRate:          r.WinRate(),
+			AvgWin:           r.AvgWin,
+			AvgLoss:          r.AvgLoss,
+			AvgTradeReturn:   r.AvgTradeReturn,
+			SuppressedExits:  r.SuppressedExits,
+			BelowFloorTrades: r.BelowFloorTrades,
+			FirstEntryIndex:  r.FirstEntryIndex,
+			FromConfig:       r.FromConfig,
+			Signals: ReportSignals{
+				Buy:            r.Signals.Buy,
+				Sell:           r.Signals.Sell,
+				Hold:           r.Signals.Hold,
+				AvgBarsBetween: r.Signals.AvgBarsBetween,
+			},
+			Parameters:  r.Parameters,
+			ExecutionMs: r.ExecutionTime.Milliseconds(),
+			AllocBytes:  r.AllocBytes,
+			Mallocs:     r.Mallocs,
+		}
+		if wf := r.WalkForward; wf != nil {
+			entry.WalkForward = &ReportWalkForward{
+				Steps:             len(wf.Folds),
+				InSampleProfit:    wf.InSampleProfit,
+				OutOfSampleProfit: wf.OutOfSampleProfit,
+				Efficiency:        wf.Efficiency,
+				Overfit:           wf.Overfit(),
+			}
+		}
+		if r.NextSignal != nil {
+			entry.NextSignal = &ReportNextSignal{
+				Signal:     r.NextSignal.SignalType.String(),
+				Time:       time.Unix(r.NextSignal.Date, 0).UTC().Format(time.RFC3339),
+				Unix:       r.NextSignal.Date,
+				Price:      r.NextSignal.Price,
+				Confidence: r.NextSignal.Confidence,
+			}
+		}
+		report.Results = append(report.Results, entry)
+	}
+	return report
+}
+
+// LoadJSONReport — читает отчет, сохраненный через --output=json или json:-
+func LoadJSONReport(filename string) (*JSONReport, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения отчета %s: %w", filename, err)
+	}
+
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		return nil, fmt.Errorf("ошибка разбора отчета %s: %w", filename, err)
+	}
+	if len(report.Results) == 0 {
+		return nil, fmt.Errorf("отчет %s не содержит результатов", filename)
+	}
+	return &report, nil
+}
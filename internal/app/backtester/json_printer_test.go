@@ -0,0 +1,60 @@
+package backtester
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"bt/internal"
+)
+
+func TestJSONFilePrinter_WritesRawResults(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	results := []BenchmarkResult{
+		{Name: "loser", TotalProfit: -0.02, TradeCount: 1, LosingTrades: 1, AvgLoss: -0.02},
+		{
+			Name:          "winner",
+			TotalProfit:   0.1234,
+			TradeCount:    4,
+			WinningTrades: 3,
+			LosingTrades:  1,
+			AvgWin:        0.05,
+			AvgLoss:       -0.01,
+			SortinoRatio:  1.5,
+			MaxDrawdown:   0.07,
+			Signals:       SignalStats{Buy: 4, Sell: 4, Hold: 92},
+			NextSignal:    &internal.FutureSignal{SignalType: internal.SELL, Date: time.Date(2025, 1, 2, 3, 0, 0, 0, time.UTC).Unix(), Price: 12.5, Confidence: 0.8},
+		},
+	}
+	NewJSONFilePrinter(DefaultOutDir).PrintComparison(results)
+
+	files, _ := filepath.Glob("results_*.json")
+	if len(files) != 1 {
+		t.Fatalf("Expected one results_*.json file, got %v", files)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var report JSONReport
+	if err := json.Unmarshal(data, &report); err != nil {
+		t.Fatalf("Report is not valid JSON: %v", err)
+	}
+
+	if len(report.Results) != 2 || report.Results[0].Strategy != "winner" {
+		t.Fatalf("Expected results sorted by profit, got %+v", report.Results)
+	}
+	best := report.Results[0]
+	if best.Profit != 0.1234 || best.WinRate != 0.75 || best.Sortino != 1.5 || best.MaxDrawdown != 0.07 {
+		t.Errorf("Expected raw metric values, got %+v", best)
+	}
+	if best.Signals.Buy != 4 || best.Signals.Hold != 92 {
+		t.Errorf("Expected signal distribution in report, got %+v", best.Signals)
+	}
+	if best.NextSignal == nil || best.NextSignal.Signal != "SELL" || best.NextSignal.Time != "2025-01-02T03:00:00Z" || best.NextSignal.Price != 12.5 {
+		t.Errorf("Expected next signal details, got %+v", best.NextSignal)
+	}
+}
@@ -0,0 +1,151 @@
+package backtester
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"time"
+
+	"bt/internal"
+)
+
+// InstrumentRun — результаты всех стратегий на одном инструменте (одном файле свечей)
+type InstrumentRun struct {
+	Instrument string
+	Candles    int
+	Results    []BenchmarkResult
+}
+
+// LeaderboardEntry — обобщающая способность стратегии по нескольким инструментам
+type LeaderboardEntry struct {
+	Name         string
+	AverageRank  float64 // средний ранг по прибыли (1 = лучшая на инструменте)
+	MedianProfit float64
+	Instruments  int // на скольких инструментах стратегия отработала
+	Profits      map[string]float64
+	Ranks        map[string]int
+}
+
+// BuildLeaderboard — ранжирует стратегии внутри каждого инструмента и агрегирует ранги и прибыль.
+// Лучшие — стратегии с наименьшим средним рангом, при равенстве — с большей медианной прибылью.
+func BuildLeaderboard(runs []InstrumentRun) []LeaderboardEntry {
+	entries := make(map[string]*LeaderboardEntry)
+
+	for _, run := range runs {
+		ranked := make([]BenchmarkResult, len(run.Results))
+		copy(ranked, run.Results)
+		sort.SliceStable(ranked, func(i, j int) bool {
+			return ranked[i].TotalProfit > ranked[j].TotalProfit
+		})
+
+		for i, r := range ranked {
+			entry, ok := entries[r.Name]
+			if !ok {
+				entry = &LeaderboardEntry{
+					Name:    r.Name,
+					Profits: make(map[string]float64),
+					Ranks:   make(map[string]int),
+				}
+				entries[r.Name] = entry
+			}
+			entry.Profits[run.Instrument] = r.TotalProfit
+			entry.Ranks[run.Instrument] = i + 1
+		}
+	}
+
+	leaderboard := make([]LeaderboardEntry, 0, len(entries))
+	for _, entry := range entries {
+		profits := make([]float64, 0, len(entry.Profits))
+		rankSum := 0
+		for instrument, profit := range entry.Profits {
+			profits = append(profits, profit)
+			rankSum += entry.Ranks[instrument]
+		}
+
+		entry.Instruments = len(profits)
+		entry.AverageRank = float64(rankSum) / float64(len(profits))
+		entry.MedianProfit = medianOf(profits)
+		leaderboard = append(leaderboard, *entry)
+	}
+
+	sort.Slice(leaderboard, func(i, j int) bool {
+		if leaderboard[i].AverageRank != leaderboard[j].AverageRank {
+			return leaderboard[i].AverageRank < leaderboard[j].AverageRank
+		}
+		if leaderboard[i].MedianProfit != leaderboard[j].MedianProfit {
+			return leaderboard[i].MedianProfit > leaderboard[j].MedianProfit
+		}
+		return leaderboard[i].Name < leaderboard[j].Name
+	})
+
+	return leaderboard
+}
+
+// WriteLeaderboardMarkdown — записывает сводную таблицу «стратегия × инструмент» в Markdown
+func WriteLeaderboardMarkdown(w io.Writer, runs []InstrumentRun, leaderboard []LeaderboardEntry) error {
+	var content strings.Builder
+
+	content.WriteString("# Сводный рейтинг стратегий по инструментам\n\n")
+	content.WriteString(fmt.Sprintf("**Дата проведения:** %s  \n", time.Now().Format("2 January 2006")))
+	content.WriteString(fmt.Sprintf("**Инструментов:** %d  \n", len(runs)))
+	content.WriteString("**Метод:** ранг по прибыли внутри каждого инструмента, затем средний ранг и медианная прибыль  \n\n")
+
+	content.WriteString("## Инструменты\n\n")
+	content.WriteString("| Инструмент | Свечей | Стратегий |\n")
+	content.WriteString("|------------|--------|-----------|\n")
+	for _, run := range runs {
+		content.WriteString(fmt.Sprintf("| %s | %d | %d |\n", run.Instrument, run.Candles, len(run.Results)))
+	}
+	content.WriteString("\n---\n\n")
+
+	content.WriteString("## Рейтинг\n\n")
+	content.WriteString("В ячейках инструментов — прибыль и место стратегии на этом инструменте.\n\n")
+
+	header := []string{"Место", "Стратегия", "Категория", "Ср. ранг", "Медианная прибыль", "Инструментов"}
+	for _, run := range runs {
+		header = append(header, run.Instrument)
+	}
+	content.WriteString("| " + strings.Join(header, " | ") + " |\n")
+	content.WriteString("|" + strings.Repeat("------|", len(header)) + "\n")
+
+	for i, entry := range leaderboard {
+		row := []string{
+			fmt.Sprintf("%d", i+1),
+			entry.Name,
+			internal.GetStrategyCategory(entry.Name),
+			fmt.Sprintf("%.1f", entry.AverageRank),
+			fmt.Sprintf("%+.2f%%", entry.MedianProfit*100),
+			fmt.Sprintf("%d/%d", entry.Instruments, len(runs)),
+		}
+		for _, run := range runs {
+			profit, ok := entry.Profits[run.Instrument]
+			if !ok {
+				row = append(row, "—")
+				continue
+			}
+			row = append(row, fmt.Sprintf("%+.2f%% (#%d)", profit*100, entry.Ranks[run.Instrument]))
+		}
+		content.WriteString("| " + strings.Join(row, " | ") + " |\n")
+	}
+
+	_, err := io.WriteString(w, content.String())
+	return err
+}
+
+// medianOf — медиана значений (для четного числа — среднее двух центральных)
+func medianOf(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+
+	sorted := make([]float64, len(values))
+	copy(sorted, values)
+	sort.Float64s(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
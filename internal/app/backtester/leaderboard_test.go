@@ -0,0 +1,56 @@
+package backtester
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildLeaderboard_AverageRankAndMedian(t *testing.T) {
+	runs := []InstrumentRun{
+		{Instrument: "AAA", Results: []BenchmarkResult{
+			{Name: "steady", TotalProfit: 0.10},
+			{Name: "overfit", TotalProfit: 0.50},
+			{Name: "weak", TotalProfit: -0.05},
+		}},
+		{Instrument: "BBB", Results: []BenchmarkResult{
+			{Name: "steady", TotalProfit: 0.08},
+			{Name: "overfit", TotalProfit: -0.20},
+			{Name: "weak", TotalProfit: 0.01},
+		}},
+		{Instrument: "CCC", Results: []BenchmarkResult{
+			{Name: "steady", TotalProfit: 0.05},
+			{Name: "overfit", TotalProfit: -0.10},
+		}},
+	}
+
+	leaderboard := BuildLeaderboard(runs)
+	if len(leaderboard) != 3 {
+		t.Fatalf("Expected 3 entries, got %d", len(leaderboard))
+	}
+
+	// steady: ранги 2, 1, 1 → 4/3; overfit: 1, 3, 2 → 2; weak: 3, 2 → 2.5
+	best := leaderboard[0]
+	if best.Name != "steady" {
+		t.Errorf("Expected steady to lead the leaderboard, got %s", best.Name)
+	}
+	if best.MedianProfit != 0.08 {
+		t.Errorf("Expected steady median profit 0.08, got %.4f", best.MedianProfit)
+	}
+	if leaderboard[1].Name != "overfit" || leaderboard[1].AverageRank != 2 {
+		t.Errorf("Expected overfit second with average rank 2, got %s with %.2f", leaderboard[1].Name, leaderboard[1].AverageRank)
+	}
+
+	// Стратегия, отсутствующая на инструменте, усредняется только по своим инструментам
+	weak := leaderboard[2]
+	if weak.Instruments != 2 || weak.AverageRank != 2.5 {
+		t.Errorf("Expected weak on 2 instruments with average rank 2.5, got %d and %.2f", weak.Instruments, weak.AverageRank)
+	}
+
+	var report strings.Builder
+	if err := WriteLeaderboardMarkdown(&report, runs, leaderboard); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(report.String(), "| AAA | BBB | CCC |") {
+		t.Error("Expected instruments as matrix columns in the report")
+	}
+}
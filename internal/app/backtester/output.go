@@ -0,0 +1,48 @@
+// output.go
+// Каталог для отчетов, конфигураций и сигналов (флаг --out_dir)
+package backtester
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// DefaultOutDir — каталог вывода по умолчанию: текущий
+const DefaultOutDir = "."
+
+// maxReportSuffix — сколько номеров перебирать, если файл с той же секундой уже есть
+const maxReportSuffix = 1000
+
+// outputPath — путь к файлу name в каталоге вывода; каталог создается, если его нет
+func outputPath(dir, name string) (string, error) {
+	if dir == "" {
+		dir = DefaultOutDir
+	}
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("ошибка создания каталога %s: %w", dir, err)
+	}
+	return filepath.Join(dir, name), nil
+}
+
+// CreateTimestampedFile — создает в каталоге вывода новый файл <prefix>_<время><ext>.
+// Если файл с тем же временем уже есть (другой запуск в ту же секунду), к имени добавляется номер:
+// существующие отчеты не перезаписываются
+func CreateTimestampedFile(dir, prefix, ext string) (*os.File, error) {
+	base, err := outputPath(dir, fmt.Sprintf("%s_%s", prefix, time.Now().Format("2006-01-02_15-04-05")))
+	if err != nil {
+		return nil, err
+	}
+	filename := base + ext
+	for n := 2; n <= maxReportSuffix; n++ {
+		f, err := os.OpenFile(filename, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+		if !errors.Is(err, fs.ErrExist) {
+			return f, err
+		}
+		filename = fmt.Sprintf("%s_%d%s", base, n, ext)
+	}
+	return nil, fmt.Errorf("не удалось подобрать свободное имя для %s%s", base, ext)
+}
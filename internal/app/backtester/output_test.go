@@ -0,0 +1,30 @@
+package backtester
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestCreateTimestampedFile_CreatesDirAndAvoidsCollisions(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "reports", "run")
+
+	// Два отчета подряд почти всегда попадают в одну секунду — второй не должен перезаписать первый
+	first, err := CreateTimestampedFile(dir, "strategy_report", ".md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	first.Close()
+	second, err := CreateTimestampedFile(dir, "strategy_report", ".md")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second.Close()
+
+	if first.Name() == second.Name() {
+		t.Fatalf("Expected distinct report files, got %s twice", first.Name())
+	}
+	files, _ := filepath.Glob(filepath.Join(dir, "strategy_report_*.md"))
+	if len(files) != 2 {
+		t.Errorf("Expected both reports in %s, got %v", dir, files)
+	}
+}
@@ -3,12 +3,97 @@ package backtester
 import (
 	"bt/internal"
 	"fmt"
-	"os"
+	"io"
+	"math"
 	"sort"
 	"strings"
 	"time"
 )
 
+// sortResultsByProfit — сортирует результаты по доходности (лучшие вверху); общий порядок для всех принтеров
+func sortResultsByProfit(results []BenchmarkResult) {
+	sort.Slice(results, func(i, j int) bool {
+		// Нечисловые результаты — всегда в конце, независимо от значения-заглушки
+		if results[i].NonFinite != results[j].NonFinite {
+			return !results[i].NonFinite
+		}
+		return results[i].TotalProfit > results[j].TotalProfit
+	})
+}
+
+// formatProfit — прибыль в процентах по формату (например "%+.2f%%") или N/A для нечислового результата
+func formatProfit(r BenchmarkResult, format string) string {
+	if r.NonFinite {
+		return "N/A"
+	}
+	return fmt.Sprintf(format, r.TotalProfit*100)
+}
+
+// formatRiskMetrics — Sharpe, Sortino и максимальная просадка для таблиц; у нечисловых результатов — N/A
+func formatRiskMetrics(r BenchmarkResult) (sharpe, sortino, maxDrawdown string) {
+	if r.NonFinite {
+		return "N/A", "N/A", "N/A"
+	}
+	return fmt.Sprintf("%.2f", r.SharpeRatio), fmt.Sprintf("%.2f", r.SortinoRatio), fmt.Sprintf("%.1f%%", r.MaxDrawdown*100)
+}
+
+// formatWinRate — доля выигрышных закрытых сделок; без сделок и для нечислового результата — прочерк
+func formatWinRate(r BenchmarkResult) string {
+	if r.NonFinite || r.TradeCount == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f%%", r.WinRate()*100)
+}
+
+// formatTradeEdge — профит-фактор и матожидание сделки; без сделок и для нечислового результата — прочерки
+func formatTradeEdge(r BenchmarkResult) (profitFactor, expectancy string) {
+	if r.NonFinite || r.TradeCount == 0 {
+		return "-", "-"
+	}
+	profitFactor = fmt.Sprintf("%.2f", r.ProfitFactor)
+	if math.IsInf(r.ProfitFactor, 1) {
+		profitFactor = "∞"
+	}
+	return profitFactor, fmt.Sprintf("%+.2f", r.Expectancy)
+}
+
+// hasMemStats — есть ли в результатах замер памяти
+func hasMemStats(results []BenchmarkResult) bool {
+	for _, r := range results {
+		if r.AllocBytes > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// formatBytes — объем памяти в удобных единицах
+func formatBytes(b uint64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d Б", b)
+	}
+	value, suffix := float64(b)/unit, "КБ"
+	for _, next := range []string{"МБ", "ГБ"} {
+		if value < unit {
+			break
+		}
+		value, suffix = value/unit, next
+	}
+	return fmt.Sprintf("%.1f %s", value, suffix)
+}
+
+// finiteResults — результаты без NaN/Inf, для средних и экстремумов в сводках
+func finiteResults(results []BenchmarkResult) []BenchmarkResult {
+	finite := make([]BenchmarkResult, 0, len(results))
+	for _, r := range results {
+		if !r.NonFinite {
+			finite = append(finite, r)
+		}
+	}
+	return finite
+}
+
 // ConsolePrinter — реализация вывода результатов в консоль
 type ConsolePrinter struct{}
 
@@ -20,20 +105,19 @@ func NewConsolePrinter() *ConsolePrinter {
 // PrintComparison — выводит сравнительную таблицу стратегий
 func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 	// Сортируем результаты по доходности (лучшие вверху)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].TotalProfit > results[j].TotalProfit
-	})
+	sortResultsByProfit(results)
 
 	// Выводим сравнительную таблицу
-	fmt.Println("\n" + strings.Repeat("═", 120))
+	fmt.Println("\n" + strings.Repeat("═", 183))
 	fmt.Println("📊 ИТОГОВЫЙ ОТЧЕТ ПО СТРАТЕГИЯМ")
-	fmt.Println(strings.Repeat("═", 120))
+	fmt.Println(strings.Repeat("═", 183))
 
 	// Заголовок таблицы с улучшенным выравниванием
-	fmt.Printf("│ %-4s │ %-25s │ %-12s │ %-8s │ %-15s │ %-10s │ %-8s │ %-12s │ %-15s │ %-12s │ %-10s │\n",
-		"Ранг", "Стратегия", "Прибыль", "Сделки", "Финал, $", "Время", "Статус", "След.сигнал", "Дата сигнала", "Цена", "Уверен.")
+	fmt.Printf("│ %-4s │ %-25s │ %-12s │ %-8s │ %-7s │ %-7s │ %-10s │ %-7s │ %-7s │ %-7s │ %-15s │ %-10s │ %-8s │ %-12s │ %-15s │ %-12s │ %-10s │\n",
+		"Ранг", "Стратегия", "Прибыль", "Сделки", "Win %", "PF", "Ожид., $", "Sharpe", "Sortino", "Max DD", "Финал, $", "Время", "Статус", "След.сигнал", "Дата сигнала", "Цена", "Уверен.")
 	fmt.Println("├" + strings.Repeat("─", 6) + "┼" + strings.Repeat("─", 27) + "┼" +
-		strings.Repeat("─", 14) + "┼" + strings.Repeat("─", 10) + "┼" +
+		strings.Repeat("─", 14) + "┼" + strings.Repeat("─", 10) + "┼" + strings.Repeat("─", 9) + "┼" +
+		strings.Repeat("─", 9) + "┼" + strings.Repeat("─", 12) + "┼" + strings.Repeat("─", 9) + "┼" + strings.Repeat("─", 9) + "┼" + strings.Repeat("─", 9) + "┼" +
 		strings.Repeat("─", 17) + "┼" + strings.Repeat("─", 12) + "┼" +
 		strings.Repeat("─", 10) + "┼" + strings.Repeat("─", 14) + "┼" +
 		strings.Repeat("─", 17) + "┼" + strings.Repeat("─", 14) + "┼" +
@@ -57,7 +141,10 @@ func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 		// Форматируем прибыль с цветовыми индикаторами
 		profitStr := ""
 		statusStr := ""
-		if r.TotalProfit > 0.05 { // > 5%
+		if r.NonFinite {
+			profitStr = "⚫ N/A"
+			statusStr = "NaN/Inf"
+		} else if r.TotalProfit > 0.05 { // > 5%
 			profitStr = fmt.Sprintf("🟢 +%.2f%%", r.TotalProfit*100)
 			statusStr = "Отлично"
 		} else if r.TotalProfit > 0 {
@@ -76,6 +163,9 @@ func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 
 		// Форматируем финальную сумму
 		finalStr := fmt.Sprintf("$%.2f", r.FinalPortfolio)
+		if r.NonFinite {
+			finalStr = "N/A"
+		}
 
 		// Форматируем информацию о следующем сигнале
 		nextSignalStr := "⏸️ HOLD"
@@ -97,12 +187,22 @@ func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 			nextSignalConfStr = fmt.Sprintf("%.1f%%", r.NextSignal.Confidence*100)
 		}
 
+		sharpeStr, sortinoStr, drawdownStr := formatRiskMetrics(r)
+		winRateStr := formatWinRate(r)
+		profitFactorStr, expectancyStr := formatTradeEdge(r)
+
 		// Выводим строку таблицы
-		fmt.Printf("│ %-4s │ %-25s │ %-12s │ %-8d │ %-15s │ %-10s │ %-8s │ %-12s │ %-15s │ %-12s │ %-10s │\n",
+		fmt.Printf("│ %-4s │ %-25s │ %-12s │ %-8d │ %-7s │ %-7s │ %-10s │ %-7s │ %-7s │ %-7s │ %-15s │ %-10s │ %-8s │ %-12s │ %-15s │ %-12s │ %-10s │\n",
 			rankStr,
 			p.truncateString(r.Name, 25),
 			profitStr,
 			r.TradeCount,
+			winRateStr,
+			profitFactorStr,
+			expectancyStr,
+			sharpeStr,
+			sortinoStr,
+			drawdownStr,
 			finalStr,
 			timeStr,
 			statusStr,
@@ -116,7 +216,8 @@ func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 
 	// Нижняя граница таблицы
 	fmt.Println("└" + strings.Repeat("─", 6) + "┴" + strings.Repeat("─", 27) + "┴" +
-		strings.Repeat("─", 14) + "┴" + strings.Repeat("─", 10) + "┴" +
+		strings.Repeat("─", 14) + "┴" + strings.Repeat("─", 10) + "┴" + strings.Repeat("─", 9) + "┴" +
+		strings.Repeat("─", 9) + "┴" + strings.Repeat("─", 12) + "┴" + strings.Repeat("─", 9) + "┴" + strings.Repeat("─", 9) + "┴" + strings.Repeat("─", 9) + "┴" +
 		strings.Repeat("─", 17) + "┴" + strings.Repeat("─", 12) + "┴" +
 		strings.Repeat("─", 10) + "┴" + strings.Repeat("─", 14) + "┴" +
 		strings.Repeat("─", 17) + "┴" + strings.Repeat("─", 14) + "┴" +
@@ -124,6 +225,9 @@ func (p *ConsolePrinter) PrintComparison(results []BenchmarkResult) {
 
 	// Добавляем статистику
 	p.printSummaryStats(results)
+	p.printSignalStats(results)
+	p.printWalkForward(results)
+	p.printHoldout(results)
 }
 
 // PrintProgress — выводит прогресс выполнения стратегий
@@ -168,10 +272,21 @@ func (p *ConsolePrinter) printSummaryStats(results []BenchmarkResult) {
 	fmt.Println("📈 СВОДНАЯ СТАТИСТИКА")
 	fmt.Println(strings.Repeat("═", 60))
 
-	// Подсчитываем статистику
+	// Подсчитываем статистику; NaN/Inf результаты не участвуют в средних и экстремумах
+	all := results
+	nonFinite := len(results) - len(finiteResults(results))
+	results = finiteResults(results)
+	if len(results) == 0 {
+		fmt.Printf("⚫ Все %d стратегий дали нечисловой результат (NaN/Inf)\n", len(all))
+		fmt.Println(strings.Repeat("═", 60))
+		return
+	}
+
 	profitable := 0
 	totalProfit := 0.0
 	totalTrades := 0
+	suppressedExits := 0
+	belowFloorTrades := 0
 	bestProfit := results[0].TotalProfit
 	worstProfit := results[len(results)-1].TotalProfit
 
@@ -181,6 +296,8 @@ func (p *ConsolePrinter) printSummaryStats(results []BenchmarkResult) {
 		}
 		totalProfit += r.TotalProfit
 		totalTrades += r.TradeCount
+		suppressedExits += r.SuppressedExits
+		belowFloorTrades += r.BelowFloorTrades
 	}
 
 	avgProfit := totalProfit / float64(len(results))
@@ -201,13 +318,22 @@ func (p *ConsolePrinter) printSummaryStats(results []BenchmarkResult) {
 		}
 	}
 
-	fmt.Printf("🎯 Всего стратегий:      %d\n", len(results))
+	fmt.Printf("🎯 Всего стратегий:      %d\n", len(all))
+	if nonFinite > 0 {
+		fmt.Printf("⚫ Нечисловой результат: %d (NaN/Inf, исключены из статистики)\n", nonFinite)
+	}
 	fmt.Printf("💰 Прибыльных:          %d (%.1f%%)\n", profitable, profitablePercent)
 	fmt.Printf("📊 Средняя прибыль:     %.2f%%\n", avgProfit*100)
 	fmt.Printf("🚀 Лучший результат:    %.2f%% (%s)\n", bestProfit*100, results[0].Name)
 	fmt.Printf("📉 Худший результат:    %.2f%% (%s)\n", worstProfit*100, results[len(results)-1].Name)
 	fmt.Printf("🔄 Всего сделок:        %d\n", totalTrades)
-	
+	if totalTrades > 0 {
+		fmt.Printf("🧹 Ниже порога прибыли: %d (%.1f%%)\n", belowFloorTrades, float64(belowFloorTrades)/float64(totalTrades)*100)
+	}
+	if suppressedExits > 0 {
+		fmt.Printf("⏸️ Пропущено выходов:   %d (движение цены меньше --min-move)\n", suppressedExits)
+	}
+
 	if withPredictions > 0 {
 		fmt.Printf("\n🔮 Предсказания:\n")
 		fmt.Printf("   Стратегий с предсказаниями: %d\n", withPredictions)
@@ -222,20 +348,113 @@ func (p *ConsolePrinter) printSummaryStats(results []BenchmarkResult) {
 	fmt.Println(strings.Repeat("═", 60))
 }
 
+// printSignalStats — выводит распределение сигналов по стратегиям
+func (p *ConsolePrinter) printSignalStats(results []BenchmarkResult) {
+	if !hasSignalStats(results) {
+		return
+	}
+
+	fmt.Println("\n🚦 РАСПРЕДЕЛЕНИЕ СИГНАЛОВ")
+	fmt.Printf("│ %-25s │ %-6s │ %-6s │ %-7s │ %-14s │\n", "Стратегия", "BUY", "SELL", "HOLD", "Баров между")
+	for _, r := range results {
+		if r.Signals.Total() == 0 {
+			continue
+		}
+		fmt.Printf("│ %-25s │ %-6d │ %-6d │ %-7d │ %-14s │\n",
+			p.truncateString(r.Name, 25), r.Signals.Buy, r.Signals.Sell, r.Signals.Hold, r.Signals.Spacing())
+	}
+}
+
+// printWalkForward — прибыль в выборке и вне ее для стратегий, подобранных walk-forward; подгонка отмечается
+func (p *ConsolePrinter) printWalkForward(results []BenchmarkResult) {
+	var walked []BenchmarkResult
+	for _, r := range results {
+		if r.WalkForward != nil {
+			walked = append(walked, r)
+		}
+	}
+	if len(walked) == 0 {
+		return
+	}
+
+	fmt.Println("\n🔁 WALK-FORWARD: ПРИБЫЛЬ В ВЫБОРКЕ И ВНЕ ЕЕ")
+	fmt.Printf("│ %-25s │ %-5s │ %-11s │ %-11s │ %-13s │\n", "Стратегия", "Шаги", "В выборке", "Вне выборки", "Эффективность")
+	overfit := 0
+	for _, r := range walked {
+		wf := r.WalkForward
+		marker := ""
+		if wf.Overfit() {
+			marker = " ⚠️ подгонка"
+			overfit++
+		}
+		fmt.Printf("│ %-25s │ %-5d │ %+10.2f%% │ %+10.2f%% │ %-13.2f │%s\n",
+			p.truncateString(r.Name, 25), len(wf.Folds), wf.InSampleProfit*100, wf.OutOfSampleProfit*100, wf.Efficiency, marker)
+	}
+	fmt.Printf("🔁 Подогнано: %d из %d (вне выборки за бар меньше половины прибыли за бар в выборке)\n", overfit, len(walked))
+}
+
+// printHoldout — прибыль в выборке подбора и на отложенной выборке (--holdout) рядом
+func (p *ConsolePrinter) printHoldout(results []BenchmarkResult) {
+	var held []BenchmarkResult
+	for _, r := range results {
+		if r.Holdout != nil {
+			held = append(held, r)
+		}
+	}
+	if len(held) == 0 {
+		return
+	}
+
+	fmt.Printf("\n🧪 ОТЛОЖЕННАЯ ВЫБОРКА: ПАРАМЕТРЫ ПОДОБРАНЫ НА ПЕРВЫХ %d СВЕЧАХ\n", held[0].Holdout.Split)
+	fmt.Printf("│ %-25s │ %-11s │ %-11s │ %-6s │\n", "Стратегия", "В выборке", "Holdout", "Сделки")
+	lost := 0
+	for _, r := range held {
+		h := r.Holdout
+		marker := ""
+		if h.InSampleProfit > 0 && h.HoldoutProfit <= 0 {
+			marker = " ⚠️ не повторилась"
+			lost++
+		}
+		fmt.Printf("│ %-25s │ %+10.2f%% │ %+10.2f%% │ %-6d │%s\n",
+			p.truncateString(r.Name, 25), h.InSampleProfit*100, h.HoldoutProfit*100, h.HoldoutTrades, marker)
+	}
+	fmt.Printf("🧪 Прибыль в выборке не повторилась на отложенной: %d из %d\n", lost, len(held))
+}
+
+// DefaultReferenceTrades — число сделок, к которому по умолчанию приводится прибыль в таблице эффективности
+const DefaultReferenceTrades = 20
+
+// frequencyEdgeShare — стратегия считается частотной, если на эталонном числе сделок
+// она дает меньше этой доли своей общей прибыли
+const frequencyEdgeShare = 0.25
+
 // MarkdownPrinter — реализация вывода результатов в Markdown файл
-type MarkdownPrinter struct{}
+type MarkdownPrinter struct {
+	referenceTrades int    // эталонное число сделок для нормированной прибыли
+	outDir          string // каталог для файла отчета
+}
 
 // NewMarkdownPrinter — конструктор для MarkdownPrinter
 func NewMarkdownPrinter() *MarkdownPrinter {
-	return &MarkdownPrinter{}
+	return &MarkdownPrinter{referenceTrades: DefaultReferenceTrades, outDir: DefaultOutDir}
+}
+
+// NewMarkdownPrinterWithConfig — конструктор для MarkdownPrinter с параметрами отчета из конфигурации
+func NewMarkdownPrinterWithConfig(config Config) *MarkdownPrinter {
+	printer := NewMarkdownPrinter()
+	if config.ReferenceTrades > 0 {
+		printer.referenceTrades = config.ReferenceTrades
+	}
+	if config.OutDir != "" {
+		printer.outDir = config.OutDir
+	}
+	return printer
 }
 
 // PrintComparison — генерирует Markdown отчет и сохраняет в файл
 func (p *MarkdownPrinter) PrintComparison(results []BenchmarkResult) {
 	// Сортируем результаты по доходности (лучшие вверху)
-	sort.Slice(results, func(i, j int) bool {
-		return results[i].TotalProfit > results[j].TotalProfit
-	})
+	sortResultsByProfit(results)
 
 	var content strings.Builder
 
@@ -245,21 +464,26 @@ func (p *MarkdownPrinter) PrintComparison(results []BenchmarkResult) {
 	content.WriteString(fmt.Sprintf("**Дата проведения:** %s  \n", time.Now().Format("2 January 2006")))
 	content.WriteString("**Система:** Параллельное выполнение на многоядерной архитектуре  \n")
 	content.WriteString("**Метод тестирования:** Бэктестинг с оптимизацией параметров  \n")
-	content.WriteString("**Проскальзывание:** 0.01 единиц  \n\n")
+	content.WriteString(fmt.Sprintf("**Проскальзывание:** %s  \n", slippageSummary(internal.DefaultBacktestOptions(), results)))
+	content.WriteString(fmt.Sprintf("**Комиссия:** %s  \n\n", commissionSummary(internal.DefaultBacktestOptions())))
 	content.WriteString("---\n\n")
 	content.WriteString("## Результаты по стратегиям\n\n")
 
 	// Создаем основную таблицу результатов
-	content.WriteString("| Ранг | Стратегия | Категория | Прибыль | Сделки | Финальный портфель | Время | Статус | След.сигнал | Дата | Цена | Уверенность |\n")
-	content.WriteString("|------|-----------|-----------|---------|--------|-------------------|-------|--------|-------------|------|------|-------------|\n")
+	content.WriteString("| Ранг | Стратегия | Категория | Прибыль | Сделки | Sharpe | Sortino | Макс. просадка | В рынке | Финальный портфель | Время | Статус | След.сигнал | Дата | Цена | Уверенность |\n")
+	content.WriteString("|------|-----------|-----------|---------|--------|--------|---------|----------------|---------|-------------------|-------|--------|-------------|------|------|-------------|\n")
 
 	for i, r := range results {
 		rank := i + 1
-		category := p.getStrategyCategory(r.Name)
-		profitStr := fmt.Sprintf("%+.2f%%", r.TotalProfit*100)
+		category := internal.GetStrategyCategory(r.Name)
+		profitStr := formatProfit(r, "%+.2f%%")
 		finalStr := fmt.Sprintf("$%.2f", r.FinalPortfolio)
 		timeStr := p.formatDurationMD(r.ExecutionTime)
 		status := p.getStatusText(r.TotalProfit)
+		if r.NonFinite {
+			finalStr = "N/A"
+			status = "NaN/Inf"
+		}
 
 		// Форматируем информацию о следующем сигнале
 		nextSignalStr := "⏸️ HOLD"
@@ -281,8 +505,10 @@ func (p *MarkdownPrinter) PrintComparison(results []BenchmarkResult) {
 			nextSignalConfStr = fmt.Sprintf("%.1f%%", r.NextSignal.Confidence*100)
 		}
 
-		content.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %d | %s | %s | %s | %s | %s | %s | %s |\n",
-			rank, r.Name, category, profitStr, r.TradeCount, finalStr, timeStr, status,
+		sharpeStr, sortinoStr, drawdownStr := formatRiskMetrics(r)
+
+		content.WriteString(fmt.Sprintf("| %d | %s | %s | %s | %d | %s | %s | %s | %.1f%% | %s | %s | %s | %s | %s | %s | %s |\n",
+			rank, r.Name, category, profitStr, r.TradeCount, sharpeStr, sortinoStr, drawdownStr, r.TimeInMarket*100, finalStr, timeStr, status,
 			nextSignalStr, nextSignalDateStr, nextSignalPriceStr, nextSignalConfStr))
 	}
 
@@ -291,18 +517,67 @@ func (p *MarkdownPrinter) PrintComparison(results []BenchmarkResult) {
 	// Добавляем аналитические таблицы
 	p.writeAnalyticsTables(&content, results)
 
+	// Добавляем параметры, давшие результаты
+	p.writeParametersTable(&content, results)
+
+	// Добавляем распределение сигналов
+	p.writeSignalTable(&content, results)
+
 	// Добавляем технические детали
 	p.writeTechnicalDetails(&content, results)
 
 	// Сохраняем в файл
-	filename := fmt.Sprintf("strategy_report_%s.md", time.Now().Format("2006-01-02_15-04-05"))
-	err := os.WriteFile(filename, []byte(content.String()), 0644)
+	file, err := CreateTimestampedFile(p.outDir, "strategy_report", ".md")
 	if err != nil {
 		fmt.Printf("❌ Ошибка сохранения отчета: %v\n", err)
 		return
 	}
+	defer file.Close()
+	if _, err := file.WriteString(content.String()); err != nil {
+		fmt.Printf("❌ Ошибка сохранения отчета: %v\n", err)
+		return
+	}
+
+	fmt.Printf("📄 Markdown отчет сохранен: %s\n", file.Name())
+}
+
+// commissionSummary — модель и размер комиссии движка для отчета
+func commissionSummary(opts internal.BacktestOptions) string {
+	var parts []string
+	if opts.Commission > 0 {
+		parts = append(parts, fmt.Sprintf("%.3f%% от суммы сделки", opts.Commission*100))
+	}
+	if opts.CommissionModel != nil {
+		parts = append(parts, opts.CommissionModel.String())
+	}
+	if len(parts) == 0 {
+		return "не учитывается"
+	}
+	return strings.Join(parts, " + ") + " на каждую сторону"
+}
 
-	fmt.Printf("📄 Markdown отчет сохранен: %s\n", filename)
+// slippageSummary — проскальзывание прогона: стратегий в единицах цены (у части стратегий может быть
+// свое из --config) и процентное движка из --slippage-pct
+func slippageSummary(opts internal.BacktestOptions, results []BenchmarkResult) string {
+	var parts []string
+	if len(results) > 0 {
+		low, high := math.Inf(1), math.Inf(-1)
+		for _, r := range results {
+			low, high = math.Min(low, r.Slippage), math.Max(high, r.Slippage)
+		}
+		if low == high && low > 0 {
+			parts = append(parts, fmt.Sprintf("%.4g единиц цены", low))
+		} else if low != high {
+			parts = append(parts, fmt.Sprintf("от %.4g до %.4g единиц цены (по стратегиям)", low, high))
+		}
+	}
+	if opts.SlippagePercent > 0 {
+		parts = append(parts, fmt.Sprintf("%.3f%% цены", opts.SlippagePercent*100))
+	}
+	if len(parts) == 0 {
+		return "не учитывается"
+	}
+	return strings.Join(parts, " + ") + " на каждую сторону"
 }
 
 // writeTechnicalDetails — записывает технические детали в Markdown
@@ -311,11 +586,14 @@ func (p *MarkdownPrinter) writeTechnicalDetails(content *strings.Builder, result
 	content.WriteString("## Технические детали\n\n")
 
 	content.WriteString("### Параметры тестирования\n")
-	content.WriteString("- **Начальный капитал:** $1,000.00\n")
-	content.WriteString("- **Комиссия за сделку:** Включена в расчет проскальзывания\n")
-	content.WriteString("- **Проскальзывание:** 0.01 единиц на сделку\n")
+	content.WriteString(fmt.Sprintf("- **Начальный капитал:** $%.2f\n", internal.DefaultBacktestOptions().Capital()))
+	content.WriteString(fmt.Sprintf("- **Комиссия за сделку:** %s\n", commissionSummary(internal.DefaultBacktestOptions())))
+	content.WriteString(fmt.Sprintf("- **Проскальзывание:** %s\n", slippageSummary(internal.DefaultBacktestOptions(), results)))
 	content.WriteString("- **Оптимизация:** Автоматическая оптимизация параметров для каждой стратегии\n\n")
 
+	p.writeAnnualized(content, results)
+	p.writeExcursions(content, results)
+
 	// Подсчитываем общее время выполнения
 	totalTime := time.Duration(0)
 	for _, r := range results {
@@ -340,47 +618,61 @@ func (p *MarkdownPrinter) writeTechnicalDetails(content *strings.Builder, result
 	content.WriteString("*Отчет сгенерирован автоматически системой бэктестинга*\n")
 }
 
-// getStrategyCategory — определяет категорию стратегии по имени
-func (p *MarkdownPrinter) getStrategyCategory(name string) string {
-	categoryMap := map[string]string{
-		"elliott_wave":          "Волновой анализ",
-		"arima":                 "Статистические методы",
-		"heston":                "Статистические методы",
-		"golden_cross":          "Трендовые стратегии",
-		"ma_crossover":          "Трендовые стратегии",
-		"supertrend":            "Трендовые стратегии",
-		"fomo":                  "Трендовые стратегии",
-		"rsi_oscillator":        "Осцилляторы",
-		"cci_oscillator":        "Осцилляторы",
-		"stochastic_oscillator": "Осцилляторы",
-		"ao_oscillator":         "Осцилляторы",
-		"qstick_oscillator":     "Осцилляторы",
-		"momentum_breakout":     "Волатильность",
-		"bollinger_bands":       "Волатильность",
-		"garch_volatility":      "Волатильность",
-		"ulcer_index":           "Волатильность",
-		"macd":                  "Моментум",
-		"ma_channel":            "Моментум",
-		"volume_breakout":       "Объемные стратегии",
-		"obv":                   "Объемные стратегии",
-		"extrema":               "Экстремумы",
-		"optimal_extrema":       "Экстремумы",
-		"ma_ema_correlation":    "Скользящие средние",
-		"buy_and_hold":          "Простые стратегии",
-		"monthly_rebalance":     "Ребалансировка",
-		"pullback_sell":         "Стратегии продажи",
-		"support_line":          "Линии поддержки/сопротивления",
-		"wavelet_denoise":       "Линии поддержки/сопротивления",
-	}
-
-	// Ищем по частичному совпадению имени
-	for key, category := range categoryMap {
-		if strings.Contains(strings.ToLower(name), key) {
-			return category
+// writeAnnualized — среднегодовая доходность (CAGR) по стратегиям: прибыль за весь период зависит от длины данных,
+// CAGR сравним между прогонами на данных разной длины
+func (p *MarkdownPrinter) writeAnnualized(content *strings.Builder, results []BenchmarkResult) {
+	content.WriteString("### Среднегодовая доходность (CAGR)\n")
+	content.WriteString("Доходность за период, приведенная к году по времени первой и последней свечи. ")
+	content.WriteString("Для данных короче 30 дней не аннуализируется и совпадает с доходностью за период.\n\n")
+	content.WriteString("| Стратегия | Прибыль за период | CAGR |\n")
+	content.WriteString("|-----------|-------------------|------|\n")
+	for _, r := range results {
+		content.WriteString(fmt.Sprintf("| %s | %+.2f%% | %+.2f%% |\n", r.Name, r.TotalProfit*100, r.CAGR*100))
+	}
+	content.WriteString("\n")
+}
+
+// writeExcursions — средние MFE/MAE закрытых сделок по стратегиям (если считались, --mfe-mae)
+func (p *MarkdownPrinter) writeExcursions(content *strings.Builder, results []BenchmarkResult) {
+	var tracked []BenchmarkResult
+	for _, r := range results {
+		if r.AvgMFE != 0 || r.AvgMAE != 0 {
+			tracked = append(tracked, r)
 		}
 	}
+	if len(tracked) == 0 {
+		return
+	}
 
-	return "Прочие стратегии"
+	content.WriteString("### Движение цены внутри сделок (MFE/MAE)\n")
+	content.WriteString("Средние по закрытым сделкам от цены входа: MFE — лучшее движение в пользу позиции, MAE — худшее против нее. ")
+	content.WriteString("Стоп-лосс ближе среднего MAE часто выбивает из сделки до разворота.\n\n")
+	content.WriteString("| Стратегия | Сделки | Средний MFE | Средний MAE |\n")
+	content.WriteString("|-----------|--------|-------------|-------------|\n")
+	for _, r := range tracked {
+		content.WriteString(fmt.Sprintf("| %s | %d | %+.2f%% | %+.2f%% |\n", r.Name, r.TradeCount, r.AvgMFE*100, r.AvgMAE*100))
+	}
+	content.WriteString("\n")
+}
+
+// writeParametersTable — приложение с параметрами конфигурации каждой стратегии для воспроизведения результата
+func (p *MarkdownPrinter) writeParametersTable(content *strings.Builder, results []BenchmarkResult) {
+	content.WriteString("---\n\n")
+	content.WriteString("## Параметры стратегий\n\n")
+	content.WriteString("| Ранг | Стратегия | Прибыль | Параметры |\n")
+	content.WriteString("|------|-----------|---------|-----------|\n")
+
+	for i, r := range results {
+		parameters := r.Parameters
+		if parameters == "" {
+			parameters = "—"
+		}
+		// Вертикальная черта в параметрах сломала бы таблицу
+		parameters = strings.ReplaceAll(parameters, "|", "\\|")
+		content.WriteString(fmt.Sprintf("| %d | %s | %s | `%s` |\n", i+1, r.Name, formatProfit(r, "%+.2f%%"), parameters))
+	}
+
+	content.WriteString("\n")
 }
 
 // getStatusText — возвращает статус без эмодзи для таблиц
@@ -412,6 +704,12 @@ func (p *MarkdownPrinter) writeAnalyticsTables(content *strings.Builder, results
 	// Анализ производительности по времени
 	content.WriteString("### Анализ производительности по времени выполнения\n\n")
 	p.writePerformanceAnalysis(content, results)
+
+	// Потребление памяти (только если замер включен флагом --mem-stats)
+	if hasMemStats(results) {
+		content.WriteString("### Потребление памяти\n\n")
+		p.writeMemoryTable(content, results)
+	}
 }
 
 // writeCategoryAnalysis — создает таблицу анализа по категориям
@@ -426,8 +724,8 @@ func (p *MarkdownPrinter) writeCategoryAnalysis(content *strings.Builder, result
 	})
 
 	// Собираем статистику по категориям
-	for _, r := range results {
-		category := p.getStrategyCategory(r.Name)
+	for _, r := range finiteResults(results) {
+		category := internal.GetStrategyCategory(r.Name)
 		stats := categoryStats[category]
 
 		if stats.count == 0 {
@@ -466,39 +764,52 @@ func (p *MarkdownPrinter) writeCategoryAnalysis(content *strings.Builder, result
 	content.WriteString("\n")
 }
 
-// writeEfficiencyTable — создает таблицу эффективности сделок
+// writeEfficiencyTable — создает таблицу эффективности сделок.
+// Прибыль приводится к эталонному числу сделок (средняя доходность сделки × N), чтобы стратегии
+// с 5 и с 5000 сделками сравнивались на одной базе; отдельно помечаются стратегии, чья прибыль набрана частотой
 func (p *MarkdownPrinter) writeEfficiencyTable(content *strings.Builder, results []BenchmarkResult) {
-	// Создаем копию для сортировки по эффективности
-	efficiency := make([]struct {
-		name           string
-		profitPerTrade float64
-		totalProfit    float64
-		tradeCount     int
-	}, 0)
+	reference := p.referenceTrades
+	if reference <= 0 {
+		reference = DefaultReferenceTrades
+	}
 
+	type efficiencyRow struct {
+		name             string
+		profitPerTrade   float64
+		normalizedProfit float64
+		totalProfit      float64
+		tradeCount       int
+		frequencyDriven  bool
+		profitFactor     string
+		expectancy       string
+	}
+
+	// Создаем копию для сортировки по эффективности
+	efficiency := make([]efficiencyRow, 0)
 	for _, r := range results {
-		if r.TradeCount > 0 {
-			efficiency = append(efficiency, struct {
-				name           string
-				profitPerTrade float64
-				totalProfit    float64
-				tradeCount     int
-			}{
-				name:           r.Name,
-				profitPerTrade: r.TotalProfit / float64(r.TradeCount),
-				totalProfit:    r.TotalProfit,
-				tradeCount:     r.TradeCount,
+		if r.TradeCount > 0 && !r.NonFinite {
+			normalized := r.AvgTradeReturn * float64(reference)
+			profitFactor, expectancy := formatTradeEdge(r)
+			efficiency = append(efficiency, efficiencyRow{
+				name:             r.Name,
+				profitPerTrade:   r.AvgTradeReturn,
+				normalizedProfit: normalized,
+				totalProfit:      r.TotalProfit,
+				tradeCount:       r.TradeCount,
+				frequencyDriven:  isFrequencyDriven(r, normalized, reference),
+				profitFactor:     profitFactor,
+				expectancy:       expectancy,
 			})
 		}
 	}
 
-	// Сортируем по прибыли на сделку
+	// Сортируем по прибыли на сделку (то же, что по нормированной прибыли)
 	sort.Slice(efficiency, func(i, j int) bool {
 		return efficiency[i].profitPerTrade > efficiency[j].profitPerTrade
 	})
 
-	content.WriteString("| Стратегия | Прибыль на сделку | Общая прибыль | Количество сделок |\n")
-	content.WriteString("|-----------|-------------------|---------------|-------------------|\n")
+	content.WriteString(fmt.Sprintf("| Стратегия | Прибыль на сделку | Прибыль на %d сделок | Общая прибыль | Количество сделок | Профит-фактор | Матожидание, $ |\n", reference))
+	content.WriteString("|-----------|-------------------|----------------------|---------------|-------------------|---------------|----------------|\n")
 
 	// Берем топ-5
 	limit := 5
@@ -509,12 +820,38 @@ func (p *MarkdownPrinter) writeEfficiencyTable(content *strings.Builder, results
 	for i := 0; i < limit; i++ {
 		e := efficiency[i]
 		profitPerTradeStr := fmt.Sprintf("%+.2f%%", e.profitPerTrade*100)
+		normalizedStr := fmt.Sprintf("%+.2f%%", e.normalizedProfit*100)
 		totalProfitStr := fmt.Sprintf("%+.2f%%", e.totalProfit*100)
 
-		content.WriteString(fmt.Sprintf("| %s | %s | %s | %d |\n",
-			e.name, profitPerTradeStr, totalProfitStr, e.tradeCount))
+		content.WriteString(fmt.Sprintf("| %s | %s | %s | %s | %d | %s | %s |\n",
+			e.name, profitPerTradeStr, normalizedStr, totalProfitStr, e.tradeCount, e.profitFactor, e.expectancy))
 	}
 	content.WriteString("\n")
+
+	// Частотные стратегии: высокая общая прибыль при слабом преимуществе в каждой сделке
+	var churners []string
+	sort.Slice(efficiency, func(i, j int) bool {
+		return efficiency[i].totalProfit > efficiency[j].totalProfit
+	})
+	for _, e := range efficiency {
+		if e.frequencyDriven {
+			churners = append(churners, fmt.Sprintf("%s (%d сделок: %+.2f%% всего, %+.2f%% на %d)",
+				e.name, e.tradeCount, e.totalProfit*100, e.normalizedProfit*100, reference))
+		}
+	}
+	if len(churners) > 0 {
+		content.WriteString(fmt.Sprintf("**🔁 Прибыль за счет частоты** (на %d сделках меньше %.0f%% общей прибыли): %s\n\n",
+			reference, frequencyEdgeShare*100, strings.Join(churners, ", ")))
+	}
+}
+
+// isFrequencyDriven — прибыльная стратегия с числом сделок выше эталонного, у которой
+// нормированная прибыль меньше frequencyEdgeShare от общей: преимущество дает частота, а не сделка
+func isFrequencyDriven(r BenchmarkResult, normalizedProfit float64, reference int) bool {
+	if r.TotalProfit <= 0 || r.TradeCount <= reference {
+		return false
+	}
+	return normalizedProfit < r.TotalProfit*frequencyEdgeShare
 }
 
 // writePerformanceAnalysis — создает таблицу анализа производительности
@@ -523,7 +860,7 @@ func (p *MarkdownPrinter) writePerformanceAnalysis(content *strings.Builder, res
 	medium := []BenchmarkResult{}
 	slow := []BenchmarkResult{}
 
-	for _, r := range results {
+	for _, r := range finiteResults(results) {
 		if r.ExecutionTime < 100*time.Millisecond {
 			fast = append(fast, r)
 		} else if r.ExecutionTime < time.Second {
@@ -561,6 +898,48 @@ func (p *MarkdownPrinter) writePerformanceAnalysis(content *strings.Builder, res
 	content.WriteString("\n")
 }
 
+// writeSignalTable — записывает распределение BUY/SELL/HOLD и среднее число баров между сигналами
+func (p *MarkdownPrinter) writeSignalTable(content *strings.Builder, results []BenchmarkResult) {
+	if !hasSignalStats(results) {
+		return
+	}
+
+	content.WriteString("## Распределение сигналов\n\n")
+	content.WriteString("| Стратегия | BUY | SELL | HOLD | Баров между сигналами |\n")
+	content.WriteString("|-----------|-----|------|------|-----------------------|\n")
+	for _, r := range results {
+		if r.Signals.Total() == 0 {
+			continue
+		}
+		content.WriteString(fmt.Sprintf("| %s | %d | %d | %d | %s |\n",
+			r.Name, r.Signals.Buy, r.Signals.Sell, r.Signals.Hold, r.Signals.Spacing()))
+	}
+	content.WriteString("\n*inactive — стратегия не выдала ни одного сигнала BUY/SELL; частые сигналы (1–2 бара) указывают на «дребезг».*\n\n")
+}
+
+// writeMemoryTable — стратегии, выделившие больше всего памяти за прогон
+func (p *MarkdownPrinter) writeMemoryTable(content *strings.Builder, results []BenchmarkResult) {
+	byMemory := make([]BenchmarkResult, len(results))
+	copy(byMemory, results)
+	sort.Slice(byMemory, func(i, j int) bool {
+		return byMemory[i].AllocBytes > byMemory[j].AllocBytes
+	})
+
+	content.WriteString("| Стратегия | Выделено памяти | Аллокаций | Время |\n")
+	content.WriteString("|-----------|-----------------|-----------|-------|\n")
+
+	// Берем топ-10
+	limit := 10
+	if len(byMemory) < limit {
+		limit = len(byMemory)
+	}
+	for _, r := range byMemory[:limit] {
+		content.WriteString(fmt.Sprintf("| %s | %s | %d | %s |\n",
+			r.Name, formatBytes(r.AllocBytes), r.Mallocs, p.formatDurationMD(r.ExecutionTime)))
+	}
+	content.WriteString("\n*Замер по приращению runtime.MemStats.TotalAlloc; стратегии выполнялись по одной.*\n\n")
+}
+
 // formatDurationMD — форматирует длительность для Markdown
 func (p *MarkdownPrinter) formatDurationMD(d time.Duration) string {
 	if d > time.Second {
@@ -573,7 +952,7 @@ func (p *MarkdownPrinter) formatDurationMD(d time.Duration) string {
 func (p *MarkdownPrinter) countCategories(results []BenchmarkResult) map[string]int {
 	categories := make(map[string]int)
 	for _, r := range results {
-		category := p.getStrategyCategory(r.Name)
+		category := internal.GetStrategyCategory(r.Name)
 		categories[category]++
 	}
 	return categories
@@ -598,6 +977,13 @@ func NewCombinedPrinter() *CombinedPrinter {
 	}
 }
 
+// NewCombinedPrinterWithConfig — конструктор для CombinedPrinter с параметрами отчета из конфигурации
+func NewCombinedPrinterWithConfig(config Config) *CombinedPrinter {
+	printer := NewCombinedPrinter()
+	printer.markdownPrinter = NewMarkdownPrinterWithConfig(config)
+	return printer
+}
+
 // PrintComparison — выводит результаты и в консоль, и в Markdown файл
 func (p *CombinedPrinter) PrintComparison(results []BenchmarkResult) {
 	// Сначала выводим в консоль
@@ -611,3 +997,31 @@ func (p *CombinedPrinter) PrintComparison(results []BenchmarkResult) {
 func (p *CombinedPrinter) PrintProgress(current, total int) {
 	p.consolePrinter.PrintProgress(current, total)
 }
+
+// OneLinePrinter — минимальный принтер для скриптов: одна строка key=value на стратегию
+type OneLinePrinter struct {
+	out io.Writer
+}
+
+// NewOneLinePrinter — конструктор для OneLinePrinter
+func NewOneLinePrinter(out io.Writer) *OneLinePrinter {
+	return &OneLinePrinter{out: out}
+}
+
+// PrintComparison — выводит строки вида name=...;profit=...;trades=...;sharpe=...
+func (p *OneLinePrinter) PrintComparison(results []BenchmarkResult) {
+	sortResultsByProfit(results)
+
+	for _, r := range results {
+		profit := fmt.Sprintf("%.6f", r.TotalProfit)
+		if r.NonFinite {
+			profit = "N/A"
+		}
+		fmt.Fprintf(p.out, "name=%s;profit=%s;trades=%d;sharpe=%.4f\n",
+			r.Name, profit, r.TradeCount, r.SharpeRatio)
+	}
+}
+
+// PrintProgress — заглушка: однострочный режим не выводит прогресс
+func (p *OneLinePrinter) PrintProgress(current, total int) {
+}
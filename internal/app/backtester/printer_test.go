@@ -0,0 +1,119 @@
+package backtester
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestEfficiencyTable_NormalizesAndFlagsChurners(t *testing.T) {
+	results := []BenchmarkResult{
+		// 5 сделок по +4%: на 10 сделках +40%
+		{Name: "selective", TotalProfit: 0.2, TradeCount: 5, AvgTradeReturn: 0.04},
+		// 500 сделок по +0.05%: общая прибыль +25%, на 10 сделках лишь +0.5%
+		{Name: "churner", TotalProfit: 0.25, TradeCount: 500, AvgTradeReturn: 0.0005},
+	}
+
+	var content strings.Builder
+	(&MarkdownPrinter{referenceTrades: 10}).writeEfficiencyTable(&content, results)
+	report := content.String()
+
+	if !strings.Contains(report, "Прибыль на 10 сделок") {
+		t.Errorf("expected normalized column header, got:\n%s", report)
+	}
+	if !strings.Contains(report, "| selective | +4.00% | +40.00% | +20.00% | 5 |") {
+		t.Errorf("expected normalized profit for selective, got:\n%s", report)
+	}
+
+	flagLine := report[strings.Index(report, "🔁"):]
+	if !strings.Contains(flagLine, "churner") || strings.Contains(flagLine, "selective") {
+		t.Errorf("expected only churner to be flagged, got: %s", flagLine)
+	}
+}
+
+func TestOneLinePrinter_NonFiniteRendersNAAndSortsLast(t *testing.T) {
+	results := []BenchmarkResult{
+		{Name: "broken", TotalProfit: internal.NonFiniteProfit, NonFinite: true},
+		{Name: "loser", TotalProfit: -0.5, TradeCount: 3},
+		{Name: "winner", TotalProfit: 0.1, TradeCount: 2},
+	}
+
+	var buf bytes.Buffer
+	NewOneLinePrinter(&buf).PrintComparison(results)
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	if len(lines) != 3 || !strings.HasPrefix(lines[0], "name=winner;") {
+		t.Fatalf("unexpected output:\n%s", buf.String())
+	}
+	if lines[2] != "name=broken;profit=N/A;trades=0;sharpe=0.0000" {
+		t.Errorf("expected broken strategy last with N/A profit, got %q", lines[2])
+	}
+}
+
+func TestFormatBytes(t *testing.T) {
+	cases := map[uint64]string{
+		512:              "512 Б",
+		1536:             "1.5 КБ",
+		21 * 1024 * 1024: "21.0 МБ",
+		3 << 30:          "3.0 ГБ",
+	}
+	for b, want := range cases {
+		if got := formatBytes(b); got != want {
+			t.Errorf("formatBytes(%d) = %q, want %q", b, got, want)
+		}
+	}
+}
+
+func TestSignalTable_ReportsDistributionAndInactive(t *testing.T) {
+	B, S, H := internal.BUY, internal.SELL, internal.HOLD
+	results := []BenchmarkResult{
+		{Name: "active", Signals: NewSignalStats([]internal.SignalType{H, B, H, H, S, H, B})},
+		{Name: "idle", Signals: NewSignalStats([]internal.SignalType{H, H, H, H})},
+		{Name: "external"}, // распределение не считалось — строки нет
+	}
+	if got := results[0].Signals.AvgBarsBetween; got != 2.5 {
+		t.Errorf("expected 2.5 bars between signals, got %.2f", got)
+	}
+
+	var content strings.Builder
+	(&MarkdownPrinter{}).writeSignalTable(&content, results)
+	report := content.String()
+
+	if !strings.Contains(report, "| active | 2 | 1 | 4 | 2.5 |") {
+		t.Errorf("expected distribution row for active strategy, got:\n%s", report)
+	}
+	if !strings.Contains(report, "| idle | 0 | 0 | 4 | inactive |") {
+		t.Errorf("expected idle strategy reported as inactive, got:\n%s", report)
+	}
+	if strings.Contains(report, "external") {
+		t.Errorf("expected results without signal stats to be skipped, got:\n%s", report)
+	}
+}
+
+func TestCostSummaries_DescribeRunSettings(t *testing.T) {
+	opts := internal.BacktestOptions{Commission: 0.0005, SlippagePercent: 0.001}
+	if got, want := commissionSummary(opts), "0.050% от суммы сделки на каждую сторону"; got != want {
+		t.Errorf("commission: expected %q, got %q", want, got)
+	}
+	opts.CommissionModel = internal.FixedCommission{PerTrade: 2}
+	if got, want := commissionSummary(opts), "0.050% от суммы сделки + fixed(2.00) на каждую сторону"; got != want {
+		t.Errorf("commission with model: expected %q, got %q", want, got)
+	}
+	if got := commissionSummary(internal.BacktestOptions{}); got != "не учитывается" {
+		t.Errorf("expected no commission, got %q", got)
+	}
+
+	same := []BenchmarkResult{{Slippage: 0.02}, {Slippage: 0.02}}
+	if got, want := slippageSummary(opts, same), "0.02 единиц цены + 0.100% цены на каждую сторону"; got != want {
+		t.Errorf("slippage: expected %q, got %q", want, got)
+	}
+	mixed := []BenchmarkResult{{Slippage: 0.01}, {Slippage: 0.05}}
+	if got, want := slippageSummary(internal.BacktestOptions{}, mixed), "от 0.01 до 0.05 единиц цены (по стратегиям) на каждую сторону"; got != want {
+		t.Errorf("per-strategy slippage: expected %q, got %q", want, got)
+	}
+	if got := slippageSummary(internal.BacktestOptions{}, []BenchmarkResult{{}}); got != "не учитывается" {
+		t.Errorf("expected no slippage, got %q", got)
+	}
+}
@@ -1,10 +1,13 @@
 package backtester
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"runtime"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -18,6 +21,8 @@ type BaseStrategyRunner struct {
 	config   Config
 	configs  map[string]json.RawMessage // Загруженные конфигурации из файла
 	slipping float64                    // Глобальный параметр проскальзывания
+	// slippages — проскальзывание отдельных стратегий из файла конфигурации (поле slipping в конфигурации стратегии)
+	slippages map[string]float64
 }
 
 // loadConfigsFromFile — загружает конфигурации стратегий из JSON файла
@@ -47,28 +52,168 @@ func (r *BaseStrategyRunner) loadConfigsFromFile() {
 
 	// Удаляем глобальный параметр из конфигураций стратегий
 	r.configs = make(map[string]json.RawMessage)
+	r.slippages = make(map[string]float64)
 	for key, value := range allConfigs {
-		if key != "slipping" {
-			r.configs[key] = value
+		if key == "slipping" {
+			continue
+		}
+		r.configs[key] = value
+
+		// Собственное проскальзывание стратегии переопределяет глобальное
+		var override struct {
+			Slipping *float64 `json:"slipping"`
+		}
+		if err := json.Unmarshal(value, &override); err != nil {
+			fmt.Printf("⚠️  Неверный тип параметра проскальзывания для %s, используем глобальное значение: %.4f\n", key, r.slipping)
+			continue
+		}
+		if override.Slipping != nil {
+			r.slippages[key] = *override.Slipping
 		}
 	}
+	if len(r.slippages) > 0 {
+		fmt.Printf("💸 Собственное проскальзывание у %d стратегий (глобальное: %.4f)\n", len(r.slippages), r.slipping)
+	}
 
 	fmt.Printf("✅ Загружены конфигурации для %d стратегий из %s\n", len(r.configs), r.config.ConfigFile)
 }
 
-// runSingleStrategy — общая логика запуска одной стратегии (поддержка V1 и V2)
-func (r *BaseStrategyRunner) runSingleStrategy(strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
+// slippageFor — проскальзывание стратегии в единицах цены: собственное из файла конфигурации или глобальное
+func (r *BaseStrategyRunner) slippageFor(strategyName string) float64 {
+	if slippage, ok := r.slippages[strategyName]; ok {
+		return slippage
+	}
+	return r.slipping
+}
+
+// memStatsMu — сериализует прогоны стратегий при --mem-stats: runtime.MemStats общие для процесса,
+// и при параллельном запуске в приращение попали бы аллокации соседних горутин
+var memStatsMu sync.Mutex
+
+// runWithTimeout — runSingleStrategy с ограничением времени StrategyTimeout. Оптимизаторы прекращают перебор
+// по отмене контекста, но расчет сигналов одной конфигурации прервать нельзя, поэтому результат по истечении
+// времени не ждется: стратегия пропускается с ошибкой context.DeadlineExceeded, а прогон досчитывается в фоне
+func (r *BaseStrategyRunner) runWithTimeout(strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
+	if r.config.StrategyTimeout <= 0 {
+		return r.runSingleStrategy(context.Background(), strategyName, candles)
+	}
+
+	// При --mem-stats время отсчитывается после захвата memStatsMu: ожидание очереди не расходует таймаут.
+	// Блокировку освобождает сам прогон, в том числе досчитанный в фоне после таймаута,
+	// чтобы в замер следующей стратегии не попали его аллокации
+	if r.config.MemStats {
+		memStatsMu.Lock()
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), r.config.StrategyTimeout)
+	defer cancel()
+
+	type outcome struct {
+		result *BenchmarkResult
+		config internal.StrategyConfig
+		err    error
+	}
+	done := make(chan outcome, 1)
+	go func() {
+		if !r.config.MemStats {
+			result, config, err := r.runStrategy(ctx, strategyName, candles)
+			done <- outcome{result, config, err}
+			return
+		}
+		defer memStatsMu.Unlock()
+		result, config, err := r.measureStrategy(ctx, strategyName, candles)
+		done <- outcome{result, config, err}
+	}()
+
+	select {
+	case o := <-done:
+		return o.result, o.config, o.err
+	case <-ctx.Done():
+		return nil, nil, fmt.Errorf("%s: превышено время %v: %w", strategyName, r.config.StrategyTimeout, ctx.Err())
+	}
+}
+
+// runSingleStrategy — общая логика запуска одной стратегии (поддержка V1 и V2);
+// при включенном MemStats замеряет выделенную за прогон память
+func (r *BaseStrategyRunner) runSingleStrategy(ctx context.Context, strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
+	if !r.config.MemStats {
+		return r.runStrategy(ctx, strategyName, candles)
+	}
+
+	memStatsMu.Lock()
+	defer memStatsMu.Unlock()
+	return r.measureStrategy(ctx, strategyName, candles)
+}
+
+// measureStrategy — runStrategy с замером аллокаций; вызывается под memStatsMu
+func (r *BaseStrategyRunner) measureStrategy(ctx context.Context, strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	result, config, err := r.runStrategy(ctx, strategyName, candles)
+	runtime.ReadMemStats(&after)
+
+	if result != nil {
+		result.AllocBytes = after.TotalAlloc - before.TotalAlloc
+		result.Mallocs = after.Mallocs - before.Mallocs
+	}
+	return result, config, err
+}
+
+// equityCurve — кривая капитала прогона, если ее нужно сохранить (--save-equity); иначе не удерживается в результатах
+func (r *BaseStrategyRunner) equityCurve(result internal.BacktestResult) []float64 {
+	if !r.config.SaveEquity {
+		return nil
+	}
+	return result.PortfolioValues
+}
+
+// monteCarlo — перевыборки сделок прогона (--monte_carlo); nil без флага
+func (r *BaseStrategyRunner) monteCarlo(result internal.BacktestResult) *internal.MCStats {
+	if r.config.MonteCarlo <= 0 {
+		return nil
+	}
+	stats := internal.MonteCarloResample(internal.TradePnLs(result.Trades), r.config.MonteCarlo, internal.DefaultMonteCarloSeed)
+	return &stats
+}
+
+// printMonteCarlo — доверительные полосы 5–95% итога и просадки; проценты — от начального капитала
+func printMonteCarlo(mc *internal.MCStats, capital float64) {
+	if mc.Trades == 0 {
+		fmt.Println("🎲 Монте-Карло: нет закрытых сделок для перевыборки")
+		return
+	}
+	fmt.Printf("🎲 Монте-Карло (%d перевыборок по %d сделок):\n", mc.Iterations, mc.Trades)
+	fmt.Printf("   Итог 5%%–95%%: $%+.2f … $%+.2f (медиана $%+.2f; %+.2f%% … %+.2f%%)\n",
+		mc.FinalP5, mc.FinalP95, mc.FinalMedian, mc.FinalP5/capital*100, mc.FinalP95/capital*100)
+	fmt.Printf("   Макс. просадка 5%%–95%%: $%.2f … $%.2f (%.2f%% … %.2f%%)\n",
+		mc.DrawdownP5, mc.DrawdownP95, mc.DrawdownP5/capital*100, mc.DrawdownP95/capital*100)
+}
+
+// gateSignals — фильтр торговой сессии (--session) для всех стратегий и фильтр бокового рынка по ADX
+// (--trend-gate) для трендовых. Применяются к итоговым сигналам: оптимизатор подбирает параметры без фильтров
+func (r *BaseStrategyRunner) gateSignals(strategyName string, candles []internal.Candle, signals []internal.SignalType) []internal.SignalType {
+	// Значения проверены при разборе флагов
+	if session, _ := internal.NewSessionFilter(r.config.Session, r.config.SessionTZ); session != nil {
+		signals = session.Apply(candles, signals)
+	}
+	if r.config.TrendGate <= 0 || !internal.IsTrendFollowing(strategyName) {
+		return signals
+	}
+	return internal.ApplyTrendGate(candles, signals, r.config.TrendGate, internal.DefaultTrendGatePeriod)
+}
+
+// runStrategy — оптимизация (или конфигурация из файла), генерация сигналов и бэктест одной стратегии
+func (r *BaseStrategyRunner) runStrategy(ctx context.Context, strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
 	// Сначала пробуем V2 стратегию
 	if strategyV2, ok := internal.GetStrategyV2(strategyName); ok {
-		return r.runStrategyV2(strategyName, strategyV2, candles)
+		return r.runStrategyV2(ctx, strategyName, strategyV2, candles)
 	}
 
 	// Если не найдена V2, используем V1
-	strategy := internal.GetStrategy(strategyName)
-	strategy.SetSlippage(r.slipping)
-	if strategy == nil {
+	strategy, ok := internal.LookupStrategy(strategyName)
+	if !ok {
 		return nil, nil, fmt.Errorf("стратегия %s не найдена", strategyName)
 	}
+	strategy.SetSlippage(r.slippageFor(strategyName))
 
 	strategyStartTime := time.Now()
 
@@ -77,11 +222,13 @@ func (r *BaseStrategyRunner) runSingleStrategy(strategyName string, candles []in
 	}
 
 	var config internal.StrategyConfig
+	fromConfig := false
 
 	// Если есть загруженная конфигурация из файла, используем её
 	if r.configs != nil {
 		if loadedConfig, exists := r.configs[strategyName]; exists {
 			config = strategy.LoadConfigFromMap(loadedConfig)
+			fromConfig = config != nil
 			if r.debug {
 				fmt.Printf("🐛 DEBUG: Используем загруженную конфигурацию для %s\n", strategyName)
 			}
@@ -89,35 +236,73 @@ func (r *BaseStrategyRunner) runSingleStrategy(strategyName string, candles []in
 			if r.debug {
 				fmt.Printf("🐛 DEBUG: Конфигурация для %s имеет неверный тип, используем оптимизацию\n", strategyName)
 			}
-			config = strategy.OptimizeWithConfig(candles)
+			config = strategy.OptimizeWithConfig(ctx, r.trainingCandles(candles))
 		}
 	} else {
 		if r.debug {
 			fmt.Printf("🐛 DEBUG: Конфигурация для %s не найдена в файле, используем оптимизацию\n", strategyName)
 		}
-		config = strategy.OptimizeWithConfig(candles)
+		config = strategy.OptimizeWithConfig(ctx, r.trainingCandles(candles))
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%s: оптимизация прервана: %w", strategyName, err)
 	}
 
 	signals := strategy.GenerateSignalsWithConfig(candles, config)
+	if err := internal.ValidateSignals(candles, signals); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", strategyName, err)
+	}
+	signals = r.gateSignals(strategyName, candles, signals)
 	result := internal.Backtest(candles, signals, strategy.GetSlippage())
+	holdout := r.evaluateHoldout(candles, signals, strategy.GetSlippage())
 
 	executionTime := time.Since(strategyStartTime)
 
 	// V1 стратегии не поддерживают предсказание
 	var nextSignal *internal.FutureSignal = nil
 
+	parameters := ""
+	if config != nil {
+		parameters = config.DefaultConfigString()
+	}
+
 	return &BenchmarkResult{
-		Name:           strategy.Name(),
-		TotalProfit:    result.TotalProfit,
-		TradeCount:     result.TradeCount,
-		FinalPortfolio: result.FinalPortfolio,
-		ExecutionTime:  executionTime,
-		NextSignal:     nextSignal,
+		Name:             strategy.Name(),
+		TotalProfit:      result.TotalProfit,
+		CAGR:             result.CAGR,
+		TradeCount:       result.TradeCount,
+		FinalPortfolio:   result.FinalPortfolio,
+		SharpeRatio:      result.SharpeRatio,
+		SortinoRatio:     result.SortinoRatio,
+		MaxDrawdown:      result.MaxDrawdown,
+		TimeInMarket:     result.TimeInMarket,
+		Parameters:       parameters,
+		Slippage:         strategy.GetSlippage(),
+		SuppressedExits:  result.SuppressedExits,
+		BelowFloorTrades: result.BelowFloorTrades,
+		AvgTradeReturn:   internal.AverageTradeReturn(result.Trades),
+		WinningTrades:    result.WinningTrades,
+		LosingTrades:     result.LosingTrades,
+		AvgWin:           result.AvgWin,
+		AvgLoss:          result.AvgLoss,
+		ProfitFactor:     result.ProfitFactor,
+		Expectancy:       result.Expectancy,
+		AvgMFE:           result.AvgMFE,
+		AvgMAE:           result.AvgMAE,
+		FirstEntryIndex:  result.FirstEntryIndex,
+		Signals:          NewSignalStats(signals),
+		NonFinite:        result.NonFinite,
+		FromConfig:       fromConfig,
+		Holdout:          holdout,
+		MonteCarlo:       r.monteCarlo(result),
+		Equity:           r.equityCurve(result),
+		ExecutionTime:    executionTime,
+		NextSignal:       nextSignal,
 	}, config, nil
 }
 
 // runStrategyV2 — запуск стратегии V2 (новая архитектура)
-func (r *BaseStrategyRunner) runStrategyV2(strategyName string, strategy internal.TradingStrategy, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
+func (r *BaseStrategyRunner) runStrategyV2(ctx context.Context, strategyName string, strategy internal.TradingStrategy, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
 	strategyStartTime := time.Now()
 
 	if r.debug {
@@ -125,6 +310,7 @@ func (r *BaseStrategyRunner) runStrategyV2(strategyName string, strategy interna
 	}
 
 	var config internal.StrategyConfigV2
+	fromConfig := false
 
 	// Если есть загруженная конфигурация из файла, используем её
 	if r.configs != nil {
@@ -135,33 +321,48 @@ func (r *BaseStrategyRunner) runStrategyV2(strategyName string, strategy interna
 				if r.debug {
 					fmt.Printf("🐛 DEBUG: Ошибка загрузки конфигурации для %s: %v, используем оптимизацию\n", strategyName, err)
 				}
-				config = strategy.Optimize(candles, strategy)
-			} else if r.debug {
-				fmt.Printf("🐛 DEBUG: Используем загруженную конфигурацию для %s\n", strategyName)
+				config = strategy.Optimize(ctx, r.trainingCandles(candles), strategy)
+			} else {
+				fromConfig = true
+				if r.debug {
+					fmt.Printf("🐛 DEBUG: Используем загруженную конфигурацию для %s\n", strategyName)
+				}
 			}
 		} else {
 			if r.debug {
 				fmt.Printf("🐛 DEBUG: Конфигурация для %s не найдена, используем оптимизацию\n", strategyName)
 			}
-			config = strategy.Optimize(candles, strategy)
+			config = strategy.Optimize(ctx, r.trainingCandles(candles), strategy)
 		}
 	} else {
 		if r.debug {
 			fmt.Printf("🐛 DEBUG: Конфигурация для %s не найдена в файле, используем оптимизацию\n", strategyName)
 		}
-		config = strategy.Optimize(candles, strategy)
+		config = strategy.Optimize(ctx, r.trainingCandles(candles), strategy)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, nil, fmt.Errorf("%s: оптимизация прервана: %w", strategyName, err)
 	}
 
 	signals := strategy.GenerateSignals(candles, config)
-	result := internal.Backtest(candles, signals, r.slipping)
+	if err := internal.ValidateSignals(candles, signals); err != nil {
+		return nil, nil, fmt.Errorf("%s: %w", strategyName, err)
+	}
+	signals = r.gateSignals(strategyName, candles, signals)
+	result := internal.Backtest(candles, signals, r.slippageFor(strategyName))
+	holdout := r.evaluateHoldout(candles, signals, r.slippageFor(strategyName))
 
 	executionTime := time.Since(strategyStartTime)
 
 	// Пытаемся предсказать следующий сигнал
 	// Используем метод из StrategyBase, который проверяет поддержку предсказания
 	var nextSignal *internal.FutureSignal
+	var walkForward *internal.WalkForwardReport
 	if strategyBase, ok := strategy.(*internal.StrategyBase); ok {
 		nextSignal = strategyBase.PredictNextSignal(candles, config)
+		if !fromConfig {
+			walkForward = strategyBase.WalkForwardReport()
+		}
 	}
 
 	// Конвертируем V2 config в интерфейс для совместимости
@@ -171,13 +372,44 @@ func (r *BaseStrategyRunner) runStrategyV2(strategyName string, strategy interna
 		v1Config = &strategyConfigV2Wrapper{config: config}
 	}
 
+	parameters := ""
+	if config != nil {
+		parameters = config.String()
+	}
+
 	return &BenchmarkResult{
-		Name:           strategy.Name(),
-		TotalProfit:    result.TotalProfit,
-		TradeCount:     result.TradeCount,
-		FinalPortfolio: result.FinalPortfolio,
-		ExecutionTime:  executionTime,
-		NextSignal:     nextSignal,
+		Name:             strategy.Name(),
+		TotalProfit:      result.TotalProfit,
+		CAGR:             result.CAGR,
+		TradeCount:       result.TradeCount,
+		FinalPortfolio:   result.FinalPortfolio,
+		SharpeRatio:      result.SharpeRatio,
+		SortinoRatio:     result.SortinoRatio,
+		MaxDrawdown:      result.MaxDrawdown,
+		TimeInMarket:     result.TimeInMarket,
+		Parameters:       parameters,
+		Slippage:         r.slippageFor(strategyName),
+		SuppressedExits:  result.SuppressedExits,
+		BelowFloorTrades: result.BelowFloorTrades,
+		AvgTradeReturn:   internal.AverageTradeReturn(result.Trades),
+		WinningTrades:    result.WinningTrades,
+		LosingTrades:     result.LosingTrades,
+		AvgWin:           result.AvgWin,
+		AvgLoss:          result.AvgLoss,
+		ProfitFactor:     result.ProfitFactor,
+		Expectancy:       result.Expectancy,
+		AvgMFE:           result.AvgMFE,
+		AvgMAE:           result.AvgMAE,
+		FirstEntryIndex:  result.FirstEntryIndex,
+		Signals:          NewSignalStats(signals),
+		NonFinite:        result.NonFinite,
+		FromConfig:       fromConfig,
+		WalkForward:      walkForward,
+		Holdout:          holdout,
+		MonteCarlo:       r.monteCarlo(result),
+		Equity:           r.equityCurve(result),
+		ExecutionTime:    executionTime,
+		NextSignal:       nextSignal,
 	}, v1Config, nil
 }
 
@@ -213,6 +445,12 @@ func (r *BaseStrategyRunner) GetSlipping() float64 {
 	return r.slipping
 }
 
+// BuyAndHold — бенчмарк Buy & Hold с тем же проскальзыванием, что у стратегии buy_and_hold в этом раннере
+// (собственное из файла конфигурации или глобальное), одинаково в одиночном и параллельном запуске
+func (r *BaseStrategyRunner) BuyAndHold(candles []internal.Candle, anchor int) BenchmarkResult {
+	return computeBuyAndHold(candles, anchor, r.slippageFor(buyAndHoldName))
+}
+
 // ParallelStrategyRunner — реализация параллельного запуска стратегий
 type ParallelStrategyRunner struct {
 	BaseStrategyRunner
@@ -254,32 +492,14 @@ func NewParallelStrategyRunnerWithConfig(debug bool, printer ResultPrinter, conf
 	return runner
 }
 
-// saveOptimizedConfigs — сохраняет оптимизированные конфигурации в JSON файл
-func (r *ParallelStrategyRunner) saveOptimizedConfigs(configs map[string]internal.StrategyConfig) {
-	filename := "optimized_configs.json"
-	data, err := json.MarshalIndent(configs, "", "  ")
-	if err != nil {
-		fmt.Printf("❌ Ошибка сериализации конфигураций: %v\n", err)
-		return
-	}
-
-	err = os.WriteFile(filename, data, 0644)
-	if err != nil {
-		fmt.Printf("❌ Ошибка сохранения файла конфигураций %s: %v\n", filename, err)
-		return
-	}
-
-	fmt.Printf("💾 Оптимизированные конфигурации сохранены в %s\n", filename)
-}
-
 // RunStrategyWithConfig — запускает одну стратегию и возвращает результат с конфигурацией
 func (r *ParallelStrategyRunner) RunStrategyWithConfig(strategyName string, candles []internal.Candle) (*BenchmarkResult, internal.StrategyConfig, error) {
-	return r.runSingleStrategy(strategyName, candles)
+	return r.runWithTimeout(strategyName, candles)
 }
 
 // RunStrategy — запускает одну стратегию
 func (r *ParallelStrategyRunner) RunStrategy(strategyName string, candles []internal.Candle) (*BenchmarkResult, error) {
-	result, _, err := r.runSingleStrategy(strategyName, candles)
+	result, _, err := r.runWithTimeout(strategyName, candles)
 	return result, err
 }
 
@@ -292,15 +512,20 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 		fmt.Println("🚀 ЗАПУСК МАССОВОГО ТЕСТИРОВАНИЯ СТРАТЕГИЙ")
 	}
 	fmt.Println(strings.Repeat("═", 80))
-	fmt.Printf("🔥 Параллельное выполнение на %d ядрах\n", runtime.NumCPU())
+	workers := r.workerCount()
+	fmt.Printf("🔥 Параллельное выполнение на %d ядрах, одновременно стратегий: %d\n", runtime.NumCPU(), workers)
 	fmt.Printf("📊 Данных для анализа: %d свечей\n", len(candles))
+	if r.config.Holdout > 0 {
+		fmt.Printf("🧪 Отложенная выборка: последние %d свечей не участвуют в подборе параметров\n",
+			len(candles)-holdoutSplit(len(candles), r.config.Holdout))
+	}
 
 	startTime := time.Now()
-	
+
 	// Получаем стратегии из обоих реестров (V1 + V2)
 	strategyNamesV1 := internal.GetStrategyNames()
 	strategyNamesV2 := internal.GetStrategyNamesV2()
-	
+
 	// Объединяем списки стратегий
 	strategyNames := append(strategyNamesV1, strategyNamesV2...)
 	totalStrategies := len(strategyNames)
@@ -313,6 +538,12 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 	}
 
 	fmt.Printf("🎯 Всего стратегий к запуску: %d (V1: %d, V2: %d)\n", totalStrategies, len(strategyNamesV1), len(strategyNamesV2))
+	if r.config.StrategyTimeout > 0 {
+		fmt.Printf("⏰ Лимит времени на стратегию: %v\n", r.config.StrategyTimeout)
+	}
+	if r.config.MemStats {
+		fmt.Println("🧠 Замер памяти включен: стратегии выполняются по одной, чтобы приращения MemStats не смешивались")
+	}
 	fmt.Println(strings.Repeat("─", 80))
 
 	// Канал для результатов
@@ -320,24 +551,67 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 	configsChan := make(chan map[string]internal.StrategyConfig, totalStrategies)
 	var wg sync.WaitGroup
 
-	// Запускаем стратегии параллельно
-	for _, name := range strategyNames {
-		wg.Add(1)
+	// Счетчик завершенных стратегий; под мьютексом, чтобы строки прогресса не перемешивались
+	var progressMu sync.Mutex
+	completed := 0
+	var timedOut []string
+	reportProgress := func() {
+		completed++
+		if r.printer != nil {
+			r.printer.PrintProgress(completed, totalStrategies)
+		}
+	}
 
-		go func(strategyName string) {
-			defer wg.Done()
+	// runOne — прогон одной стратегии; прогресс продвигается по завершении каждой
+	runOne := func(strategyName string) {
+		result, config, err := r.RunStrategyWithConfig(strategyName, candles)
 
-			if result, config, err := r.RunStrategyWithConfig(strategyName, candles); err != nil {
-				fmt.Printf("❌ Ошибка при запуске стратегии %s: %v\n", strategyName, err)
-				return
-			} else {
-				resultsChan <- *result
-				configsChan <- map[string]internal.StrategyConfig{strategyName: config}
-				fmt.Printf("✅ %-25s │ Прибыль: %+7.2f%% │ Сделки: %4d │ Время: %8v\n",
-					result.Name, result.TotalProfit*100, result.TradeCount, result.ExecutionTime)
+		progressMu.Lock()
+		defer progressMu.Unlock()
+
+		if errors.Is(err, context.DeadlineExceeded) {
+			timedOut = append(timedOut, strategyName)
+			fmt.Printf("⏰ %-25s │ Превышен лимит времени %v, стратегия пропущена\n", strategyName, r.config.StrategyTimeout)
+			reportProgress()
+			return
+		}
+		if err != nil {
+			fmt.Printf("❌ Ошибка при запуске стратегии %s: %v\n", strategyName, err)
+			reportProgress()
+			return
+		}
+
+		resultsChan <- *result
+		configsChan <- map[string]internal.StrategyConfig{strategyName: config}
+		memory := ""
+		if r.config.MemStats {
+			memory = fmt.Sprintf(" │ Память: %9s", formatBytes(result.AllocBytes))
+		}
+		holdout := ""
+		if h := result.Holdout; h != nil {
+			holdout = fmt.Sprintf(" │ В выборке: %+7.2f%% │ Holdout: %+7.2f%%", h.InSampleProfit*100, h.HoldoutProfit*100)
+		}
+		fmt.Printf("✅ %-25s │ Прибыль: %+7.2f%% │ Сделки: %4d │ Время: %8v%s%s\n",
+			result.Name, result.TotalProfit*100, result.TradeCount, result.ExecutionTime, holdout, memory)
+		reportProgress()
+	}
+
+	// Запускаем стратегии пулом из workers горутин: каждая стратегия сама загружает все ядра перебором сетки,
+	// и горутина на каждую стратегию только вытесняла бы их друг у друга
+	jobs := make(chan string)
+	for range workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for strategyName := range jobs {
+				runOne(strategyName)
 			}
-		}(name)
+		}()
 	}
+	for _, name := range strategyNames {
+		jobs <- name
+	}
+	close(jobs)
 
 	// Ждем завершения всех горутин
 	wg.Wait()
@@ -346,10 +620,8 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 
 	// Собираем результаты
 	var results []BenchmarkResult
-	completed := 0
 	for result := range resultsChan {
 		results = append(results, result)
-		completed++
 	}
 
 	// Собираем конфигурации для сохранения
@@ -364,11 +636,24 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 	fmt.Println(strings.Repeat("─", 80))
 	fmt.Printf("⚡ Все %d стратегий выполнены за %v\n", totalStrategies, elapsed)
 	fmt.Printf("⏱️  Среднее время на стратегию: %v\n", elapsed/time.Duration(totalStrategies))
+	if len(timedOut) > 0 {
+		sort.Strings(timedOut)
+		fmt.Printf("⏰ Превысили лимит времени %v и пропущены (%d): %s\n", r.config.StrategyTimeout, len(timedOut), strings.Join(timedOut, ", "))
+	}
 
 	// Сохраняем оптимизированные конфигурации если не используется файл конфигурации
 	if r.config.ConfigFile == "" && len(optimizedConfigs) > 0 {
-		r.saveOptimizedConfigs(optimizedConfigs)
+		// Значение проверено при разборе флагов; при ошибке сохраняется только общий файл
+		spec, _ := ParseSaveConfigs(r.config.SaveConfigs)
+		SaveOptimizedConfigs(r.config.OutDir, optimizedConfigs, results, spec)
+	}
+
+	// Строка buy_and_hold считается тем же бенчмарком, что и в одиночном запуске
+	anchor := 0
+	if r.config.BenchmarkAnchor == BenchmarkAnchorTrade {
+		anchor = benchmarkAnchor(results)
 	}
+	replaceBuyAndHold(results, r.BuyAndHold(candles, anchor))
 
 	// Выводим результаты через принтер
 	if r.printer != nil {
@@ -378,6 +663,14 @@ func (r *ParallelStrategyRunner) RunAllStrategies(candles []internal.Candle) ([]
 	return results, nil
 }
 
+// workerCount — число стратегий, выполняемых одновременно: --workers или число ядер
+func (r *ParallelStrategyRunner) workerCount() int {
+	if r.config.Workers > 0 {
+		return r.config.Workers
+	}
+	return runtime.NumCPU()
+}
+
 // GetSlipping — возвращает значение параметра проскальзывания
 // func (r *ParallelStrategyRunner) GetSlipping() float64 {
 // 	return r.slipping
@@ -424,6 +717,10 @@ func (r *SingleStrategyRunner) RunStrategy(strategyName string, candles []intern
 	fmt.Println(strings.Repeat("═", 80))
 	fmt.Printf("📈 Стратегия: %s\n", strategyName)
 	fmt.Printf("📊 Данных для анализа: %d свечей\n", len(candles))
+	if r.config.Holdout > 0 {
+		fmt.Printf("🧪 Отложенная выборка: последние %d свечей не участвуют в подборе параметров\n",
+			len(candles)-holdoutSplit(len(candles), r.config.Holdout))
+	}
 	fmt.Println(strings.Repeat("─", 80))
 
 	startTime := time.Now()
@@ -442,24 +739,37 @@ func (r *SingleStrategyRunner) RunStrategy(strategyName string, candles []intern
 		fmt.Println("🔄 Оптимизация параметров...")
 	}
 
-	result, _, err := r.runSingleStrategy(strategyName, candles)
+	result, config, err := r.runWithTimeout(strategyName, candles)
 	if err != nil {
 		return nil, err
 	}
 
+	if r.config.Explain {
+		signals, reasons, err := ExplainHolds(strategyName, candles, config)
+		if err != nil {
+			fmt.Printf("⚠️  %v\n", err)
+		} else {
+			WriteHoldExplanation(os.Stdout, candles, signals, reasons)
+		}
+	}
+
 	fmt.Println("📡 Генерация торговых сигналов...")
 	fmt.Println("💹 Выполнение бэктестинга...")
 
-	// Запуск Buy & Hold как бенчмарка
-	bnhStrategy := internal.GetStrategy("buy_and_hold")
-	bnhConfig := bnhStrategy.DefaultConfig()
-	bnhSignals := bnhStrategy.GenerateSignalsWithConfig(candles, bnhConfig)
-	internal.Backtest(candles, bnhSignals, r.slipping)
-
 	executionTime := time.Since(startTime)
 
 	fmt.Println(strings.Repeat("─", 80))
 	fmt.Printf("⚡ Тестирование завершено за %v\n", executionTime)
+	if h := result.Holdout; h != nil {
+		fmt.Printf("🧪 Прибыль в выборке: %+.2f%% │ на отложенной выборке: %+.2f%% (%d сделок)\n",
+			h.InSampleProfit*100, h.HoldoutProfit*100, h.HoldoutTrades)
+	}
+	if mc := result.MonteCarlo; mc != nil {
+		printMonteCarlo(mc, internal.DefaultBacktestOptions().Capital())
+	}
+	if r.config.MemStats {
+		fmt.Printf("🧠 Выделено памяти: %s (%d аллокаций)\n", formatBytes(result.AllocBytes), result.Mallocs)
+	}
 
 	return result, nil
 }
@@ -0,0 +1,245 @@
+package backtester
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"bt/internal"
+)
+
+// truncatedConfig и truncatedStrategy — стратегия с ошибкой: сигналов на один меньше, чем свечей
+type truncatedConfig struct{}
+
+func (c *truncatedConfig) Validate() error             { return nil }
+func (c *truncatedConfig) DefaultConfigString() string { return "Truncated()" }
+
+type truncatedStrategy struct {
+	internal.BaseStrategy
+}
+
+func (s *truncatedStrategy) Name() string { return "truncated_signals_test" }
+
+func (s *truncatedStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	return make([]internal.SignalType, len(candles)-1)
+}
+
+func (s *truncatedStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	best := s.ProcessConfigs(ctx, s, candles, []internal.StrategyConfig{&truncatedConfig{}})
+	return best.A
+}
+
+func TestRunStrategy_ReportsWrongLengthSignals(t *testing.T) {
+	strategy := &truncatedStrategy{}
+	strategy.Config = &truncatedConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	candles := make([]internal.Candle, 30)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + float64(i))}
+	}
+
+	result, _, err := (&BaseStrategyRunner{slipping: 0.01}).runStrategy(context.Background(), strategy.Name(), candles)
+	if err == nil || result != nil {
+		t.Fatalf("expected an error instead of a result, got result %v", result)
+	}
+	if !strings.Contains(err.Error(), strategy.Name()) || !strings.Contains(err.Error(), "29 сигналов на 30 свечей") {
+		t.Errorf("expected error naming the strategy and the lengths, got: %v", err)
+	}
+}
+
+func TestRunStrategy_UnknownStrategyReturnsError(t *testing.T) {
+	candles := []internal.Candle{{Close: 100}, {Close: 101}}
+	result, _, err := (&BaseStrategyRunner{slipping: 0.01}).runStrategy(context.Background(), "no_such_strategy_test", candles)
+	if err == nil || result != nil || !strings.Contains(err.Error(), "не найдена") {
+		t.Errorf("expected a not-found error instead of a result, got result %v, err %v", result, err)
+	}
+}
+
+// stuckStrategy — стратегия, оптимизация которой не заканчивается, пока контекст не отменен
+type stuckStrategy struct {
+	internal.BaseStrategy
+	stopped chan struct{}
+}
+
+func (s *stuckStrategy) Name() string { return "stuck_optimizer_test" }
+
+func (s *stuckStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	return make([]internal.SignalType, len(candles))
+}
+
+func (s *stuckStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	<-ctx.Done()
+	close(s.stopped)
+	return s.DefaultConfig()
+}
+
+func TestRunWithTimeout_CancelsOptimizationAndSkipsStrategy(t *testing.T) {
+	strategy := &stuckStrategy{stopped: make(chan struct{})}
+	strategy.Config = &truncatedConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	candles := make([]internal.Candle, 30)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + float64(i))}
+	}
+
+	runner := &BaseStrategyRunner{slipping: 0.01, config: Config{StrategyTimeout: 20 * time.Millisecond}}
+	result, _, err := runner.runWithTimeout(strategy.Name(), candles)
+	if !errors.Is(err, context.DeadlineExceeded) || result != nil {
+		t.Fatalf("expected a deadline error instead of a result, got result %v, err %v", result, err)
+	}
+
+	select {
+	case <-strategy.stopped:
+	case <-time.After(time.Second):
+		t.Fatal("optimization was not cancelled")
+	}
+}
+
+// slowStrategy — стратегия, оптимизация которой занимает delay и не прерывается отменой контекста
+type slowStrategy struct {
+	internal.BaseStrategy
+	delay time.Duration
+}
+
+func (s *slowStrategy) Name() string { return "slow_optimizer_test" }
+
+func (s *slowStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	return make([]internal.SignalType, len(candles))
+}
+
+func (s *slowStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	time.Sleep(s.delay)
+	return s.DefaultConfig()
+}
+
+func TestRunWithTimeout_MemStatsQueueDoesNotConsumeTimeout(t *testing.T) {
+	strategy := &slowStrategy{delay: 80 * time.Millisecond}
+	strategy.Config = &truncatedConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	candles := make([]internal.Candle, 30)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + float64(i))}
+	}
+
+	// Каждый прогон укладывается в таймаут, но три прогона подряд под memStatsMu — нет
+	runner := &BaseStrategyRunner{slipping: 0.01, config: Config{MemStats: true, StrategyTimeout: 200 * time.Millisecond}}
+	errs := make(chan error, 3)
+	for range 3 {
+		go func() {
+			_, _, err := runner.runWithTimeout(strategy.Name(), candles)
+			errs <- err
+		}()
+	}
+	for range 3 {
+		if err := <-errs; err != nil {
+			t.Errorf("expected waiting for --mem-stats lock not to count towards the timeout, got %v", err)
+		}
+	}
+}
+
+func TestLoadConfigsFromFile_PerStrategySlippageOverridesGlobal(t *testing.T) {
+	strategy := &fixedTradeStrategy{name: "fixed_trade_slippage_test"}
+	strategy.Config = &fixedTradeConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	configFile := filepath.Join(t.TempDir(), "configs.json")
+	saved := `{
+		"slipping": 0.5,
+		"fixed_trade_slippage_test": {"entry": 1, "exit": 8, "slipping": 2},
+		"free_fills": {"slipping": 0},
+		"global_fills": {"period": 14}
+	}`
+	if err := os.WriteFile(configFile, []byte(saved), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runner := &BaseStrategyRunner{config: Config{ConfigFile: configFile}}
+	runner.loadConfigsFromFile()
+
+	for name, want := range map[string]float64{
+		"fixed_trade_slippage_test": 2,
+		"free_fills":                0, // явный ноль тоже переопределяет глобальное значение
+		"global_fills":              0.5,
+		"not_in_file":               0.5,
+	} {
+		if got := runner.slippageFor(name); got != want {
+			t.Errorf("slippageFor(%s) = %v, want %v", name, got, want)
+		}
+	}
+
+	// Бэктест стратегии идет с ее собственным проскальзыванием, а поле slipping не мешает разбору конфигурации
+	candles := trendCandles(12, 1)
+	result, _, err := runner.runStrategy(context.Background(), strategy.Name(), candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	config := &fixedTradeConfig{Entry: 1, Exit: 8}
+	want := internal.Backtest(candles, strategy.GenerateSignalsWithConfig(candles, config), 2)
+	if result.Parameters != config.DefaultConfigString() || result.TotalProfit != want.TotalProfit {
+		t.Errorf("got %s with profit %.6f, want %s with profit %.6f at slippage 2",
+			result.Parameters, result.TotalProfit, config.DefaultConfigString(), want.TotalProfit)
+	}
+	if global := internal.Backtest(candles, strategy.GenerateSignalsWithConfig(candles, config), 0.5); global.TotalProfit == want.TotalProfit {
+		t.Fatal("test candles do not distinguish the override from the global slippage")
+	}
+}
+
+// holdoutProbeStrategy — запоминает, сколько свечей видел оптимизатор; торгует одну сделку на каждом отрезке
+type holdoutProbeStrategy struct {
+	internal.BaseStrategy
+	optimized int
+}
+
+func (s *holdoutProbeStrategy) Name() string { return "holdout_probe_test" }
+
+func (s *holdoutProbeStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	signals := make([]internal.SignalType, len(candles))
+	signals[0], signals[29], signals[30], signals[len(candles)-1] = internal.BUY, internal.SELL, internal.BUY, internal.SELL
+	return signals
+}
+
+func (s *holdoutProbeStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	s.optimized = len(candles)
+	return s.DefaultConfig()
+}
+
+func TestRunStrategy_HoldoutHidesTailFromOptimizer(t *testing.T) {
+	strategy := &holdoutProbeStrategy{}
+	strategy.Config = &truncatedConfig{}
+	internal.RegisterStrategy(strategy.Name(), strategy)
+
+	candles := make([]internal.Candle, 40)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + float64(i))}
+	}
+
+	runner := &BaseStrategyRunner{slipping: 0.01, config: Config{Holdout: 0.25}}
+	result, _, err := runner.runStrategy(context.Background(), strategy.Name(), candles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strategy.optimized != 30 {
+		t.Errorf("expected the optimizer to see 30 candles, got %d", strategy.optimized)
+	}
+	h := result.Holdout
+	if h == nil || h.Split != 30 || h.HoldoutTrades != 1 {
+		t.Fatalf("expected a holdout split at 30 with one trade, got %+v", h)
+	}
+	if h.HoldoutProfit <= 0 {
+		t.Errorf("expected holdout profit from the tail trade, got %.4f", h.HoldoutProfit)
+	}
+	if h.InSampleProfit <= 0 || result.TradeCount != 2 {
+		t.Errorf("expected in-sample profit and both trades in the full run, got %.4f and %d trades", h.InSampleProfit, result.TradeCount)
+	}
+
+	runner.config.Holdout = 0
+	if result, _, _ := runner.runStrategy(context.Background(), strategy.Name(), candles); result.Holdout != nil || strategy.optimized != 40 {
+		t.Errorf("expected no holdout and optimization on all candles, got %+v and %d", result.Holdout, strategy.optimized)
+	}
+}
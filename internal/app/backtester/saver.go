@@ -1,9 +1,9 @@
 package backtester
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
 	"os"
 	"path/filepath"
 	"strings"
@@ -13,11 +13,22 @@ import (
 )
 
 // FileSaver — реализация сохранения результатов в файлы
-type FileSaver struct{}
+type FileSaver struct {
+	outDir string // каталог для файлов сигналов и кривых капитала
+}
 
-// NewFileSaver — конструктор для FileSaver
+// NewFileSaver — конструктор для FileSaver, сохраняющего файлы в текущий каталог
 func NewFileSaver() *FileSaver {
-	return &FileSaver{}
+	return &FileSaver{outDir: DefaultOutDir}
+}
+
+// NewFileSaverWithConfig — конструктор для FileSaver с каталогом вывода из конфигурации
+func NewFileSaverWithConfig(config Config) *FileSaver {
+	saver := NewFileSaver()
+	if config.OutDir != "" {
+		saver.outDir = config.OutDir
+	}
+	return saver
 }
 
 // SaveTopStrategies — сохраняет топ-N стратегии с сигналами в отдельные файлы
@@ -31,6 +42,9 @@ func (s *FileSaver) SaveTopStrategies(candles []internal.Candle, results []Bench
 
 	// Получаем базовое имя файла без расширения
 	baseName := strings.TrimSuffix(filepath.Base(inputFilename), filepath.Ext(inputFilename))
+	if inputFilename == internal.StdinPath {
+		baseName = "stdin"
+	}
 
 	for i := 0; i < topN && i < len(results); i++ {
 		strategyName := results[i].Name
@@ -38,21 +52,21 @@ func (s *FileSaver) SaveTopStrategies(candles []internal.Candle, results []Bench
 		// Пробуем получить стратегию V2
 		var signals []internal.SignalType
 		var configInterface interface{}
-		
+
 		strategyV2, isV2 := internal.GetStrategyV2(strategyName)
 		if isV2 && strategyV2 != nil {
 			// Стратегия V2
-			config := strategyV2.Optimize(candles, strategyV2)
+			config := strategyV2.Optimize(context.Background(), candles, strategyV2)
 			signals = strategyV2.GenerateSignals(candles, config)
 			configInterface = config
 		} else {
 			// Стратегия V1
 			strategy := internal.GetStrategy(strategyName)
 			if strategy == nil {
-				log.Printf("❌ Стратегия %s не найдена", strategyName)
+				internal.Log.Errorf("❌ Стратегия %s не найдена", strategyName)
 				continue
 			}
-			config := strategy.OptimizeWithConfig(candles)
+			config := strategy.OptimizeWithConfig(context.Background(), candles)
 			signals = strategy.GenerateSignalsWithConfig(candles, config)
 			configInterface = config
 		}
@@ -78,7 +92,10 @@ func (s *FileSaver) SaveTopStrategies(candles []internal.Candle, results []Bench
 		}
 
 		// Создаем имя файла с постфиксом стратегии
-		outputFilename := fmt.Sprintf("%s_%s_signals.json", baseName, strategyName)
+		outputFilename, err := outputPath(s.outDir, fmt.Sprintf("%s_%s_signals.json", baseName, strategyName))
+		if err != nil {
+			return err
+		}
 
 		// Сохраняем в файл
 		data := struct {
@@ -95,13 +112,13 @@ func (s *FileSaver) SaveTopStrategies(candles []internal.Candle, results []Bench
 
 		jsonData, err := json.MarshalIndent(data, "", "  ")
 		if err != nil {
-			log.Printf("❌ Ошибка сериализации данных для %s: %v", strategyName, err)
+			internal.Log.Errorf("❌ Ошибка сериализации данных для %s: %v", strategyName, err)
 			continue
 		}
 
 		err = os.WriteFile(outputFilename, jsonData, 0644)
 		if err != nil {
-			log.Printf("❌ Ошибка сохранения файла %s: %v", outputFilename, err)
+			internal.Log.Errorf("❌ Ошибка сохранения файла %s: %v", outputFilename, err)
 			continue
 		}
 
@@ -112,6 +129,77 @@ func (s *FileSaver) SaveTopStrategies(candles []internal.Candle, results []Bench
 	return nil
 }
 
+// EquityPoint — стоимость портфеля на момент закрытия свечи
+type EquityPoint struct {
+	Time  string  `json:"time"`
+	Value float64 `json:"value"`
+}
+
+// SaveEquityCurves — сохраняет кривые капитала топ-N стратегий в отдельные файлы <вход>_<стратегия>_equity.json.
+// Кривые есть только у результатов прогона с --save-equity (и у Buy & Hold), остальные пропускаются
+func (s *FileSaver) SaveEquityCurves(candles []internal.Candle, results []BenchmarkResult, inputFilename string, topN int) error {
+	if topN <= 0 {
+		return nil
+	}
+
+	baseName := strings.TrimSuffix(filepath.Base(inputFilename), filepath.Ext(inputFilename))
+	if inputFilename == internal.StdinPath {
+		baseName = "stdin"
+	}
+
+	for i := 0; i < topN && i < len(results); i++ {
+		result := results[i]
+		// Первое значение — капитал до первой свечи, дальше по одному на свечу
+		if len(result.Equity) != len(candles)+1 {
+			internal.Log.Warnf("⚠️ Нет кривой капитала для %s — пропущено", result.Name)
+			continue
+		}
+
+		points := make([]EquityPoint, len(candles))
+		for j, candle := range candles {
+			ts := candle.Time
+			if t := candle.ToTime(); !t.IsZero() {
+				ts = t.Format(time.RFC3339Nano)
+			}
+			points[j] = EquityPoint{Time: ts, Value: result.Equity[j+1]}
+		}
+
+		data := struct {
+			Strategy      string        `json:"strategy"`
+			Parameters    string        `json:"parameters"`
+			Profit        float64       `json:"profit"`
+			InitialEquity float64       `json:"initial_equity"`
+			Equity        []EquityPoint `json:"equity"`
+		}{
+			Strategy:      result.Name,
+			Parameters:    result.Parameters,
+			Profit:        result.TotalProfit,
+			InitialEquity: result.Equity[0],
+			Equity:        points,
+		}
+
+		jsonData, err := json.MarshalIndent(data, "", "  ")
+		if err != nil {
+			internal.Log.Errorf("❌ Ошибка сериализации кривой капитала для %s: %v", result.Name, err)
+			continue
+		}
+
+		outputFilename, err := outputPath(s.outDir, fmt.Sprintf("%s_%s_equity.json", baseName, result.Name))
+		if err != nil {
+			return err
+		}
+		if err := os.WriteFile(outputFilename, jsonData, 0644); err != nil {
+			internal.Log.Errorf("❌ Ошибка сохранения файла %s: %v", outputFilename, err)
+			continue
+		}
+
+		fmt.Printf("📈 Сохранена кривая капитала: %s (прибыль: %.2f%%, точек: %d)\n",
+			outputFilename, result.TotalProfit*100, len(points))
+	}
+
+	return nil
+}
+
 // getSignalAtIndex — возвращает сигнал по индексу с проверкой границ
 func getSignalAtIndex(signals []internal.SignalType, index int) internal.SignalType {
 	if index < 0 || index >= len(signals) {
@@ -0,0 +1,84 @@
+package backtester
+
+import (
+	"encoding/json"
+	"os"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestFileSaver_SaveEquityCurvesPerCandle(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	candles := []internal.Candle{
+		{Close: internal.Price(100.0), Time: "2024-01-01T10:00:00Z"},
+		{Close: internal.Price(110.0), Time: "2024-01-01T10:30:00Z"},
+		{Close: internal.Price(121.0), Time: "2024-01-01T11:00:00Z"},
+	}
+	results := []BenchmarkResult{
+		BuyAndHoldBenchmark(candles, 0, 0),
+		{Name: "no_curve", TotalProfit: 0.01}, // прогон без --save-equity
+	}
+
+	if err := NewFileSaver().SaveEquityCurves(candles, results, "data/prices.json", 2); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("prices_buy_and_hold_equity.json")
+	if err != nil {
+		t.Fatalf("Expected equity file named after input and strategy: %v", err)
+	}
+	var saved struct {
+		InitialEquity float64       `json:"initial_equity"`
+		Equity        []EquityPoint `json:"equity"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+
+	// Одна точка на свечу, после ее закрытия: покупка по 100, рост на 10% за бар
+	if saved.InitialEquity != 10000 || len(saved.Equity) != len(candles) {
+		t.Fatalf("Expected 3 points from 10000, got %d from %.2f", len(saved.Equity), saved.InitialEquity)
+	}
+	if saved.Equity[2].Value < 12099.99 || saved.Equity[2].Value > 12100.01 || saved.Equity[2].Time != "2024-01-01T11:00:00Z" {
+		t.Errorf("Expected 12100 at the last candle, got %+v", saved.Equity[2])
+	}
+
+	if _, err := os.Stat("prices_no_curve_equity.json"); !os.IsNotExist(err) {
+		t.Error("Expected result without an equity curve to be skipped")
+	}
+}
+
+func TestFileSaver_SaveEquityCurveWithSellBeforeFirstBuy(t *testing.T) {
+	t.Chdir(t.TempDir())
+
+	candles := []internal.Candle{
+		{Close: internal.Price(100.0), Time: "2024-01-01T10:00:00Z"},
+		{Close: internal.Price(100.0), Time: "2024-01-01T10:30:00Z"},
+		{Close: internal.Price(110.0), Time: "2024-01-01T11:00:00Z"},
+	}
+	// Первый SELL до первого BUY игнорируется движком, но бар все равно должен попасть в кривую капитала
+	signals := []internal.SignalType{internal.SELL, internal.BUY, internal.HOLD}
+	runner := &BaseStrategyRunner{config: Config{SaveEquity: true}}
+	backtest := internal.BacktestWithOptions(candles, signals, internal.BacktestOptions{})
+	results := []BenchmarkResult{{Name: "sell_first", TotalProfit: backtest.TotalProfit, Equity: runner.equityCurve(backtest)}}
+
+	if err := NewFileSaver().SaveEquityCurves(candles, results, "prices.json", 1); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := os.ReadFile("prices_sell_first_equity.json")
+	if err != nil {
+		t.Fatalf("Expected equity file for a strategy that sells before its first buy: %v", err)
+	}
+	var saved struct {
+		Equity []EquityPoint `json:"equity"`
+	}
+	if err := json.Unmarshal(data, &saved); err != nil {
+		t.Fatal(err)
+	}
+	if len(saved.Equity) != len(candles) || saved.Equity[0].Value != 10000 {
+		t.Fatalf("Expected %d points starting flat at 10000, got %+v", len(candles), saved.Equity)
+	}
+}
@@ -0,0 +1,95 @@
+package backtester
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+
+	"bt/internal"
+)
+
+// SaveSearchSurface — сохраняет все проверенные оптимизатором конфигурации в CSV (флаг --dump-search)
+func SaveSearchSurface(filename string, points []internal.SearchPoint) error {
+	f, err := os.Create(filename)
+	if err != nil {
+		return fmt.Errorf("не удалось создать %s: %w", filename, err)
+	}
+	defer f.Close()
+
+	if err := WriteSearchSurface(f, points); err != nil {
+		return fmt.Errorf("ошибка записи %s: %w", filename, err)
+	}
+	return nil
+}
+
+// WriteSearchSurface — CSV поверхности поиска: по колонке на каждый параметр конфигурации и метрики прогона.
+// Строки отсортированы по целевой функции (лучшие вверху)
+func WriteSearchSurface(out io.Writer, points []internal.SearchPoint) error {
+	sorted := make([]internal.SearchPoint, len(points))
+	copy(sorted, points)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].Score != sorted[j].Score {
+			return sorted[i].Score > sorted[j].Score
+		}
+		return sorted[i].Config < sorted[j].Config
+	})
+
+	// Колонки параметров — объединение ключей всех конфигураций в алфавитном порядке
+	seen := make(map[string]bool)
+	var params []string
+	for _, p := range sorted {
+		for name := range p.Params {
+			if !seen[name] {
+				seen[name] = true
+				params = append(params, name)
+			}
+		}
+	}
+	sort.Strings(params)
+
+	w := csv.NewWriter(out)
+	header := append([]string{"config"}, params...)
+	header = append(header, "objective", "profit", "sharpe", "trades")
+	if err := w.Write(header); err != nil {
+		return err
+	}
+
+	for _, p := range sorted {
+		record := []string{p.Config}
+		for _, name := range params {
+			record = append(record, formatSearchParam(p.Params[name]))
+		}
+		profit := formatCSVFloat(p.TotalProfit, 6)
+		if p.NonFinite {
+			profit = "N/A"
+		}
+		record = append(record,
+			formatCSVFloat(p.Score, 6),
+			profit,
+			formatCSVFloat(p.SharpeRatio, 4),
+			strconv.Itoa(p.TradeCount))
+		if err := w.Write(record); err != nil {
+			return err
+		}
+	}
+
+	w.Flush()
+	return w.Error()
+}
+
+// formatSearchParam — значение параметра из JSON: числа без экспоненты, отсутствующий параметр — пустая ячейка
+func formatSearchParam(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case string:
+		return v
+	default:
+		return fmt.Sprint(v)
+	}
+}
@@ -0,0 +1,31 @@
+package backtester
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestWriteSearchSurface_ParamColumnsAndOrder(t *testing.T) {
+	points := []internal.SearchPoint{
+		{Config: "RSI(7)", Params: map[string]interface{}{"period": 7.0, "level": 30.5}, Score: -0.02, TotalProfit: -0.02, TradeCount: 4},
+		{Config: "RSI(14)", Params: map[string]interface{}{"period": 14.0}, Score: 0.1, TotalProfit: 0.1, SharpeRatio: 1.5, TradeCount: 2},
+	}
+
+	var buf bytes.Buffer
+	if err := WriteSearchSurface(&buf, points); err != nil {
+		t.Fatal(err)
+	}
+	lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+
+	expected := []string{
+		"config,level,period,objective,profit,sharpe,trades",
+		"RSI(14),,14,0.100000,0.100000,1.5000,2",
+		"RSI(7),30.5,7,-0.020000,-0.020000,0.0000,4",
+	}
+	if strings.Join(lines, "\n") != strings.Join(expected, "\n") {
+		t.Errorf("unexpected CSV:\n%s", buf.String())
+	}
+}
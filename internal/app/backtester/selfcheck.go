@@ -0,0 +1,119 @@
+// selfcheck.go — прогон всех зарегистрированных стратегий на синтетических данных
+package backtester
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"bt/internal"
+)
+
+// SelfCheckResult — итог самопроверки одной стратегии
+type SelfCheckResult struct {
+	Name          string
+	TotalProfit   float64
+	TradeCount    int
+	ExecutionTime time.Duration
+	Err           error // nil — стратегия прошла проверку
+}
+
+// RunSelfCheck — запускает все стратегии (V1 + V2) параллельно на переданных свечах и проверяет,
+// что каждая завершается без паники и с конечными значениями прибыли, портфеля и Sharpe
+// (в том числе без замены нечислового результата движком).
+// Печатает pass/fail по каждой стратегии и возвращает результаты, отсортированные по имени
+func RunSelfCheck(candles []internal.Candle) []SelfCheckResult {
+	runner := &BaseStrategyRunner{slipping: 0.01}
+	names := append(internal.GetStrategyNames(), internal.GetStrategyNamesV2()...)
+
+	fmt.Println("\n" + strings.Repeat("═", 80))
+	fmt.Println("🩺 САМОПРОВЕРКА СТРАТЕГИЙ НА СИНТЕТИЧЕСКИХ ДАННЫХ")
+	fmt.Println(strings.Repeat("═", 80))
+	fmt.Printf("📊 Свечей: %d, стратегий: %d\n", len(candles), len(names))
+	fmt.Println(strings.Repeat("─", 80))
+
+	results := make([]SelfCheckResult, len(names))
+	var wg sync.WaitGroup
+	for i, name := range names {
+		wg.Add(1)
+		go func(i int, name string) {
+			defer wg.Done()
+			results[i] = checkStrategy(runner, name, candles)
+		}(i, name)
+	}
+	wg.Wait()
+
+	sort.Slice(results, func(i, j int) bool { return results[i].Name < results[j].Name })
+
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Printf("❌ %-30s │ %v\n", r.Name, r.Err)
+			continue
+		}
+		fmt.Printf("✅ %-30s │ Прибыль: %+7.2f%% │ Сделки: %4d │ Время: %8v\n",
+			r.Name, r.TotalProfit*100, r.TradeCount, r.ExecutionTime)
+	}
+
+	failed := SelfCheckFailures(results)
+	fmt.Println(strings.Repeat("─", 80))
+	fmt.Printf("🩺 Пройдено: %d, с ошибками: %d\n", len(results)-failed, failed)
+
+	return results
+}
+
+// checkStrategy — прогоняет одну стратегию, превращая панику и нечисловые метрики в ошибку
+func checkStrategy(runner *BaseStrategyRunner, name string, candles []internal.Candle) (check SelfCheckResult) {
+	check.Name = name
+	defer func() {
+		if p := recover(); p != nil {
+			check.Err = fmt.Errorf("паника: %v", p)
+		}
+	}()
+
+	result, _, err := runner.runSingleStrategy(context.Background(), name, candles)
+	if err != nil {
+		check.Err = err
+		return check
+	}
+
+	check.TotalProfit = result.TotalProfit
+	check.TradeCount = result.TradeCount
+	check.ExecutionTime = result.ExecutionTime
+
+	if result.NonFinite {
+		check.Err = fmt.Errorf("нечисловой результат движка (NaN/Inf)")
+		return check
+	}
+
+	metrics := []struct {
+		name  string
+		value float64
+	}{
+		{"прибыль", result.TotalProfit},
+		{"портфель", result.FinalPortfolio},
+		{"Sharpe", result.SharpeRatio},
+	}
+	for _, m := range metrics {
+		if math.IsNaN(m.value) || math.IsInf(m.value, 0) {
+			check.Err = fmt.Errorf("%s = %v", m.name, m.value)
+			return check
+		}
+	}
+
+	return check
+}
+
+// SelfCheckFailures — число стратегий, не прошедших самопроверку
+func SelfCheckFailures(results []SelfCheckResult) int {
+	failed := 0
+	for _, r := range results {
+		if r.Err != nil {
+			failed++
+		}
+	}
+	return failed
+}
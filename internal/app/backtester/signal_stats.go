@@ -0,0 +1,73 @@
+package backtester
+
+import (
+	"fmt"
+
+	"bt/internal"
+)
+
+// SignalStats — распределение сигналов стратегии: выявляет вырожденные стратегии
+// (одни HOLD или смена сигнала на каждом баре) еще до анализа прибыли
+type SignalStats struct {
+	Buy  int
+	Sell int
+	Hold int
+	// AvgBarsBetween — среднее число баров между соседними сигналами BUY/SELL (0, если таких сигналов меньше двух)
+	AvgBarsBetween float64
+}
+
+// NewSignalStats — считает распределение по массиву сигналов, который runner передает в бэктест
+func NewSignalStats(signals []internal.SignalType) SignalStats {
+	var stats SignalStats
+	first, last := -1, -1
+	for i, signal := range signals {
+		switch signal {
+		case internal.BUY:
+			stats.Buy++
+		case internal.SELL:
+			stats.Sell++
+		default:
+			stats.Hold++
+			continue
+		}
+		if first < 0 {
+			first = i
+		}
+		last = i
+	}
+	if actions := stats.Buy + stats.Sell; actions > 1 {
+		stats.AvgBarsBetween = float64(last-first) / float64(actions-1)
+	}
+	return stats
+}
+
+// Total — число проанализированных баров; 0 — распределение не считалось (результат собран не runner'ом)
+func (s SignalStats) Total() int {
+	return s.Buy + s.Sell + s.Hold
+}
+
+// Inactive — стратегия не выдала ни одного сигнала BUY/SELL
+func (s SignalStats) Inactive() bool {
+	return s.Total() > 0 && s.Buy+s.Sell == 0
+}
+
+// Spacing — среднее число баров между сигналами для отчетов
+func (s SignalStats) Spacing() string {
+	if s.Inactive() {
+		return "inactive"
+	}
+	if s.AvgBarsBetween == 0 {
+		return "-"
+	}
+	return fmt.Sprintf("%.1f", s.AvgBarsBetween)
+}
+
+// hasSignalStats — есть ли в результатах хотя бы одно посчитанное распределение сигналов
+func hasSignalStats(results []BenchmarkResult) bool {
+	for _, r := range results {
+		if r.Signals.Total() > 0 {
+			return true
+		}
+	}
+	return false
+}
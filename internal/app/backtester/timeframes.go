@@ -0,0 +1,104 @@
+package backtester
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"bt/internal"
+)
+
+// TimeframeResult — результат стратегии на одном таймфрейме
+type TimeframeResult struct {
+	Timeframe  time.Duration
+	Candles    int
+	Result     BenchmarkResult
+	BuyAndHold float64 // прибыль Buy & Hold на тех же данных
+}
+
+// Edge — превышение прибыли стратегии над Buy & Hold
+func (r TimeframeResult) Edge() float64 {
+	return r.Result.TotalProfit - r.BuyAndHold
+}
+
+// RunTimeframeStability — агрегирует свечи в каждый таймфрейм, оптимизирует и тестирует стратегию на каждом
+func RunTimeframeStability(runner StrategyRunner, strategyName string, candles []internal.Candle, timeframes []time.Duration, slippage float64) ([]TimeframeResult, error) {
+	bnhStrategy := internal.GetStrategy("buy_and_hold")
+
+	var results []TimeframeResult
+	for _, timeframe := range timeframes {
+		resampled, err := internal.ResampleCandles(candles, timeframe)
+		if err != nil {
+			return nil, fmt.Errorf("таймфрейм %s: %w", FormatTimeframe(timeframe), err)
+		}
+
+		// Записи кэша прошлого таймфрейма больше не понадобятся — сбрасываем его, чтобы он не рос
+		internal.ResetCache()
+
+		fmt.Printf("⏳ Таймфрейм %s: %d свечей, оптимизация %s...\n", FormatTimeframe(timeframe), len(resampled), strategyName)
+		result, err := runner.RunStrategy(strategyName, resampled)
+		if err != nil {
+			return nil, fmt.Errorf("таймфрейм %s: %w", FormatTimeframe(timeframe), err)
+		}
+
+		bnhSignals := bnhStrategy.GenerateSignalsWithConfig(resampled, bnhStrategy.DefaultConfig())
+		bnhResult := internal.Backtest(resampled, bnhSignals, slippage)
+
+		results = append(results, TimeframeResult{
+			Timeframe:  timeframe,
+			Candles:    len(resampled),
+			Result:     *result,
+			BuyAndHold: bnhResult.TotalProfit,
+		})
+	}
+
+	return results, nil
+}
+
+// PrintTimeframeStability — выводит таблицу по таймфреймам и вывод о стабильности преимущества стратегии
+func PrintTimeframeStability(strategyName string, results []TimeframeResult) {
+	fmt.Println("\n" + strings.Repeat("═", 100))
+	fmt.Printf("🔬 СТАБИЛЬНОСТЬ ПО ТАЙМФРЕЙМАМ: %s\n", strategyName)
+	fmt.Println(strings.Repeat("═", 100))
+	fmt.Printf("%-10s │ %8s │ %10s │ %7s │ %8s │ %10s │ %10s\n",
+		"Таймфрейм", "Свечей", "Прибыль", "Сделки", "Sharpe", "Buy&Hold", "Преимущ.")
+	fmt.Println(strings.Repeat("─", 100))
+
+	beats := 0
+	for _, r := range results {
+		marker := "❌"
+		if r.Edge() > 0 {
+			marker = "✅"
+			beats++
+		}
+		fmt.Printf("%-10s │ %8d │ %+9.2f%% │ %7d │ %8.2f │ %+9.2f%% │ %+9.2f%% %s\n",
+			FormatTimeframe(r.Timeframe), r.Candles, r.Result.TotalProfit*100, r.Result.TradeCount, r.Result.SharpeRatio,
+			r.BuyAndHold*100, r.Edge()*100, marker)
+	}
+	fmt.Println(strings.Repeat("─", 100))
+
+	switch {
+	case len(results) == 0:
+		fmt.Println("⚠️ Нет результатов")
+	case beats == len(results):
+		fmt.Printf("🟢 Преимущество устойчиво: стратегия обгоняет Buy & Hold на всех %d таймфреймах\n", len(results))
+	case beats <= 1:
+		fmt.Printf("🔴 Подозрительно: стратегия обгоняет Buy & Hold лишь на %d из %d таймфреймов — вероятна подгонка\n", beats, len(results))
+	default:
+		fmt.Printf("🟡 Частично: стратегия обгоняет Buy & Hold на %d из %d таймфреймов\n", beats, len(results))
+	}
+}
+
+// FormatTimeframe — компактная запись таймфрейма: 30m, 4h, 1d
+func FormatTimeframe(d time.Duration) string {
+	switch {
+	case d%(24*time.Hour) == 0:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d%time.Minute == 0:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	default:
+		return d.String()
+	}
+}
@@ -9,11 +9,65 @@ import (
 type BenchmarkResult struct {
 	Name           string
 	TotalProfit    float64
+	CAGR           float64 // среднегодовая доходность по времени первой и последней свечи
 	TradeCount     int
 	FinalPortfolio float64
-	ExecutionTime  time.Duration
+	SharpeRatio    float64
+	SortinoRatio   float64
+	MaxDrawdown    float64 // максимальная просадка портфеля от пика (0..1)
+	TimeInMarket   float64 // доля баров с открытой позицией
+	Parameters     string  // параметры конфигурации, давшей результат (DefaultConfigString / String)
+	// Slippage — проскальзывание стратегии в единицах цены на каждую сторону сделки (глобальное или из --config)
+	Slippage float64
+	// SuppressedExits — SELL-сигналы, пропущенные фильтром минимального движения цены
+	SuppressedExits int
+	// BelowFloorTrades — сделки с чистой доходностью ниже порога
+	BelowFloorTrades int
+	// AvgTradeReturn — средняя чистая доходность одной сделки по журналу сделок
+	AvgTradeReturn float64
+	// WinningTrades и LosingTrades — закрытые сделки с прибылью и с убытком (открытая на конец позиция не считается)
+	WinningTrades int
+	LosingTrades  int
+	// AvgWin и AvgLoss — средняя доходность выигрышной и проигрышной сделки (AvgLoss отрицательная)
+	AvgWin  float64
+	AvgLoss float64
+	// ProfitFactor — валовая прибыль / валовый убыток закрытых сделок (+Inf без убыточных сделок)
+	ProfitFactor float64
+	// Expectancy — средний чистый результат закрытой сделки в деньгах
+	Expectancy float64
+	// AvgMFE и AvgMAE — среднее лучшее и худшее движение цены за время закрытых сделок (только с --mfe-mae)
+	AvgMFE float64
+	AvgMAE float64
+	// AllocBytes и Mallocs — выделенная за прогон память и число аллокаций (только с --mem-stats)
+	AllocBytes uint64
+	Mallocs    uint64
+	// NonFinite — движок получил NaN/Inf; прибыль заменена на internal.NonFiniteProfit, в отчетах — N/A
+	NonFinite bool
+	// FirstEntryIndex — бар первого входа в позицию (-1, если входов не было)
+	FirstEntryIndex int
+	// Signals — распределение BUY/SELL/HOLD в сигналах, переданных в бэктест
+	Signals SignalStats
+	// FromConfig — конфигурация загружена из --config, а не подобрана оптимизатором на этих данных
+	FromConfig bool
+	// WalkForward — прибыль в выборке и вне ее при walk-forward оптимизации (nil — подбор по всем свечам)
+	WalkForward *internal.WalkForwardReport
+	// Holdout — прибыль на свечах подбора и на отложенной выборке (nil без --holdout)
+	Holdout *HoldoutResult
+	// MonteCarlo — доверительные полосы итога и просадки по перевыборкам сделок (nil без --monte_carlo)
+	MonteCarlo *internal.MCStats
+	// Equity — стоимость портфеля до первой свечи и после каждой (только с --save-equity)
+	Equity        []float64
+	ExecutionTime time.Duration
 	// Предсказание следующего сигнала
-	NextSignal     *internal.FutureSignal
+	NextSignal *internal.FutureSignal
+}
+
+// WinRate — доля выигрышных среди закрытых сделок (0 без сделок)
+func (r BenchmarkResult) WinRate() float64 {
+	if r.TradeCount == 0 {
+		return 0
+	}
+	return float64(r.WinningTrades) / float64(r.TradeCount)
 }
 
 // CandleWithSignal — свеча с сигналом для построения графиков
@@ -31,11 +85,14 @@ type CandleWithSignal struct {
 type StrategyRunner interface {
 	RunStrategy(strategyName string, candles []internal.Candle) (*BenchmarkResult, error)
 	RunAllStrategies(candles []internal.Candle) ([]BenchmarkResult, error)
+	// BuyAndHold — бенчмарк Buy & Hold с покупкой на баре anchor и проскальзыванием раннера
+	BuyAndHold(candles []internal.Candle, anchor int) BenchmarkResult
 }
 
 // ResultSaver — интерфейс для сохранения результатов
 type ResultSaver interface {
 	SaveTopStrategies(candles []internal.Candle, results []BenchmarkResult, inputFilename string, topN int) error
+	SaveEquityCurves(candles []internal.Candle, results []BenchmarkResult, inputFilename string, topN int) error
 }
 
 // ResultPrinter — интерфейс для вывода результатов
@@ -46,7 +103,11 @@ type ResultPrinter interface {
 
 // Config — конфигурация приложения
 type Config struct {
-	Filename    string
+	Filename string
+	// Dir — каталог с файлами свечей (по файлу на инструмент) для сводного рейтинга
+	Dir string
+	// Source — тип источника данных: json или csv
+	Source      string
 	Strategy    string
 	Debug       bool
 	SaveSignals int
@@ -54,4 +115,149 @@ type Config struct {
 	MemProfile  string
 	ConfigFile  string
 	ProfPort    int
+	// AllowBadTime — разрешить данные с одинаковым временем у всех свечей
+	AllowBadTime bool
+	// ZeroTime — что делать со свечами без времени: drop (удалить) или interpolate (время по соседям)
+	ZeroTime string
+	// FailOnGaps — завершаться с ошибкой, если в ряду свечей есть пропуски, повторы или пустое время
+	FailOnGaps bool
+	// Oneline — вывод одной строки на стратегию для скриптов
+	Oneline bool
+	// PeriodsPerYear — число баров в году для аннуализации метрик (0 = определить автоматически)
+	PeriodsPerYear float64
+	// Market — пресет рынка для аннуализации: crypto, equity или пусто (календарное время)
+	Market string
+	// ExecutionDelay — задержка исполнения сделки в барах после сигнала
+	ExecutionDelay int
+	// Fill — цена исполнения: close, open, vwap или worst
+	Fill string
+	// FillModel — модель исполнения: close, next-open или worst (задает задержку и цену вместе)
+	FillModel string
+	// ReturnMode — учет доходности: compound (реинвестирование) или fixed (фиксированная сумма сделки)
+	ReturnMode string
+	// BreakerTrip — просадка (0..1), после которой не открываются новые позиции (0 = выключено)
+	BreakerTrip float64
+	// BreakerReset — доля отыгранной просадки (0..1) для возобновления входов
+	BreakerReset float64
+	// Timeframes — список таймфреймов для проверки стабильности стратегии (например, "30m,1h,4h")
+	Timeframes string
+	// SlippagePercent — проскальзывание в долях цены
+	SlippagePercent float64
+	// Commission — комиссия на каждую сторону: доля от суммы сделки (percent) или сумма за сделку (fixed)
+	Commission float64
+	// CommissionType — модель комиссии: percent, fixed или tiered
+	CommissionType string
+	// CommissionTiers — ступени комиссии для tiered: "сумма:ставка,..."
+	CommissionTiers string
+	// ForceClose — закрывать открытую позицию в конце периода
+	ForceClose bool
+	// Realistic — пресет реалистичных издержек и исполнения
+	Realistic bool
+	// MinTradeMove — минимальное движение цены от входа (в долях) для исполнения выхода (0 = выключено)
+	MinTradeMove float64
+	// MinHoldBars — минимальное удержание позиции в барах: более ранний выход по сигналу отклоняется (0 = выключено)
+	MinHoldBars int
+	// CooldownBars — пауза в барах после закрытия позиции, в течение которой вход отклоняется (0 = выключено)
+	CooldownBars int
+	// ProfitFloor — порог чистой доходности сделки для отчета о слабых сделках
+	ProfitFloor float64
+	// Explain — для одиночной стратегии вывести, какое условие блокировало сигналы
+	Explain bool
+	// PriceSource — цена свечи для ценового ряда стратегий: close, open, hl2, hlc3 или ohlc4
+	PriceSource string
+	// TrendGate — порог ADX, ниже которого трендовые стратегии не открывают позиции (0 = выключено)
+	TrendGate float64
+	// SaveConfigs — какие файлы конфигураций сохранять после оптимизации: combined, split, top:N[:метрика]
+	SaveConfigs string
+	// Objective — целевая функция оптимизации: profit или upi (Ulcer Performance Index)
+	Objective string
+	// RecencyHalfLife — период полураспада веса доходностей в целевой функции, в барах (0 = равные веса)
+	RecencyHalfLife int
+	// WFWindow — окно подбора walk-forward оптимизации в свечах (0 = подбор по всем свечам)
+	WFWindow int
+	// WFStep — проверочный отрезок и шаг сдвига окна walk-forward в свечах
+	WFStep int
+	// MaxConfigs — сколько конфигураций сетки GridSearchOptimizer проверять (0 = все)
+	MaxConfigs int
+	// GridSeed — зерно случайной подвыборки конфигураций при MaxConfigs
+	GridSeed int64
+	// Forward — проверка конфигураций из --config на новых данных: какие стратегии перестали зарабатывать
+	Forward bool
+	// Calibration — для одиночной V2-стратегии проверить уверенность предсказаний по истории
+	Calibration bool
+	// CalibHorizon — через сколько баров после предсказанного сигнала проверяется направление цены
+	CalibHorizon int
+	// CalibBins — число интервалов уверенности в таблице надежности
+	CalibBins int
+	// DumpSearch — CSV-файл для всех проверенных оптимизатором конфигураций (пусто = не сохранять)
+	DumpSearch string
+	// Bars — нарезка баров: time (исходные свечи), volume:N или dollar:N
+	Bars string
+	// KellyMultiplier — множитель дробного Келли для размера позиции (0 = выключено)
+	KellyMultiplier float64
+	// KellyCap — максимальная доля капитала на сделку при размере по Келли
+	KellyCap float64
+	// ScaleIn — максимальное число входов в позицию по повторным BUY (0 = докупка выключена)
+	ScaleIn int
+	// AllowShorts — открывать короткие позиции по SELL без открытой позиции
+	AllowShorts bool
+	// StopLoss и TakeProfit — уровни принудительного выхода от цены входа, в долях (0 = выключено)
+	StopLoss   float64
+	TakeProfit float64
+	// TrailingStop — стоп-лосс от лучшей цены с момента входа
+	TrailingStop bool
+	// BenchmarkAnchor — где покупает Buy & Hold: first (первая свеча) или trade (первый вход стратегии)
+	BenchmarkAnchor string
+	// RegimeFilter — режим волатильности (low, normal, high), в котором не открываются позиции (пусто = выключено)
+	RegimeFilter string
+	// RegimeWindow — окно доходностей для определения режима волатильности
+	RegimeWindow int
+	// Format — формат итоговой таблицы: table или csv (json — устаревший синоним Output=json:-)
+	Format string
+	// Output — куда выводить итоги при --format=table: combined, console, markdown, json (файл) или json:- (stdout)
+	Output string
+	// SelfCheck — прогнать все стратегии на синтетических свечах и выйти с ненулевым кодом при ошибках
+	SelfCheck bool
+	// Stats — вывести статистику загруженных свечей перед запуском стратегий
+	Stats bool
+	// StatsOnly — вывести статистику свечей и выйти
+	StatsOnly bool
+	// ReferenceTrades — число сделок, к которому приводится прибыль в таблице эффективности
+	ReferenceTrades int
+	// LogLevel — уровень логов стратегий: error, warn, info, debug (пусто = warn, а с Debug — debug)
+	LogLevel string
+	// CompareTo — JSON-отчет (--output=json или json:-) прошлого прогона, с которым сравниваются результаты
+	CompareTo string
+	// MaxRegression — допустимое падение доходности стратегии относительно CompareTo (в долях)
+	MaxRegression float64
+	// CVFolds — число фолдов k-fold кросс-валидации оптимизации (0 = выключено)
+	CVFolds int
+	// MemStats — замерять память, выделенную каждой стратегией (прогоны сериализуются)
+	MemStats bool
+	// SaveEquity — сохранять кривую капитала топ-N стратегий (--save-equity, N задает --save_signals) для графиков роста
+	SaveEquity bool
+	// OutDir — каталог для отчетов, оптимизированных конфигураций и сохраненных сигналов (создается при необходимости)
+	OutDir string
+	// Workers — сколько стратегий выполняется одновременно при запуске всех стратегий (0 = число ядер)
+	Workers int
+	// StrategyTimeout — предельное время оптимизации и прогона одной стратегии (0 = без ограничения)
+	StrategyTimeout time.Duration
+	// InitialCapital — начальный капитал счета для всех стратегий и Buy & Hold
+	InitialCapital float64
+	// Session — торговая сессия ЧЧ:ММ-ЧЧ:ММ: сигналы вне нее заменяются на HOLD (пусто = без ограничения)
+	Session string
+	// SessionTZ — часовой пояс времени сессии, например Europe/Moscow (пусто = пояс времени свечей)
+	SessionTZ string
+	// Holdout — доля последних свечей, которую оптимизатор не видит; на ней отдельно считается прибыль (0 = выключено)
+	Holdout float64
+	// MonteCarlo — число перевыборок сделок для доверительных полос одиночной стратегии (0 = выключено)
+	MonteCarlo int
+	// TrackExcursions — считать MFE/MAE закрытых сделок для отчета
+	TrackExcursions bool
+	// PositionSizing — расчет размера позиции: fractional, whole (целые бумаги) или fixed-fraction
+	PositionSizing string
+	// RiskFraction — доля капитала счета на вход при PositionSizing = fixed-fraction
+	RiskFraction float64
+	// NonFinitePolicy — что делать с NaN/Inf результатами: na (показать N/A) или fail (код выхода 1)
+	NonFinitePolicy string
 }
@@ -2,66 +2,668 @@
 package internal
 
 import (
+	"fmt"
 	"log"
+	"math"
 )
 
 type BacktestResult struct {
 	TotalProfit     float64
+	CAGR            float64 // среднегодовая доходность по времени первой и последней свечи (см. CAGR)
 	TradeCount      int
 	FinalPortfolio  float64
 	PortfolioValues []float64
+	SharpeRatio     float64 // годовой Sharpe по побаровым доходностям портфеля
+	SortinoRatio    float64 // годовой Sortino: как Sharpe, но в знаменателе только отрицательные доходности
+	MaxDrawdown     float64 // максимальная просадка портфеля от пика до минимума (0..1)
+	TimeInMarket    float64 // доля баров с открытой позицией (0..1)
+	BreakerTrips    int     // сколько раз срабатывал прерыватель по просадке
+	// SuppressedExits — сколько SELL-сигналов пропущено из-за MinTradeMove
+	SuppressedExits int
+	// ForcedExits — сколько позиций закрыто стоп-лоссом или тейк-профитом (BacktestOptions.Exits)
+	ForcedExits int
+	// BelowFloorTrades — сколько закрытых сделок принесли чистую доходность ниже ProfitFloor
+	BelowFloorTrades int
+	// Trades — журнал закрытых сделок
+	Trades []Trade
+	// WinningTrades и LosingTrades — закрытые сделки с положительной и отрицательной чистой доходностью;
+	// позиция, открытая на конец данных, не считается (сделки в ноль не входят ни в одну группу)
+	WinningTrades int
+	LosingTrades  int
+	// AvgWin и AvgLoss — средняя чистая доходность выигрышной и проигрышной сделки (AvgLoss отрицательная)
+	AvgWin  float64
+	AvgLoss float64
+	// ProfitFactor — валовая прибыль / валовый убыток закрытых сделок (+Inf, если убыточных сделок нет)
+	ProfitFactor float64
+	// Expectancy — средний чистый результат закрытой сделки в деньгах (с учетом издержек)
+	Expectancy float64
+	// AvgMFE и AvgMAE — среднее лучшее и худшее движение цены за время закрытых сделок (AvgMAE отрицательное);
+	// считаются только с BacktestOptions.TrackExcursions, позиция, открытая на конец данных, не учитывается
+	AvgMFE float64
+	AvgMAE float64
+	// FirstEntryIndex — бар исполнения первого входа (-1, если стратегия ни разу не вошла в позицию)
+	FirstEntryIndex int
+	// KellyFraction — доля капитала на сделку, подобранная по критерию Келли (0, если Келли выключен)
+	KellyFraction float64
+	// NonFinite — прибыль или портфель получились NaN/Inf (нулевые или нечисловые цены) и заменены
+	// на NonFiniteProfit; такой прогон считается проваленным, а не лучшим или худшим результатом
+	NonFinite bool
 }
 
+// NonFiniteProfit — значение TotalProfit для прогона с нечисловым результатом: потеря всего капитала
+const NonFiniteProfit = -1.0
+
+// DefaultInitialCapital — начальный капитал счета, если BacktestOptions.InitialCapital не задан
+const DefaultInitialCapital = 10000.0
+
+// BacktestOptions — параметры движка бэктеста
+type BacktestOptions struct {
+	// Slippage — проскальзывание в единицах цены на каждую сторону сделки
+	Slippage float64
+	// InitialCapital — начальный капитал счета (0 = DefaultInitialCapital)
+	InitialCapital float64
+	// ConflictPolicy — разрешение противоречивых сигналов в BacktestSignalSets (по умолчанию ConflictPreferHold)
+	ConflictPolicy ConflictPolicy
+	// PeriodsPerYear — явное число баров в году для аннуализации метрик (0 = определить по Market)
+	PeriodsPerYear float64
+	// Market — тип рынка для определения числа баров в году (по умолчанию MarketAuto — календарное время)
+	Market Market
+	// ExecutionDelay — через сколько баров после сигнала исполняется сделка (0 = на баре сигнала)
+	ExecutionDelay int
+	// Fill — цена исполнения на баре исполнения (по умолчанию FillClose)
+	Fill FillPrice
+	// BreakerTrip — просадка капитала от пика (0..1), после которой новые позиции не открываются (0 = выключено)
+	BreakerTrip float64
+	// BreakerReset — доля отыгранной просадки (0..1), после которой входы снова разрешены
+	BreakerReset float64
+	// SlippagePercent — проскальзывание в долях цены (0.0005 = 0.05%), добавляется к абсолютному Slippage
+	SlippagePercent float64
+	// Commission — комиссия брокера в долях от суммы сделки, взимается при покупке и при продаже
+	Commission float64
+	// CommissionModel — комиссия в деньгах за каждую сторону сделки, списывается со счета
+	// в дополнение к Commission и проскальзыванию (nil = без комиссии)
+	CommissionModel CommissionModel
+	// ForceClose — закрыть открытую позицию по последней цене закрытия (с издержками) и засчитать сделку
+	ForceClose bool
+	// MinTradeMove — минимальное движение цены от входа (в долях), при котором исполняется SELL;
+	// при меньшем движении позиция удерживается, чтобы издержки не съедали «шумовые» сделки (0 = выключено)
+	MinTradeMove float64
+	// MinHoldBars — минимальное число баров удержания позиции: выход по сигналу раньше отклоняется (HOLD).
+	// Стоп-лосс и тейк-профит не ограничиваются (0 = выключено)
+	MinHoldBars int
+	// CooldownBars — пауза в барах после закрытия позиции, в течение которой новый вход отклоняется (0 = выключено)
+	CooldownBars int
+	// TrackExcursions — записывать в каждую сделку MFE/MAE по High/Low баров, пока позиция открыта
+	TrackExcursions bool
+	// ProfitFloor — порог чистой доходности сделки для отчета BelowFloorTrades (0 = считать убыточные сделки)
+	ProfitFloor float64
+	// PositionFraction — доля свободного капитала, вкладываемая в сделку (0 или 1 = весь капитал)
+	PositionFraction float64
+	// PositionSizing — расчет размера позиции: дробное количество (по умолчанию), целые бумаги или доля капитала
+	PositionSizing PositionSizing
+	// RiskFraction — доля капитала счета на один вход при SizingFixedFraction (0.25 = 25%)
+	RiskFraction float64
+	// KellyMultiplier — множитель дробного Келли (0.5 = половина Келли); размер позиции подбирается
+	// по журналу сделок первого прогона и применяется во втором (0 = выключено)
+	KellyMultiplier float64
+	// KellyCap — верхняя граница доли капитала на сделку при размере по Келли
+	KellyCap float64
+	// ScaleIn — максимальное число входов в одну позицию: повторный BUY докупает по новой цене,
+	// капитал делится на равные части, вход считается по средневзвешенной цене, SELL закрывает все (0 или 1 = выключено)
+	ScaleIn int
+	// RegimeFilter — режим волатильности (RegimeLow/RegimeNormal/RegimeHigh), в котором BUY игнорируется;
+	// выходы не блокируются, чтобы позиция не застревала (пусто = выключено)
+	RegimeFilter string
+	// RegimeWindow — окно доходностей для определения режима (0 = DefaultRegimeWindow)
+	RegimeWindow int
+	// ReturnMode — реинвестирование прибыли (ReturnCompound, по умолчанию) или фиксированная сумма сделки (ReturnFixed)
+	ReturnMode ReturnMode
+	// AllowShorts — SELL без открытой позиции открывает короткую позицию, следующий BUY ее закрывает.
+	// Разворота нет: SELL в длинной позиции только закрывает ее, BUY в короткой — только покрывает.
+	// Докупка (ScaleIn) действует только для длинных позиций (false = только длинные позиции)
+	AllowShorts bool
+	// Exits — стоп-лосс и тейк-профит, закрывающие позицию между сигналами стратегии (нулевые = выключено)
+	Exits ExitRules
+}
+
+// defaultBacktestOptions — параметры, с которыми работает Backtest (задаются флагами командной строки)
+var defaultBacktestOptions BacktestOptions
+
+// SetDefaultBacktestOptions — задает параметры движка для всех последующих вызовов Backtest.
+// Вызывается один раз при старте, до запуска стратегий; Slippage в Backtest передается явно.
+func SetDefaultBacktestOptions(opts BacktestOptions) {
+	defaultBacktestOptions = opts
+}
+
+// DefaultBacktestOptions — текущие параметры движка по умолчанию
+func DefaultBacktestOptions() BacktestOptions {
+	return defaultBacktestOptions
+}
+
+// Capital — начальный капитал прогона с учетом значения по умолчанию
+func (o BacktestOptions) Capital() float64 {
+	if o.InitialCapital > 0 {
+		return o.InitialCapital
+	}
+	return DefaultInitialCapital
+}
+
+// Backtest — бэктест с параметрами движка по умолчанию и абсолютным проскальзыванием slippage
+// в единицах цены (SlippageAbsolute); для доли цены — BacktestWithSlippage с SlippagePercent
 func Backtest(candles []Candle, signals []SignalType, slippage float64) BacktestResult {
+	return BacktestWithSlippage(candles, signals, SlippageAbsolute(slippage))
+}
 
+// ValidateSignals — проверяет, что стратегия вернула ровно по одному сигналу на свечу.
+// Backtest на массиве другой длины завершает программу, поэтому runner и оптимизаторы проверяют его заранее
+func ValidateSignals(candles []Candle, signals []SignalType) error {
+	if len(signals) != len(candles) {
+		return fmt.Errorf("стратегия вернула %d сигналов на %d свечей", len(signals), len(candles))
+	}
+	return nil
+}
+
+// BacktestWithOptions — бэктест одного массива сигналов с явными параметрами движка
+func BacktestWithOptions(candles []Candle, signals []SignalType, opts BacktestOptions) BacktestResult {
 	if len(candles) != len(signals) {
 		log.Fatal("Mismatch between candles and signals length")
 	}
 
-	cashCurrent, initCash := 10000.0, 10000.0
-	holdings := 0.0
-	portfolioValues := []float64{cashCurrent}
-	tradeCount := 0
-	firstTradeExecuted := false // Флаг для отслеживания первой сделки
+	return runBacktest(candles, opts, func(i int, side PositionSide) SignalType {
+		return signals[i]
+	})
+}
+
+// BacktestSignalSets — бэктест нескольких массивов сигналов одновременно.
+// Если на одном баре одни массивы дают BUY, а другие SELL, сигнал выбирается по opts.ConflictPolicy
+// с учётом текущей позиции движка.
+func BacktestSignalSets(candles []Candle, signalSets [][]SignalType, opts BacktestOptions) BacktestResult {
+	for _, signals := range signalSets {
+		if len(candles) != len(signals) {
+			log.Fatal("Mismatch between candles and signals length")
+		}
+	}
+
+	return runBacktest(candles, opts, func(i int, side PositionSide) SignalType {
+		buyVotes, sellVotes := 0, 0
+		for _, signals := range signalSets {
+			switch signals[i] {
+			case BUY:
+				buyVotes++
+			case SELL:
+				sellVotes++
+			}
+		}
+		return opts.ConflictPolicy.Resolve(buyVotes, sellVotes, side)
+	})
+}
+
+// runBacktest — прогон по всем свечам; signalAt возвращает сигнал бара i с учётом стороны текущей позиции
+func runBacktest(candles []Candle, opts BacktestOptions, signalAt func(i int, side PositionSide) SignalType) BacktestResult {
+	if opts.KellyMultiplier > 0 {
+		return runKellyBacktest(candles, opts, signalAt)
+	}
+
+	b := newBacktester(opts, signalAt)
+	b.candles = candles
+	for i := range candles {
+		b.step(i)
+	}
+	return b.finish()
+}
+
+// Backtester — бэктест по одной свече за раз: позиция и портфель обновляются на каждом Step,
+// поэтому один и тот же учет сделок работает и на истории (Backtest), и на живых данных.
+// Размер позиции по Келли (KellyMultiplier) подбирается по сделкам всего прогона и в потоке не применяется
+type Backtester struct {
+	opts     BacktestOptions
+	signalAt func(i int, side PositionSide) SignalType
+	candles  []Candle
+	signals  []SignalType // сигналы, переданные в Step (в пакетном прогоне не используются)
+	// streaming — свечи приходят через Step, и срез candles растет с каждым баром
+	streaming bool
+
+	initCash, cash float64
+	holdings       float64
+	// portfolioValues — стоимость портфеля: начальный капитал и значение на закрытии каждого бара
+	portfolioValues []float64
+	tradeCount      int
+	barsInMarket    int
+	entryIndex      int
+	entryFill       float64 // цена исполнения входа
+	entryPrice      float64 // цена входа с издержками
+	entryCost       float64 // стоимость входа
+	entries         int     // число входов в текущую позицию (больше 1 только при ScaleIn)
+	bestFill        float64 // лучшая цена с момента входа для трейлинг-стопа
+	excursion       excursionTracker
+	trades          []Trade
+	suppressedExits int
+	belowFloor      int
+	forcedExits     int
+	firstTrade      bool // Флаг для отслеживания первой сделки
+	firstEntryIndex int
+	lastExitIndex   int // бар последнего закрытия позиции (-1 — позиций еще не было)
+
+	breaker       *circuitBreaker
+	regime        *regimeTracker
+	regimeBlocked []bool // запрет входа по режиму волатильности на каждом баре (при включенном RegimeFilter)
+}
+
+// NewBacktester — потоковый бэктест с параметрами движка opts; свечи и сигналы подаются через Step
+func NewBacktester(opts BacktestOptions) *Backtester {
+	opts.KellyMultiplier = 0
+	b := newBacktester(opts, nil)
+	b.streaming = true
+	b.signalAt = func(i int, side PositionSide) SignalType {
+		return b.signals[i]
+	}
+	return b
+}
+
+func newBacktester(opts BacktestOptions, signalAt func(i int, side PositionSide) SignalType) *Backtester {
+	b := &Backtester{
+		opts:            opts,
+		signalAt:        signalAt,
+		initCash:        opts.Capital(),
+		cash:            opts.Capital(),
+		firstEntryIndex: -1,
+		lastExitIndex:   -1,
+		regime:          opts.regimeTracker(),
+	}
+	b.portfolioValues = []float64{b.cash}
+	if opts.BreakerTrip > 0 {
+		b.breaker = newCircuitBreaker(opts.BreakerTrip, opts.BreakerReset, b.initCash)
+		b.breaker.shorts = opts.AllowShorts
+	}
+	return b
+}
+
+// Step — обрабатывает очередную свечу и сигнал стратегии на ней.
+// При ExecutionDelay сигнал исполняется через соответствующее число вызовов Step
+func (b *Backtester) Step(candle Candle, signal SignalType) {
+	b.candles = append(b.candles, candle)
+	b.signals = append(b.signals, signal)
+	b.step(len(b.candles) - 1)
+}
+
+// Result — результат по всем обработанным свечам; состояние не меняется, Step можно продолжать.
+// ForceClose применяется к копии: открытая позиция закрывается только в отчете
+func (b *Backtester) Result() BacktestResult {
+	snapshot := *b
+	snapshot.trades = append([]Trade(nil), b.trades...)
+	snapshot.portfolioValues = append([]float64(nil), b.portfolioValues...)
+	return snapshot.finish()
+}
+
+// closeLong — продажа всей длинной позиции по цене исполнения fill
+func (b *Backtester) closeLong(i int, fill float64) {
+	proceeds := b.holdings * b.opts.sellPrice(fill)
+	proceeds -= b.opts.fee(proceeds)
+	b.cash += proceeds
+	trade := newTrade(b.entryIndex, i, b.entryPrice, proceeds/b.holdings, b.entryCost, proceeds)
+	b.recordExcursions(&trade, fill)
+	b.trades = append(b.trades, trade)
+	b.holdings = 0
+	b.lastExitIndex = i
+	if proceeds/b.entryCost-1 < b.opts.ProfitFloor {
+		b.belowFloor++
+	}
+	b.tradeCount++ // Считаем полную сделку (пару BUY+SELL) только при закрытии
+}
+
+// coverShort — покрытие всей короткой позиции по цене исполнения fill
+func (b *Backtester) coverShort(i int, fill float64) {
+	quantity := -b.holdings
+	coverCost := quantity * b.opts.buyPrice(fill)
+	coverCost += b.opts.fee(coverCost)
+	b.cash -= coverCost
+	b.holdings = 0
+	b.lastExitIndex = i
+	// Доходность шорта относительно суммы входа: выручка при открытии минус стоимость покрытия
+	trade := newTrade(b.entryIndex, i, b.entryPrice, coverCost/quantity, b.entryCost, b.entryCost+quantity*b.entryPrice-coverCost)
+	trade.Short = true
+	b.recordExcursions(&trade, fill)
+	b.trades = append(b.trades, trade)
+	if trade.Return < b.opts.ProfitFloor {
+		b.belowFloor++
+	}
+	b.tradeCount++
+}
+
+// recordExcursions — MFE/MAE закрываемой по цене fill сделки (при TrackExcursions)
+func (b *Backtester) recordExcursions(trade *Trade, fill float64) {
+	if !b.opts.TrackExcursions {
+		return
+	}
+	b.excursion.add(fill)
+	trade.MFE, trade.MAE = b.excursion.excursions(b.entryFill, trade.Short)
+}
+
+// step — обработка бара i из b.candles
+func (b *Backtester) step(i int) {
+	opts := &b.opts
+	price := b.candles[i].Close.ToFloat64()
+	buyFill, sellFill := opts.Fill.BuyPriceAt(b.candles[i]), opts.Fill.SellPriceAt(b.candles[i])
+	if b.regime != nil {
+		b.regimeBlocked = append(b.regimeBlocked, b.regime.next(price) == opts.RegimeFilter)
+	}
+
+	// Стоп-лосс и тейк-профит проверяются до сигнала бара: уровень мог быть пройден внутри бара.
+	// Бар входа не проверяется — вход исполнен по его цене, и движение до нее позиции не касается
+	if b.holdings != 0 && i > b.entryIndex && opts.Exits.enabled() {
+		if fill, ok := opts.Exits.exitFill(b.candles[i], b.entryFill, b.bestFill, b.holdings < 0); ok {
+			if b.holdings > 0 {
+				b.closeLong(i, fill)
+			} else {
+				b.coverShort(i, fill)
+			}
+			b.forcedExits++
+		} else {
+			b.bestFill = bestPrice(b.candles[i], b.bestFill, b.holdings < 0)
+		}
+	}
+
+	// Сигнал бара i-ExecutionDelay исполняется на баре i
+	signal := HOLD
+	if i >= opts.ExecutionDelay {
+		signal = b.signalAt(i-opts.ExecutionDelay, positionSide(b.holdings))
+	}
+	// Открывает ли сигнал позицию (или докупает), а не закрывает открытую
+	entering := (signal == BUY && b.holdings >= 0) || (signal == SELL && b.holdings == 0 && opts.AllowShorts)
+
+	if b.breaker != nil {
+		shadowSignal := signal
+		if side := positionSide(b.breaker.holdings); i >= opts.ExecutionDelay && side != positionSide(b.holdings) {
+			shadowSignal = b.signalAt(i-opts.ExecutionDelay, side)
+		}
+		b.breaker.update(shadowSignal, opts.buyPrice(buyFill), opts.sellPrice(sellFill), price)
+
+		// Прерыватель сработал: закрывать позиции можно, открывать новые — нет
+		if b.breaker.halted && entering {
+			signal = HOLD
+		}
+	}
+
+	// Вход запрещен режимом волатильности на баре сигнала
+	if entering && b.regime != nil && b.regimeBlocked[i-opts.ExecutionDelay] {
+		signal = HOLD
+	}
 
-	for i, signal := range signals {
-		price := candles[i].Close.ToFloat64()
+	if b.violatesHoldRules(i, signal, entering) {
+		signal = HOLD
+	}
 
-		switch signal {
-		case BUY:
-			if holdings == 0 && cashCurrent > 0 {
-				effectivePrice := price + slippage
-				holdings = cashCurrent / effectivePrice
-				cashCurrent = 0
-				//	fmt.Printf("📈 BUY at %.2f (effective %.2f, candle %d, %s)\n", price, effectivePrice, i, candles[i].Time)
-				firstTradeExecuted = true
+	switch signal {
+	case BUY:
+		if b.holdings < 0 {
+			// Покрытие короткой позиции
+			if opts.MinTradeMove > 0 && math.Abs(buyFill/b.entryFill-1) < opts.MinTradeMove {
+				b.suppressedExits++
+				break
 			}
-		case SELL:
-			// КРИТИЧНО: Первая сделка должна быть BUY, игнорируем SELL до первого BUY
-			if !firstTradeExecuted {
-				continue
+			b.coverShort(i, buyFill)
+			break
+		}
+
+		// В режиме fixed сумма сделки не зависит от результата: после убытков свободные деньги могут уйти в минус
+		canEnter := b.cash > 0 || opts.ReturnMode == ReturnFixed
+		if canEnter && (b.holdings == 0 || opts.canScaleIn(b.entries)) {
+			if b.holdings == 0 {
+				b.entryIndex, b.entryFill, b.entryCost, b.entries, b.bestFill = i, 0, 0, 0, 0
 			}
-			if holdings > 0 {
-				effectivePrice := price - slippage
-				cashCurrent = holdings * effectivePrice
-				holdings = 0
-				//	fmt.Printf("📉 SELL at %.2f (effective %.2f, candle %d, %s)\n", price, effectivePrice, i, candles[i].Time)
-				tradeCount++ // Считаем полную сделку (пару BUY+SELL) только при SELL
+			effectivePrice := opts.buyPrice(buyFill)
+			stake := opts.entryBudget(b.cash, b.initCash, b.entryCost, b.cash+b.holdings*buyFill, b.entries)
+			quantity, stake := opts.longEntry(stake, effectivePrice)
+			if quantity <= 0 {
+				break // комиссия съедает всю сумму входа или ее не хватает на одну бумагу
+			}
+			if b.firstEntryIndex < 0 {
+				b.firstEntryIndex = i
+			}
+			// При докупке цены входа усредняются по количеству; для одного входа совпадают с ценой сделки
+			b.entryFill = (b.entryFill*b.holdings + buyFill*quantity) / (b.holdings + quantity)
+			b.holdings += quantity
+			b.entryCost += stake
+			b.entryPrice = b.entryCost / b.holdings
+			if b.entries == 0 {
+				b.excursion.reset(buyFill)
+			} else {
+				b.excursion.add(buyFill)
+			}
+			b.entries++
+			b.bestFill = math.Max(b.bestFill, buyFill)
+			b.cash -= stake
+			//	fmt.Printf("📈 BUY at %.2f (effective %.2f, candle %d, %s)\n", price, effectivePrice, i, candles[i].Time)
+			b.firstTrade = true
+		}
+	case SELL:
+		if b.holdings == 0 && opts.AllowShorts {
+			// Открытие короткой позиции: выручка от продажи зачисляется, позиция учитывается отрицательным количеством
+			if b.cash > 0 || opts.ReturnMode == ReturnFixed {
+				stake := opts.entryBudget(b.cash, b.initCash, 0, b.cash, 0)
+				quantity, stake := opts.shortEntry(stake, opts.sellPrice(sellFill))
+				if quantity <= 0 {
+					break // бюджета не хватает на одну бумагу
+				}
+				if b.firstEntryIndex < 0 {
+					b.firstEntryIndex = i
+				}
+				// Цена входа шорта — выручка за единицу за вычетом комиссии
+				proceeds := stake - opts.fee(stake)
+				b.entryIndex, b.entryFill, b.entryPrice, b.entryCost, b.entries = i, sellFill, proceeds/quantity, stake, 1
+				b.bestFill = sellFill
+				b.excursion.reset(sellFill)
+				b.holdings = -quantity
+				b.cash += proceeds
+				b.firstTrade = true
 			}
+			break
 		}
+		// КРИТИЧНО: Первая сделка должна быть BUY, игнорируем SELL до первого BUY
+		// (бар учитывается как HOLD: стоимость портфеля — свободные деньги, во время в рынке не входит)
+		if !b.firstTrade {
+			break
+		}
+		if b.holdings > 0 && opts.MinTradeMove > 0 && math.Abs(sellFill/b.entryFill-1) < opts.MinTradeMove {
+			b.suppressedExits++
+		} else if b.holdings > 0 {
+			b.closeLong(i, sellFill)
+			//	fmt.Printf("📉 SELL at %.2f (effective %.2f, candle %d, %s)\n", price, effectivePrice, i, candles[i].Time)
+		}
+	}
+
+	if b.holdings != 0 {
+		b.barsInMarket++
+		if opts.TrackExcursions {
+			if i == b.entryIndex {
+				b.excursion.add(price)
+			} else {
+				b.excursion.addBar(b.candles[i])
+			}
+		}
+	}
+
+	portfolioValue := b.cash + b.holdings*price
+	b.portfolioValues = append(b.portfolioValues, portfolioValue)
+}
+
+// violatesHoldRules — нарушает ли сигнал бара i минимальное удержание (выход раньше MinHoldBars баров
+// после входа) или паузу после выхода (новая позиция раньше CooldownBars баров после закрытия)
+func (b *Backtester) violatesHoldRules(i int, signal SignalType, entering bool) bool {
+	if signal == HOLD {
+		return false
+	}
+	if entering {
+		return b.holdings == 0 && b.lastExitIndex >= 0 && i-b.lastExitIndex < b.opts.CooldownBars
+	}
+	return b.holdings != 0 && i-b.entryIndex < b.opts.MinHoldBars
+}
+
+// periodsPerYear — число баров в году для аннуализации метрик
+func (b *Backtester) periodsPerYear() float64 {
+	if b.streaming && b.opts.PeriodsPerYear <= 0 {
+		// Срез свечей растет с каждым Step: кэш по адресу и длине среза копил бы запись на каждый вызов Result
+		return inferPeriodsPerYearForMarket(b.candles, b.opts.Market)
+	}
+	return b.opts.PeriodsPerYearFor(b.candles)
+}
+
+// finish — итоги прогона; закрывает позицию при ForceClose, поэтому вызывается один раз (Result работает на копии)
+func (b *Backtester) finish() BacktestResult {
+	opts := &b.opts
+	finalPrice := 0.0
+	if len(b.candles) > 0 {
+		finalPrice = b.candles[len(b.candles)-1].Close.ToFloat64()
+	}
+
+	// Принудительное закрытие позиции в конце периода: прибыль учитывает издержки выхода
+	if opts.ForceClose && b.holdings > 0 {
+		b.closeLong(len(b.candles)-1, finalPrice)
+		b.portfolioValues[len(b.portfolioValues)-1] = b.cash
+	}
+	if opts.ForceClose && b.holdings < 0 {
+		b.coverShort(len(b.candles)-1, finalPrice)
+		b.portfolioValues[len(b.portfolioValues)-1] = b.cash
+	}
+
+	finalPortfolio := b.cash + b.holdings*finalPrice
+	profit := (finalPortfolio - b.initCash) / b.initCash
+
+	timeInMarket := 0.0
+	if len(b.candles) > 0 {
+		timeInMarket = float64(b.barsInMarket) / float64(len(b.candles))
+	}
 
-		portfolioValue := cashCurrent + holdings*price
-		portfolioValues = append(portfolioValues, portfolioValue)
+	breakerTrips := 0
+	if b.breaker != nil {
+		breakerTrips = b.breaker.trips
 	}
 
-	finalPrice := candles[len(candles)-1].Close.ToFloat64()
-	finalPortfolio := cashCurrent + holdings*finalPrice
-	profit := (finalPortfolio - initCash) / initCash
+	cagr := profit
+	if len(b.candles) > 0 {
+		cagr = CAGR(profit, b.candles[0].ParsedTime, b.candles[len(b.candles)-1].ParsedTime)
+	}
+
+	periodsPerYear := b.periodsPerYear()
+	result := BacktestResult{
+		TotalProfit:      profit,
+		CAGR:             cagr,
+		TradeCount:       b.tradeCount,
+		FinalPortfolio:   finalPortfolio,
+		PortfolioValues:  b.portfolioValues,
+		SharpeRatio:      calculateSharpeRatio(b.portfolioValues, periodsPerYear),
+		SortinoRatio:     calculateSortinoRatio(b.portfolioValues, periodsPerYear),
+		MaxDrawdown:      calculateMaxDrawdown(b.portfolioValues),
+		TimeInMarket:     timeInMarket,
+		BreakerTrips:     breakerTrips,
+		SuppressedExits:  b.suppressedExits,
+		ForcedExits:      b.forcedExits,
+		BelowFloorTrades: b.belowFloor,
+		Trades:           b.trades,
+		FirstEntryIndex:  b.firstEntryIndex,
+	}
+	result.WinningTrades, result.LosingTrades, result.AvgWin, result.AvgLoss = tradeOutcomes(b.trades)
+	result.ProfitFactor, result.Expectancy = profitFactor(b.trades)
+	if opts.TrackExcursions {
+		result.AvgMFE, result.AvgMAE = averageExcursions(b.trades)
+	}
+	sanitizeResult(&result)
+	return result
+}
 
-	return BacktestResult{
-		TotalProfit:     profit,
-		TradeCount:      tradeCount,
-		FinalPortfolio:  finalPortfolio,
-		PortfolioValues: portfolioValues,
+// sanitizeResult — заменяет нечисловые итоги прогона на NonFiniteProfit и помечает результат
+func sanitizeResult(result *BacktestResult) {
+	if isFinite(result.TotalProfit) && isFinite(result.FinalPortfolio) {
+		return
+	}
+	result.NonFinite = true
+	result.TotalProfit = NonFiniteProfit
+	result.CAGR = NonFiniteProfit
+	result.FinalPortfolio = 0
+	result.SharpeRatio = 0
+	result.SortinoRatio = 0
+	result.MaxDrawdown = 1
+	result.ProfitFactor = 0
+	result.Expectancy = 0
+}
+
+func isFinite(v float64) bool {
+	return !math.IsNaN(v) && !math.IsInf(v, 0)
+}
+
+// calculateSharpeRatio — годовой коэффициент Шарпа по кривой капитала (безрисковая ставка = 0)
+func calculateSharpeRatio(portfolioValues []float64, periodsPerYear float64) float64 {
+	returns := curveReturns(portfolioValues)
+	if returns == nil {
+		return 0
+	}
+
+	mean, stdDev := calculateMeanStd(returns)
+	if stdDev == 0 {
+		return 0
+	}
+	sharpe := mean / stdDev * math.Sqrt(periodsPerYear)
+	if !isFinite(sharpe) {
+		return 0
+	}
+	return sharpe
+}
+
+// calculateSortinoRatio — годовой коэффициент Сортино: средняя доходность к нисходящему отклонению
+// от нуля (downsideDeviationOfReturns). Без убыточных баров — 0, как и Sharpe без разброса
+func calculateSortinoRatio(portfolioValues []float64, periodsPerYear float64) float64 {
+	returns := curveReturns(portfolioValues)
+	if returns == nil {
+		return 0
+	}
+
+	downside := downsideDeviationOfReturns(returns, 0)
+	if downside == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, r := range returns {
+		sum += r
+	}
+	mean := sum / float64(len(returns))
+	sortino := mean / downside * math.Sqrt(periodsPerYear)
+	if !isFinite(sortino) {
+		return 0
+	}
+	return sortino
+}
+
+// calculateMaxDrawdown — наибольшее падение портфеля от предшествующего пика, в долях пика
+func calculateMaxDrawdown(portfolioValues []float64) float64 {
+	peak, maxDrawdown := 0.0, 0.0
+	for _, value := range portfolioValues {
+		if value > peak {
+			peak = value
+		}
+		if peak > 0 {
+			if drawdown := (peak - value) / peak; drawdown > maxDrawdown {
+				maxDrawdown = drawdown
+			}
+		}
+	}
+	if !isFinite(maxDrawdown) {
+		return 0
+	}
+	return maxDrawdown
+}
+
+// curveReturns — побаровые доходности кривой капитала (nil, если баров слишком мало для оценки разброса)
+func curveReturns(portfolioValues []float64) []float64 {
+	if len(portfolioValues) < 3 {
+		return nil
+	}
+
+	returns := make([]float64, 0, len(portfolioValues)-1)
+	for i := 1; i < len(portfolioValues); i++ {
+		if portfolioValues[i-1] == 0 {
+			continue
+		}
+		returns = append(returns, portfolioValues[i]/portfolioValues[i-1]-1)
 	}
+	return returns
 }
@@ -1,7 +1,10 @@
 package internal
 
 import (
+	"math"
+	"reflect"
 	"testing"
+	"time"
 )
 
 func TestBacktest_FirstTradeMustBeBuy(t *testing.T) {
@@ -84,3 +87,896 @@ func TestBacktest_SignalAlternation(t *testing.T) {
 		t.Errorf("Expected 1 trade (second SELL should be ignored), got %d", result2.TradeCount)
 	}
 }
+
+func TestBacktest_TimeInMarket(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(105.0)},
+		{Close: Price(110.0)},
+		{Close: Price(108.0)},
+	}
+
+	// Позиция открыта на барах 0 и 1, закрыта на баре 2
+	signals := []SignalType{BUY, HOLD, SELL, HOLD}
+	result := Backtest(candles, signals, 0.0)
+
+	if result.TimeInMarket != 0.5 {
+		t.Errorf("Expected time in market 0.5, got %.2f", result.TimeInMarket)
+	}
+
+	// Без сделок позиция не открывается
+	result2 := Backtest(candles, []SignalType{HOLD, HOLD, HOLD, HOLD}, 0.0)
+	if result2.TimeInMarket != 0 {
+		t.Errorf("Expected time in market 0, got %.2f", result2.TimeInMarket)
+	}
+}
+
+func TestBacktestSignalSets_ConflictPolicies(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(110.0)},
+		{Close: Price(120.0)},
+		{Close: Price(130.0)},
+	}
+
+	// Бар 0: все согласны на BUY; бар 2: два SELL против одного BUY
+	setA := []SignalType{BUY, HOLD, SELL, HOLD}
+	setB := []SignalType{BUY, HOLD, SELL, HOLD}
+	setC := []SignalType{BUY, HOLD, BUY, HOLD}
+	sets := [][]SignalType{setA, setB, setC}
+
+	// prefer-hold: противоречие на баре 2 игнорируется, позиция остаётся открытой
+	hold := BacktestSignalSets(candles, sets, BacktestOptions{ConflictPolicy: ConflictPreferHold})
+	if hold.TradeCount != 0 {
+		t.Errorf("prefer-hold: expected 0 closed trades, got %d", hold.TradeCount)
+	}
+	if hold.TimeInMarket != 1 {
+		t.Errorf("prefer-hold: expected position to stay open, time in market %.2f", hold.TimeInMarket)
+	}
+
+	// prefer-close: позиция открыта, поэтому побеждает закрывающий SELL
+	closePolicy := BacktestSignalSets(candles, sets, BacktestOptions{ConflictPolicy: ConflictPreferClose})
+	if closePolicy.TradeCount != 1 {
+		t.Errorf("prefer-close: expected 1 closed trade, got %d", closePolicy.TradeCount)
+	}
+
+	// prefer-signal-strength: 2 SELL против 1 BUY
+	strength := BacktestSignalSets(candles, sets, BacktestOptions{ConflictPolicy: ConflictPreferStrength})
+	if strength.TradeCount != 1 {
+		t.Errorf("prefer-signal-strength: expected 1 closed trade, got %d", strength.TradeCount)
+	}
+}
+
+func TestBacktestSignalSets_PreferCloseCoversShort(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(90.0)},
+		{Close: Price(80.0)},
+		{Close: Price(85.0)},
+	}
+
+	// Бар 0: оба за SELL — открывается шорт; бар 2: BUY против SELL при открытом шорте
+	sets := [][]SignalType{
+		{SELL, HOLD, BUY, HOLD},
+		{SELL, HOLD, SELL, HOLD},
+	}
+
+	result := BacktestSignalSets(candles, sets, BacktestOptions{ConflictPolicy: ConflictPreferClose, AllowShorts: true})
+	if result.TradeCount != 1 || len(result.Trades) != 1 || !result.Trades[0].Short {
+		t.Fatalf("prefer-close: expected the short to be covered, got %d trades", result.TradeCount)
+	}
+	// Шорт по 100, покрытие по 80
+	if math.Abs(result.TotalProfit-0.2) > 1e-9 {
+		t.Errorf("prefer-close: expected +20%% from covering at 80, got %.4f", result.TotalProfit)
+	}
+}
+
+func TestConflictPolicy_Resolve(t *testing.T) {
+	tests := []struct {
+		policy    ConflictPolicy
+		buy, sell int
+		side      PositionSide
+		expected  SignalType
+	}{
+		{ConflictPreferHold, 1, 1, PositionLong, HOLD},
+		{ConflictPreferHold, 2, 0, PositionFlat, BUY},
+		{ConflictPreferClose, 1, 1, PositionFlat, HOLD},
+		{ConflictPreferClose, 1, 1, PositionLong, SELL},
+		{ConflictPreferClose, 1, 1, PositionShort, BUY},
+		{ConflictPreferStrength, 3, 1, PositionFlat, BUY},
+		{ConflictPreferStrength, 2, 2, PositionLong, HOLD},
+		{ConflictPreferStrength, 0, 0, PositionLong, HOLD},
+	}
+
+	for _, tt := range tests {
+		if got := tt.policy.Resolve(tt.buy, tt.sell, tt.side); got != tt.expected {
+			t.Errorf("%s: Resolve(buy=%d, sell=%d, side=%d) = %s, expected %s",
+				tt.policy, tt.buy, tt.sell, tt.side, got, tt.expected)
+		}
+	}
+}
+
+func TestPeriodsPerYearFor_MarketPresets(t *testing.T) {
+	// Часовые свечи по 9 баров в сессию, 3 торговых дня
+	var candles []Candle
+	start := time.Date(2024, 1, 8, 7, 0, 0, 0, time.UTC)
+	for day := 0; day < 3; day++ {
+		for bar := 0; bar < 9; bar++ {
+			candles = append(candles, Candle{
+				Close:      Price(100.0),
+				ParsedTime: start.AddDate(0, 0, day).Add(time.Duration(bar) * time.Hour),
+			})
+		}
+	}
+
+	// Календарное время: часовой интервал, 24/7
+	calendar := BacktestOptions{}.PeriodsPerYearFor(candles)
+	if math.Abs(calendar-365.25*24) > 1e-6 {
+		t.Errorf("auto: expected %.2f periods, got %.2f", 365.25*24, calendar)
+	}
+	if crypto := (BacktestOptions{Market: MarketCrypto}).PeriodsPerYearFor(candles); crypto != calendar {
+		t.Errorf("crypto: expected calendar periods %.2f, got %.2f", calendar, crypto)
+	}
+
+	// Фондовый рынок: 252 сессии × 9 баров
+	if equity := (BacktestOptions{Market: MarketEquity}).PeriodsPerYearFor(candles); equity != 252*9 {
+		t.Errorf("equity: expected %d periods, got %.2f", 252*9, equity)
+	}
+
+	// Явное значение имеет приоритет над пресетом
+	if override := (BacktestOptions{Market: MarketEquity, PeriodsPerYear: 1000}).PeriodsPerYearFor(candles); override != 1000 {
+		t.Errorf("override: expected 1000 periods, got %.2f", override)
+	}
+
+	// Дневные свечи на фондовом рынке — 252 бара в году
+	daily := make([]Candle, 10)
+	for i := range daily {
+		daily[i] = Candle{Close: Price(100.0), ParsedTime: start.AddDate(0, 0, i)}
+	}
+	if equity := (BacktestOptions{Market: MarketEquity}).PeriodsPerYearFor(daily); equity != 252 {
+		t.Errorf("equity daily: expected 252 periods, got %.2f", equity)
+	}
+
+	// Кэш привязан к временам свечей, а не к адресу среза: те же свечи с часовым шагом — новая оценка
+	if calendarDaily := InferPeriodsPerYear(daily); math.Abs(calendarDaily-365.25) > 1e-6 {
+		t.Errorf("auto daily: expected 365.25 periods, got %.2f", calendarDaily)
+	}
+	for i := range daily {
+		daily[i].ParsedTime = start.Add(time.Duration(i) * time.Hour)
+	}
+	if hourly := InferPeriodsPerYear(daily); math.Abs(hourly-365.25*24) > 1e-6 {
+		t.Errorf("mutated slice: expected %.2f periods, got %.2f", 365.25*24, hourly)
+	}
+}
+
+func TestBacktest_ExecutionDelayChangesFills(t *testing.T) {
+	candles := []Candle{
+		{Open: Price(99.0), Close: Price(100.0)},
+		{Open: Price(102.0), Close: Price(104.0)},
+		{Open: Price(106.0), Close: Price(108.0)},
+		{Open: Price(110.0), Close: Price(112.0)},
+	}
+	signals := []SignalType{BUY, HOLD, SELL, HOLD}
+
+	// Без задержки: покупка по 100, продажа по 108
+	atClose := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if math.Abs(atClose.FinalPortfolio-10800) > 1e-6 {
+		t.Errorf("zero delay: expected final portfolio 10800, got %.2f", atClose.FinalPortfolio)
+	}
+
+	// Задержка 1 бар по открытию: покупка по 102, продажа по 110
+	delayed := BacktestWithOptions(candles, signals, BacktestOptions{ExecutionDelay: 1, Fill: FillOpen})
+	expected := 10000.0 / 102.0 * 110.0
+	if math.Abs(delayed.FinalPortfolio-expected) > 1e-6 {
+		t.Errorf("delay=1 at open: expected final portfolio %.2f, got %.2f", expected, delayed.FinalPortfolio)
+	}
+	if delayed.TradeCount != 1 {
+		t.Errorf("delay=1 at open: expected 1 trade, got %d", delayed.TradeCount)
+	}
+
+	// Сигнал на последнем баре с задержкой не исполняется
+	late := BacktestWithOptions(candles, []SignalType{HOLD, HOLD, HOLD, BUY}, BacktestOptions{ExecutionDelay: 1})
+	if late.TimeInMarket != 0 {
+		t.Errorf("signal on last bar must not be filled with delay, time in market %.2f", late.TimeInMarket)
+	}
+}
+
+func TestBacktest_FillModels(t *testing.T) {
+	// Бар 1 открывается гэпом вверх, бар 3 — гэпом вниз: цены исполнения моделей заметно расходятся
+	candles := []Candle{
+		{Open: Price(100.0), High: Price(101.0), Low: Price(99.0), Close: Price(100.0)},
+		{Open: Price(110.0), High: Price(114.0), Low: Price(108.0), Close: Price(112.0)},
+		{Open: Price(120.0), High: Price(122.0), Low: Price(119.0), Close: Price(120.0)},
+		{Open: Price(111.0), High: Price(113.0), Low: Price(105.0), Close: Price(106.0)},
+	}
+	signals := []SignalType{BUY, HOLD, SELL, HOLD}
+
+	cases := []struct {
+		model     FillModel
+		buy, sell float64
+	}{
+		{FillModelClose, 100, 120},    // закрытие бара сигнала
+		{FillModelNextOpen, 110, 111}, // открытие следующего бара
+		{FillModelWorst, 114, 105},    // High следующего бара для покупки, Low — для продажи
+	}
+	for _, tc := range cases {
+		opts := tc.model.Apply(BacktestOptions{})
+		result := BacktestWithOptions(candles, signals, opts)
+		if len(result.Trades) != 1 {
+			t.Fatalf("%s: expected 1 trade, got %d", tc.model, len(result.Trades))
+		}
+		trade := result.Trades[0]
+		if math.Abs(trade.EntryPrice-tc.buy) > 1e-6 || math.Abs(trade.ExitPrice-tc.sell) > 1e-6 {
+			t.Errorf("%s: expected fills %.0f -> %.0f, got %.2f -> %.2f", tc.model, tc.buy, tc.sell, trade.EntryPrice, trade.ExitPrice)
+		}
+		if expected := 10000.0 / tc.buy * tc.sell; math.Abs(result.FinalPortfolio-expected) > 1e-6 {
+			t.Errorf("%s: expected final portfolio %.2f, got %.2f", tc.model, expected, result.FinalPortfolio)
+		}
+	}
+}
+
+func TestBacktest_CircuitBreakerSuppressesTrades(t *testing.T) {
+	// Просадка 50% на втором баре, затем вялое восстановление
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(50.0)},
+		{Close: Price(50.0)},
+		{Close: Price(55.0)},
+		{Close: Price(55.0)},
+		{Close: Price(60.0)},
+	}
+	signals := []SignalType{BUY, SELL, BUY, SELL, BUY, SELL}
+
+	free := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if free.TradeCount != 3 {
+		t.Fatalf("without breaker: expected 3 trades, got %d", free.TradeCount)
+	}
+
+	// Срабатывание на 30%: бумажный капитал отыгрывает лишь 1000 из 5000 — входы остаются запрещены
+	halted := BacktestWithOptions(candles, signals, BacktestOptions{BreakerTrip: 0.3, BreakerReset: 0.5})
+	if halted.BreakerTrips != 1 {
+		t.Errorf("expected breaker to trip once, got %d", halted.BreakerTrips)
+	}
+	if halted.TradeCount != 1 {
+		t.Errorf("expected new entries to be suppressed after trip, got %d trades", halted.TradeCount)
+	}
+	if math.Abs(halted.FinalPortfolio-5000) > 1e-6 {
+		t.Errorf("expected final portfolio 5000 (flat after trip), got %.2f", halted.FinalPortfolio)
+	}
+
+	// Бумажный капитал полностью восстанавливается — торговля возобновляется
+	recovery := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(50.0)},
+		{Close: Price(50.0)},
+		{Close: Price(100.0)},
+		{Close: Price(100.0)},
+		{Close: Price(110.0)},
+	}
+	resumed := BacktestWithOptions(recovery, signals, BacktestOptions{BreakerTrip: 0.3, BreakerReset: 0.5})
+	if resumed.TradeCount != 2 {
+		t.Errorf("expected trading to resume after recovery, got %d trades", resumed.TradeCount)
+	}
+}
+
+func TestBacktest_CommissionAndForceClose(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(110.0)},
+		{Close: Price(120.0)},
+	}
+	signals := []SignalType{BUY, HOLD, HOLD}
+
+	// Без принудительного закрытия позиция оценивается по рынку, сделка не засчитывается
+	open := BacktestWithOptions(candles, signals, BacktestOptions{Commission: 0.01})
+	if open.TradeCount != 0 {
+		t.Errorf("Expected no closed trades, got %d", open.TradeCount)
+	}
+	expectedOpen := 10000.0 / (100 * 1.01) * 120
+	if math.Abs(open.FinalPortfolio-expectedOpen) > 1e-6 {
+		t.Errorf("Expected final portfolio %.4f, got %.4f", expectedOpen, open.FinalPortfolio)
+	}
+
+	// С закрытием в конце издержки выхода входят в результат
+	closed := BacktestWithOptions(candles, signals, BacktestOptions{Commission: 0.01, SlippagePercent: 0.001, ForceClose: true})
+	if closed.TradeCount != 1 {
+		t.Errorf("Expected forced close to count as a trade, got %d", closed.TradeCount)
+	}
+	holdings := 10000.0 / (100 * 1.001 * 1.01)
+	expectedClosed := holdings * 120 * 0.999 * 0.99
+	if math.Abs(closed.FinalPortfolio-expectedClosed) > 1e-6 {
+		t.Errorf("Expected final portfolio %.4f, got %.4f", expectedClosed, closed.FinalPortfolio)
+	}
+	if last := closed.PortfolioValues[len(closed.PortfolioValues)-1]; math.Abs(last-expectedClosed) > 1e-6 {
+		t.Errorf("Expected equity curve to end at realized value %.4f, got %.4f", expectedClosed, last)
+	}
+}
+
+func TestBacktest_MinTradeMoveSuppressesNoiseTrades(t *testing.T) {
+	// Пила ±0.1% с последующим ростом на 10%
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(100.1)},
+		{Close: Price(100.0)},
+		{Close: Price(100.1)},
+		{Close: Price(100.0)},
+		{Close: Price(100.1)},
+		{Close: Price(110.0)},
+	}
+	signals := []SignalType{BUY, SELL, BUY, SELL, BUY, SELL, SELL}
+	opts := BacktestOptions{Commission: 0.001}
+
+	noisy := BacktestWithOptions(candles, signals, opts)
+	if noisy.TradeCount != 3 {
+		t.Fatalf("Expected 3 trades without filter, got %d", noisy.TradeCount)
+	}
+	if noisy.BelowFloorTrades != 3 {
+		t.Errorf("Expected all 3 noise trades below the zero floor, got %d", noisy.BelowFloorTrades)
+	}
+
+	// Выход разрешен только при движении цены от входа не менее 0.2% (2× издержки на сторону)
+	opts.MinTradeMove = 0.002
+	filtered := BacktestWithOptions(candles, signals, opts)
+	if filtered.TradeCount != 1 {
+		t.Errorf("Expected a single trade with filter, got %d", filtered.TradeCount)
+	}
+	if filtered.SuppressedExits != 3 {
+		t.Errorf("Expected 3 suppressed exits, got %d", filtered.SuppressedExits)
+	}
+	if filtered.BelowFloorTrades != 0 {
+		t.Errorf("Expected no trades below floor, got %d", filtered.BelowFloorTrades)
+	}
+	if filtered.TotalProfit <= noisy.TotalProfit {
+		t.Errorf("Expected filter to improve profit: %.4f <= %.4f", filtered.TotalProfit, noisy.TotalProfit)
+	}
+}
+
+func TestBacktest_KellySizing(t *testing.T) {
+	// Две сделки: +20% и −10% → W = 0.5, R = 2, f* = 0.5 − 0.5/2 = 0.25
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(120.0)},
+		{Close: Price(100.0)},
+		{Close: Price(90.0)},
+	}
+	signals := []SignalType{BUY, SELL, BUY, SELL}
+
+	full := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if len(full.Trades) != 2 {
+		t.Fatalf("Expected 2 trades in ledger, got %d", len(full.Trades))
+	}
+	if kelly := KellyFraction(full.Trades); math.Abs(kelly-0.25) > 1e-9 {
+		t.Fatalf("Expected Kelly fraction 0.25, got %.4f", kelly)
+	}
+
+	// Половина Келли: 12.5% капитала в каждой сделке
+	half := BacktestWithOptions(candles, signals, BacktestOptions{KellyMultiplier: 0.5, KellyCap: 1})
+	if math.Abs(half.KellyFraction-0.125) > 1e-9 {
+		t.Errorf("Expected applied fraction 0.125, got %.4f", half.KellyFraction)
+	}
+	afterWin := 10000.0 * (1 + 0.125*0.2)
+	expected := afterWin * (1 - 0.125*0.1)
+	if math.Abs(half.FinalPortfolio-expected) > 1e-6 {
+		t.Errorf("Expected final portfolio %.4f, got %.4f", expected, half.FinalPortfolio)
+	}
+
+	// Ограничение доли сверху
+	capped := BacktestWithOptions(candles, signals, BacktestOptions{KellyMultiplier: 1, KellyCap: 0.1})
+	if math.Abs(capped.KellyFraction-0.1) > 1e-9 {
+		t.Errorf("Expected fraction capped at 0.1, got %.4f", capped.KellyFraction)
+	}
+}
+
+func TestBacktest_ScaleInAveragesEntry(t *testing.T) {
+	// Покупка по 100, докупка по 50, продажа по 75
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(50.0)},
+		{Close: Price(75.0)},
+	}
+	signals := []SignalType{BUY, BUY, SELL}
+
+	// Без докупки второй BUY игнорируется: вход по 100, выход по 75
+	single := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if math.Abs(single.TotalProfit-(-0.25)) > 1e-9 {
+		t.Errorf("Expected -25%% without scale-in, got %.4f", single.TotalProfit)
+	}
+
+	// Две равные части по 5000: 50 + 100 акций, средний вход 10000 / 150 = 66.67
+	scaled := BacktestWithOptions(candles, signals, BacktestOptions{ScaleIn: 2})
+	if scaled.TradeCount != 1 || len(scaled.Trades) != 1 {
+		t.Fatalf("Expected one closed trade, got %d (ledger %d)", scaled.TradeCount, len(scaled.Trades))
+	}
+	trade := scaled.Trades[0]
+	if math.Abs(trade.EntryPrice-10000.0/150) > 1e-9 {
+		t.Errorf("Expected blended entry %.4f, got %.4f", 10000.0/150, trade.EntryPrice)
+	}
+	if trade.EntryIndex != 0 {
+		t.Errorf("Expected trade to start at the first entry, got bar %d", trade.EntryIndex)
+	}
+	expected := 150 * 75.0
+	if math.Abs(scaled.FinalPortfolio-expected) > 1e-6 {
+		t.Errorf("Expected final portfolio %.2f, got %.2f", expected, scaled.FinalPortfolio)
+	}
+	if math.Abs(trade.Return-0.125) > 1e-9 {
+		t.Errorf("Expected trade return +12.5%% from blended entry, got %.4f", trade.Return)
+	}
+}
+
+func TestBacktest_NonFiniteResultIsSanitized(t *testing.T) {
+	// Покупка по нулевой цене дает бесконечное количество акций и бесконечный портфель
+	zero := []Candle{{Close: Price(0)}, {Close: Price(10.0)}, {Close: Price(12.0)}}
+	result := BacktestWithOptions(zero, []SignalType{BUY, HOLD, SELL}, BacktestOptions{})
+	if !result.NonFinite {
+		t.Fatalf("Expected non-finite result to be flagged, got profit %v", result.TotalProfit)
+	}
+	if result.TotalProfit != NonFiniteProfit || result.FinalPortfolio != 0 || result.SharpeRatio != 0 ||
+		result.SortinoRatio != 0 || result.MaxDrawdown != 1 {
+		t.Errorf("Expected sentinel values, got profit=%v portfolio=%v sharpe=%v sortino=%v drawdown=%v",
+			result.TotalProfit, result.FinalPortfolio, result.SharpeRatio, result.SortinoRatio, result.MaxDrawdown)
+	}
+
+	// NaN в цене закрытия на открытой позиции
+	nan := []Candle{{Close: Price(10.0)}, {Close: Price(math.NaN())}}
+	if result := BacktestWithOptions(nan, []SignalType{BUY, HOLD}, BacktestOptions{}); !result.NonFinite {
+		t.Errorf("Expected NaN close to be flagged, got profit %v", result.TotalProfit)
+	}
+
+	// Обычный прогон не помечается
+	normal := []Candle{{Close: Price(10.0)}, {Close: Price(11.0)}, {Close: Price(12.0)}}
+	if result := BacktestWithOptions(normal, []SignalType{BUY, HOLD, SELL}, BacktestOptions{}); result.NonFinite {
+		t.Error("Expected finite result not to be flagged")
+	}
+}
+
+func TestCalculateSharpeRatio_NonFiniteReturnsZero(t *testing.T) {
+	values := []float64{100, 110, math.Inf(1), 120}
+	if sharpe := calculateSharpeRatio(values, 252); sharpe != 0 {
+		t.Errorf("Expected 0 Sharpe for non-finite equity curve, got %v", sharpe)
+	}
+	if sortino := calculateSortinoRatio(values, 252); sortino != 0 {
+		t.Errorf("Expected 0 Sortino for non-finite equity curve, got %v", sortino)
+	}
+}
+
+func TestRiskMetrics_DrawdownAndSortino(t *testing.T) {
+	// Пик 120, минимум после него 90: просадка 25%; последующий новый пик ее не отменяет
+	values := []float64{100, 120, 108, 90, 130, 117}
+	if dd := calculateMaxDrawdown(values); math.Abs(dd-0.25) > 1e-12 {
+		t.Errorf("Expected max drawdown 0.25, got %v", dd)
+	}
+
+	returns := curveReturns(values)
+	sum, downside := 0.0, 0.0
+	for _, r := range returns {
+		sum += r
+		if r < 0 {
+			downside += r * r
+		}
+	}
+	n := float64(len(returns))
+	want := sum / n / math.Sqrt(downside/n) * math.Sqrt(252)
+	if got := calculateSortinoRatio(values, 252); math.Abs(got-want) > 1e-9 {
+		t.Errorf("Expected Sortino %.6f, got %.6f", want, got)
+	}
+	// Снижения штрафуются, рост — нет: при таком же среднем Sortino выше Sharpe
+	if sortino, sharpe := calculateSortinoRatio(values, 252), calculateSharpeRatio(values, 252); sortino <= sharpe {
+		t.Errorf("Expected Sortino (%.3f) above Sharpe (%.3f) for a profitable curve", sortino, sharpe)
+	}
+
+	// Без убыточных баров нисходящего отклонения нет
+	if sortino := calculateSortinoRatio([]float64{100, 101, 103, 106}, 252); sortino != 0 {
+		t.Errorf("Expected 0 Sortino without losing bars, got %v", sortino)
+	}
+}
+
+func TestBacktest_ZeroTradesReportZeroRiskMetrics(t *testing.T) {
+	candles := []Candle{{Close: Price(10.0)}, {Close: Price(8.0)}, {Close: Price(12.0)}, {Close: Price(9.0)}}
+	result := Backtest(candles, make([]SignalType, len(candles)), 0)
+	if result.SharpeRatio != 0 || result.SortinoRatio != 0 || result.MaxDrawdown != 0 {
+		t.Errorf("Expected zero risk metrics without trades, got sharpe=%v sortino=%v drawdown=%v",
+			result.SharpeRatio, result.SortinoRatio, result.MaxDrawdown)
+	}
+
+	// С позицией просадка считается по кривой капитала
+	result = Backtest(candles, []SignalType{BUY, HOLD, HOLD, SELL}, 0)
+	if math.Abs(result.MaxDrawdown-0.25) > 1e-9 {
+		t.Errorf("Expected 25%% drawdown from the 12 peak to 9, got %v", result.MaxDrawdown)
+	}
+}
+
+func TestBacktest_ReturnModeCompoundVsFixed(t *testing.T) {
+	// Две сделки: +50% (100 → 150), затем −20% (100 → 80)
+	prices := []float64{100, 150, 100, 80}
+	candles := make([]Candle, len(prices))
+	for i, p := range prices {
+		candles[i] = Candle{Close: Price(p)}
+	}
+	signals := []SignalType{BUY, SELL, BUY, SELL}
+
+	// Сложный процент: 10000 × 1.5 × 0.8 = 12000
+	compound := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if math.Abs(compound.FinalPortfolio-12000) > 1e-6 {
+		t.Errorf("compound: expected final portfolio 12000, got %.2f", compound.FinalPortfolio)
+	}
+
+	// Фиксированная сумма: 10000 + 5000 − 2000 = 13000
+	fixed := BacktestWithOptions(candles, signals, BacktestOptions{ReturnMode: ReturnFixed})
+	if math.Abs(fixed.FinalPortfolio-13000) > 1e-6 {
+		t.Errorf("fixed: expected final portfolio 13000, got %.2f", fixed.FinalPortfolio)
+	}
+	if fixed.TradeCount != 2 || math.Abs(fixed.Trades[1].Return+0.2) > 1e-9 {
+		t.Errorf("fixed: expected 2 trades with the second at -20%%, got %d trades", fixed.TradeCount)
+	}
+
+	// После убытка больше начального капитала fixed продолжает торговать той же суммой
+	losing := []Candle{{Close: Price(100.0)}, {Close: Price(40.0)}, {Close: Price(100.0)}, {Close: Price(40.0)}}
+	drained := BacktestWithOptions(losing, signals, BacktestOptions{ReturnMode: ReturnFixed})
+	if math.Abs(drained.FinalPortfolio+2000) > 1e-6 {
+		t.Errorf("fixed: expected losses to be summed to -2000, got %.2f", drained.FinalPortfolio)
+	}
+}
+
+func TestBacktest_ShortSellingProfitsInDowntrend(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(90.0)},
+		{Close: Price(80.0)},
+		{Close: Price(75.0)},
+	}
+	signals := []SignalType{SELL, HOLD, HOLD, BUY}
+
+	// Без AllowShorts движок только длинный: SELL до первого BUY игнорируется, BUY открывает позицию в конце
+	longOnly := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if longOnly.TradeCount != 0 || longOnly.TotalProfit != 0 {
+		t.Errorf("Expected long-only engine to skip the short, got %d trades, profit %.4f", longOnly.TradeCount, longOnly.TotalProfit)
+	}
+
+	// Шорт по 100, покрытие по 75: +25% от суммы входа
+	short := BacktestWithOptions(candles, signals, BacktestOptions{AllowShorts: true})
+	if short.TradeCount != 1 || len(short.Trades) != 1 {
+		t.Fatalf("Expected one closed short, got %d (ledger %d)", short.TradeCount, len(short.Trades))
+	}
+	if !short.Trades[0].Short {
+		t.Error("Expected trade to be marked as short")
+	}
+	if math.Abs(short.TotalProfit-0.25) > 1e-9 {
+		t.Errorf("Expected +25%% from the short, got %.4f", short.TotalProfit)
+	}
+	if math.Abs(short.Trades[0].Return-0.25) > 1e-9 {
+		t.Errorf("Expected trade return +25%%, got %.4f", short.Trades[0].Return)
+	}
+	// Пока шорт открыт, капитал растет вместе с падением цены
+	if math.Abs(short.PortfolioValues[3]-12000) > 1e-6 {
+		t.Errorf("Expected marked-to-market equity 12000 at price 80, got %.4f", short.PortfolioValues[3])
+	}
+
+	// Проскальзывание на каждой стороне: продажа дешевле, покрытие дороже
+	slipped := BacktestWithOptions(candles, signals, BacktestOptions{AllowShorts: true, SlippagePercent: 0.01})
+	expected := 1 - 75*1.01/(100*0.99)
+	if math.Abs(slipped.TotalProfit-expected) > 1e-9 {
+		t.Errorf("Expected %.4f with slippage on both sides, got %.4f", expected, slipped.TotalProfit)
+	}
+
+	// Незакрытый шорт покрывается при ForceClose по последней цене
+	forced := BacktestWithOptions(candles, []SignalType{SELL, HOLD, HOLD, HOLD}, BacktestOptions{AllowShorts: true, ForceClose: true})
+	if forced.TradeCount != 1 || math.Abs(forced.TotalProfit-0.25) > 1e-9 {
+		t.Errorf("Expected forced cover with +25%%, got %d trades, profit %.4f", forced.TradeCount, forced.TotalProfit)
+	}
+}
+
+func TestBacktest_WinLossStatsSkipOpenPosition(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(110.0)}, // +10%
+		{Close: Price(100.0)},
+		{Close: Price(95.0)}, // -5%
+		{Close: Price(100.0)},
+		{Close: Price(80.0)}, // шорт по 100, покрытие по 80: +20%
+		{Close: Price(90.0)},
+		{Close: Price(200.0)}, // позиция открыта на конец данных
+	}
+	signals := []SignalType{BUY, SELL, BUY, SELL, SELL, BUY, BUY, HOLD}
+
+	result := BacktestWithOptions(candles, signals, BacktestOptions{AllowShorts: true})
+	if result.TradeCount != 3 {
+		t.Fatalf("Expected 3 closed trades, got %d", result.TradeCount)
+	}
+	if result.WinningTrades != 2 || result.LosingTrades != 1 {
+		t.Errorf("Expected 2 wins and 1 loss (open position excluded), got %d and %d", result.WinningTrades, result.LosingTrades)
+	}
+	if math.Abs(result.AvgWin-0.15) > 1e-9 {
+		t.Errorf("Expected average win +15%%, got %.4f", result.AvgWin)
+	}
+	if math.Abs(result.AvgLoss-(-0.05)) > 1e-9 {
+		t.Errorf("Expected average loss -5%%, got %.4f", result.AvgLoss)
+	}
+
+	// Без закрытых сделок статистика нулевая
+	open := BacktestWithOptions(candles, []SignalType{BUY, HOLD, HOLD, HOLD, HOLD, HOLD, HOLD, HOLD}, BacktestOptions{})
+	if open.WinningTrades != 0 || open.LosingTrades != 0 || open.AvgWin != 0 || open.AvgLoss != 0 {
+		t.Errorf("Expected no win/loss stats for an open position, got %+v", open)
+	}
+}
+
+func TestBacktest_TakeProfitFiresBeforeStrategySell(t *testing.T) {
+	candles := []Candle{
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 101, High: 104, Low: 100, Close: 103},
+		{Open: 103, High: 112, Low: 102, Close: 108}, // внутри бара пройден уровень 110
+		{Open: 106, High: 107, Low: 95, Close: 96},
+		{Open: 96, High: 97, Low: 94, Close: 95},
+		{Open: 95, High: 101, Low: 95, Close: 100},
+	}
+	signals := []SignalType{BUY, HOLD, HOLD, SELL, BUY, SELL}
+
+	// Без правил выхода стратегия продает на падении: 100 → 96, затем 95 → 100
+	plain := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if plain.ForcedExits != 0 || plain.Trades[0].ExitIndex != 3 {
+		t.Fatalf("Expected strategy exit at bar 3 without exit rules, got bar %d", plain.Trades[0].ExitIndex)
+	}
+
+	// Тейк-профит 10% закрывает позицию на баре 2 по 110 с проскальзыванием, SELL на баре 3 уже ничего не продает
+	opts := BacktestOptions{SlippagePercent: 0.001, Exits: ExitRules{TakeProfitPercent: 0.10}}
+	result := BacktestWithOptions(candles, signals, opts)
+	if result.ForcedExits != 1 || result.TradeCount != 2 {
+		t.Fatalf("Expected one forced exit and two trades, got %d forced, %d trades", result.ForcedExits, result.TradeCount)
+	}
+	first := result.Trades[0]
+	if first.ExitIndex != 2 {
+		t.Errorf("Expected take-profit at bar 2, got bar %d", first.ExitIndex)
+	}
+	if expected := 110 * (1 - 0.001); math.Abs(first.ExitPrice-expected) > 1e-9 {
+		t.Errorf("Expected exit at take-profit price %.4f, got %.4f", expected, first.ExitPrice)
+	}
+
+	// Позиция сброшена: следующий BUY открывает новую сделку, и стратегия закрывает ее сама
+	second := result.Trades[1]
+	if second.EntryIndex != 4 || second.ExitIndex != 5 {
+		t.Errorf("Expected a normal trade 4→5 after the forced exit, got %d→%d", second.EntryIndex, second.ExitIndex)
+	}
+	if result.TotalProfit <= plain.TotalProfit {
+		t.Errorf("Expected take-profit to beat the late strategy exit: %.4f vs %.4f", result.TotalProfit, plain.TotalProfit)
+	}
+}
+
+func TestBacktest_TrailingStopFollowsHigh(t *testing.T) {
+	candles := []Candle{
+		{Open: 100, High: 100, Low: 100, Close: 100},
+		{Open: 100, High: 120, Low: 99, Close: 118},
+		{Open: 117, High: 118, Low: 107, Close: 108}, // ниже 120 × 0.9 = 108
+		{Open: 108, High: 109, Low: 107, Close: 108},
+	}
+	signals := []SignalType{BUY, HOLD, HOLD, SELL}
+
+	// Фиксированный стоп 10% от входа (90) не достигнут, трейлинг от максимума 120 срабатывает на 108
+	fixed := BacktestWithOptions(candles, signals, BacktestOptions{Exits: ExitRules{StopLossPercent: 0.10}})
+	if fixed.ForcedExits != 0 {
+		t.Errorf("Expected fixed stop to stay untouched, got %d forced exits", fixed.ForcedExits)
+	}
+
+	trailing := BacktestWithOptions(candles, signals, BacktestOptions{Exits: ExitRules{StopLossPercent: 0.10, TrailingStop: true}})
+	if trailing.ForcedExits != 1 || trailing.Trades[0].ExitIndex != 2 {
+		t.Fatalf("Expected trailing stop at bar 2, got %d forced exits", trailing.ForcedExits)
+	}
+	if math.Abs(trailing.Trades[0].ExitPrice-108) > 1e-9 {
+		t.Errorf("Expected exit at the trailing stop 108, got %.4f", trailing.Trades[0].ExitPrice)
+	}
+}
+
+func TestBacktester_StreamingMatchesBatch(t *testing.T) {
+	start := time.Date(2024, 1, 8, 7, 0, 0, 0, time.UTC)
+	candles := make([]Candle, 300)
+	signals := make([]SignalType, len(candles))
+	for i := range candles {
+		close := 100 + 10*math.Sin(float64(i)/9) + 3*math.Sin(float64(i)/2.3)
+		candles[i] = Candle{
+			Open:       Price(close - 0.4),
+			High:       Price(close + 1.2),
+			Low:        Price(close - 1.5),
+			Close:      Price(close),
+			ParsedTime: start.Add(time.Duration(i) * time.Hour),
+		}
+		switch {
+		case i%17 == 0 || i%23 == 3:
+			signals[i] = BUY
+		case i%11 == 5:
+			signals[i] = SELL
+		}
+	}
+
+	optionSets := map[string]BacktestOptions{
+		"defaults": {Slippage: 0.01},
+		"delay, regime, breaker, force close": {
+			ExecutionDelay: 2, Fill: FillOpen, RegimeFilter: RegimeHigh, RegimeWindow: 10,
+			BreakerTrip: 0.03, BreakerReset: 0.5, ForceClose: true, Commission: 0.001,
+		},
+		"shorts, exits, scale-in": {
+			AllowShorts: true, ScaleIn: 3, MinTradeMove: 0.002, SlippagePercent: 0.0005,
+			Exits: ExitRules{StopLossPercent: 0.03, TakeProfitPercent: 0.05, TrailingStop: true},
+		},
+		"fixed returns, equity market": {ReturnMode: ReturnFixed, Market: MarketEquity, ProfitFloor: 0.01},
+	}
+
+	for name, opts := range optionSets {
+		batch := BacktestWithOptions(candles, signals, opts)
+
+		streaming := NewBacktester(opts)
+		for i := range candles {
+			streaming.Step(candles[i], signals[i])
+			if i == len(candles)/2 {
+				streaming.Result() // промежуточный отчет не должен менять состояние
+			}
+		}
+
+		if got := streaming.Result(); !reflect.DeepEqual(got, batch) {
+			t.Errorf("%s: streaming result differs from batch:\nbatch:     %+v\nstreaming: %+v", name, batch, got)
+		}
+		if batch.TradeCount == 0 {
+			t.Errorf("%s: expected the fixture to trade", name)
+		}
+	}
+}
+
+func TestBacktest_InitialCapitalScalesPortfolio(t *testing.T) {
+	candles := []Candle{{Close: 100}, {Close: 110}, {Close: 121}, {Close: 115}}
+	signals := []SignalType{BUY, HOLD, SELL, HOLD}
+
+	standard := BacktestWithOptions(candles, signals, BacktestOptions{Slippage: 0.5})
+	small := BacktestWithOptions(candles, signals, BacktestOptions{Slippage: 0.5, InitialCapital: 1000})
+
+	if standard.PortfolioValues[0] != DefaultInitialCapital || small.PortfolioValues[0] != 1000 {
+		t.Fatalf("Expected curves to start at %.0f and 1000, got %.2f and %.2f",
+			DefaultInitialCapital, standard.PortfolioValues[0], small.PortfolioValues[0])
+	}
+	if math.Abs(small.TotalProfit-standard.TotalProfit) > 1e-12 {
+		t.Errorf("Expected the same profit for any capital, got %.6f and %.6f", small.TotalProfit, standard.TotalProfit)
+	}
+	if math.Abs(small.FinalPortfolio-1000*(1+small.TotalProfit)) > 1e-9 {
+		t.Errorf("Expected final portfolio %.2f, got %.2f", 1000*(1+small.TotalProfit), small.FinalPortfolio)
+	}
+}
+
+func TestBacktest_WholeSharesCarryCashRemainder(t *testing.T) {
+	candles := []Candle{{Close: 300}, {Close: 330}, {Close: 500}, {Close: 550}}
+	signals := []SignalType{BUY, SELL, BUY, SELL}
+	opts := BacktestOptions{InitialCapital: 1000, PositionSizing: SizingWholeShares}
+
+	result := BacktestWithOptions(candles, signals, opts)
+
+	// 3 бумаги по 300 (остаток 100) → 1090; 2 бумаги по 500 (остаток 90) → 1190
+	if math.Abs(result.FinalPortfolio-1190) > 1e-9 {
+		t.Errorf("Expected final portfolio 1190 with cash remainder carried forward, got %.2f", result.FinalPortfolio)
+	}
+	if math.Abs(result.PortfolioValues[2]-1090) > 1e-9 {
+		t.Errorf("Expected equity 1090 after the second entry, got %.2f", result.PortfolioValues[2])
+	}
+
+	opts.PositionSizing = SizingFractional
+	if fractional := BacktestWithOptions(candles, signals, opts); math.Abs(fractional.FinalPortfolio-1210) > 1e-9 {
+		t.Errorf("Expected fractional sizing to compound the full capital to 1210, got %.2f", fractional.FinalPortfolio)
+	}
+}
+
+func TestBacktest_FixedFractionRisksShareOfEquity(t *testing.T) {
+	candles := []Candle{{Close: 100}, {Close: 120}, {Close: 120}}
+	signals := []SignalType{BUY, SELL, HOLD}
+	opts := BacktestOptions{InitialCapital: 1000, PositionSizing: SizingFixedFraction, RiskFraction: 0.25}
+
+	result := BacktestWithOptions(candles, signals, opts)
+
+	if math.Abs(result.FinalPortfolio-1050) > 1e-9 {
+		t.Errorf("Expected 25%% of equity to earn 20%% (final 1050), got %.2f", result.FinalPortfolio)
+	}
+}
+
+func TestBacktest_ExcursionsPerClosedTrade(t *testing.T) {
+	candles := []Candle{
+		{Close: 100, High: 105, Low: 95}, // вход по закрытию: экстремумы до входа не считаются
+		{Close: 110, High: 112, Low: 97},
+		{Close: 108, High: 120, Low: 90}, // выход по закрытию: учитывается только цена выхода
+		{Close: 100, High: 100, Low: 100},
+		{Close: 150, High: 200, Low: 50}, // позиция открыта на конец данных
+	}
+	signals := []SignalType{BUY, HOLD, SELL, BUY, HOLD}
+
+	result := BacktestWithOptions(candles, signals, BacktestOptions{TrackExcursions: true})
+
+	if len(result.Trades) != 1 {
+		t.Fatalf("Expected one closed trade, got %d", len(result.Trades))
+	}
+	trade := result.Trades[0]
+	if math.Abs(trade.MFE-0.12) > 1e-9 || math.Abs(trade.MAE+0.03) > 1e-9 {
+		t.Errorf("Expected MFE +12%% and MAE -3%%, got %.4f and %.4f", trade.MFE, trade.MAE)
+	}
+	if result.AvgMFE != trade.MFE || result.AvgMAE != trade.MAE {
+		t.Errorf("Expected averages to exclude the open position, got %.4f and %.4f", result.AvgMFE, result.AvgMAE)
+	}
+
+	if untracked := BacktestWithOptions(candles, signals, BacktestOptions{}); untracked.AvgMFE != 0 || untracked.Trades[0].MAE != 0 {
+		t.Errorf("Expected no excursions without TrackExcursions, got %+v", untracked.Trades[0])
+	}
+}
+
+func TestBacktest_ProfitFactorAndExpectancy(t *testing.T) {
+	candles := []Candle{{Close: 100}, {Close: 120}, {Close: 120}, {Close: 108}}
+	opts := BacktestOptions{InitialCapital: 1000}
+
+	// +200 на первой сделке, −120 на второй
+	result := BacktestWithOptions(candles, []SignalType{BUY, SELL, BUY, SELL}, opts)
+	if math.Abs(result.ProfitFactor-200.0/120.0) > 1e-9 {
+		t.Errorf("Expected profit factor %.4f, got %.4f", 200.0/120.0, result.ProfitFactor)
+	}
+	if math.Abs(result.Expectancy-40) > 1e-9 {
+		t.Errorf("Expected expectancy 40 per trade, got %.4f", result.Expectancy)
+	}
+
+	winnersOnly := BacktestWithOptions(candles, []SignalType{BUY, SELL, HOLD, HOLD}, opts)
+	if !math.IsInf(winnersOnly.ProfitFactor, 1) {
+		t.Errorf("Expected +Inf profit factor without losing trades, got %.4f", winnersOnly.ProfitFactor)
+	}
+	if none := BacktestWithOptions(candles, make([]SignalType, len(candles)), opts); none.ProfitFactor != 0 || none.Expectancy != 0 {
+		t.Errorf("Expected zero metrics without trades, got %.4f and %.4f", none.ProfitFactor, none.Expectancy)
+	}
+}
+
+func TestBacktest_MinHoldAndCooldownRejectSignals(t *testing.T) {
+	candles := make([]Candle, 14)
+	signals := make([]SignalType, len(candles))
+	for i := range candles {
+		candles[i] = Candle{Close: Price(100 + float64(i))}
+		// Стратегия меняет мнение на каждом баре
+		signals[i] = BUY
+		if i%2 == 1 {
+			signals[i] = SELL
+		}
+	}
+
+	free := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if free.TradeCount != 7 {
+		t.Fatalf("Expected 7 one-bar trades without limits, got %d", free.TradeCount)
+	}
+
+	held := BacktestWithOptions(candles, signals, BacktestOptions{MinHoldBars: 5})
+	if held.TradeCount != 2 {
+		t.Fatalf("Expected 2 trades with a 5-bar minimum hold, got %d", held.TradeCount)
+	}
+	for _, trade := range held.Trades {
+		if trade.ExitIndex-trade.EntryIndex < 5 {
+			t.Errorf("Trade %d→%d is shorter than 5 bars", trade.EntryIndex, trade.ExitIndex)
+		}
+	}
+	if held.Trades[0].ExitIndex != 5 || held.Trades[1].EntryIndex != 6 {
+		t.Errorf("Expected exit at the first SELL after 5 bars, got %+v", held.Trades)
+	}
+
+	// После выхода на баре 5 вход на барах 6 и 7 отклоняется, BUY на баре 8 исполняется
+	cooled := BacktestWithOptions(candles, signals, BacktestOptions{MinHoldBars: 5, CooldownBars: 3})
+	if len(cooled.Trades) < 2 || cooled.Trades[1].EntryIndex != 8 {
+		t.Errorf("Expected the second entry at bar 8 after a 3-bar cooldown, got %+v", cooled.Trades)
+	}
+}
+
+func TestBacktest_CAGROverTwoYears(t *testing.T) {
+	start := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	// Ровно два года по 365.25 дня: +21% за период → 1.21^(1/2) − 1 = 10% годовых
+	end := start.Add(2 * 365.25 * 24 * time.Hour)
+	candles := []Candle{
+		{Close: 100, ParsedTime: start},
+		{Close: 121, ParsedTime: end},
+	}
+
+	result := BacktestWithOptions(candles, []SignalType{BUY, SELL}, BacktestOptions{})
+	if math.Abs(result.TotalProfit-0.21) > 1e-9 {
+		t.Fatalf("Expected 21%% total return, got %.4f", result.TotalProfit)
+	}
+	if math.Abs(result.CAGR-0.10) > 1e-9 {
+		t.Errorf("Expected 10%% CAGR, got %.6f", result.CAGR)
+	}
+
+	// Неделя данных не аннуализируется
+	candles[1].ParsedTime = start.Add(7 * 24 * time.Hour)
+	if short := BacktestWithOptions(candles, []SignalType{BUY, SELL}, BacktestOptions{}); short.CAGR != short.TotalProfit {
+		t.Errorf("Expected raw return for a one-week span, got CAGR %.4f vs %.4f", short.CAGR, short.TotalProfit)
+	}
+}
@@ -0,0 +1,125 @@
+// bars.go
+// Агрегация свечей в бары равного объема или равного оборота (volume/dollar bars)
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// BarKind — способ нарезки баров
+type BarKind string
+
+const (
+	// TimeBars — исходные свечи без изменений
+	TimeBars BarKind = "time"
+	// VolumeBars — бары с равным суммарным объемом
+	VolumeBars BarKind = "volume"
+	// DollarBars — бары с равным оборотом (объем × цена закрытия)
+	DollarBars BarKind = "dollar"
+)
+
+// BarSpec — разобранное значение флага --bars
+type BarSpec struct {
+	Kind      BarKind
+	Threshold float64
+}
+
+// ParseBarSpec — разбирает "time", "volume:100000" или "dollar:5000000"
+func ParseBarSpec(s string) (BarSpec, error) {
+	if s == "" || s == string(TimeBars) {
+		return BarSpec{Kind: TimeBars}, nil
+	}
+
+	kind, value, ok := strings.Cut(s, ":")
+	if !ok || (BarKind(kind) != VolumeBars && BarKind(kind) != DollarBars) {
+		return BarSpec{}, fmt.Errorf("неизвестный тип баров '%s' (ожидается time, volume:N или dollar:N)", s)
+	}
+	threshold, err := strconv.ParseFloat(value, 64)
+	if err != nil || !isFinite(threshold) || threshold <= 0 {
+		return BarSpec{}, fmt.Errorf("порог баров '%s' должен быть положительным числом", value)
+	}
+	return BarSpec{Kind: BarKind(kind), Threshold: threshold}, nil
+}
+
+// Apply — перестраивает свечи согласно спецификации; для time-баров возвращает их без изменений
+func (b BarSpec) Apply(candles []Candle) []Candle {
+	switch b.Kind {
+	case VolumeBars:
+		return AggregateVolumeBars(candles, b.Threshold)
+	case DollarBars:
+		return AggregateDollarBars(candles, b.Threshold)
+	default:
+		return candles
+	}
+}
+
+func (b BarSpec) String() string {
+	if b.Kind == TimeBars || b.Kind == "" {
+		return string(TimeBars)
+	}
+	return string(b.Kind) + ":" + strconv.FormatFloat(b.Threshold, 'f', -1, 64)
+}
+
+// AggregateVolumeBars — объединяет свечи в бары, каждый из которых закрывается, когда накопленный объем
+// достигает volumePerBar. Свеча не делится между барами, поэтому бар может превысить порог
+func AggregateVolumeBars(candles []Candle, volumePerBar float64) []Candle {
+	return aggregateBars(candles, volumePerBar, func(c Candle) float64 {
+		return c.VolumeFloat
+	})
+}
+
+// AggregateDollarBars — то же, что AggregateVolumeBars, но порог задается оборотом: объем × цена закрытия
+func AggregateDollarBars(candles []Candle, dollarsPerBar float64) []Candle {
+	return aggregateBars(candles, dollarsPerBar, func(c Candle) float64 {
+		return c.VolumeFloat * c.Close.ToFloat64()
+	})
+}
+
+// aggregateBars — общий цикл нарезки: Open — первой свечи бара, Close и время — последней,
+// High/Low — экстремумы, объем суммируется. Последний неполный бар сохраняется с IsComplete=false
+func aggregateBars(candles []Candle, threshold float64, weight func(Candle) float64) []Candle {
+	if len(candles) == 0 || threshold <= 0 {
+		return candles
+	}
+
+	var bars []Candle
+	var current Candle
+	accumulated := 0.0
+	open := false
+
+	for _, c := range candles {
+		if !open {
+			current = c
+			current.VolumeFloat = 0
+			accumulated = 0
+			open = true
+		}
+
+		if c.High > current.High {
+			current.High = c.High
+		}
+		if c.Low < current.Low {
+			current.Low = c.Low
+		}
+		current.Close = c.Close
+		current.VolumeFloat += c.VolumeFloat
+		current.Time = c.Time
+		current.ParsedTime = c.ParsedTime
+		current.IsComplete = c.IsComplete
+		accumulated += weight(c)
+
+		if accumulated >= threshold {
+			bars = append(bars, finishResampledCandle(current))
+			open = false
+		}
+	}
+
+	if open {
+		current.IsComplete = false
+		bars = append(bars, finishResampledCandle(current))
+	}
+
+	return bars
+}
@@ -0,0 +1,57 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestAggregateVolumeBars_KeepsPartialBarAndLastTime(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	volumes := []float64{40, 70, 100, 30, 20}
+	candles := make([]Candle, len(volumes))
+	for i, v := range volumes {
+		price := 100.0 + float64(i)
+		candles[i] = Candle{
+			Open:        Price(price),
+			High:        Price(price + 2),
+			Low:         Price(price - 1),
+			Close:       Price(price + 1),
+			VolumeFloat: v,
+			IsComplete:  true,
+			ParsedTime:  start.Add(time.Duration(i) * time.Minute),
+		}
+	}
+
+	bars := AggregateVolumeBars(candles, 100)
+	if len(bars) != 3 {
+		t.Fatalf("Expected 3 bars (40+70, 100, partial 30+20), got %d", len(bars))
+	}
+
+	first := bars[0]
+	if first.Open != 100 || first.Close != 102 || first.High != 103 || first.Low != 99 {
+		t.Errorf("Unexpected OHLC for first bar: O=%.0f H=%.0f L=%.0f C=%.0f", first.Open, first.High, first.Low, first.Close)
+	}
+	if first.VolumeFloat != 110 || first.Volume != "110" {
+		t.Errorf("Expected volume 110, got %.0f (%s)", first.VolumeFloat, first.Volume)
+	}
+	if !first.ParsedTime.Equal(candles[1].ParsedTime) {
+		t.Errorf("Expected bar time of its last candle %v, got %v", candles[1].ParsedTime, first.ParsedTime)
+	}
+
+	partial := bars[2]
+	if partial.IsComplete || partial.VolumeFloat != 50 {
+		t.Errorf("Expected incomplete partial bar with volume 50, got complete=%v volume=%.0f", partial.IsComplete, partial.VolumeFloat)
+	}
+}
+
+func TestParseBarSpec(t *testing.T) {
+	spec, err := ParseBarSpec("dollar:5e6")
+	if err != nil || spec.Kind != DollarBars || spec.Threshold != 5e6 {
+		t.Errorf("Unexpected spec %+v, err %v", spec, err)
+	}
+	for _, bad := range []string{"volume", "volume:0", "tick:10", "dollar:abc"} {
+		if _, err := ParseBarSpec(bad); err == nil {
+			t.Errorf("Expected error for %q", bad)
+		}
+	}
+}
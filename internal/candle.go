@@ -3,7 +3,6 @@ package internal
 
 import (
 	"encoding/json"
-	"log"
 	"strconv"
 	"time"
 )
@@ -25,12 +24,24 @@ func (p *Price) UnmarshalJSON(data []byte) error {
 	if err != nil {
 		return err
 	}
-	*p = Price(float64(units) + float64(temp.Nano)/1_000_000_000.0)
+	*p = Price(quotationToFloat64(units, temp.Nano))
 	return nil
 }
 
+// quotationToFloat64 — переводит пару units/nano в ближайшее float64.
+// Сумма units + nano/1e9 округляется дважды и иногда расходится с десятичной записью цены на младший бит;
+// деление целого числа нано на 1e9 округляется один раз, пока число нано представимо точно (|units| < 9e6)
+func quotationToFloat64(units int64, nano int32) float64 {
+	const maxExactUnits = 9_000_000
+	if units > -maxExactUnits && units < maxExactUnits {
+		return float64(units*1_000_000_000+int64(nano)) / 1_000_000_000.0
+	}
+	return float64(units) + float64(nano)/1_000_000_000.0
+}
+
 // ToFloat64 возвращает значение Price как float64.
-// Теперь это простое приведение типов, поскольку преобразование происходит на этапе загрузки JSON.
+// Это простое приведение типов без затрат: преобразование Quotation выполняется один раз при загрузке JSON,
+// поэтому отдельные float64-поля OHLC в Candle не нужны.
 func (p Price) ToFloat64() float64 {
 	return float64(p)
 }
@@ -76,7 +87,7 @@ func (c *Candle) UnmarshalJSON(data []byte) error {
 				// Если не получилось, пробуем без timezone
 				parsedTime, err = time.Parse("2006-01-02T15:04:05", aux.Time)
 				if err != nil {
-					log.Printf("❌ Все форматы времени провалились для: '%s', используем zero time", aux.Time)
+					Log.Warnf("❌ Все форматы времени провалились для: '%s', используем zero time", aux.Time)
 				} else {
 					c.ParsedTime = parsedTime
 				}
@@ -91,7 +102,7 @@ func (c *Candle) UnmarshalJSON(data []byte) error {
 	// Преобразуем Volume из string в float64 один раз при загрузке
 	vol, err := strconv.ParseInt(aux.Volume, 10, 64)
 	if err != nil {
-		log.Printf("Failed to parse volume: %s, error: %v", aux.Volume, err)
+		Log.Warnf("Failed to parse volume: %s, error: %v", aux.Volume, err)
 		c.VolumeFloat = 0.0 // присваиваем 0 в случае ошибки
 	} else {
 		c.VolumeFloat = float64(vol)
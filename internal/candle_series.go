@@ -0,0 +1,194 @@
+// candle_series.go
+// Проверка ряда свечей: основной интервал, пропуски, повторяющееся и пустое время
+package internal
+
+import (
+	"fmt"
+	"time"
+)
+
+// gapTolerance — интервал больше основного во столько раз считается пропуском свечей
+const gapTolerance = 1.5
+
+// GapKind — вид нарушения равномерности ряда свечей
+type GapKind int
+
+const (
+	// GapMissing — между соседними свечами прошло больше 1.5 основных интервалов
+	GapMissing GapKind = iota
+	// GapDuplicate — у свечи то же время, что у предыдущей
+	GapDuplicate
+	// GapZeroTime — у свечи пустое или некорректное время
+	GapZeroTime
+)
+
+func (k GapKind) String() string {
+	switch k {
+	case GapDuplicate:
+		return "duplicate"
+	case GapZeroTime:
+		return "zero-time"
+	default:
+		return "missing"
+	}
+}
+
+// Gap — нарушение ряда перед свечой Index: From — время предыдущей свечи со временем, To — время свечи Index
+// (для GapZeroTime оба нулевые)
+type Gap struct {
+	Kind     GapKind
+	Index    int
+	From, To time.Time
+}
+
+// Duration — длительность пропуска (0 для повторов и пустого времени)
+func (g Gap) Duration() time.Duration {
+	if g.Kind != GapMissing {
+		return 0
+	}
+	return g.To.Sub(g.From)
+}
+
+// ValidateCandleSeries — определяет основной (самый частый) интервал между соседними свечами в секундах
+// и перечисляет пропуски длиннее 1.5 интервала, свечи с повторяющимся временем и свечи без времени.
+// Свечи без времени в расчет интервалов не входят: соседями считаются ближайшие свечи со временем
+func ValidateCandleSeries(candles []Candle) (intervalSeconds int64, gaps []Gap) {
+	intervalSeconds = modalIntervalSeconds(candles)
+
+	prev := -1
+	for i, c := range candles {
+		if c.ParsedTime.IsZero() {
+			gaps = append(gaps, Gap{Kind: GapZeroTime, Index: i})
+			continue
+		}
+		if prev >= 0 {
+			from := candles[prev].ParsedTime
+			step := c.ParsedTime.Sub(from)
+			switch {
+			case step == 0:
+				gaps = append(gaps, Gap{Kind: GapDuplicate, Index: i, From: from, To: c.ParsedTime})
+			case intervalSeconds > 0 && step.Seconds() > gapTolerance*float64(intervalSeconds):
+				gaps = append(gaps, Gap{Kind: GapMissing, Index: i, From: from, To: c.ParsedTime})
+			}
+		}
+		prev = i
+	}
+	return intervalSeconds, gaps
+}
+
+// modalIntervalSeconds — самый частый положительный интервал между соседними свечами со временем
+// (при равной частоте — меньший); 0, если таких пар нет
+func modalIntervalSeconds(candles []Candle) int64 {
+	counts := make(map[int64]int)
+	var last time.Time
+	for _, c := range candles {
+		if c.ParsedTime.IsZero() {
+			continue
+		}
+		if !last.IsZero() {
+			if step := int64(c.ParsedTime.Sub(last) / time.Second); step > 0 {
+				counts[step]++
+			}
+		}
+		last = c.ParsedTime
+	}
+
+	var modal int64
+	best := 0
+	for step, count := range counts {
+		if count > best || (count == best && step < modal) {
+			modal, best = step, count
+		}
+	}
+	return modal
+}
+
+// ZeroTimePolicy — что делать со свечами без времени (--zero-time)
+type ZeroTimePolicy int
+
+const (
+	// ZeroTimeDrop — удалить свечи без времени
+	ZeroTimeDrop ZeroTimePolicy = iota
+	// ZeroTimeInterpolate — назначить им время по соседним свечам с шагом основного интервала
+	ZeroTimeInterpolate
+)
+
+func (p ZeroTimePolicy) String() string {
+	if p == ZeroTimeInterpolate {
+		return "interpolate"
+	}
+	return "drop"
+}
+
+// ParseZeroTimePolicy — политика из флага --zero-time: drop (по умолчанию) или interpolate
+func ParseZeroTimePolicy(s string) (ZeroTimePolicy, error) {
+	switch s {
+	case "", "drop":
+		return ZeroTimeDrop, nil
+	case "interpolate":
+		return ZeroTimeInterpolate, nil
+	default:
+		return ZeroTimeDrop, fmt.Errorf("неизвестная политика для свечей без времени '%s' (ожидается drop или interpolate)", s)
+	}
+}
+
+// ResolveZeroTimeCandles — убирает из ряда свечи без времени по политике policy и возвращает ряд и число
+// таких свечей. Без этого нулевое время ломает расчет интервала свечей в предсказаниях стратегий.
+// Ряд без единой свечи со временем возвращается как есть: его обрабатывает --allow-bad-time
+func ResolveZeroTimeCandles(candles []Candle, policy ZeroTimePolicy) ([]Candle, int) {
+	var timed []int
+	for i, c := range candles {
+		if !c.ParsedTime.IsZero() {
+			timed = append(timed, i)
+		}
+	}
+	missing := len(candles) - len(timed)
+	if missing == 0 || len(timed) == 0 {
+		return candles, 0
+	}
+
+	if policy == ZeroTimeDrop {
+		kept := make([]Candle, 0, len(timed))
+		for _, i := range timed {
+			kept = append(kept, candles[i])
+		}
+		return kept, missing
+	}
+
+	interval := time.Duration(modalIntervalSeconds(candles)) * time.Second
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	for k, i := range timed {
+		// Свечи без времени перед первой свечой со временем — отсчитываем назад от нее
+		if k == 0 {
+			for j := 0; j < i; j++ {
+				setCandleTime(&candles[j], candles[i].ParsedTime.Add(-time.Duration(i-j)*interval))
+			}
+		}
+
+		// Между соседними свечами со временем шаг не больше основного интервала, но так,
+		// чтобы все вставленные свечи уместились строго до следующей
+		next := len(candles)
+		if k+1 < len(timed) {
+			next = timed[k+1]
+		}
+		if next == i+1 {
+			continue
+		}
+		step := interval
+		if next < len(candles) {
+			step = min(step, candles[next].ParsedTime.Sub(candles[i].ParsedTime)/time.Duration(next-i))
+		}
+		for j := i + 1; j < next; j++ {
+			setCandleTime(&candles[j], candles[i].ParsedTime.Add(time.Duration(j-i)*step))
+		}
+	}
+	return candles, missing
+}
+
+// setCandleTime — назначает свече время вместе с его строковым представлением
+func setCandleTime(c *Candle, t time.Time) {
+	c.ParsedTime = t
+	c.Time = t.Format(time.RFC3339)
+}
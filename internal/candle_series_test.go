@@ -0,0 +1,59 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestValidateCandleSeries_GapsDuplicatesAndZeroTime(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	at := func(minutes int) Candle { return Candle{ParsedTime: start.Add(time.Duration(minutes) * time.Minute)} }
+
+	candles := []Candle{
+		at(0), at(30), at(60), at(90),
+		at(90),  // повтор времени
+		{},      // пустое время
+		at(120), // соседом считается свеча 90 — интервал обычный
+		at(240), // пропуск трех свечей
+		at(270),
+		at(310), // 40 минут — меньше 1.5 интервала, не пропуск
+	}
+
+	interval, gaps := ValidateCandleSeries(candles)
+	if interval != 1800 {
+		t.Fatalf("Expected modal interval 1800s, got %d", interval)
+	}
+
+	expected := []struct {
+		kind  GapKind
+		index int
+	}{{GapDuplicate, 4}, {GapZeroTime, 5}, {GapMissing, 7}}
+	if len(gaps) != len(expected) {
+		t.Fatalf("Expected %d issues, got %+v", len(expected), gaps)
+	}
+	for i, e := range expected {
+		if gaps[i].Kind != e.kind || gaps[i].Index != e.index {
+			t.Errorf("Issue %d: expected %s at candle %d, got %s at %d", i, e.kind, e.index, gaps[i].Kind, gaps[i].Index)
+		}
+	}
+	if gaps[2].Duration() != 2*time.Hour {
+		t.Errorf("Expected 2h gap, got %v", gaps[2].Duration())
+	}
+}
+
+func TestSortCandlesByTime_KeepsZeroTimeInPlace(t *testing.T) {
+	start := time.Date(2024, 1, 1, 10, 0, 0, 0, time.UTC)
+	candles := []Candle{
+		{ParsedTime: start.Add(time.Hour), Close: 2},
+		{ParsedTime: start, Close: 1},
+		{Close: 99}, // без времени
+		{ParsedTime: start.Add(2 * time.Hour), Close: 3},
+	}
+
+	sortCandlesByTime(candles)
+
+	// Свеча без времени не уходит в начало ряда, свечи со временем упорядочены вокруг нее
+	if candles[0].Close != 1 || candles[1].Close != 2 || candles[2].Close != 99 || candles[3].Close != 3 {
+		t.Errorf("Expected closes 1, 2, 99, 3, got %v, %v, %v, %v", candles[0].Close, candles[1].Close, candles[2].Close, candles[3].Close)
+	}
+}
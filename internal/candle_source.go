@@ -0,0 +1,233 @@
+// candle_source.go
+// Источники свечей: бэктестер получает данные через CandleSource, не завися от формата хранения
+package internal
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CandleSource — источник свечей (файл, API, база данных)
+type CandleSource interface {
+	Load() ([]Candle, error)
+}
+
+// StdinPath — путь к данным "-" означает чтение свечей из стандартного ввода
+const StdinPath = "-"
+
+// stdin — стандартный ввод; подменяется в тестах
+var stdin io.Reader = os.Stdin
+
+// openCandleInput — открывает файл со свечами, а для пути "-" — стандартный ввод
+func openCandleInput(path string) (io.ReadCloser, error) {
+	if path == StdinPath {
+		return io.NopCloser(stdin), nil
+	}
+	return os.Open(path)
+}
+
+// NewCandleSource — создает источник по типу из флага --source: json (по умолчанию) или csv
+func NewCandleSource(kind, path string) (CandleSource, error) {
+	switch kind {
+	case "", "json":
+		return &JSONFileSource{Path: path}, nil
+	case "csv":
+		return &CSVFileSource{Path: path}, nil
+	default:
+		return nil, fmt.Errorf("неизвестный источник данных '%s' (ожидается json или csv)", kind)
+	}
+}
+
+// JSONFileSource — файл в формате ответа API: {"candles": [...]}; Path "-" — чтение из stdin
+type JSONFileSource struct {
+	Path string
+}
+
+func (s *JSONFileSource) Load() ([]Candle, error) {
+	f, err := openCandleInput(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	defer f.Close()
+
+	data, err := io.ReadAll(f)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось прочитать файл: %w", err)
+	}
+
+	// Время и объем разбираются в Candle.UnmarshalJSON
+	var wrapper GetCandlesResponse
+	if err := json.Unmarshal(data, &wrapper); err != nil {
+		return nil, fmt.Errorf("ошибка парсинга JSON: %w", err)
+	}
+
+	return normalizeCandles(wrapper.Candles), nil
+}
+
+// CSVFileSource — CSV с заголовком. Обязательные колонки: time (или date/timestamp), open, high, low, close;
+// volume — необязательна; Path "-" — чтение из stdin. Время: RFC3339, "2006-01-02 15:04:05", "2006-01-02" или Unix-время в секундах.
+type CSVFileSource struct {
+	Path string
+}
+
+func (s *CSVFileSource) Load() ([]Candle, error) {
+	f, err := openCandleInput(s.Path)
+	if err != nil {
+		return nil, fmt.Errorf("не удалось открыть файл: %w", err)
+	}
+	defer f.Close()
+
+	reader := csv.NewReader(f)
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("ошибка чтения заголовка CSV: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	timeColumn := -1
+	for _, name := range []string{"time", "date", "timestamp"} {
+		if i, ok := columns[name]; ok {
+			timeColumn = i
+			break
+		}
+	}
+	if timeColumn < 0 {
+		return nil, fmt.Errorf("в CSV нет колонки времени (time, date или timestamp)")
+	}
+	for _, name := range []string{"open", "high", "low", "close"} {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("в CSV нет колонки %s", name)
+		}
+	}
+	volumeColumn, hasVolume := columns["volume"]
+
+	var candles []Candle
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("ошибка чтения CSV (строка %d): %w", line, err)
+		}
+
+		var c Candle
+		var prices [4]float64
+		for i, name := range []string{"open", "high", "low", "close"} {
+			prices[i], err = strconv.ParseFloat(strings.TrimSpace(record[columns[name]]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: некорректное значение %s: %w", line, name, err)
+			}
+		}
+		c.Open, c.High, c.Low, c.Close = Price(prices[0]), Price(prices[1]), Price(prices[2]), Price(prices[3])
+
+		c.ParsedTime, err = parseCSVTime(strings.TrimSpace(record[timeColumn]))
+		if err != nil {
+			return nil, fmt.Errorf("строка %d: %w", line, err)
+		}
+		c.Time = c.ParsedTime.Format(time.RFC3339)
+
+		c.Volume = "0"
+		if hasVolume {
+			volume, err := strconv.ParseFloat(strings.TrimSpace(record[volumeColumn]), 64)
+			if err != nil {
+				return nil, fmt.Errorf("строка %d: некорректный объем: %w", line, err)
+			}
+			c.VolumeFloat = volume
+			c.Volume = strconv.FormatInt(int64(volume), 10)
+		}
+		c.IsComplete = true
+
+		candles = append(candles, c)
+	}
+
+	return normalizeCandles(candles), nil
+}
+
+// parseCSVTime — разбирает время свечи из CSV
+func parseCSVTime(value string) (time.Time, error) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04:05", "2006-01-02T15:04:05", "2006-01-02"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, nil
+		}
+	}
+	if seconds, err := strconv.ParseInt(value, 10, 64); err == nil {
+		return time.Unix(seconds, 0).UTC(), nil
+	}
+	return time.Time{}, fmt.Errorf("некорректное время '%s'", value)
+}
+
+// sortCandlesByTime — сортирует свечи по времени, сохраняя исходный порядок свечей с одинаковым временем.
+// Свечи без времени остаются на своих местах в файле (их не с чем сравнить), сортируются только свечи со временем;
+// о них сообщает ValidateCandleSeries
+func sortCandlesByTime(candles []Candle) {
+	var slots []int
+	var timed []Candle
+	for i, c := range candles {
+		if !c.ParsedTime.IsZero() {
+			slots = append(slots, i)
+			timed = append(timed, c)
+		}
+	}
+	sort.SliceStable(timed, func(i, j int) bool {
+		return timed[i].ParsedTime.Before(timed[j].ParsedTime)
+	})
+	for k, i := range slots {
+		candles[i] = timed[k]
+	}
+}
+
+// normalizeCandles — сортирует свечи, удаляет дубликаты по времени и предупреждает о нарушенном порядке
+func normalizeCandles(candles []Candle) []Candle {
+	sortCandlesByTime(candles)
+
+	candles, dropped := dedupCandlesByTime(candles)
+	if dropped > 0 {
+		Log.Warnf("⚠️ Удалено %d свечей с повторяющимся временем (оставлена последняя из каждой группы)", dropped)
+	}
+	if disordered := countNonMonotonic(candles); disordered > 0 {
+		Log.Warnf("⚠️ После сортировки %d свечей идут не строго по возрастанию времени (пустое или некорректное время)", disordered)
+	}
+	return candles
+}
+
+// dedupCandlesByTime — оставляет последнюю свечу из каждой группы с одинаковым временем (загрузчик свечей
+// запрашивает пересекающиеся диапазоны, и граничные свечи попадают в файл дважды). Свечи без времени
+// и файл, где у всех свечей одно время, не трогаются: их обрабатывает --allow-bad-time
+func dedupCandlesByTime(candles []Candle) ([]Candle, int) {
+	if len(candles) < 2 || candles[0].ParsedTime.Equal(candles[len(candles)-1].ParsedTime) {
+		return candles, 0
+	}
+
+	kept := candles[:0]
+	for i, c := range candles {
+		duplicate := i+1 < len(candles) && !c.ParsedTime.IsZero() && c.ParsedTime.Equal(candles[i+1].ParsedTime)
+		if !duplicate {
+			kept = append(kept, c)
+		}
+	}
+	return kept, len(candles) - len(kept)
+}
+
+// countNonMonotonic — сколько свечей не позже предыдущей (для отсортированного ряда без дубликатов — только пустое время)
+func countNonMonotonic(candles []Candle) int {
+	count := 0
+	for i := 1; i < len(candles); i++ {
+		if !candles[i].ParsedTime.After(candles[i-1].ParsedTime) {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,197 @@
+package internal
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCSVFileSource_Load(t *testing.T) {
+	data := "Date,Open,High,Low,Close,Volume\n" +
+		"2024-01-03,101,103,100,102,1500\n" +
+		"2024-01-02,100,102,99,101,1000\n"
+	filename := filepath.Join(t.TempDir(), "candles.csv")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	source, err := NewCandleSource("csv", filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	candles, err := source.Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("Expected 2 candles, got %d", len(candles))
+	}
+
+	// Свечи отсортированы по времени
+	first := candles[0]
+	if !first.ParsedTime.Equal(time.Date(2024, 1, 2, 0, 0, 0, 0, time.UTC)) {
+		t.Errorf("Expected first candle on 2024-01-02, got %v", first.ParsedTime)
+	}
+	if first.Open != 100 || first.High != 102 || first.Low != 99 || first.Close != 101 {
+		t.Errorf("Unexpected OHLC: O=%.0f H=%.0f L=%.0f C=%.0f", first.Open, first.High, first.Low, first.Close)
+	}
+	if first.VolumeFloat64() != 1000 {
+		t.Errorf("Expected volume 1000, got %.0f", first.VolumeFloat64())
+	}
+}
+
+func TestJSONFileSource_DropsDuplicateTimestamps(t *testing.T) {
+	candle := func(time string, close int, volume string) string {
+		price := fmt.Sprintf(`{"units": "%d", "nano": 0}`, close)
+		return fmt.Sprintf(`{"open": %s, "high": %s, "low": %s, "close": %s, "volume": "%s", "time": "%s", "isComplete": true}`,
+			price, price, price, price, volume, time)
+	}
+	// Пересекающиеся диапазоны загрузчика: свеча 10:30 пришла дважды, вторая копия — обновленная
+	data := `{"candles": [` + strings.Join([]string{
+		candle("2024-01-02T10:00:00Z", 100, "10"),
+		candle("2024-01-02T10:30:00Z", 101, "20"),
+		candle("2024-01-02T10:30:00Z", 102, "25"),
+		candle("2024-01-02T11:00:00Z", 103, "30"),
+	}, ",") + `]}`
+	filename := filepath.Join(t.TempDir(), "candles.json")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	candles, err := (&JSONFileSource{Path: filename}).Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(candles) != 3 {
+		t.Fatalf("Expected 3 candles after dedup, got %d", len(candles))
+	}
+	if candles[1].Close != 102 || candles[1].VolumeFloat64() != 25 {
+		t.Errorf("Expected the last duplicate to be kept, got close %.0f volume %.0f", candles[1].Close, candles[1].VolumeFloat64())
+	}
+	if countNonMonotonic(candles) != 0 {
+		t.Errorf("Expected strictly increasing times after dedup")
+	}
+}
+
+func TestResolveZeroTimeCandles_LoadedSeriesIsMonotonic(t *testing.T) {
+	candle := func(time string, close int) string {
+		price := fmt.Sprintf(`{"units": "%d", "nano": 0}`, close)
+		return fmt.Sprintf(`{"open": %s, "high": %s, "low": %s, "close": %s, "volume": "10", "time": "%s", "isComplete": true}`,
+			price, price, price, price, time)
+	}
+	data := `{"candles": [` + strings.Join([]string{
+		candle("", 99),
+		candle("2024-01-02T10:00:00Z", 100),
+		candle("2024-01-02T10:30:00Z", 101),
+		candle("", 102),
+		candle("", 103),
+		candle("2024-01-02T12:00:00Z", 104),
+	}, ",") + `]}`
+	filename := filepath.Join(t.TempDir(), "candles.json")
+	if err := os.WriteFile(filename, []byte(data), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, policy := range []ZeroTimePolicy{ZeroTimeDrop, ZeroTimeInterpolate} {
+		loaded, err := (&JSONFileSource{Path: filename}).Load()
+		if err != nil {
+			t.Fatalf("Unexpected error: %v", err)
+		}
+		candles, resolved := ResolveZeroTimeCandles(loaded, policy)
+		if resolved != 3 {
+			t.Errorf("%s: expected 3 zero-time candles, got %d", policy, resolved)
+		}
+		if countNonMonotonic(candles) != 0 || candles[0].ParsedTime.IsZero() {
+			t.Errorf("%s: expected strictly increasing times, got %v", policy, candles)
+		}
+
+		switch policy {
+		case ZeroTimeDrop:
+			if len(candles) != 3 {
+				t.Errorf("Expected 3 timed candles after drop, got %d", len(candles))
+			}
+		case ZeroTimeInterpolate:
+			if len(candles) != 6 {
+				t.Fatalf("Expected all 6 candles after interpolate, got %d", len(candles))
+			}
+			start := time.Date(2024, 1, 2, 9, 30, 0, 0, time.UTC)
+			for i, c := range candles {
+				if want := start.Add(time.Duration(i) * 30 * time.Minute); !c.ParsedTime.Equal(want) {
+					t.Errorf("Candle %d (close %.0f): expected %v, got %v", i, c.Close, want, c.ParsedTime)
+				}
+			}
+		}
+	}
+}
+
+func TestJSONFileSource_ReadsStdin(t *testing.T) {
+	price := func(units int) string { return fmt.Sprintf(`{"units": "%d", "nano": 0}`, units) }
+	// Свечи приходят не по порядку — загрузка из stdin сортирует их так же, как из файла
+	data := fmt.Sprintf(`{"candles": [
+		{"open": %[2]s, "high": %[2]s, "low": %[2]s, "close": %[2]s, "volume": "7", "time": "2024-01-02T11:00:00Z", "isComplete": true},
+		{"open": %[1]s, "high": %[1]s, "low": %[1]s, "close": %[1]s, "volume": "5", "time": "2024-01-02T10:00:00Z", "isComplete": true}
+	]}`, price(100), price(101))
+
+	original := stdin
+	stdin = strings.NewReader(data)
+	defer func() { stdin = original }()
+
+	candles, err := (&JSONFileSource{Path: StdinPath}).Load()
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(candles) != 2 {
+		t.Fatalf("Expected 2 candles from stdin, got %d", len(candles))
+	}
+	if candles[0].Close != 100 || candles[1].Close != 101 {
+		t.Errorf("Expected candles sorted by time, got closes %.0f, %.0f", candles[0].Close, candles[1].Close)
+	}
+	if want := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC); !candles[0].ParsedTime.Equal(want) {
+		t.Errorf("Expected ParsedTime %v, got %v", want, candles[0].ParsedTime)
+	}
+}
+
+func TestDedupCandlesByTime_KeepsAllSameTimeFile(t *testing.T) {
+	// Файл, где у всех свечей одно время, обрабатывается --allow-bad-time, а не схлопывается в одну свечу
+	candles := make([]Candle, 5)
+	if kept, dropped := dedupCandlesByTime(candles); len(kept) != 5 || dropped != 0 {
+		t.Errorf("Expected all-same-time candles to be kept, got %d (dropped %d)", len(kept), dropped)
+	}
+}
+
+func TestCSVFileSource_MissingColumn(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "bad.csv")
+	if err := os.WriteFile(filename, []byte("time,open,high,close\n2024-01-02,1,2,1\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := (&CSVFileSource{Path: filename}).Load(); err == nil {
+		t.Error("Expected error for CSV without low column")
+	}
+}
+
+func TestPrice_UnmarshalJSONIsCorrectlyRounded(t *testing.T) {
+	cases := []struct {
+		data string
+		want float64
+	}{
+		{`{"units":"1","nano":140000000}`, 1.14},
+		{`{"units":"1","nano":570000000}`, 1.57},
+		{`{"units":"0","nano":70000000}`, 0.07},
+		{`{"units":"-3","nano":-250000000}`, -3.25},
+		{`{"units":"257","nano":610000000}`, 257.61},
+	}
+
+	for _, c := range cases {
+		var p Price
+		if err := p.UnmarshalJSON([]byte(c.data)); err != nil {
+			t.Fatalf("%s: %v", c.data, err)
+		}
+		if p.ToFloat64() != c.want {
+			t.Errorf("%s: expected %v, got %v", c.data, c.want, p.ToFloat64())
+		}
+	}
+}
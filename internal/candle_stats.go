@@ -0,0 +1,86 @@
+// candle_stats.go
+// Сводка по загруженным свечам: объем данных, интервал, цены, объемы, волатильность и пропуски (флаг --stats)
+package internal
+
+import (
+	"math"
+	"time"
+)
+
+// gapIntervalMultiple — интервал между свечами больше медианного во столько раз считается пропуском
+const gapIntervalMultiple = 2
+
+// CandleStats — описательная статистика ряда свечей
+type CandleStats struct {
+	Count int
+	// First, Last — время первой и последней свечи (нулевое, если времени нет)
+	First, Last time.Time
+	// Interval — медианный интервал между свечами (0, если времени нет)
+	Interval time.Duration
+	// MinPrice, MaxPrice, MeanPrice — по ценам закрытия
+	MinPrice, MaxPrice, MeanPrice float64
+	// AvgDailyVolume — средний суммарный объем за календарный день с данными
+	AvgDailyVolume float64
+	// Volatility — стандартное отклонение доходностей закрытия за бар (0.01 = 1%)
+	Volatility float64
+	// Gaps — сколько раз интервал между соседними свечами превысил медианный более чем вдвое
+	Gaps int
+	// MaxGap — самый длинный интервал между соседними свечами
+	MaxGap time.Duration
+}
+
+// ComputeCandleStats — считает сводку по свечам; свечи должны быть отсортированы по времени
+func ComputeCandleStats(candles []Candle) CandleStats {
+	stats := CandleStats{Count: len(candles)}
+	if len(candles) == 0 {
+		return stats
+	}
+
+	stats.First = candles[0].ParsedTime
+	stats.Last = candles[len(candles)-1].ParsedTime
+	stats.Interval = medianInterval(candles)
+
+	closes := make([]float64, len(candles))
+	stats.MinPrice = math.Inf(1)
+	stats.MaxPrice = math.Inf(-1)
+	sum := 0.0
+	dailyVolume := make(map[string]float64)
+	for i, c := range candles {
+		price := c.Close.ToFloat64()
+		closes[i] = price
+		stats.MinPrice = math.Min(stats.MinPrice, price)
+		stats.MaxPrice = math.Max(stats.MaxPrice, price)
+		sum += price
+		if !c.ParsedTime.IsZero() {
+			dailyVolume[c.ParsedTime.Format("2006-01-02")] += c.VolumeFloat64()
+		}
+	}
+	stats.MeanPrice = sum / float64(len(candles))
+	stats.Volatility = CalculateStdDevOfReturns(closes)
+
+	if len(dailyVolume) > 0 {
+		total := 0.0
+		for _, volume := range dailyVolume {
+			total += volume
+		}
+		stats.AvgDailyVolume = total / float64(len(dailyVolume))
+	}
+
+	if stats.Interval > 0 {
+		for i := 1; i < len(candles); i++ {
+			prev, curr := candles[i-1].ParsedTime, candles[i].ParsedTime
+			if prev.IsZero() || curr.IsZero() {
+				continue
+			}
+			d := curr.Sub(prev)
+			if d > stats.MaxGap {
+				stats.MaxGap = d
+			}
+			if d > gapIntervalMultiple*stats.Interval {
+				stats.Gaps++
+			}
+		}
+	}
+
+	return stats
+}
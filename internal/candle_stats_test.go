@@ -0,0 +1,39 @@
+package internal
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+func TestComputeCandleStats(t *testing.T) {
+	start := time.Date(2024, 1, 2, 10, 0, 0, 0, time.UTC)
+	// Часовые свечи: три в первый день, затем пропуск до следующего дня
+	times := []time.Time{start, start.Add(time.Hour), start.Add(2 * time.Hour), start.Add(24 * time.Hour), start.Add(25 * time.Hour)}
+	closes := []float64{100, 110, 99, 99, 108.9}
+	candles := make([]Candle, len(times))
+	for i := range candles {
+		candles[i] = Candle{Close: Price(closes[i]), ParsedTime: times[i], VolumeFloat: 10}
+	}
+
+	stats := ComputeCandleStats(candles)
+	if stats.Count != 5 || !stats.First.Equal(start) || !stats.Last.Equal(times[4]) {
+		t.Errorf("Unexpected count or range: %+v", stats)
+	}
+	if stats.Interval != time.Hour {
+		t.Errorf("Expected hourly interval, got %v", stats.Interval)
+	}
+	if stats.MinPrice != 99 || stats.MaxPrice != 110 || math.Abs(stats.MeanPrice-103.38) > 1e-9 {
+		t.Errorf("Unexpected prices: min %.2f max %.2f mean %.2f", stats.MinPrice, stats.MaxPrice, stats.MeanPrice)
+	}
+	// 30 единиц объема в первый день и 20 во второй
+	if stats.AvgDailyVolume != 25 {
+		t.Errorf("Expected average daily volume 25, got %.2f", stats.AvgDailyVolume)
+	}
+	if stats.Gaps != 1 || stats.MaxGap != 22*time.Hour {
+		t.Errorf("Expected one 22h gap, got %d gaps, max %v", stats.Gaps, stats.MaxGap)
+	}
+	if stats.Volatility <= 0 {
+		t.Errorf("Expected positive volatility, got %.4f", stats.Volatility)
+	}
+}
@@ -0,0 +1,93 @@
+// category.go
+// Реестр категорий стратегий — общий для принтеров, списка стратегий и фильтрации
+package internal
+
+import "strings"
+
+// Категории стратегий
+const (
+	CategoryWave           = "Волновой анализ"
+	CategoryStatistical    = "Статистические методы"
+	CategoryTrend          = "Трендовые стратегии"
+	CategoryOscillators    = "Осцилляторы"
+	CategoryVolatility     = "Волатильность"
+	CategoryMomentum       = "Моментум"
+	CategoryVolume         = "Объемные стратегии"
+	CategoryExtrema        = "Экстремумы"
+	CategoryMovingAverages = "Скользящие средние"
+	CategorySimple         = "Простые стратегии"
+	CategoryRebalance      = "Ребалансировка"
+	CategorySell           = "Стратегии продажи"
+	CategoryLines          = "Линии поддержки/сопротивления"
+	CategorySpline         = "Сплайн-аппроксимация"
+	CategoryEnsemble       = "Ансамбли стратегий"
+
+	// FallbackCategory — категория для стратегий, которые не удалось классифицировать
+	FallbackCategory = "Прочие стратегии"
+)
+
+// CategorizedStrategy — опциональный интерфейс стратегии, объявляющей свою категорию.
+// Категория запоминается в момент регистрации (RegisterStrategy / RegisterStrategyV2).
+type CategorizedStrategy interface {
+	Category() string
+}
+
+var strategyCategories = make(map[string]string)
+
+// fallbackCategoryMap — сопоставление по подстроке имени для стратегий без Category()
+var fallbackCategoryMap = map[string]string{
+	"elliott_wave":          CategoryWave,
+	"arima":                 CategoryStatistical,
+	"heston":                CategoryStatistical,
+	"golden_cross":          CategoryTrend,
+	"ma_crossover":          CategoryTrend,
+	"supertrend":            CategoryTrend,
+	"fomo":                  CategoryTrend,
+	"rsi_oscillator":        CategoryOscillators,
+	"cci_oscillator":        CategoryOscillators,
+	"stochastic_oscillator": CategoryOscillators,
+	"ao_oscillator":         CategoryOscillators,
+	"qstick_oscillator":     CategoryOscillators,
+	"momentum_breakout":     CategoryVolatility,
+	"bollinger_bands":       CategoryVolatility,
+	"garch_volatility":      CategoryVolatility,
+	"ulcer_index":           CategoryVolatility,
+	"macd":                  CategoryMomentum,
+	"ma_channel":            CategoryMomentum,
+	"volume_breakout":       CategoryVolume,
+	"obv":                   CategoryVolume,
+	"extrema":               CategoryExtrema,
+	"optimal_extrema":       CategoryExtrema,
+	"ma_ema_correlation":    CategoryMovingAverages,
+	"buy_and_hold":          CategorySimple,
+	"monthly_rebalance":     CategoryRebalance,
+	"pullback_sell":         CategorySell,
+	"support_line":          CategoryLines,
+	"wavelet_denoise":       CategoryLines,
+}
+
+// registerCategory — запоминает категорию, объявленную стратегией при регистрации
+func registerCategory(name string, strategy interface{}) {
+	if categorized, ok := strategy.(CategorizedStrategy); ok {
+		if category := categorized.Category(); category != "" {
+			strategyCategories[name] = category
+		}
+	}
+}
+
+// GetStrategyCategory — возвращает категорию стратегии по имени.
+// Сначала используется категория, объявленная стратегией, затем сопоставление по подстроке имени.
+func GetStrategyCategory(name string) string {
+	if category, ok := strategyCategories[name]; ok {
+		return category
+	}
+
+	lowerName := strings.ToLower(name)
+	for key, category := range fallbackCategoryMap {
+		if strings.Contains(lowerName, key) {
+			return category
+		}
+	}
+
+	return FallbackCategory
+}
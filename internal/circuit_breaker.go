@@ -0,0 +1,75 @@
+// circuit_breaker.go
+// Риск-менеджмент: остановка открытия новых позиций при глубокой просадке
+package internal
+
+// circuitBreaker — прерыватель по просадке.
+// Просадка считается по «бумажному» капиталу стратегии, который исполняет все сигналы без ограничений:
+// пока прерыватель сработал, реальный счет стоит в деньгах и его капитал не меняется,
+// поэтому восстановление можно отследить только по тому, как торговала бы сама стратегия.
+type circuitBreaker struct {
+	trip  float64 // просадка от пика (0..1), при которой запрещаются новые входы
+	reset float64 // доля отыгранной просадки (0..1), после которой входы снова разрешены
+
+	halted bool
+	trips  int
+	peak   float64
+	trough float64
+
+	cash     float64
+	holdings float64 // отрицательное количество — короткая позиция
+	shorts   bool    // SELL без позиции открывает короткую позицию, как в движке с AllowShorts
+}
+
+func newCircuitBreaker(trip, reset, initCash float64) *circuitBreaker {
+	return &circuitBreaker{
+		trip:  trip,
+		reset: reset,
+		peak:  initCash,
+		cash:  initCash,
+	}
+}
+
+// update — исполняет сигнал на бумажном счете по ценам с издержками, переоценивает его по цене закрытия
+// и обновляет состояние прерывателя
+func (b *circuitBreaker) update(signal SignalType, buyPrice, sellPrice, closePrice float64) {
+	switch signal {
+	case BUY:
+		if b.holdings < 0 {
+			b.cash += b.holdings * buyPrice
+			b.holdings = 0
+		} else if b.holdings == 0 && b.cash > 0 {
+			b.holdings = b.cash / buyPrice
+			b.cash = 0
+		}
+	case SELL:
+		if b.holdings > 0 {
+			b.cash = b.holdings * sellPrice
+			b.holdings = 0
+		} else if b.holdings == 0 && b.shorts && b.cash > 0 {
+			b.holdings = -b.cash / sellPrice
+			b.cash *= 2
+		}
+	}
+
+	equity := b.cash + b.holdings*closePrice
+	if equity > b.peak {
+		b.peak = equity
+	}
+
+	if !b.halted {
+		if b.peak > 0 && (b.peak-equity)/b.peak >= b.trip {
+			b.halted = true
+			b.trips++
+			b.trough = equity
+		}
+		return
+	}
+
+	if equity < b.trough {
+		b.trough = equity
+	}
+	// Возобновляем торговлю, когда отыграна заданная доля просадки от минимума до пика
+	if equity >= b.trough+b.reset*(b.peak-b.trough) {
+		b.halted = false
+	}
+}
@@ -0,0 +1,101 @@
+// commission.go
+// Модели комиссии брокера: отдельно от проскальзывания, в деньгах за каждую сторону сделки
+package internal
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CommissionModel — комиссия брокера за одну сторону сделки (покупку или продажу) на сумму notional.
+// Процентная комиссия сюда не входит: она учитывается в цене сделки через BacktestOptions.Commission
+type CommissionModel interface {
+	Fee(notional float64) float64
+	String() string
+}
+
+// FixedCommission — фиксированная сумма за сделку независимо от ее размера
+type FixedCommission struct {
+	PerTrade float64
+}
+
+func (c FixedCommission) Fee(notional float64) float64 {
+	return c.PerTrade
+}
+
+func (c FixedCommission) String() string {
+	return fmt.Sprintf("fixed(%.2f)", c.PerTrade)
+}
+
+// CommissionTier — ставка комиссии для сделок от суммы From
+type CommissionTier struct {
+	From float64
+	Rate float64
+}
+
+// TieredCommission — ставка зависит от суммы сделки: действует ступень с наибольшим From, не превышающим сумму
+type TieredCommission struct {
+	Tiers []CommissionTier // по возрастанию From
+}
+
+func (c TieredCommission) Fee(notional float64) float64 {
+	rate := 0.0
+	for _, tier := range c.Tiers {
+		if notional < tier.From {
+			break
+		}
+		rate = tier.Rate
+	}
+	return notional * rate
+}
+
+func (c TieredCommission) String() string {
+	parts := make([]string, len(c.Tiers))
+	for i, tier := range c.Tiers {
+		parts[i] = fmt.Sprintf("от %.0f: %.3f%%", tier.From, tier.Rate*100)
+	}
+	return "tiered(" + strings.Join(parts, ", ") + ")"
+}
+
+// ParseCommissionTiers — разбирает значение флага --commission-tiers вида "0:0.001,100000:0.0005"
+func ParseCommissionTiers(s string) (TieredCommission, error) {
+	var tiers []CommissionTier
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		from, rate, ok := strings.Cut(part, ":")
+		if !ok {
+			return TieredCommission{}, fmt.Errorf("ступень комиссии '%s' должна иметь вид сумма:ставка", part)
+		}
+		tier := CommissionTier{}
+		var err error
+		if tier.From, err = strconv.ParseFloat(strings.TrimSpace(from), 64); err != nil || tier.From < 0 {
+			return TieredCommission{}, fmt.Errorf("некорректная сумма ступени комиссии '%s'", from)
+		}
+		if tier.Rate, err = strconv.ParseFloat(strings.TrimSpace(rate), 64); err != nil || tier.Rate < 0 || tier.Rate >= 1 {
+			return TieredCommission{}, fmt.Errorf("ставка ступени комиссии '%s' должна быть в диапазоне [0, 1)", rate)
+		}
+		tiers = append(tiers, tier)
+	}
+	if len(tiers) == 0 {
+		return TieredCommission{}, fmt.Errorf("не заданы ступени комиссии (ожидается, например, 0:0.001,100000:0.0005)")
+	}
+	sort.Slice(tiers, func(i, j int) bool { return tiers[i].From < tiers[j].From })
+	return TieredCommission{Tiers: tiers}, nil
+}
+
+// fee — комиссия CommissionModel за сторону сделки; не больше самой суммы, чтобы позиция не стала отрицательной
+func (o BacktestOptions) fee(notional float64) float64 {
+	if o.CommissionModel == nil || notional <= 0 {
+		return 0
+	}
+	fee := o.CommissionModel.Fee(notional)
+	if fee > notional {
+		return notional
+	}
+	return fee
+}
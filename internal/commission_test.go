@@ -0,0 +1,72 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestCommissionModels_Fee(t *testing.T) {
+	tiered, err := ParseCommissionTiers("100000:0.0005, 0:0.001")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	tests := []struct {
+		model    CommissionModel
+		notional float64
+		expected float64
+	}{
+		{FixedCommission{PerTrade: 5}, 10000, 5},
+		{FixedCommission{PerTrade: 5}, 100, 5},
+		{tiered, 50000, 50},
+		{tiered, 200000, 100},
+	}
+	for _, tt := range tests {
+		if got := tt.model.Fee(tt.notional); math.Abs(got-tt.expected) > 1e-9 {
+			t.Errorf("%s.Fee(%.0f) = %.4f, expected %.4f", tt.model, tt.notional, got, tt.expected)
+		}
+	}
+
+	for _, bad := range []string{"", "0.001", "0:abc", "-1:0.001", "0:1.5"} {
+		if _, err := ParseCommissionTiers(bad); err == nil {
+			t.Errorf("Expected error for tiers %q", bad)
+		}
+	}
+}
+
+func TestBacktest_CommissionModelDeductsFees(t *testing.T) {
+	candles := []Candle{
+		{Close: Price(100.0)},
+		{Close: Price(110.0)},
+	}
+	signals := []SignalType{BUY, SELL}
+
+	// Без модели комиссии результат не меняется
+	free := BacktestWithOptions(candles, signals, BacktestOptions{})
+	if math.Abs(free.TotalProfit-0.1) > 1e-9 {
+		t.Errorf("Expected +10%% without commission, got %.4f", free.TotalProfit)
+	}
+
+	// Фиксированные 10 за вход и 10 за выход: (10000 - 10) / 100 * 110 - 10
+	fixed := BacktestWithOptions(candles, signals, BacktestOptions{CommissionModel: FixedCommission{PerTrade: 10}})
+	expected := (10000.0-10)/100*110 - 10
+	if math.Abs(fixed.FinalPortfolio-expected) > 1e-6 {
+		t.Errorf("Expected final portfolio %.4f, got %.4f", expected, fixed.FinalPortfolio)
+	}
+	if trade := fixed.Trades[0]; math.Abs(trade.Return-(expected/10000-1)) > 1e-9 {
+		t.Errorf("Expected trade return to include both fees, got %.6f", trade.Return)
+	}
+
+	// Комиссия списывается в дополнение к проскальзыванию
+	both := BacktestWithOptions(candles, signals, BacktestOptions{SlippagePercent: 0.01, CommissionModel: FixedCommission{PerTrade: 10}})
+	expectedBoth := (10000.0-10)/(100*1.01)*110*0.99 - 10
+	if math.Abs(both.FinalPortfolio-expectedBoth) > 1e-6 {
+		t.Errorf("Expected final portfolio %.4f with slippage and commission, got %.4f", expectedBoth, both.FinalPortfolio)
+	}
+
+	// Комиссия больше суммы входа — позиция не открывается
+	tooExpensive := BacktestWithOptions(candles, signals, BacktestOptions{CommissionModel: FixedCommission{PerTrade: 20000}})
+	if tooExpensive.TradeCount != 0 || tooExpensive.FinalPortfolio != 10000 || tooExpensive.FirstEntryIndex != -1 {
+		t.Errorf("Expected no entry when commission exceeds the stake, got %+v", tooExpensive)
+	}
+}
@@ -8,20 +8,37 @@ import (
 	"crypto/md5"
 	"encoding/binary"
 	"encoding/hex"
+	"fmt"
 	"math"
 	"sync"
 )
 
 var Cache sync.Map
 
+// ResetCache — очищает кэш индикаторов и числа баров в году. Ключи учитывают сами данные, поэтому
+// сброс нужен только чтобы кэш не рос, когда прогоны идут на множестве разных наборов свечей
+func ResetCache() {
+	Cache.Clear()
+	resetPeriodsPerYearCache()
+}
+
+// cacheKeyVersion — версия ключей кэша индикаторов; увеличивается при изменении расчета
+// индикаторов или формата ключа, чтобы записи старого формата не подходили к новым
+const cacheKeyVersion = 2
+
 type GridSearchResult struct {
 	X      int     `json:"X"`
 	Y      int     `json:"Y"`
 	Profit float64 `json:"profit"`
 }
 
-func keyFor(typeAlgo string, typeInput string, period int) string {
+// keyFor — ключ кэша индикатора: алгоритм, описание входа с дополнительными параметрами, период
+// и отпечаток данных (FingerprintCandles / fingerprintValues), чтобы разные ряды одной длины не совпадали
+func keyFor(typeAlgo string, typeInput string, period int, data uint64) string {
 	var buf bytes.Buffer
+	if err := binary.Write(&buf, binary.LittleEndian, int32(cacheKeyVersion)); err != nil {
+		panic(err)
+	}
 	algoLen := int32(len(typeAlgo))
 	if err := binary.Write(&buf, binary.LittleEndian, algoLen); err != nil {
 		panic(err)
@@ -39,12 +56,49 @@ func keyFor(typeAlgo string, typeInput string, period int) string {
 	if err := binary.Write(&buf, binary.LittleEndian, int64(period)); err != nil {
 		panic(err)
 	}
+	if err := binary.Write(&buf, binary.LittleEndian, data); err != nil {
+		panic(err)
+	}
 
 	hash := md5.Sum(buf.Bytes())
 	return hex.EncodeToString(hash[:])
 
 }
 
+// Отпечатки рядов — FNV-1a по 64-битным словам с перемешиванием: побайтовый hash/fnv заметно
+// замедляет попадания в кэш на длинных рядах
+const (
+	fnvOffset64 = 14695981039346656037
+	fnvPrime64  = 1099511628211
+)
+
+func fnvMix(h, word uint64) uint64 {
+	h = (h ^ word) * fnvPrime64
+	return h ^ h>>32
+}
+
+// fingerprintValues — отпечаток длины и всех значений ряда
+func fingerprintValues(values []float64) uint64 {
+	h := fnvMix(fnvOffset64, uint64(len(values)))
+	for _, v := range values {
+		h = fnvMix(h, math.Float64bits(v))
+	}
+	return h
+}
+
+// FingerprintCandles — отпечаток длины и OHLCV всех свечей
+func FingerprintCandles(candles []Candle) uint64 {
+	h := fnvMix(fnvOffset64, uint64(len(candles)))
+	for _, c := range candles {
+		h = fnvMix(h, math.Float64bits(c.Open.ToFloat64()))
+		h = fnvMix(h, math.Float64bits(c.High.ToFloat64()))
+		h = fnvMix(h, math.Float64bits(c.Low.ToFloat64()))
+		h = fnvMix(h, math.Float64bits(c.Close.ToFloat64()))
+		h = fnvMix(h, math.Float64bits(c.VolumeFloat))
+	}
+	return h
+}
+
 // calculateSMACommon вычисляет простую скользящую среднюю
 func CalculateSMACommon(candles []Candle, period int) []float64 {
 	if len(candles) < period {
@@ -69,7 +123,7 @@ func CalculateSMACommon(candles []Candle, period int) []float64 {
 
 // calculateRSICommon вычисляет RSI
 func CalculateRSICommon(candles []Candle, period int) []float64 {
-	key := keyFor("RSI", "candles", period)
+	key := keyFor("RSI", "candles", period, FingerprintCandles(candles))
 	if cached, ok := Cache.Load(key); ok {
 		return cached.([]float64)
 	}
@@ -163,6 +217,57 @@ func CalculateOBV(candles []Candle) []float64 {
 	return obv
 }
 
+// CalculateVWAP вычисляет накопительную средневзвешенную по объему цену (VWAP) с первой свечи:
+// сумма типичной цены (High+Low+Close)/3 × объем, деленная на накопленный объем.
+// Пока накопленный объем нулевой, значение равно 0
+func CalculateVWAP(candles []Candle) []float64 {
+	vwap := make([]float64, len(candles))
+	var priceVolume, volume float64
+	for i, c := range candles {
+		v := c.VolumeFloat64()
+		priceVolume += typicalPrice(c) * v
+		volume += v
+		if volume > 0 {
+			vwap[i] = priceVolume / volume
+		}
+	}
+	return vwap
+}
+
+// CalculateRollingVWAP вычисляет VWAP в скользящем окне period свечей.
+// Первые period−1 значений не определены и равны 0, как и значения для окон с нулевым объемом
+func CalculateRollingVWAP(candles []Candle, period int) []float64 {
+	if period <= 0 || len(candles) < period {
+		return nil
+	}
+
+	key := keyFor("RollingVWAP", "candles", period, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		return cached.([]float64)
+	}
+
+	vwap := make([]float64, len(candles))
+	for i := period - 1; i < len(candles); i++ {
+		var priceVolume, volume float64
+		for _, c := range candles[i-period+1 : i+1] {
+			v := c.VolumeFloat64()
+			priceVolume += typicalPrice(c) * v
+			volume += v
+		}
+		if volume > 0 {
+			vwap[i] = priceVolume / volume
+		}
+	}
+
+	Cache.Store(key, vwap)
+	return vwap
+}
+
+// typicalPrice — типичная цена свечи (High+Low+Close)/3
+func typicalPrice(c Candle) float64 {
+	return (c.High.ToFloat64() + c.Low.ToFloat64() + c.Close.ToFloat64()) / 3
+}
+
 // avgCommon вычисляет среднее значение
 func avgCommon(xs []float64) float64 {
 	if len(xs) == 0 {
@@ -269,7 +374,7 @@ func CalculateStochastic(candles []Candle, kPeriod, dPeriod int) ([]float64, []f
 
 // calculateSMACommonForValues вычисляет SMA для массива значений
 func CalculateSMACommonForValues(values []float64, period int) []float64 {
-	key := keyFor("SMA", "values", period)
+	key := keyFor("SMA", "values", period, fingerprintValues(values))
 	if cached, ok := Cache.Load(key); ok {
 		return cached.([]float64)
 	}
@@ -297,7 +402,7 @@ func CalculateSMACommonForValues(values []float64, period int) []float64 {
 
 // calculateVolatilityQstick рассчитывает волатильность цены за период
 func CalculateVolatilityQstick(candles []Candle, period int) []float64 {
-	key := keyFor("VolatilityQStick", "candles", period)
+	key := keyFor("VolatilityQStick", "candles", period, FingerprintCandles(candles))
 	if cached, ok := Cache.Load(key); ok {
 		return cached.([]float64)
 	}
@@ -487,6 +592,48 @@ func CalculateRollingCorrelation(x, y []float64, period int) []float64 {
 	return correlations
 }
 
+// LinearRegression вычисляет регрессию y по индексу бара x = 0..len(y)-1 методом наименьших квадратов.
+// Возвращает наклон, значение прямой в x = 0 и коэффициент детерминации R² (0 для постоянного ряда).
+// Для одной точки наклон равен 0, а intercept — самой точке
+func LinearRegression(y []float64) (slope, intercept, r2 float64) {
+	if len(y) == 0 {
+		return 0, 0, 0
+	}
+	n := float64(len(y))
+	if len(y) < 2 {
+		return 0, y[0], 0
+	}
+
+	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
+	for i, yi := range y {
+		xi := float64(i)
+		sumX += xi
+		sumY += yi
+		sumXY += xi * yi
+		sumXX += xi * xi
+	}
+
+	denominator := n*sumXX - sumX*sumX
+	if math.Abs(denominator) < 1e-10 {
+		return 0, sumY / n, 0
+	}
+	slope = (n*sumXY - sumX*sumY) / denominator
+	intercept = (sumY - slope*sumX) / n
+
+	mean := sumY / n
+	ssRes, ssTot := 0.0, 0.0
+	for i, yi := range y {
+		residual := yi - (slope*float64(i) + intercept)
+		ssRes += residual * residual
+		ssTot += (yi - mean) * (yi - mean)
+	}
+	if ssTot > 0 {
+		r2 = 1 - ssRes/ssTot
+	}
+
+	return slope, intercept, r2
+}
+
 // calculateMeanStd вычисляет среднее значение и стандартное отклонение массива
 func calculateMeanStd(data []float64) (float64, float64) {
 	if len(data) == 0 {
@@ -508,7 +655,7 @@ func calculateMeanStd(data []float64) (float64, float64) {
 
 // CalculateRollingStdDevOfReturns вычисляет скользящую волатильность как стандартное отклонение доходностей
 func CalculateRollingStdDevOfReturns(prices []float64, period int) []float64 {
-	key := keyFor("Rstd", "values", period)
+	key := keyFor("Rstd", "values", period, fingerprintValues(prices))
 	if cached, ok := Cache.Load(key); ok {
 		return cached.([]float64)
 	}
@@ -546,3 +693,501 @@ func CalculateStdDevOfReturns(prices []float64) float64 {
 	_, stdDev := calculateMeanStd(returns)
 	return stdDev
 }
+
+// CalculateDownsideDeviation вычисляет нисходящее отклонение доходностей: среднеквадратичное отклонение вниз
+// от targetReturn, где доходности выше цели считаются нулевыми, а делитель — число всех доходностей
+// (знаменатель Sortino, см. calculateSortinoRatio). Рост цены волатильность не увеличивает
+func CalculateDownsideDeviation(prices []float64, targetReturn float64) float64 {
+	if len(prices) < 2 {
+		return 0
+	}
+	returns := make([]float64, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		returns[i-1] = (prices[i] - prices[i-1]) / prices[i-1]
+	}
+	return downsideDeviationOfReturns(returns, targetReturn)
+}
+
+// downsideDeviationOfReturns — нисходящее отклонение уже посчитанных доходностей от targetReturn
+func downsideDeviationOfReturns(returns []float64, targetReturn float64) float64 {
+	if len(returns) == 0 {
+		return 0
+	}
+	sumSquares := 0.0
+	for _, r := range returns {
+		if r < targetReturn {
+			sumSquares += (r - targetReturn) * (r - targetReturn)
+		}
+	}
+	return math.Sqrt(sumSquares / float64(len(returns)))
+}
+
+// CalculateReturnVolatilityBands вычисляет процентные полосы волатильности вокруг EMA.
+// Ширина полос задаётся стандартным отклонением доходностей, поэтому полосы масштабируются вместе с ценой:
+// верхняя = EMA × (1 + mult × σ), нижняя = EMA × (1 − mult × σ)
+func CalculateReturnVolatilityBands(prices []float64, period int, mult float64) (upper, middle, lower []float64) {
+	if period <= 0 || len(prices) < period+1 {
+		return nil, nil, nil
+	}
+
+	middle = CalculateEMAForValues(prices, period)
+	volatility := CalculateRollingStdDevOfReturns(prices, period)
+	if middle == nil || volatility == nil {
+		return nil, nil, nil
+	}
+
+	upper = make([]float64, len(prices))
+	lower = make([]float64, len(prices))
+
+	// Полосы определены только там, где есть и EMA, и волатильность
+	for i := period; i < len(prices); i++ {
+		if middle[i] == 0 || volatility[i] == 0 {
+			continue
+		}
+		width := mult * volatility[i]
+		upper[i] = middle[i] * (1 + width)
+		lower[i] = middle[i] * (1 - width)
+	}
+
+	return upper, middle, lower
+}
+
+// CalculateBollingerBands вычисляет полосы Боллинджера: средняя — SMA закрытий за period,
+// верхняя и нижняя — ± numStdDev стандартных отклонений закрытий в том же окне.
+// Первые period−1 значений не определены и равны 0
+func CalculateBollingerBands(candles []Candle, period int, numStdDev float64) (upper, middle, lower []float64) {
+	if period <= 0 || len(candles) < period {
+		return nil, nil, nil
+	}
+
+	key := keyFor("Bollinger", fmt.Sprintf("candles:%g", numStdDev), period, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		bands := cached.([3][]float64)
+		return bands[0], bands[1], bands[2]
+	}
+
+	middle = CalculateSMACommon(candles, period)
+	upper = make([]float64, len(candles))
+	lower = make([]float64, len(candles))
+
+	for i := period - 1; i < len(candles); i++ {
+		var sumSquares float64
+		for j := i - period + 1; j <= i; j++ {
+			diff := candles[j].Close.ToFloat64() - middle[i]
+			sumSquares += diff * diff
+		}
+		width := numStdDev * math.Sqrt(sumSquares/float64(period))
+		upper[i] = middle[i] + width
+		lower[i] = middle[i] - width
+	}
+
+	Cache.Store(key, [3][]float64{upper, middle, lower})
+	return upper, middle, lower
+}
+
+// CalculateCCI вычисляет Commodity Channel Index: отклонение типичной цены (High+Low+Close)/3
+// от ее SMA за period, деленное на 0.015 × среднее абсолютное отклонение в том же окне.
+// Первые period−1 значений не определены и равны 0; при нулевом отклонении CCI равен 0
+func CalculateCCI(candles []Candle, period int) []float64 {
+	if period <= 0 || len(candles) < period {
+		return nil
+	}
+
+	key := keyFor("CCI", "candles", period, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		return cached.([]float64)
+	}
+
+	typicalPrices := make([]float64, len(candles))
+	for i, c := range candles {
+		typicalPrices[i] = typicalPrice(c)
+	}
+
+	cci := make([]float64, len(candles))
+	for i := period - 1; i < len(candles); i++ {
+		window := typicalPrices[i-period+1 : i+1]
+
+		var tpSum float64
+		for _, tp := range window {
+			tpSum += tp
+		}
+		ma := tpSum / float64(period)
+
+		var mdSum float64
+		for _, tp := range window {
+			mdSum += math.Abs(tp - ma)
+		}
+		meanDeviation := mdSum / float64(period)
+
+		if meanDeviation != 0 {
+			cci[i] = (typicalPrices[i] - ma) / (0.015 * meanDeviation)
+		}
+	}
+
+	Cache.Store(key, cci)
+	return cci
+}
+
+// CalculateTrueRange вычисляет истинный диапазон (True Range) каждой свечи — основу ATR, ADX и Vortex.
+// Для первой свечи предыдущего закрытия нет, поэтому используется High − Low
+func CalculateTrueRange(candles []Candle) []float64 {
+	trueRanges := make([]float64, len(candles))
+	for i := range candles {
+		high := candles[i].High.ToFloat64()
+		low := candles[i].Low.ToFloat64()
+		if i == 0 {
+			trueRanges[i] = high - low
+			continue
+		}
+		prevClose := candles[i-1].Close.ToFloat64()
+		trueRanges[i] = math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
+	}
+	return trueRanges
+}
+
+// CalculateATR вычисляет Average True Range со сглаживанием Уайлдера: первое значение (на баре period) —
+// среднее True Range со второй по (period+1)-ю свечу, дальше ATR = (ATR₋₁×(period−1) + TR) / period.
+// Первые period значений не определены и равны 0 (см. ATRWarmup)
+func CalculateATR(candles []Candle, period int) []float64 {
+	if period <= 0 || len(candles) < period+1 {
+		return nil
+	}
+
+	key := keyFor("ATR", "candles", period, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		return cached.([]float64)
+	}
+
+	trueRanges := CalculateTrueRange(candles)
+	atr := make([]float64, len(candles))
+
+	sum := 0.0
+	for i := 1; i <= period; i++ {
+		sum += trueRanges[i]
+	}
+	atr[period] = sum / float64(period)
+
+	for i := period + 1; i < len(candles); i++ {
+		atr[i] = (atr[i-1]*float64(period-1) + trueRanges[i]) / float64(period)
+	}
+
+	Cache.Store(key, atr)
+	return atr
+}
+
+// CalculateKeltnerChannels вычисляет каналы Кельтнера: средняя — EMA закрытий за emaPeriod,
+// верхняя и нижняя — ± multiplier × ATR(atrPeriod). Значения до прогрева обоих индикаторов
+// (первые max(emaPeriod−1, atrPeriod)) не определены и равны 0
+func CalculateKeltnerChannels(candles []Candle, emaPeriod, atrPeriod int, multiplier float64) (upper, middle, lower []float64) {
+	if emaPeriod <= 0 || atrPeriod <= 0 || len(candles) < max(emaPeriod, atrPeriod+1) {
+		return nil, nil, nil
+	}
+
+	key := keyFor("Keltner", fmt.Sprintf("candles:%d:%g", atrPeriod, multiplier), emaPeriod, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		bands := cached.([3][]float64)
+		return bands[0], bands[1], bands[2]
+	}
+
+	prices := make([]float64, len(candles))
+	for i, candle := range candles {
+		prices[i] = candle.Close.ToFloat64()
+	}
+	ema := CalculateEMAForValues(prices, emaPeriod)
+	atr := CalculateATR(candles, atrPeriod)
+
+	upper = make([]float64, len(candles))
+	middle = make([]float64, len(candles))
+	lower = make([]float64, len(candles))
+	for i := max(emaPeriod-1, atrPeriod); i < len(candles); i++ {
+		middle[i] = ema[i]
+		upper[i] = ema[i] + multiplier*atr[i]
+		lower[i] = ema[i] - multiplier*atr[i]
+	}
+
+	Cache.Store(key, [3][]float64{upper, middle, lower})
+	return upper, middle, lower
+}
+
+// CalculateADX вычисляет Average Directional Index (сглаживание Уайлдера).
+// Первые 2×period−1 значений не определены и равны 0
+func CalculateADX(candles []Candle, period int) []float64 {
+	if period <= 0 || len(candles) < 2*period {
+		return nil
+	}
+
+	trueRanges := CalculateTrueRange(candles)
+	plusDM := make([]float64, len(candles))
+	minusDM := make([]float64, len(candles))
+
+	for i := 1; i < len(candles); i++ {
+		high := candles[i].High.ToFloat64()
+		low := candles[i].Low.ToFloat64()
+		prevHigh := candles[i-1].High.ToFloat64()
+		prevLow := candles[i-1].Low.ToFloat64()
+
+		upMove := high - prevHigh
+		downMove := prevLow - low
+		if upMove > downMove && upMove > 0 {
+			plusDM[i] = upMove
+		}
+		if downMove > upMove && downMove > 0 {
+			minusDM[i] = downMove
+		}
+	}
+
+	// Начальные суммы за первый период
+	var smoothedTR, smoothedPlusDM, smoothedMinusDM float64
+	for i := 1; i <= period; i++ {
+		smoothedTR += trueRanges[i]
+		smoothedPlusDM += plusDM[i]
+		smoothedMinusDM += minusDM[i]
+	}
+
+	dx := make([]float64, len(candles))
+	for i := period; i < len(candles); i++ {
+		if i > period {
+			smoothedTR = smoothedTR - smoothedTR/float64(period) + trueRanges[i]
+			smoothedPlusDM = smoothedPlusDM - smoothedPlusDM/float64(period) + plusDM[i]
+			smoothedMinusDM = smoothedMinusDM - smoothedMinusDM/float64(period) + minusDM[i]
+		}
+
+		if smoothedTR == 0 {
+			continue
+		}
+		plusDI := 100 * smoothedPlusDM / smoothedTR
+		minusDI := 100 * smoothedMinusDM / smoothedTR
+		if plusDI+minusDI == 0 {
+			continue
+		}
+		dx[i] = 100 * math.Abs(plusDI-minusDI) / (plusDI + minusDI)
+	}
+
+	adx := make([]float64, len(candles))
+	first := 2*period - 1
+
+	// Первое значение ADX — среднее DX за период
+	sum := 0.0
+	for i := period; i <= first; i++ {
+		sum += dx[i]
+	}
+	adx[first] = sum / float64(period)
+
+	for i := first + 1; i < len(candles); i++ {
+		adx[i] = (adx[i-1]*float64(period-1) + dx[i]) / float64(period)
+	}
+
+	return adx
+}
+
+// CalculateVortex вычисляет индикатор Vortex (VI+ и VI−).
+// VM+ = |High − предыдущий Low|, VM− = |Low − предыдущий High|;
+// VI± = сумма VM± за период / сумма True Range за период. Первые period значений равны 0
+func CalculateVortex(candles []Candle, period int) (viPlus, viMinus []float64) {
+	key := keyFor("Vortex", "candles", period, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		vi := cached.([2][]float64)
+		return vi[0], vi[1]
+	}
+
+	if period <= 0 || len(candles) <= period {
+		return nil, nil
+	}
+
+	trueRanges := CalculateTrueRange(candles)
+	vmPlus := make([]float64, len(candles))
+	vmMinus := make([]float64, len(candles))
+	for i := 1; i < len(candles); i++ {
+		vmPlus[i] = math.Abs(candles[i].High.ToFloat64() - candles[i-1].Low.ToFloat64())
+		vmMinus[i] = math.Abs(candles[i].Low.ToFloat64() - candles[i-1].High.ToFloat64())
+	}
+
+	viPlus = make([]float64, len(candles))
+	viMinus = make([]float64, len(candles))
+
+	// Скользящие суммы за окно (i-period, i]
+	var sumTR, sumPlus, sumMinus float64
+	for i := 1; i < len(candles); i++ {
+		sumTR += trueRanges[i]
+		sumPlus += vmPlus[i]
+		sumMinus += vmMinus[i]
+		if i > period {
+			sumTR -= trueRanges[i-period]
+			sumPlus -= vmPlus[i-period]
+			sumMinus -= vmMinus[i-period]
+		}
+
+		if i >= period && sumTR > 0 {
+			viPlus[i] = sumPlus / sumTR
+			viMinus[i] = sumMinus / sumTR
+		}
+	}
+
+	Cache.Store(key, [2][]float64{viPlus, viMinus})
+	return viPlus, viMinus
+}
+
+// CalculateWMA вычисляет взвешенную скользящую среднюю с линейными весами 1..period
+// (последнее значение окна имеет вес period). Первые period−1 значений равны 0
+func CalculateWMA(values []float64, period int) []float64 {
+	if period <= 0 || len(values) < period {
+		return nil
+	}
+
+	wma := make([]float64, len(values))
+	denominator := float64(period*(period+1)) / 2
+
+	for i := period - 1; i < len(values); i++ {
+		sum := 0.0
+		for j := 0; j < period; j++ {
+			sum += values[i-period+1+j] * float64(j+1)
+		}
+		wma[i] = sum / denominator
+	}
+
+	return wma
+}
+
+// CalculateHMA вычисляет скользящую среднюю Халла: WMA(2×WMA(n/2) − WMA(n), √n).
+// Первое определенное значение — на индексе period + ⌊√period⌋ − 2, до него значения равны 0
+func CalculateHMA(prices []float64, period int) []float64 {
+	if period < 2 {
+		return nil
+	}
+	halfPeriod := period / 2
+	sqrtPeriod := int(math.Sqrt(float64(period)))
+
+	start := period - 1
+	if len(prices) < start+sqrtPeriod {
+		return nil
+	}
+
+	wmaHalf := CalculateWMA(prices, halfPeriod)
+	wmaFull := CalculateWMA(prices, period)
+
+	// Разностный ряд определен начиная с индекса period−1
+	diff := make([]float64, len(prices)-start)
+	for i := start; i < len(prices); i++ {
+		diff[i-start] = 2*wmaHalf[i] - wmaFull[i]
+	}
+
+	smoothed := CalculateWMA(diff, sqrtPeriod)
+	hma := make([]float64, len(prices))
+	for i := sqrtPeriod - 1; i < len(smoothed); i++ {
+		hma[start+i] = smoothed[i]
+	}
+
+	return hma
+}
+
+// CalculateStochRSI вычисляет Stochastic RSI: стохастик, примененный к значениям RSI.
+// Сырое значение = 100 × (RSI − min RSI) / (max RSI − min RSI) за stochPeriod,
+// %K — SMA сырого значения за kSmooth, %D — SMA %K за dSmooth. До прогрева значения равны 0
+func CalculateStochRSI(candles []Candle, rsiPeriod, stochPeriod, kSmooth, dSmooth int) ([]float64, []float64) {
+	key := keyFor("StochRSI", fmt.Sprintf("candles:%d:%d:%d", stochPeriod, kSmooth, dSmooth), rsiPeriod, FingerprintCandles(candles))
+	if cached, ok := Cache.Load(key); ok {
+		stoch := cached.([2][]float64)
+		return stoch[0], stoch[1]
+	}
+
+	if rsiPeriod <= 0 || stochPeriod <= 0 || kSmooth <= 0 || dSmooth <= 0 {
+		return nil, nil
+	}
+
+	rsi := CalculateRSICommon(candles, rsiPeriod)
+	if rsi == nil {
+		return nil, nil
+	}
+
+	rawStart := rsiPeriod + stochPeriod - 1
+	kStart := rawStart + kSmooth - 1
+	dStart := kStart + dSmooth - 1
+	if len(candles) <= dStart {
+		return nil, nil
+	}
+
+	raw := make([]float64, len(candles))
+	for i := rawStart; i < len(candles); i++ {
+		lowest, highest := rsi[i], rsi[i]
+		for j := i - stochPeriod + 1; j < i; j++ {
+			lowest = math.Min(lowest, rsi[j])
+			highest = math.Max(highest, rsi[j])
+		}
+
+		if highest-lowest == 0 {
+			raw[i] = 50 // нейтральное значение, если RSI не менялся
+		} else {
+			raw[i] = 100 * (rsi[i] - lowest) / (highest - lowest)
+		}
+	}
+
+	// Сглаживание считаем только по определенным значениям, без кэшируемой SMA по массиву
+	smooth := func(values []float64, start, period int) []float64 {
+		out := make([]float64, len(values))
+		for i := start + period - 1; i < len(values); i++ {
+			sum := 0.0
+			for j := i - period + 1; j <= i; j++ {
+				sum += values[j]
+			}
+			out[i] = sum / float64(period)
+		}
+		return out
+	}
+
+	kValues := smooth(raw, rawStart, kSmooth)
+	dValues := smooth(kValues, kStart, dSmooth)
+
+	Cache.Store(key, [2][]float64{kValues, dValues})
+	return kValues, dValues
+}
+
+// CalculateUlcerIndex вычисляет Ulcer Index: среднеквадратичную просадку от скользящего максимума за period баров.
+// Просадка бара j считается от максимума цен за period баров до j включительно, индекс бара i — корень из
+// среднего квадрата просадок за period баров до i; результат в долях (0.05 = 5%), первые period-1 значений — 0
+func CalculateUlcerIndex(prices []float64, period int) []float64 {
+	key := keyFor("Ulcer", "values", period, fingerprintValues(prices))
+	if cached, ok := Cache.Load(key); ok {
+		return cached.([]float64)
+	}
+
+	if period <= 0 || len(prices) < period {
+		return nil
+	}
+	ulcer := ulcerIndex(prices, period)
+
+	Cache.Store(key, ulcer)
+	return ulcer
+}
+
+// ulcerIndex — расчет CalculateUlcerIndex без кэша (для кривых капитала, которые различаются от прогона к прогону).
+// Скользящий максимум — через монотонную очередь, сумма квадратов — скользящая, поэтому расчет линейный
+func ulcerIndex(prices []float64, period int) []float64 {
+	ulcer := make([]float64, len(prices))
+	squared := make([]float64, len(prices))
+	var maxQueue []int // индексы с убывающими ценами; в голове — максимум окна
+	sum := 0.0
+
+	for j, price := range prices {
+		for len(maxQueue) > 0 && prices[maxQueue[len(maxQueue)-1]] <= price {
+			maxQueue = maxQueue[:len(maxQueue)-1]
+		}
+		maxQueue = append(maxQueue, j)
+		if maxQueue[0] <= j-period {
+			maxQueue = maxQueue[1:]
+		}
+
+		if peak := prices[maxQueue[0]]; peak > 0 {
+			drawdown := (price - peak) / peak
+			squared[j] = drawdown * drawdown
+		}
+		sum += squared[j]
+		if j >= period {
+			sum -= squared[j-period]
+		}
+		if j >= period-1 {
+			ulcer[j] = math.Sqrt(math.Max(0, sum) / float64(period))
+		}
+	}
+
+	return ulcer
+}
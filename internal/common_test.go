@@ -0,0 +1,344 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestCalculateWMA_LinearWeights(t *testing.T) {
+	values := []float64{1, 2, 3, 4, 5}
+
+	wma := CalculateWMA(values, 3)
+	if wma == nil {
+		t.Fatal("Expected WMA values")
+	}
+
+	// Первые period−1 значений не определены
+	if wma[0] != 0 || wma[1] != 0 {
+		t.Errorf("Expected zero warm-up values, got %.4f, %.4f", wma[0], wma[1])
+	}
+
+	// (1×1 + 2×2 + 3×3) / 6 — последнее значение окна имеет наибольший вес
+	if expected := 14.0 / 6.0; math.Abs(wma[2]-expected) > 1e-9 {
+		t.Errorf("Expected WMA %.4f at index 2, got %.4f", expected, wma[2])
+	}
+	if expected := (3.0*1 + 4*2 + 5*3) / 6; math.Abs(wma[4]-expected) > 1e-9 {
+		t.Errorf("Expected WMA %.4f at index 4, got %.4f", expected, wma[4])
+	}
+
+	// Веса смещают среднее к последним значениям сильнее, чем SMA
+	if wma[4] <= 4 {
+		t.Errorf("Expected WMA above SMA (4) on rising series, got %.4f", wma[4])
+	}
+
+	if CalculateWMA(values, 6) != nil {
+		t.Error("Expected nil for period longer than data")
+	}
+}
+
+func TestCalculateATR_WilderSmoothing(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	// Диапазоны свечей 2, 2, 4, 2 с гэпом вверх на третьей: True Range = 2, 2, 5, 2
+	candles := []Candle{
+		{High: 11, Low: 9, Close: 10},
+		{High: 11, Low: 9, Close: 10},
+		{High: 15, Low: 11, Close: 14},
+		{High: 15, Low: 13, Close: 14},
+	}
+
+	atr := CalculateATR(candles, 2)
+	if atr == nil {
+		t.Fatal("Expected ATR values")
+	}
+	if atr[0] != 0 || atr[1] != 0 {
+		t.Errorf("Expected zero warm-up values, got %.4f, %.4f", atr[0], atr[1])
+	}
+	// Затравка — среднее TR второй и третьей свечей, без первой, у которой нет предыдущего закрытия
+	if expected := (2.0 + 5.0) / 2; math.Abs(atr[ATRWarmup(2)]-expected) > 1e-9 {
+		t.Errorf("Expected first ATR %.4f, got %.4f", expected, atr[2])
+	}
+	if expected := (3.5*1 + 2) / 2; math.Abs(atr[3]-expected) > 1e-9 {
+		t.Errorf("Expected Wilder-smoothed ATR %.4f, got %.4f", expected, atr[3])
+	}
+
+	ResetCache()
+	if CalculateATR(candles, 4) != nil {
+		t.Error("Expected nil when there are fewer than period+1 candles")
+	}
+}
+
+func TestCalculateBollingerBands_StdDevOfCloses(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	candles := []Candle{{Close: 2}, {Close: 4}, {Close: 4}, {Close: 4}, {Close: 5}, {Close: 5}, {Close: 7}, {Close: 9}}
+
+	upper, middle, lower := CalculateBollingerBands(candles, 8, 2)
+	if upper == nil {
+		t.Fatal("Expected Bollinger bands")
+	}
+	if upper[6] != 0 || middle[6] != 0 || lower[6] != 0 {
+		t.Errorf("Expected zero warm-up values, got %.4f, %.4f, %.4f", upper[6], middle[6], lower[6])
+	}
+	// Среднее 5, стандартное отклонение 2: полосы 5 ± 2×2
+	if math.Abs(middle[7]-5) > 1e-9 || math.Abs(upper[7]-9) > 1e-9 || math.Abs(lower[7]-1) > 1e-9 {
+		t.Errorf("Expected bands 9/5/1, got %.4f/%.4f/%.4f", upper[7], middle[7], lower[7])
+	}
+
+	// Множитель входит в ключ кэша
+	if wider, _, _ := CalculateBollingerBands(candles, 8, 3); math.Abs(wider[7]-11) > 1e-9 {
+		t.Errorf("Expected upper band 11 for 3σ, got %.4f", wider[7])
+	}
+}
+
+func TestIndicatorCache_KeysIncludeInputData(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	first := []float64{1, 2, 3, 4, 5}
+	second := []float64{10, 20, 30, 40, 50}
+
+	if sma := CalculateSMACommonForValues(first, 3); sma[4] != 4 {
+		t.Fatalf("Expected SMA 4, got %.4f", sma[4])
+	}
+	if sma := CalculateSMACommonForValues(second, 3); sma[4] != 40 {
+		t.Errorf("Expected SMA 40 for a different slice of the same length, got stale %.4f", sma[4])
+	}
+
+	rising := []Candle{{Close: 1}, {Close: 2}, {Close: 3}, {Close: 4}}
+	falling := []Candle{{Close: 4}, {Close: 3}, {Close: 2}, {Close: 1}}
+	if up, down := CalculateRSICommon(rising, 2), CalculateRSICommon(falling, 2); up[3] == down[3] {
+		t.Errorf("Expected different RSI for different candles, got %.2f for both", up[3])
+	}
+}
+
+func TestCalculateCCI_TypicalPriceDeviation(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	candles := []Candle{
+		{High: 2, Low: 0, Close: 1}, // типичные цены 1, 2, 3, 3, 3
+		{High: 3, Low: 1, Close: 2},
+		{High: 4, Low: 2, Close: 3},
+		{High: 3, Low: 3, Close: 3},
+		{High: 3, Low: 3, Close: 3},
+	}
+
+	if CalculateCCI(candles[:2], 3) != nil {
+		t.Error("Expected nil CCI for fewer candles than period")
+	}
+
+	cci := CalculateCCI(candles, 3)
+	if cci[0] != 0 || cci[1] != 0 {
+		t.Errorf("Expected zero warm-up values, got %.4f, %.4f", cci[0], cci[1])
+	}
+	// Окно 1, 2, 3: SMA 2, среднее отклонение 2/3 → (3 − 2) / (0.015 × 2/3) = 100
+	if math.Abs(cci[2]-100) > 1e-9 {
+		t.Errorf("Expected CCI 100, got %.4f", cci[2])
+	}
+	// Окно 2, 3, 3: SMA 8/3, среднее отклонение 4/9 → 50
+	if math.Abs(cci[3]-50) > 1e-9 {
+		t.Errorf("Expected CCI 50, got %.4f", cci[3])
+	}
+	if cci[4] != 0 {
+		t.Errorf("Expected zero CCI for a flat window, got %.4f", cci[4])
+	}
+}
+
+func TestCalculateDownsideDeviation_IgnoresUpsideMoves(t *testing.T) {
+	// Доходности +10%, −10%, +20%, −5%: ниже нуля только −10% и −5%
+	prices := []float64{100, 110, 99, 118.8, 112.86}
+
+	expected := math.Sqrt((0.1*0.1 + 0.05*0.05) / 4)
+	if got := CalculateDownsideDeviation(prices, 0); math.Abs(got-expected) > 1e-9 {
+		t.Errorf("Expected downside deviation %.6f, got %.6f", expected, got)
+	}
+
+	// Только рост — нисходящей волатильности нет, хотя обычное отклонение ненулевое
+	rising := []float64{100, 101, 105, 106}
+	if got := CalculateDownsideDeviation(rising, 0); got != 0 || CalculateStdDevOfReturns(rising) == 0 {
+		t.Errorf("Expected zero downside deviation on a rising series, got %.6f", got)
+	}
+
+	// Цель выше нуля делает недобор до нее тоже нисходящим
+	if got := CalculateDownsideDeviation(rising, 0.02); got <= 0 {
+		t.Errorf("Expected returns below a 2%% target to count, got %.6f", got)
+	}
+
+	if CalculateDownsideDeviation([]float64{100}, 0) != 0 {
+		t.Error("Expected 0 for fewer than two prices")
+	}
+}
+
+func TestCalculateHMA_TracksLinearTrend(t *testing.T) {
+	prices := make([]float64, 30)
+	for i := range prices {
+		prices[i] = 100 + float64(i)
+	}
+
+	hma := CalculateHMA(prices, 9)
+	if hma == nil {
+		t.Fatal("Expected HMA values")
+	}
+
+	// period + ⌊√period⌋ − 2 = 10: первое определенное значение
+	if hma[9] != 0 || hma[10] == 0 {
+		t.Errorf("Expected first HMA value at index 10, got hma[9]=%.4f hma[10]=%.4f", hma[9], hma[10])
+	}
+
+	// На линейном тренде HMA практически не запаздывает
+	for i := 10; i < len(prices); i++ {
+		if math.Abs(hma[i]-prices[i]) > 1e-9 {
+			t.Errorf("Expected HMA to match linear trend at %d: %.4f vs %.4f", i, hma[i], prices[i])
+		}
+	}
+}
+
+func TestCalculateUlcerIndex_RMSOfDrawdowns(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	prices := []float64{100, 90, 100, 80, 80}
+
+	ulcer := CalculateUlcerIndex(prices, 2)
+	if ulcer == nil {
+		t.Fatal("Expected Ulcer Index values")
+	}
+	if ulcer[0] != 0 {
+		t.Errorf("Expected zero warm-up value, got %.4f", ulcer[0])
+	}
+
+	// Просадки от максимума за 2 бара: 0, −10%, 0, −20%, 0
+	expected := []float64{0, math.Sqrt(0.01 / 2), math.Sqrt(0.01 / 2), math.Sqrt(0.04 / 2), math.Sqrt(0.04 / 2)}
+	for i := 1; i < len(expected); i++ {
+		if math.Abs(ulcer[i]-expected[i]) > 1e-9 {
+			t.Errorf("Expected Ulcer Index %.4f at index %d, got %.4f", expected[i], i, ulcer[i])
+		}
+	}
+
+	// Рост без просадок — нулевой индекс, в отличие от стандартного отклонения
+	if rising := ulcerIndex([]float64{1, 2, 3, 4}, 3); rising[3] != 0 {
+		t.Errorf("Expected zero Ulcer Index on a rising series, got %.4f", rising[3])
+	}
+}
+
+func TestUlcerPerformanceIndex_PenalizesDrawdowns(t *testing.T) {
+	smooth := BacktestResult{TotalProfit: 0.1, PortfolioValues: []float64{100, 104, 107, 110}}
+	bumpy := BacktestResult{TotalProfit: 0.1, PortfolioValues: []float64{100, 120, 80, 110}}
+
+	if UlcerPerformanceIndex(smooth) <= UlcerPerformanceIndex(bumpy) {
+		t.Errorf("Expected higher UPI for the smooth curve: %.2f vs %.2f",
+			UlcerPerformanceIndex(smooth), UlcerPerformanceIndex(bumpy))
+	}
+}
+
+func TestRecencyWeightedProfit_FavorsRecentGains(t *testing.T) {
+	// Одинаковая итоговая прибыль +21%: одна кривая заработала в начале, другая — в конце
+	early := []float64{100, 110, 121, 121, 121, 121}
+	late := []float64{100, 100, 100, 100, 110, 121}
+
+	for _, curve := range [][]float64{early, late} {
+		if got := RecencyWeightedProfit(curve, 0); math.Abs(got-0.21) > 1e-9 {
+			t.Errorf("Expected uniform weighting to equal plain profit 0.21, got %.6f", got)
+		}
+	}
+
+	earlyScore, lateScore := RecencyWeightedProfit(early, 2), RecencyWeightedProfit(late, 2)
+	if lateScore <= 0.21 || earlyScore >= 0.21 {
+		t.Errorf("Expected recent gains to score above plain profit and early gains below, got late %.4f, early %.4f",
+			lateScore, earlyScore)
+	}
+
+	SetDefaultRecencyHalfLife(2)
+	defer SetDefaultRecencyHalfLife(0)
+	if ObjectiveScore(BacktestResult{TotalProfit: 0.21, PortfolioValues: late}) != lateScore {
+		t.Errorf("Expected ObjectiveScore to use the recency-weighted profit")
+	}
+}
+
+func TestLinearRegression_KnownLinePlusNoise(t *testing.T) {
+	rng := rand.New(rand.NewSource(3))
+	y := make([]float64, 200)
+	for i := range y {
+		y[i] = 10 + 2*float64(i) + rng.NormFloat64()
+	}
+
+	slope, intercept, r2 := LinearRegression(y)
+	if math.Abs(slope-2) > 0.01 || math.Abs(intercept-10) > 0.5 {
+		t.Errorf("Expected slope ≈ 2 and intercept ≈ 10, got %.4f and %.4f", slope, intercept)
+	}
+	if r2 < 0.999 || r2 > 1 {
+		t.Errorf("Expected R² close to 1 for a strong trend, got %.6f", r2)
+	}
+
+	if slope, intercept, r2 := LinearRegression([]float64{5, 5, 5}); slope != 0 || intercept != 5 || r2 != 0 {
+		t.Errorf("Expected flat series to give (0, 5, 0), got (%.2f, %.2f, %.2f)", slope, intercept, r2)
+	}
+	if slope, intercept, r2 := LinearRegression([]float64{7}); slope != 0 || intercept != 7 || r2 != 0 {
+		t.Errorf("Expected single point to give (0, 7, 0), got (%.2f, %.2f, %.2f)", slope, intercept, r2)
+	}
+}
+
+func TestCalculateKeltnerChannels_EMAPlusMinusATR(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	// Закрытия постоянны, диапазон каждой свечи 2: EMA = 10, ATR = 2
+	candles := make([]Candle, 6)
+	for i := range candles {
+		candles[i] = Candle{High: 11, Low: 9, Close: 10}
+	}
+
+	if upper, _, _ := CalculateKeltnerChannels(candles[:2], 3, 2, 1.5); upper != nil {
+		t.Error("Expected nil channels before both indicators can warm up")
+	}
+
+	upper, middle, lower := CalculateKeltnerChannels(candles, 3, 2, 1.5)
+	for i := 0; i < 2; i++ {
+		if upper[i] != 0 || middle[i] != 0 || lower[i] != 0 {
+			t.Errorf("Expected zero warm-up values at %d, got %.4f/%.4f/%.4f", i, upper[i], middle[i], lower[i])
+		}
+	}
+	for i := 2; i < len(candles); i++ {
+		if math.Abs(middle[i]-10) > 1e-9 || math.Abs(upper[i]-13) > 1e-9 || math.Abs(lower[i]-7) > 1e-9 {
+			t.Errorf("Candle %d: expected 13/10/7, got %.4f/%.4f/%.4f", i, upper[i], middle[i], lower[i])
+		}
+	}
+}
+
+func TestCalculateVWAP_CumulativeAndRolling(t *testing.T) {
+	ResetCache()
+	defer ResetCache()
+
+	candles := []Candle{
+		{High: 10, Low: 10, Close: 10, VolumeFloat: 0}, // объема еще нет
+		{High: 12, Low: 9, Close: 9, VolumeFloat: 1},   // типичная цена 10
+		{High: 20, Low: 20, Close: 20, VolumeFloat: 3},
+		{High: 30, Low: 30, Close: 30, VolumeFloat: 0},
+		{High: 30, Low: 30, Close: 30, VolumeFloat: 0},
+	}
+
+	vwap := CalculateVWAP(candles)
+	// (10×1 + 20×3) / 4 = 17.5; свечи без объема VWAP не меняют
+	expected := []float64{0, 10, 17.5, 17.5, 17.5}
+	for i, want := range expected {
+		if math.Abs(vwap[i]-want) > 1e-9 {
+			t.Errorf("VWAP[%d]: expected %.4f, got %.4f", i, want, vwap[i])
+		}
+	}
+
+	if CalculateRollingVWAP(candles[:1], 2) != nil {
+		t.Error("Expected nil rolling VWAP for fewer candles than period")
+	}
+	rolling := CalculateRollingVWAP(candles, 2)
+	// Окна: [0,1] → 10, [1,2] → 17.5, [2,3] → 20, [3,4] — нулевой объем → 0
+	expected = []float64{0, 10, 17.5, 20, 0}
+	for i, want := range expected {
+		if math.Abs(rolling[i]-want) > 1e-9 {
+			t.Errorf("Rolling VWAP[%d]: expected %.4f, got %.4f", i, want, rolling[i])
+		}
+	}
+}
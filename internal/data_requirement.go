@@ -0,0 +1,50 @@
+// data_requirement.go
+// Отсев конфигураций, которым не хватает свечей, до запуска бэктеста в оптимизаторах
+package internal
+
+// DataRequirement — опциональный интерфейс конфигурации, объявляющей минимальное число свечей.
+// Если свечей меньше, стратегия вернет одни HOLD, и такой прогон не является настоящей оценкой.
+type DataRequirement interface {
+	MinCandles() int
+}
+
+// minCandlesOf — требование конфигурации к данным (0, если конфигурация его не объявляет)
+func minCandlesOf(config interface{}) int {
+	if requirement, ok := config.(DataRequirement); ok {
+		return requirement.MinCandles()
+	}
+	return 0
+}
+
+// FilterByDataRequirement — оставляет конфигурации, для которых хватает свечей, и сообщает, сколько пропущено.
+// Используется ProcessConfigs, сеткой V2 и собственными циклами перебора стратегий V1.
+// Если не подходит ни одна, остается наименее требовательная: оптимизатор всегда возвращает конфигурацию.
+func FilterByDataRequirement[T any](configs []T, candleCount int) []T {
+	kept := make([]T, 0, len(configs))
+	leastDemanding := -1
+	for i, config := range configs {
+		required := minCandlesOf(config)
+		if required <= candleCount {
+			kept = append(kept, config)
+			continue
+		}
+		if leastDemanding < 0 || required < minCandlesOf(configs[leastDemanding]) {
+			leastDemanding = i
+		}
+	}
+
+	skipped := len(configs) - len(kept)
+	if skipped == 0 {
+		return kept
+	}
+
+	if len(kept) == 0 {
+		Log.Warnf("⚠️ Ни одной конфигурации не хватает данных (%d свечей, нужно минимум %d) — оценивается наименее требовательная",
+			candleCount, minCandlesOf(configs[leastDemanding]))
+		return []T{configs[leastDemanding]}
+	}
+
+	Log.Infof("⏭️ Пропущено %d из %d конфигураций: требуется больше свечей, чем доступно (%d)",
+		skipped, len(configs), candleCount)
+	return kept
+}
@@ -0,0 +1,50 @@
+package internal
+
+import (
+	"context"
+	"testing"
+)
+
+type windowConfig struct {
+	window int
+}
+
+func (c *windowConfig) Validate() error             { return nil }
+func (c *windowConfig) DefaultConfigString() string { return "" }
+func (c *windowConfig) MinCandles() int             { return c.window }
+
+type holdStrategy struct{}
+
+func (s *holdStrategy) GenerateSignalsWithConfig(candles []Candle, config StrategyConfig) []SignalType {
+	return make([]SignalType, len(candles))
+}
+
+func TestProcessConfigs_SkipsConfigsExceedingData(t *testing.T) {
+	candles := make([]Candle, 40)
+	for i := range candles {
+		candles[i] = Candle{Close: Price(100.0 + float64(i))}
+	}
+
+	configs := []StrategyConfig{
+		&windowConfig{window: 20},
+		&windowConfig{window: 80},
+		&windowConfig{window: 120},
+	}
+
+	kept := FilterByDataRequirement(configs, len(candles))
+	if len(kept) != 1 || kept[0].(*windowConfig).window != 20 {
+		t.Errorf("Expected only the 20-candle config to be kept, got %d configs", len(kept))
+	}
+
+	// Ни одна конфигурация не помещается — остается наименее требовательная
+	kept = FilterByDataRequirement(configs[1:], len(candles))
+	if len(kept) != 1 || kept[0].(*windowConfig).window != 80 {
+		t.Errorf("Expected fallback to the least demanding config (80), got %v", kept)
+	}
+
+	var base BaseStrategy
+	best := base.ProcessConfigs(context.Background(), &holdStrategy{}, candles, configs)
+	if best.A.(*windowConfig).window != 20 {
+		t.Errorf("Expected optimizer to pick the only feasible config, got window %d", best.A.(*windowConfig).window)
+	}
+}
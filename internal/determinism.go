@@ -0,0 +1,50 @@
+// determinism.go
+// Проверка воспроизводимости сигналов стратегии
+package internal
+
+import "fmt"
+
+// AssertDeterministic — дважды генерирует сигналы зарегистрированной стратегии (V1 или V2) с конфигурацией
+// по умолчанию и возвращает ошибку, если они различаются. Первый прогон идет с пустым кэшем индикаторов,
+// второй — с заполненным, поэтому ловится и зависимость от порядка обхода map или глобального rand,
+// и порча общих данных кэша
+func AssertDeterministic(name string, candles []Candle) error {
+	generate, err := defaultSignalGenerator(name)
+	if err != nil {
+		return err
+	}
+
+	ResetCache()
+	first := generate(candles)
+	second := generate(candles)
+
+	if len(first) != len(second) {
+		return fmt.Errorf("%s: длина сигналов различается между прогонами: %d и %d", name, len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			return fmt.Errorf("%s: сигнал на баре %d различается между прогонами: %v и %v", name, i, first[i], second[i])
+		}
+	}
+
+	return nil
+}
+
+// defaultSignalGenerator — генерация сигналов стратегии с конфигурацией по умолчанию
+func defaultSignalGenerator(name string) (func([]Candle) []SignalType, error) {
+	if strategy, ok := GetStrategyV2(name); ok {
+		config := strategy.DefaultConfig()
+		return func(candles []Candle) []SignalType {
+			return strategy.GenerateSignals(candles, config)
+		}, nil
+	}
+
+	strategy, ok := strategies[name]
+	if !ok {
+		return nil, fmt.Errorf("стратегия %s не найдена", name)
+	}
+	config := strategy.DefaultConfig()
+	return func(candles []Candle) []SignalType {
+		return strategy.GenerateSignalsWithConfig(candles, config)
+	}, nil
+}
@@ -0,0 +1,58 @@
+// excursion.go
+// MFE/MAE: лучшее и худшее движение цены в пользу и против открытой позиции
+package internal
+
+import "math"
+
+// excursionTracker — экстремумы цены за время удержания позиции.
+// Бары, которые позиция удерживала целиком, учитываются по High/Low; на баре входа известно только
+// движение от цены входа до закрытия, на баре выхода — до цены выхода
+type excursionTracker struct {
+	high, low float64
+}
+
+// reset — новая позиция, открытая по цене fill
+func (t *excursionTracker) reset(fill float64) {
+	t.high, t.low = fill, fill
+}
+
+// add — цена, которую позиция видела внутри бара
+func (t *excursionTracker) add(price float64) {
+	t.high = math.Max(t.high, price)
+	t.low = math.Min(t.low, price)
+}
+
+// addBar — свеча, которую позиция удерживала целиком (без High/Low — по закрытию)
+func (t *excursionTracker) addBar(c Candle) {
+	high, low := c.High.ToFloat64(), c.Low.ToFloat64()
+	if high <= 0 || low <= 0 {
+		high, low = c.Close.ToFloat64(), c.Close.ToFloat64()
+	}
+	t.add(high)
+	t.add(low)
+}
+
+// excursions — MFE (≥ 0) и MAE (≤ 0) в долях от цены исполнения входа entryFill
+func (t *excursionTracker) excursions(entryFill float64, short bool) (mfe, mae float64) {
+	if entryFill <= 0 {
+		return 0, 0
+	}
+	up, down := t.high/entryFill-1, t.low/entryFill-1
+	if short {
+		up, down = -down, -up
+	}
+	// При докупке цена входа усредняется и может выйти за экстремумы отдельных входов
+	return math.Max(up, 0), math.Min(down, 0)
+}
+
+// averageExcursions — средние MFE и MAE по журналу закрытых сделок
+func averageExcursions(trades []Trade) (avgMFE, avgMAE float64) {
+	if len(trades) == 0 {
+		return 0, 0
+	}
+	for _, t := range trades {
+		avgMFE += t.MFE
+		avgMAE += t.MAE
+	}
+	return avgMFE / float64(len(trades)), avgMAE / float64(len(trades))
+}
@@ -0,0 +1,147 @@
+// execution.go
+// Модель исполнения сделок: задержка относительно сигнала и цена заполнения
+package internal
+
+import "fmt"
+
+// FillPrice — цена, по которой исполняется сделка на баре исполнения
+type FillPrice int
+
+const (
+	// FillClose — цена закрытия бара (поведение по умолчанию)
+	FillClose FillPrice = iota
+	// FillOpen — цена открытия бара
+	FillOpen
+	// FillVWAP — средневзвешенная цена бара; внутри одной свечи объемы по ценам неизвестны,
+	// поэтому используется типичная цена (High + Low + Close) / 3
+	FillVWAP
+	// FillWorst — худшая для сделки цена бара: покупка по High, продажа по Low (стресс-тест исполнения)
+	FillWorst
+)
+
+func (f FillPrice) String() string {
+	switch f {
+	case FillOpen:
+		return "open"
+	case FillVWAP:
+		return "vwap"
+	case FillWorst:
+		return "worst"
+	default:
+		return "close"
+	}
+}
+
+// ParseFillPrice — разбирает значение флага --fill
+func ParseFillPrice(s string) (FillPrice, error) {
+	switch s {
+	case "", "close":
+		return FillClose, nil
+	case "open":
+		return FillOpen, nil
+	case "vwap":
+		return FillVWAP, nil
+	case "worst":
+		return FillWorst, nil
+	default:
+		return FillClose, fmt.Errorf("неизвестная цена исполнения '%s' (ожидается close, open, vwap или worst)", s)
+	}
+}
+
+// PriceAt — цена исполнения на свече; если нужных полей нет, используется цена закрытия.
+// Для FillWorst цена зависит от стороны сделки — см. BuyPriceAt и SellPriceAt.
+func (f FillPrice) PriceAt(c Candle) float64 {
+	closePrice := c.Close.ToFloat64()
+	switch f {
+	case FillOpen:
+		if open := c.Open.ToFloat64(); open > 0 {
+			return open
+		}
+	case FillVWAP:
+		high, low := c.High.ToFloat64(), c.Low.ToFloat64()
+		if high > 0 && low > 0 {
+			return (high + low + closePrice) / 3
+		}
+	}
+	return closePrice
+}
+
+// BuyPriceAt — цена исполнения покупки на свече
+func (f FillPrice) BuyPriceAt(c Candle) float64 {
+	if high := c.High.ToFloat64(); f == FillWorst && high > 0 {
+		return high
+	}
+	return f.PriceAt(c)
+}
+
+// SellPriceAt — цена исполнения продажи на свече
+func (f FillPrice) SellPriceAt(c Candle) float64 {
+	if low := c.Low.ToFloat64(); f == FillWorst && low > 0 {
+		return low
+	}
+	return f.PriceAt(c)
+}
+
+// FillModel — модель исполнения из флага --fill-model: задержка и цена заполнения одним параметром
+type FillModel int
+
+const (
+	// FillModelClose — по закрытию бара сигнала (поведение по умолчанию)
+	FillModelClose FillModel = iota
+	// FillModelNextOpen — по открытию следующего бара
+	FillModelNextOpen
+	// FillModelWorst — по худшей цене следующего бара: High для покупки, Low для продажи
+	FillModelWorst
+)
+
+func (m FillModel) String() string {
+	switch m {
+	case FillModelNextOpen:
+		return "next-open"
+	case FillModelWorst:
+		return "worst"
+	default:
+		return "close"
+	}
+}
+
+// ParseFillModel — разбирает значение флага --fill-model
+func ParseFillModel(s string) (FillModel, error) {
+	switch s {
+	case "", "close":
+		return FillModelClose, nil
+	case "next-open":
+		return FillModelNextOpen, nil
+	case "worst":
+		return FillModelWorst, nil
+	default:
+		return FillModelClose, fmt.Errorf("неизвестная модель исполнения '%s' (ожидается close, next-open или worst)", s)
+	}
+}
+
+// Apply — задает задержку и цену исполнения модели; FillModelClose оставляет параметры без изменений,
+// а большая задержка, заданная явно, сохраняется
+func (m FillModel) Apply(opts BacktestOptions) BacktestOptions {
+	switch m {
+	case FillModelNextOpen:
+		opts.Fill = FillOpen
+	case FillModelWorst:
+		opts.Fill = FillWorst
+	default:
+		return opts
+	}
+	if opts.ExecutionDelay < 1 {
+		opts.ExecutionDelay = 1
+	}
+	return opts
+}
+
+// buyPrice — цена покупки одной единицы с учетом проскальзывания и комиссии
+func (o BacktestOptions) buyPrice(fillPrice float64) float64 {
+	return (fillPrice*(1+o.SlippagePercent) + o.Slippage) * (1 + o.Commission)
+}
+
+// sellPrice — выручка с продажи одной единицы за вычетом проскальзывания и комиссии
+func (o BacktestOptions) sellPrice(fillPrice float64) float64 {
+	return (fillPrice*(1-o.SlippagePercent) - o.Slippage) * (1 - o.Commission)
+}
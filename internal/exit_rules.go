@@ -0,0 +1,80 @@
+// exit_rules.go
+// Принудительные выходы движка: стоп-лосс, тейк-профит и трейлинг-стоп между сигналами стратегии
+package internal
+
+import "math"
+
+// ExitRules — стоп-лосс и тейк-профит, которые движок проверяет на каждом баре открытой позиции.
+// Уровни считаются от цены исполнения входа (без издержек), срабатывание определяется по High/Low бара,
+// выход исполняется по цене уровня с проскальзыванием и комиссией (или по Open, если цена открылась гэпом за уровнем)
+type ExitRules struct {
+	// StopLossPercent — допустимый убыток от входа в долях цены (0.03 = 3%, 0 = выключено)
+	StopLossPercent float64
+	// TakeProfitPercent — целевая прибыль от входа в долях цены (0.05 = 5%, 0 = выключено)
+	TakeProfitPercent float64
+	// TrailingStop — стоп-лосс отсчитывается от лучшей цены с момента входа (максимума для длинной позиции,
+	// минимума для короткой), а не от цены входа
+	TrailingStop bool
+}
+
+// enabled — задан ли хотя бы один уровень выхода
+func (r ExitRules) enabled() bool {
+	return r.StopLossPercent > 0 || r.TakeProfitPercent > 0
+}
+
+// exitFill — цена принудительного выхода на свече, если она достигла стоп-лосса или тейк-профита.
+// entryFill — цена входа, best — лучшая цена с момента входа на предыдущих барах. Если на одной свече
+// достигнуты оба уровня, порядок внутри бара неизвестен и выбирается стоп-лосс
+func (r ExitRules) exitFill(c Candle, entryFill, best float64, short bool) (float64, bool) {
+	closePrice := c.Close.ToFloat64()
+	high, low, open := c.High.ToFloat64(), c.Low.ToFloat64(), c.Open.ToFloat64()
+	if high <= 0 || low <= 0 {
+		high, low = closePrice, closePrice
+	}
+	if open <= 0 {
+		open = closePrice
+	}
+
+	base := entryFill
+	if r.TrailingStop {
+		base = best
+	}
+
+	if short {
+		if r.StopLossPercent > 0 {
+			if stop := base * (1 + r.StopLossPercent); high >= stop {
+				return math.Max(stop, open), true
+			}
+		}
+		if r.TakeProfitPercent > 0 {
+			if target := entryFill * (1 - r.TakeProfitPercent); low <= target {
+				return math.Min(target, open), true
+			}
+		}
+		return 0, false
+	}
+
+	if r.StopLossPercent > 0 {
+		if stop := base * (1 - r.StopLossPercent); low <= stop {
+			return math.Min(stop, open), true
+		}
+	}
+	if r.TakeProfitPercent > 0 {
+		if target := entryFill * (1 + r.TakeProfitPercent); high >= target {
+			return math.Max(target, open), true
+		}
+	}
+	return 0, false
+}
+
+// bestPrice — лучшая цена позиции с учетом свечи: максимум High для длинной, минимум Low для короткой
+func bestPrice(c Candle, best float64, short bool) float64 {
+	high, low := c.High.ToFloat64(), c.Low.ToFloat64()
+	if high <= 0 || low <= 0 {
+		high, low = c.Close.ToFloat64(), c.Close.ToFloat64()
+	}
+	if short {
+		return math.Min(best, low)
+	}
+	return math.Max(best, high)
+}
@@ -0,0 +1,40 @@
+package internal
+
+import "fmt"
+
+// Коды условий, заблокировавших сигнал (режим --explain)
+const (
+	HoldVolatility  = "volatility_filter"    // волатильность ниже порога
+	HoldTrendGate   = "trend_gate"           // тренд не подтверждает направление сделки
+	HoldMomentum    = "momentum_gate"        // цена или индикатор не движутся в сторону сделки
+	HoldCooldown    = "cooldown"             // слишком мало баров после предыдущего сигнала
+	HoldConfidence  = "confidence_threshold" // уверенность предсказания ниже порога
+	HoldAlternation = "alternation"          // сигнал того же типа, что и предыдущий
+)
+
+// HoldReason — почему на баре, где стратегия могла торговать, остался HOLD
+type HoldReason struct {
+	Blocked SignalType // заблокированный сигнал: BUY или SELL
+	Code    string     // условие, заблокировавшее сигнал (см. Hold*)
+	Detail  string     // значения, на которых сработало условие
+}
+
+// HoldReasons — причины по барам (пустой Code — бар не блокировался).
+// Запись в nil-срез ничего не делает, поэтому генератор сигналов ведет ее без отдельной ветки для оптимизации
+type HoldReasons []HoldReason
+
+// Record — запоминает причину для бара i
+func (r HoldReasons) Record(i int, blocked SignalType, code string, format string, args ...interface{}) {
+	if r == nil {
+		return
+	}
+	r[i] = HoldReason{Blocked: blocked, Code: code, Detail: fmt.Sprintf(format, args...)}
+}
+
+// ExplainSignals — сигналы и причины HOLD, если генератор стратегии поддерживает объяснения; иначе nil, nil
+func (sb *StrategyBase) ExplainSignals(candles []Candle, config StrategyConfigV2) ([]SignalType, HoldReasons) {
+	if explaining, ok := sb.signalGenerator.(ExplainingSignalGenerator); ok {
+		return explaining.ExplainSignals(candles, config)
+	}
+	return nil, nil
+}
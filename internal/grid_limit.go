@@ -0,0 +1,46 @@
+// grid_limit.go
+// Ограничение перебора GridSearchOptimizer: случайная подвыборка конфигураций при слишком большой сетке
+package internal
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// DefaultGridSeed — зерно подвыборки конфигураций по умолчанию
+const DefaultGridSeed int64 = 1
+
+// GridLimit — сколько конфигураций сетки проверять (MaxConfigs = 0 — все) и зерно случайной подвыборки
+type GridLimit struct {
+	MaxConfigs int
+	Seed       int64
+}
+
+// defaultGridLimit — ограничение для оптимизаторов без собственного лимита (задается флагами --max-configs и --grid-seed)
+var defaultGridLimit = GridLimit{Seed: DefaultGridSeed}
+
+// SetDefaultGridLimit — задает ограничение перебора; вызывается один раз при старте, до запуска стратегий
+func SetDefaultGridLimit(limit GridLimit) {
+	defaultGridLimit = limit
+}
+
+// DefaultGridLimit — текущее ограничение перебора по умолчанию
+func DefaultGridLimit() GridLimit {
+	return defaultGridLimit
+}
+
+// subsampleConfigs — случайные MaxConfigs конфигураций в исходном порядке; при том же зерне выборка повторяется
+func subsampleConfigs[T any](configs []T, limit GridLimit) []T {
+	if limit.MaxConfigs <= 0 || len(configs) <= limit.MaxConfigs {
+		return configs
+	}
+
+	indices := rand.New(rand.NewSource(limit.Seed)).Perm(len(configs))[:limit.MaxConfigs]
+	sort.Ints(indices)
+
+	sampled := make([]T, len(indices))
+	for i, index := range indices {
+		sampled[i] = configs[index]
+	}
+	return sampled
+}
@@ -0,0 +1,33 @@
+package internal
+
+import (
+	"reflect"
+	"sort"
+	"testing"
+)
+
+func TestSubsampleConfigs(t *testing.T) {
+	configs := make([]int, 100)
+	for i := range configs {
+		configs[i] = i
+	}
+
+	if got := subsampleConfigs(configs, GridLimit{}); len(got) != 100 {
+		t.Errorf("Expected no subsampling without a cap, got %d configs", len(got))
+	}
+
+	limit := GridLimit{MaxConfigs: 10, Seed: 7}
+	sampled := subsampleConfigs(configs, limit)
+	if len(sampled) != 10 {
+		t.Fatalf("Expected 10 configs, got %d", len(sampled))
+	}
+	if !sort.IntsAreSorted(sampled) {
+		t.Errorf("Expected the original order to be kept, got %v", sampled)
+	}
+	if again := subsampleConfigs(configs, limit); !reflect.DeepEqual(sampled, again) {
+		t.Errorf("Expected the same subsample for the same seed, got %v and %v", sampled, again)
+	}
+	if other := subsampleConfigs(configs, GridLimit{MaxConfigs: 10, Seed: 8}); reflect.DeepEqual(sampled, other) {
+		t.Errorf("Expected a different subsample for a different seed")
+	}
+}
@@ -0,0 +1,92 @@
+// hurst.go
+// Показатель Херста: склонность ряда к продолжению движения (тренд) или к возврату к среднему
+package internal
+
+import "math"
+
+const (
+	// HurstRandomWalk — показатель Херста случайного блуждания: выше — тренд, ниже — возврат к среднему
+	HurstRandomWalk = 0.5
+	// minHurstChunk — минимальная длина отрезка доходностей для R/S-анализа: на более коротких R/S слишком шумит
+	minHurstChunk = 8
+)
+
+// CalculateHurst вычисляет скользящий показатель Херста методом R/S-анализа (rescaled range).
+// Для бара i берутся window последних лог-доходностей, делятся на отрезки длины n = 8, 16, 32, ... ≤ window,
+// по каждому отрезку считается размах накопленных отклонений от среднего R, деленный на стандартное отклонение S;
+// H — наклон регрессии log(R/S) по log(n). H > 0.5 — трендовый режим, H < 0.5 — возврат к среднему.
+// Классический R/S на коротких окнах завышает H (для случайного блуждания ≈ 0.55 при window = 256),
+// поэтому пороги режимов лучше брать с запасом. Нужно window ≥ 16 (хотя бы две длины отрезка); первые window значений — 0
+func CalculateHurst(prices []float64, window int) []float64 {
+	if window < 2*minHurstChunk || len(prices) <= window {
+		return nil
+	}
+
+	returns := make([]float64, len(prices))
+	for i := 1; i < len(prices); i++ {
+		if prices[i] > 0 && prices[i-1] > 0 {
+			returns[i] = math.Log(prices[i] / prices[i-1])
+		}
+	}
+
+	hurst := make([]float64, len(prices))
+	for i := window; i < len(prices); i++ {
+		hurst[i] = hurstExponent(returns[i-window+1 : i+1])
+	}
+	return hurst
+}
+
+// hurstExponent — показатель Херста одного окна доходностей; 0, если R/S не определен ни на одной паре длин
+func hurstExponent(returns []float64) float64 {
+	var logSizes, logRS []float64
+	for n := minHurstChunk; n <= len(returns); n *= 2 {
+		// Отрезки выравниваются по концу окна, чтобы самые свежие доходности участвовали при любой длине
+		chunks := len(returns) / n
+		offset := len(returns) - chunks*n
+		sum, count := 0.0, 0
+		for c := 0; c < chunks; c++ {
+			if rs := rescaledRange(returns[offset+c*n : offset+(c+1)*n]); rs > 0 {
+				sum += rs
+				count++
+			}
+		}
+		if count > 0 {
+			logSizes = append(logSizes, math.Log(float64(n)))
+			logRS = append(logRS, math.Log(sum/float64(count)))
+		}
+	}
+	if len(logSizes) < 2 {
+		return 0
+	}
+
+	// Наклон МНК log(R/S) по log(n)
+	meanX, meanY := 0.0, 0.0
+	for k := range logSizes {
+		meanX += logSizes[k]
+		meanY += logRS[k]
+	}
+	meanX /= float64(len(logSizes))
+	meanY /= float64(len(logSizes))
+	cov, varX := 0.0, 0.0
+	for k := range logSizes {
+		cov += (logSizes[k] - meanX) * (logRS[k] - meanY)
+		varX += (logSizes[k] - meanX) * (logSizes[k] - meanX)
+	}
+	return cov / varX
+}
+
+// rescaledRange — R/S одного отрезка: размах накопленных отклонений от среднего, деленный на стандартное отклонение
+func rescaledRange(chunk []float64) float64 {
+	mean, std := calculateMeanStd(chunk)
+	if std == 0 {
+		return 0
+	}
+
+	cumulative, minCum, maxCum := 0.0, 0.0, 0.0
+	for _, r := range chunk {
+		cumulative += r - mean
+		minCum = math.Min(minCum, cumulative)
+		maxCum = math.Max(maxCum, cumulative)
+	}
+	return (maxCum - minCum) / std
+}
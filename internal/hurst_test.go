@@ -0,0 +1,44 @@
+package internal
+
+import (
+	"math/rand"
+	"testing"
+)
+
+// meanHurst — среднее значение показателя Херста после прогрева
+func meanHurst(prices []float64, window int) float64 {
+	hurst := CalculateHurst(prices, window)
+	sum := 0.0
+	for _, h := range hurst[window:] {
+		sum += h
+	}
+	return sum / float64(len(hurst)-window)
+}
+
+func TestCalculateHurst_RandomWalkVsTrend(t *testing.T) {
+	const n, window = 3000, 256
+	rng := rand.New(rand.NewSource(11))
+
+	// Случайное блуждание: независимые доходности
+	walk := make([]float64, n)
+	// Трендовый ряд: доходности с положительной автокорреляцией, движение продолжается
+	trend := make([]float64, n)
+	walk[0], trend[0] = 100, 100
+	momentum := 0.0
+	for i := 1; i < n; i++ {
+		walk[i] = walk[i-1] * (1 + 0.01*rng.NormFloat64())
+		momentum = 0.7*momentum + 0.01*rng.NormFloat64()
+		trend[i] = trend[i-1] * (1 + momentum)
+	}
+
+	if h := meanHurst(walk, window); h < 0.45 || h > 0.6 {
+		t.Errorf("Expected H ≈ 0.5 for a random walk, got %.3f", h)
+	}
+	if h := meanHurst(trend, window); h < 0.65 {
+		t.Errorf("Expected H > 0.65 for a trending series, got %.3f", h)
+	}
+
+	if CalculateHurst(walk[:100], 8) != nil {
+		t.Errorf("Expected nil for a window shorter than two R/S chunk sizes")
+	}
+}
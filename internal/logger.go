@@ -0,0 +1,95 @@
+// logger.go
+// Уровни логирования для стратегий: по умолчанию выводятся только ошибки и предупреждения
+package internal
+
+import (
+	"fmt"
+	"log"
+)
+
+// LogLevel — уровень подробности логов
+type LogLevel int
+
+const (
+	// LogError — только ошибки
+	LogError LogLevel = iota
+	// LogWarn — ошибки и предупреждения (уровень по умолчанию)
+	LogWarn
+	// LogInfo — плюс сообщения о ходе работы стратегий (запуск, параметры, итоги)
+	LogInfo
+	// LogDebug — плюс подробности по барам и промежуточные результаты
+	LogDebug
+)
+
+func (l LogLevel) String() string {
+	switch l {
+	case LogError:
+		return "error"
+	case LogInfo:
+		return "info"
+	case LogDebug:
+		return "debug"
+	default:
+		return "warn"
+	}
+}
+
+// ParseLogLevel — разбирает значение флага --log-level
+func ParseLogLevel(s string) (LogLevel, error) {
+	switch s {
+	case "error":
+		return LogError, nil
+	case "", "warn":
+		return LogWarn, nil
+	case "info":
+		return LogInfo, nil
+	case "debug":
+		return LogDebug, nil
+	default:
+		return LogWarn, fmt.Errorf("неизвестный уровень логирования '%s' (ожидается error, warn, info или debug)", s)
+	}
+}
+
+// LevelLogger — обертка над стандартным log, отбрасывающая сообщения выше заданного уровня
+type LevelLogger struct {
+	level LogLevel
+}
+
+// Log — логгер стратегий; уровень задается флагами --log-level / --debug
+var Log = &LevelLogger{level: LogWarn}
+
+// SetLogLevel — задает уровень логирования; вызывается один раз при старте, до запуска стратегий
+func SetLogLevel(level LogLevel) {
+	Log.level = level
+}
+
+// Enabled — будут ли выведены сообщения уровня level; для дорогой подготовки данных только ради лога
+func (l *LevelLogger) Enabled(level LogLevel) bool {
+	return level <= l.level
+}
+
+func (l *LevelLogger) logf(level LogLevel, format string, args ...interface{}) {
+	if l.Enabled(level) {
+		log.Printf(format, args...)
+	}
+}
+
+// Errorf — ошибка: неверная конфигурация, сбой сохранения
+func (l *LevelLogger) Errorf(format string, args ...interface{}) {
+	l.logf(LogError, format, args...)
+}
+
+// Warnf — предупреждение: результат получен, но, возможно, не тот, что ожидался
+func (l *LevelLogger) Warnf(format string, args ...interface{}) {
+	l.logf(LogWarn, format, args...)
+}
+
+// Infof — ход работы стратегии: запуск, параметры, итоги анализа
+func (l *LevelLogger) Infof(format string, args ...interface{}) {
+	l.logf(LogInfo, format, args...)
+}
+
+// Debugf — подробности для отладки: отдельные сигналы, промежуточные вычисления
+func (l *LevelLogger) Debugf(format string, args ...interface{}) {
+	l.logf(LogDebug, format, args...)
+}
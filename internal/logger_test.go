@@ -0,0 +1,25 @@
+package internal
+
+import "testing"
+
+func TestLevelLogger_Enabled(t *testing.T) {
+	level, err := ParseLogLevel("info")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	logger := &LevelLogger{level: level}
+	if !logger.Enabled(LogWarn) || !logger.Enabled(LogInfo) {
+		t.Error("expected warn and info to be enabled at info level")
+	}
+	if logger.Enabled(LogDebug) {
+		t.Error("expected debug to be suppressed at info level")
+	}
+
+	if _, err := ParseLogLevel("verbose"); err == nil {
+		t.Error("expected error for unknown level")
+	}
+	if level, _ := ParseLogLevel(""); level != LogWarn {
+		t.Errorf("expected warn by default, got %s", level)
+	}
+}
@@ -0,0 +1,79 @@
+// lookahead.go
+// Проверка стратегий на заглядывание в будущее
+package internal
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrNoSignals — стратегия не дала на ряде ни одного BUY или SELL: совпадение сигналов ничего не доказывает,
+// и заглядывание в будущее остается непроверенным
+var ErrNoSignals = errors.New("нет ни одного сигнала BUY/SELL, заглядывание в будущее не проверено")
+
+// CheckNoLookAhead — проверяет, что стратегия не заглядывает в будущее: сигнал на баре i, посчитанный
+// по всей истории, должен совпадать с сигналом, посчитанным по свечам candles[:i+1] — только их стратегия
+// видела бы в момент закрытия бара i при торговле вживую. Стратегия генерирует сигналы с конфигурацией
+// по умолчанию на каждом растущем префиксе, поэтому проверка квадратична по числу свечей.
+// Префиксы, на которых стратегия паникует (обычно слишком мало свечей для прогрева), пропускаются:
+// это не заглядывание в будущее. Возвращает ошибку с первым баром, сигнал которого меняется
+// после добавления следующих свечей, или ErrNoSignals, если проверять было нечего
+func CheckNoLookAhead(name string, candles []Candle) error {
+	generate, err := defaultSignalGenerator(name)
+	if err != nil {
+		return err
+	}
+	defer ResetCache()
+
+	full := generate(candles)
+	if len(full) != len(candles) {
+		return fmt.Errorf("%s: %d сигналов на %d свечей", name, len(full), len(candles))
+	}
+
+	firstBar, mismatches := -1, 0
+	var known, future SignalType
+	for i := range candles {
+		prefix, ok := generatePrefix(generate, candles[:i+1])
+		if !ok {
+			continue
+		}
+		if len(prefix) != i+1 {
+			return fmt.Errorf("%s: %d сигналов на %d свечей", name, len(prefix), i+1)
+		}
+		if prefix[i] != full[i] {
+			if firstBar < 0 {
+				firstBar, known, future = i, prefix[i], full[i]
+			}
+			mismatches++
+		}
+	}
+
+	if mismatches > 0 {
+		return fmt.Errorf("%s: сигнал на баре %d зависит от будущих свечей: по свечам до бара — %v, по всей истории — %v (таких баров %d из %d)",
+			name, firstBar, known, future, mismatches, len(candles))
+	}
+	if !hasTradeSignals(full) {
+		return fmt.Errorf("%s: %w", name, ErrNoSignals)
+	}
+	return nil
+}
+
+// hasTradeSignals — есть ли среди сигналов BUY или SELL
+func hasTradeSignals(signals []SignalType) bool {
+	for _, s := range signals {
+		if s == BUY || s == SELL {
+			return true
+		}
+	}
+	return false
+}
+
+// generatePrefix — сигналы по префиксу свечей; false, если стратегия запаниковала
+func generatePrefix(generate func([]Candle) []SignalType, candles []Candle) (signals []SignalType, ok bool) {
+	defer func() {
+		if recover() != nil {
+			signals, ok = nil, false
+		}
+	}()
+	return generate(candles), true
+}
@@ -0,0 +1,78 @@
+// monte_carlo.go
+// Монте-Карло по сделкам: насколько итог стратегии зависит от конкретной последовательности сделок
+package internal
+
+import (
+	"math/rand"
+	"sort"
+)
+
+// DefaultMonteCarloSeed — зерно генератора перевыборок по умолчанию
+const DefaultMonteCarloSeed int64 = 1
+
+// MCStats — распределение итогов перевыборок сделок. Суммы в деньгах: итог — сумма результатов сделок
+// перевыборки, просадка — наибольшее падение накопленного результата от его максимума (≥ 0)
+type MCStats struct {
+	Iterations int
+	Trades     int
+
+	FinalP5     float64
+	FinalMedian float64
+	FinalP95    float64
+
+	DrawdownP5  float64
+	DrawdownP95 float64
+}
+
+// MonteCarloResample — бутстреп результатов сделок tradePnLs: iterations раз из них выбирается
+// столько же сделок с возвращением, и для каждой последовательности считаются итог и максимальная просадка.
+// При одинаковом seed результат одинаков; без сделок или итераций — нулевая статистика
+func MonteCarloResample(tradePnLs []float64, iterations int, seed int64) MCStats {
+	stats := MCStats{Iterations: iterations, Trades: len(tradePnLs)}
+	if len(tradePnLs) == 0 || iterations <= 0 {
+		return stats
+	}
+
+	rng := rand.New(rand.NewSource(seed))
+	finals := make([]float64, iterations)
+	drawdowns := make([]float64, iterations)
+	for it := range finals {
+		var equity, peak, drawdown float64
+		for range tradePnLs {
+			equity += tradePnLs[rng.Intn(len(tradePnLs))]
+			peak = max(peak, equity)
+			drawdown = max(drawdown, peak-equity)
+		}
+		finals[it] = equity
+		drawdowns[it] = drawdown
+	}
+
+	sort.Float64s(finals)
+	sort.Float64s(drawdowns)
+	stats.FinalP5 = percentileSorted(finals, 0.05)
+	stats.FinalMedian = percentileSorted(finals, 0.5)
+	stats.FinalP95 = percentileSorted(finals, 0.95)
+	stats.DrawdownP5 = percentileSorted(drawdowns, 0.05)
+	stats.DrawdownP95 = percentileSorted(drawdowns, 0.95)
+	return stats
+}
+
+// percentileSorted — перцентиль p (0..1) отсортированной выборки с линейной интерполяцией
+func percentileSorted(sorted []float64, p float64) float64 {
+	pos := p * float64(len(sorted)-1)
+	lower := int(pos)
+	if lower+1 >= len(sorted) {
+		return sorted[len(sorted)-1]
+	}
+	frac := pos - float64(lower)
+	return sorted[lower] + (sorted[lower+1]-sorted[lower])*frac
+}
+
+// TradePnLs — результаты закрытых сделок в деньгах в порядке закрытия
+func TradePnLs(trades []Trade) []float64 {
+	pnls := make([]float64, len(trades))
+	for i, t := range trades {
+		pnls[i] = t.PnL
+	}
+	return pnls
+}
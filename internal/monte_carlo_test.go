@@ -0,0 +1,31 @@
+package internal
+
+import "testing"
+
+func TestMonteCarloResample_DeterministicBands(t *testing.T) {
+	pnls := []float64{120, -80, 45, -30, 200, -150, 60, 10}
+
+	stats := MonteCarloResample(pnls, 500, DefaultMonteCarloSeed)
+	if again := MonteCarloResample(pnls, 500, DefaultMonteCarloSeed); again != stats {
+		t.Fatalf("Expected identical stats for the same seed, got %+v and %+v", stats, again)
+	}
+	if stats.Iterations != 500 || stats.Trades != len(pnls) {
+		t.Errorf("Unexpected sizes: %+v", stats)
+	}
+	if !(stats.FinalP5 < stats.FinalMedian && stats.FinalMedian < stats.FinalP95) {
+		t.Errorf("Expected ordered final equity bands, got %+v", stats)
+	}
+	if stats.DrawdownP5 < 0 || stats.DrawdownP5 > stats.DrawdownP95 {
+		t.Errorf("Expected non-negative ordered drawdown bands, got %+v", stats)
+	}
+
+	// Все сделки одинаковые: порядок не важен, итог фиксирован, просадки нет
+	flat := MonteCarloResample([]float64{10, 10, 10}, 50, 7)
+	if flat.FinalP5 != 30 || flat.FinalP95 != 30 || flat.DrawdownP95 != 0 {
+		t.Errorf("Expected fixed total 30 without drawdown, got %+v", flat)
+	}
+
+	if empty := MonteCarloResample(nil, 100, 1); empty.Trades != 0 || empty.FinalMedian != 0 {
+		t.Errorf("Expected zero stats without trades, got %+v", empty)
+	}
+}
@@ -0,0 +1,129 @@
+// objective.go
+// Целевая функция оптимизаторов: по какому показателю выбирается лучшая конфигурация
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// Objective — показатель, который максимизируют оптимизаторы: ProcessConfigs, GridSearchOptimizer
+// и собственные циклы перебора стратегий V1 (через ObjectiveScore)
+type Objective string
+
+const (
+	// ObjectiveProfit — итоговая прибыль (по умолчанию)
+	ObjectiveProfit Objective = "profit"
+	// ObjectiveUPI — Ulcer Performance Index: прибыль, деленная на Ulcer Index кривой капитала.
+	// В отличие от Sharpe штрафует только просадки, а не рост
+	ObjectiveUPI Objective = "upi"
+
+	// minUlcerIndex — нижняя граница знаменателя UPI: кривая без просадок не дает деления на ноль
+	minUlcerIndex = 0.001
+)
+
+// ParseObjective — разбирает значение флага --objective
+func ParseObjective(s string) (Objective, error) {
+	switch Objective(s) {
+	case "", ObjectiveProfit:
+		return ObjectiveProfit, nil
+	case ObjectiveUPI:
+		return ObjectiveUPI, nil
+	default:
+		return ObjectiveProfit, fmt.Errorf("неизвестная целевая функция '%s' (ожидается profit или upi)", s)
+	}
+}
+
+// defaultObjective — целевая функция общих оптимизаторов (задается флагом --objective)
+var defaultObjective = ObjectiveProfit
+
+// SetDefaultObjective — задает целевую функцию оптимизаторов; вызывается один раз при старте
+func SetDefaultObjective(objective Objective) {
+	defaultObjective = objective
+}
+
+// DefaultObjective — текущая целевая функция оптимизаторов
+func DefaultObjective() Objective {
+	return defaultObjective
+}
+
+// defaultRecencyHalfLife — период полураспада веса доходностей в барах (0 = все бары равноценны; флаг --recency-half-life)
+var defaultRecencyHalfLife int
+
+// SetDefaultRecencyHalfLife — задает взвешивание целевой функции по давности; вызывается один раз при старте
+func SetDefaultRecencyHalfLife(bars int) {
+	defaultRecencyHalfLife = bars
+}
+
+// DefaultRecencyHalfLife — текущий период полураспада веса доходностей (0 = равные веса)
+func DefaultRecencyHalfLife() int {
+	return defaultRecencyHalfLife
+}
+
+// ObjectiveLabel — название текущей целевой функции для логов оптимизаторов
+func ObjectiveLabel() string {
+	if defaultRecencyHalfLife > 0 {
+		return fmt.Sprintf("%s (recency half-life %d)", defaultObjective, defaultRecencyHalfLife)
+	}
+	return string(defaultObjective)
+}
+
+// ObjectiveScore — оценка прогона по текущей целевой функции; больше — лучше.
+// При заданном периоде полураспада вместо прибыли используется RecencyWeightedProfit
+func ObjectiveScore(result BacktestResult) float64 {
+	if result.NonFinite {
+		return result.TotalProfit
+	}
+	profit := result.TotalProfit
+	if defaultRecencyHalfLife > 0 {
+		profit = RecencyWeightedProfit(result.PortfolioValues, defaultRecencyHalfLife)
+	}
+	if defaultObjective == ObjectiveUPI {
+		return profit / ulcerOf(result)
+	}
+	return profit
+}
+
+// RecencyWeightedProfit — прибыль по кривой капитала, где лог-доходность бара весит 0.5^(давность / halfLife).
+// Средневзвешенная доходность бара переводится обратно в прибыль за весь период, поэтому при равных весах
+// результат совпадает с обычной прибылью. Если капитал не положителен (лог-доходность не определена),
+// возвращается обычная прибыль
+func RecencyWeightedProfit(portfolioValues []float64, halfLife int) float64 {
+	n := len(portfolioValues)
+	if n < 2 {
+		return 0
+	}
+	plain := portfolioValues[n-1]/portfolioValues[0] - 1
+	if halfLife <= 0 {
+		return plain
+	}
+
+	decay := math.Pow(0.5, 1/float64(halfLife))
+	weight, weightedSum, totalWeight := 1.0, 0.0, 0.0
+	for i := n - 1; i > 0; i-- {
+		if portfolioValues[i] <= 0 || portfolioValues[i-1] <= 0 {
+			return plain
+		}
+		weightedSum += weight * math.Log(portfolioValues[i]/portfolioValues[i-1])
+		totalWeight += weight
+		weight *= decay
+	}
+	return math.Exp(float64(n-1)*weightedSum/totalWeight) - 1
+}
+
+// UlcerPerformanceIndex — прибыль прогона на единицу Ulcer Index его кривой капитала (просадки от исторического пика)
+func UlcerPerformanceIndex(result BacktestResult) float64 {
+	return result.TotalProfit / ulcerOf(result)
+}
+
+// ulcerOf — Ulcer Index кривой капитала прогона, не меньше minUlcerIndex
+func ulcerOf(result BacktestResult) float64 {
+	ulcer := 0.0
+	if n := len(result.PortfolioValues); n > 0 {
+		ulcer = ulcerIndex(result.PortfolioValues, n)[n-1]
+	}
+	if ulcer < minUlcerIndex {
+		ulcer = minUlcerIndex
+	}
+	return ulcer
+}
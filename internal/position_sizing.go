@@ -0,0 +1,294 @@
+// position_sizing.go
+// Размер позиции: доля капитала на сделку и подбор этой доли по критерию Келли
+package internal
+
+import (
+	"fmt"
+	"math"
+)
+
+// ReturnMode — как размер сделки зависит от накопленного результата
+type ReturnMode int
+
+const (
+	// ReturnCompound — сделка размещает текущий капитал: прибыль реинвестируется (поведение по умолчанию)
+	ReturnCompound ReturnMode = iota
+	// ReturnFixed — каждая сделка размещает одинаковый начальный капитал, результаты сделок суммируются;
+	// показывает перевес сигналов без эффекта сложного процента
+	ReturnFixed
+)
+
+func (m ReturnMode) String() string {
+	if m == ReturnFixed {
+		return "fixed"
+	}
+	return "compound"
+}
+
+// ParseReturnMode — разбирает значение флага --return-mode
+func ParseReturnMode(s string) (ReturnMode, error) {
+	switch s {
+	case "", "compound":
+		return ReturnCompound, nil
+	case "fixed":
+		return ReturnFixed, nil
+	default:
+		return ReturnCompound, fmt.Errorf("неизвестный режим доходности '%s' (ожидается compound или fixed)", s)
+	}
+}
+
+// PositionSizing — как определяется размер позиции при входе
+type PositionSizing int
+
+const (
+	// SizingFractional — весь доступный капитал, дробное количество бумаг (поведение по умолчанию)
+	SizingFractional PositionSizing = iota
+	// SizingWholeShares — целое число бумаг на доступный капитал; остаток остается деньгами на счете
+	// и переходит в следующие сделки. Для дорогих бумаг разница с дробным количеством заметна
+	SizingWholeShares
+	// SizingFixedFraction — на каждый вход RiskFraction от капитала счета (деньги плюс открытая позиция)
+	SizingFixedFraction
+)
+
+func (s PositionSizing) String() string {
+	switch s {
+	case SizingWholeShares:
+		return "whole"
+	case SizingFixedFraction:
+		return "fixed-fraction"
+	default:
+		return "fractional"
+	}
+}
+
+// ParsePositionSizing — разбирает значение флага --sizing
+func ParsePositionSizing(s string) (PositionSizing, error) {
+	switch s {
+	case "", "fractional":
+		return SizingFractional, nil
+	case "whole":
+		return SizingWholeShares, nil
+	case "fixed-fraction":
+		return SizingFixedFraction, nil
+	default:
+		return SizingFractional, fmt.Errorf("неизвестный способ расчета позиции '%s' (ожидается fractional, whole или fixed-fraction)", s)
+	}
+}
+
+// Trade — закрытая сделка из журнала бэктеста
+type Trade struct {
+	EntryIndex int     // бар входа
+	ExitIndex  int     // бар выхода
+	EntryPrice float64 // цена входа с издержками (для короткой позиции — цена продажи)
+	ExitPrice  float64 // цена выхода с издержками (для короткой позиции — цена покрытия)
+	Return     float64 // чистая доходность сделки (0.02 = +2%)
+	PnL        float64 // чистый результат сделки в деньгах, после всех издержек
+	Short      bool    // короткая позиция (BacktestOptions.AllowShorts)
+	MFE        float64 // лучшее движение цены в пользу позиции от цены входа (только с TrackExcursions)
+	MAE        float64 // худшее движение цены против позиции, отрицательное (только с TrackExcursions)
+}
+
+func newTrade(entryIndex, exitIndex int, entryPrice, exitPrice, cost, proceeds float64) Trade {
+	return Trade{
+		EntryIndex: entryIndex,
+		ExitIndex:  exitIndex,
+		EntryPrice: entryPrice,
+		ExitPrice:  exitPrice,
+		Return:     proceeds/cost - 1,
+		PnL:        proceeds - cost,
+	}
+}
+
+// profitFactor — валовая прибыль выигрышных сделок, деленная на валовый убыток проигрышных;
+// без убыточных сделок равен +Inf при наличии прибыли и 0 без нее.
+// expectancy — средний чистый результат сделки в деньгах
+func profitFactor(trades []Trade) (factor, expectancy float64) {
+	if len(trades) == 0 {
+		return 0, 0
+	}
+	var grossProfit, grossLoss, total float64
+	for _, t := range trades {
+		total += t.PnL
+		if t.PnL > 0 {
+			grossProfit += t.PnL
+		} else {
+			grossLoss -= t.PnL
+		}
+	}
+	expectancy = total / float64(len(trades))
+	switch {
+	case grossLoss > 0:
+		return grossProfit / grossLoss, expectancy
+	case grossProfit > 0:
+		return math.Inf(1), expectancy
+	default:
+		return 0, expectancy
+	}
+}
+
+// tradeOutcomes — число выигрышных и проигрышных сделок и их средняя доходность
+func tradeOutcomes(trades []Trade) (wins, losses int, avgWin, avgLoss float64) {
+	for _, t := range trades {
+		switch {
+		case t.Return > 0:
+			wins++
+			avgWin += t.Return
+		case t.Return < 0:
+			losses++
+			avgLoss += t.Return
+		}
+	}
+	if wins > 0 {
+		avgWin /= float64(wins)
+	}
+	if losses > 0 {
+		avgLoss /= float64(losses)
+	}
+	return wins, losses, avgWin, avgLoss
+}
+
+// AverageTradeReturn — средняя чистая доходность закрытой сделки (0 без сделок)
+func AverageTradeReturn(trades []Trade) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+	sum := 0.0
+	for _, t := range trades {
+		sum += t.Return
+	}
+	return sum / float64(len(trades))
+}
+
+// stake — сумма, вкладываемая в сделку из свободного капитала
+func (o BacktestOptions) stake(cash float64) float64 {
+	if o.PositionFraction > 0 && o.PositionFraction < 1 {
+		return cash * o.PositionFraction
+	}
+	return cash
+}
+
+// sizingCapital — капитал, от которого считается вход: свободные деньги (compound)
+// или начальный капитал за вычетом уже вложенного в текущую позицию (fixed)
+func (o BacktestOptions) sizingCapital(cash, initCash, committed float64) float64 {
+	if o.ReturnMode == ReturnFixed {
+		return initCash - committed
+	}
+	return cash
+}
+
+// canScaleIn — можно ли докупить в открытую позицию, в которую уже было entries входов
+func (o BacktestOptions) canScaleIn(entries int) bool {
+	return o.ScaleIn > 1 && entries < o.ScaleIn
+}
+
+// entryStake — сумма очередного входа; при докупке доступный капитал делится на оставшиеся части
+func (o BacktestOptions) entryStake(cash float64, entries int) float64 {
+	stake := o.stake(cash)
+	if o.ScaleIn > 1 {
+		stake /= float64(o.ScaleIn - entries)
+	}
+	return stake
+}
+
+// entryBudget — сумма очередного входа. equity — капитал счета с открытой позицией по цене входа;
+// при SizingFixedFraction вход размещает RiskFraction от него, но не больше доступных денег
+func (o BacktestOptions) entryBudget(cash, initCash, committed, equity float64, entries int) float64 {
+	available := o.sizingCapital(cash, initCash, committed)
+	if o.PositionSizing != SizingFixedFraction {
+		return o.entryStake(available, entries)
+	}
+	if o.ReturnMode == ReturnFixed {
+		equity = initCash
+	}
+	return math.Min(equity*o.RiskFraction, available)
+}
+
+// longEntry — количество бумаг и списываемая сумма при покупке на бюджет stake по цене единицы с издержками.
+// При SizingWholeShares количество округляется вниз, а неизрасходованный остаток бюджета не списывается
+func (o BacktestOptions) longEntry(stake, unitCost float64) (quantity, cost float64) {
+	quantity = (stake - o.fee(stake)) / unitCost
+	if o.PositionSizing != SizingWholeShares {
+		return quantity, stake
+	}
+	for quantity = math.Floor(quantity); quantity > 0; quantity-- {
+		notional := quantity * unitCost
+		// Комиссия считается от фактической суммы покупки; ступенчатая ставка может сделать ее больше бюджета
+		if cost = notional + o.fee(notional); cost <= stake {
+			return quantity, cost
+		}
+	}
+	return 0, 0
+}
+
+// shortEntry — количество бумаг и сумма продажи при открытии шорта на бюджет stake по выручке за единицу
+func (o BacktestOptions) shortEntry(stake, unitProceeds float64) (quantity, notional float64) {
+	quantity = stake / unitProceeds
+	if o.PositionSizing != SizingWholeShares {
+		return quantity, stake
+	}
+	quantity = math.Floor(quantity)
+	return quantity, quantity * unitProceeds
+}
+
+// KellyFraction — оптимальная доля капитала по критерию Келли: f* = W − (1 − W) / R,
+// где W — доля прибыльных сделок, R — отношение средней прибыли к среднему убытку.
+// Без убыточных сделок возвращает W (то есть 1); при отрицательном перевесе — 0
+func KellyFraction(trades []Trade) float64 {
+	if len(trades) == 0 {
+		return 0
+	}
+
+	wins, losses := 0, 0
+	sumWin, sumLoss := 0.0, 0.0
+	for _, t := range trades {
+		if t.Return > 0 {
+			wins++
+			sumWin += t.Return
+		} else if t.Return < 0 {
+			losses++
+			sumLoss -= t.Return
+		}
+	}
+
+	winRate := float64(wins) / float64(len(trades))
+	if losses == 0 || wins == 0 {
+		return winRate
+	}
+
+	payoff := (sumWin / float64(wins)) / (sumLoss / float64(losses))
+	kelly := winRate - (1-winRate)/payoff
+	if kelly < 0 {
+		return 0
+	}
+	return kelly
+}
+
+// runKellyBacktest — два прогона: первый (весь капитал) набирает журнал сделок для статистики,
+// второй повторяет сигналы с долей KellyMultiplier × f*, ограниченной KellyCap
+func runKellyBacktest(candles []Candle, opts BacktestOptions, signalAt func(i int, side PositionSide) SignalType) BacktestResult {
+	firstPass := opts
+	firstPass.KellyMultiplier = 0
+	firstPass.PositionFraction = 0
+	initial := runBacktest(candles, firstPass, signalAt)
+	if len(initial.Trades) == 0 {
+		return initial
+	}
+
+	fraction := opts.KellyMultiplier * KellyFraction(initial.Trades)
+	if opts.KellyCap > 0 && fraction > opts.KellyCap {
+		fraction = opts.KellyCap
+	}
+
+	// Перевеса нет — стратегия не торгует
+	if fraction <= 0 {
+		return runBacktest(candles, firstPass, func(i int, side PositionSide) SignalType {
+			return HOLD
+		})
+	}
+
+	sized := firstPass
+	sized.PositionFraction = fraction
+	result := runBacktest(candles, sized, signalAt)
+	result.KellyFraction = fraction
+	return result
+}
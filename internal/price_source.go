@@ -0,0 +1,103 @@
+// price_source.go
+// Источник цены свечи для построения ценового ряда стратегий
+package internal
+
+import "fmt"
+
+// PriceSource — какая цена свечи используется как «цена» в ценовом ряду стратегии
+type PriceSource int
+
+const (
+	// PriceClose — цена закрытия (поведение по умолчанию)
+	PriceClose PriceSource = iota
+	// PriceOpen — цена открытия
+	PriceOpen
+	// PriceHL2 — середина диапазона (High + Low) / 2
+	PriceHL2
+	// PriceHLC3 — типичная цена (High + Low + Close) / 3
+	PriceHLC3
+	// PriceOHLC4 — средняя цена (Open + High + Low + Close) / 4
+	PriceOHLC4
+)
+
+func (p PriceSource) String() string {
+	switch p {
+	case PriceOpen:
+		return "open"
+	case PriceHL2:
+		return "hl2"
+	case PriceHLC3:
+		return "hlc3"
+	case PriceOHLC4:
+		return "ohlc4"
+	default:
+		return "close"
+	}
+}
+
+// ParsePriceSource — разбирает значение флага --price-source
+func ParsePriceSource(s string) (PriceSource, error) {
+	switch s {
+	case "", "close":
+		return PriceClose, nil
+	case "open":
+		return PriceOpen, nil
+	case "hl2":
+		return PriceHL2, nil
+	case "hlc3":
+		return PriceHLC3, nil
+	case "ohlc4":
+		return PriceOHLC4, nil
+	default:
+		return PriceClose, fmt.Errorf("неизвестный источник цены '%s' (ожидается close, open, hl2, hlc3 или ohlc4)", s)
+	}
+}
+
+// PriceOf — цена свечи по источнику; если нужных полей нет, используется цена закрытия
+func (p PriceSource) PriceOf(c Candle) float64 {
+	closePrice := c.Close.ToFloat64()
+	open, high, low := c.Open.ToFloat64(), c.High.ToFloat64(), c.Low.ToFloat64()
+
+	switch p {
+	case PriceOpen:
+		if open > 0 {
+			return open
+		}
+	case PriceHL2:
+		if high > 0 && low > 0 {
+			return (high + low) / 2
+		}
+	case PriceHLC3:
+		if high > 0 && low > 0 {
+			return (high + low + closePrice) / 3
+		}
+	case PriceOHLC4:
+		if open > 0 && high > 0 && low > 0 {
+			return (open + high + low + closePrice) / 4
+		}
+	}
+	return closePrice
+}
+
+// ExtractPrices — ценовой ряд свечей по выбранному источнику
+func ExtractPrices(candles []Candle, source PriceSource) []float64 {
+	prices := make([]float64, len(candles))
+	for i, candle := range candles {
+		prices[i] = source.PriceOf(candle)
+	}
+	return prices
+}
+
+// defaultPriceSource — источник цены для стратегий, перешедших на ExtractPrices (задается флагом --price-source).
+// Кэшируемые индикаторы по свечам (RSI, SMA и др.) по-прежнему считаются по цене закрытия
+var defaultPriceSource = PriceClose
+
+// SetDefaultPriceSource — задает источник цены; вызывается один раз при старте, до запуска стратегий
+func SetDefaultPriceSource(source PriceSource) {
+	defaultPriceSource = source
+}
+
+// DefaultPriceSource — текущий источник цены по умолчанию
+func DefaultPriceSource() PriceSource {
+	return defaultPriceSource
+}
@@ -0,0 +1,30 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestExtractPrices_Sources(t *testing.T) {
+	candles := []Candle{{Open: Price(10), High: Price(14), Low: Price(8), Close: Price(12)}}
+
+	expected := map[PriceSource]float64{
+		PriceClose: 12,
+		PriceOpen:  10,
+		PriceHL2:   11,
+		PriceHLC3:  34.0 / 3,
+		PriceOHLC4: 11,
+	}
+	for source, want := range expected {
+		got := ExtractPrices(candles, source)[0]
+		if math.Abs(got-want) > 1e-9 {
+			t.Errorf("%s: expected %.4f, got %.4f", source, want, got)
+		}
+	}
+
+	// Без OHLC-полей используется цена закрытия
+	closeOnly := []Candle{{Close: Price(5)}}
+	if got := ExtractPrices(closeOnly, PriceHLC3)[0]; got != 5 {
+		t.Errorf("expected fallback to close 5, got %.4f", got)
+	}
+}
@@ -0,0 +1,70 @@
+// resample.go
+// Агрегация свечей в более крупный таймфрейм (например, 10m → 1h)
+package internal
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// ResampleCandles — объединяет свечи в бары заданной длительности.
+// Границы баров выравниваются по UTC (time.Truncate); Open — первой свечи, Close — последней,
+// High/Low — экстремумы, объем суммируется. Свечи должны идти по возрастанию времени.
+func ResampleCandles(candles []Candle, interval time.Duration) ([]Candle, error) {
+	if interval <= 0 {
+		return nil, fmt.Errorf("интервал должен быть положительным, получено %v", interval)
+	}
+	if len(candles) == 0 {
+		return nil, nil
+	}
+	if source := medianInterval(candles); source == 0 {
+		return nil, fmt.Errorf("у свечей нет времени — агрегация невозможна")
+	} else if interval < source {
+		return nil, fmt.Errorf("таймфрейм %v меньше исходного интервала свечей %v", interval, source)
+	}
+
+	var resampled []Candle
+	var current Candle
+	var bucket time.Time
+
+	for i, c := range candles {
+		if c.ParsedTime.IsZero() {
+			return nil, fmt.Errorf("свеча %d без времени — агрегация невозможна", i)
+		}
+
+		start := c.ParsedTime.Truncate(interval)
+		if i > 0 {
+			if start.Equal(bucket) {
+				if c.High > current.High {
+					current.High = c.High
+				}
+				if c.Low < current.Low {
+					current.Low = c.Low
+				}
+				current.Close = c.Close
+				current.VolumeFloat += c.VolumeFloat
+				current.IsComplete = c.IsComplete
+				continue
+			}
+			if start.Before(bucket) {
+				return nil, fmt.Errorf("свеча %d (%s) идет раньше предыдущей — данные не отсортированы", i, c.Time)
+			}
+			resampled = append(resampled, finishResampledCandle(current))
+		}
+
+		bucket = start
+		current = c
+		current.ParsedTime = start
+		current.Time = start.UTC().Format(time.RFC3339)
+	}
+	resampled = append(resampled, finishResampledCandle(current))
+
+	return resampled, nil
+}
+
+// finishResampledCandle — синхронизирует строковый объем с суммой объемов бара
+func finishResampledCandle(c Candle) Candle {
+	c.Volume = strconv.FormatInt(int64(c.VolumeFloat), 10)
+	return c
+}
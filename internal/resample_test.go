@@ -0,0 +1,46 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestResampleCandles_AggregatesOHLCV(t *testing.T) {
+	start := time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC)
+	candles := make([]Candle, 6)
+	for i := range candles {
+		price := 100.0 + float64(i)
+		candles[i] = Candle{
+			Open:        Price(price),
+			High:        Price(price + 2),
+			Low:         Price(price - 1),
+			Close:       Price(price + 1),
+			VolumeFloat: 10,
+			ParsedTime:  start.Add(time.Duration(i) * 20 * time.Minute),
+		}
+	}
+
+	hourly, err := ResampleCandles(candles, time.Hour)
+	if err != nil {
+		t.Fatalf("Unexpected error: %v", err)
+	}
+	if len(hourly) != 2 {
+		t.Fatalf("Expected 2 hourly candles, got %d", len(hourly))
+	}
+
+	first := hourly[0]
+	if first.Open != 100 || first.Close != 103 || first.High != 104 || first.Low != 99 {
+		t.Errorf("Unexpected OHLC for first hour: O=%.0f H=%.0f L=%.0f C=%.0f", first.Open, first.High, first.Low, first.Close)
+	}
+	if first.VolumeFloat != 30 || first.Volume != "30" {
+		t.Errorf("Expected volume 30, got %.0f (%s)", first.VolumeFloat, first.Volume)
+	}
+	if !hourly[1].ParsedTime.Equal(start.Add(time.Hour)) {
+		t.Errorf("Expected second bar at %v, got %v", start.Add(time.Hour), hourly[1].ParsedTime)
+	}
+
+	// Таймфрейм мельче исходного интервала не допускается
+	if _, err := ResampleCandles(candles, 10*time.Minute); err == nil {
+		t.Error("Expected error for timeframe smaller than source interval")
+	}
+}
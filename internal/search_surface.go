@@ -0,0 +1,70 @@
+// search_surface.go
+// Поверхность поиска оптимизатора: все проверенные конфигурации с метриками (флаг --dump-search)
+package internal
+
+import (
+	"encoding/json"
+	"sync"
+)
+
+// SearchPoint — одна проверенная оптимизатором конфигурация и результат ее бэктеста
+type SearchPoint struct {
+	// Config — описание конфигурации (String / DefaultConfigString)
+	Config string
+	// Params — параметры конфигурации по JSON-тегам; пусто, если конфигурация не сериализуется
+	Params      map[string]interface{}
+	Score       float64 // значение целевой функции, по которому выбирался лучший результат
+	TotalProfit float64
+	SharpeRatio float64
+	TradeCount  int
+	NonFinite   bool
+}
+
+// searchRecorder — накопитель точек поиска; nil — запись выключена (поведение по умолчанию)
+var searchRecorder *searchRecording
+
+type searchRecording struct {
+	mu     sync.Mutex
+	points []SearchPoint
+}
+
+// EnableSearchRecording — включает запись поверхности поиска в общих оптимизаторах
+// (ProcessConfigs, GridSearchOptimizer); вызывается один раз при старте, до запуска стратегий
+func EnableSearchRecording() {
+	searchRecorder = &searchRecording{}
+}
+
+// TakeSearchSurface — возвращает накопленные точки в порядке записи и очищает накопитель
+func TakeSearchSurface() []SearchPoint {
+	if searchRecorder == nil {
+		return nil
+	}
+	searchRecorder.mu.Lock()
+	defer searchRecorder.mu.Unlock()
+	points := searchRecorder.points
+	searchRecorder.points = nil
+	return points
+}
+
+// recordSearchPoint — сохраняет результат одной конфигурации; безопасен для параллельного перебора
+func recordSearchPoint(config interface{}, description string, result BacktestResult, score float64) {
+	if searchRecorder == nil {
+		return
+	}
+
+	point := SearchPoint{
+		Config:      description,
+		Score:       score,
+		TotalProfit: result.TotalProfit,
+		SharpeRatio: result.SharpeRatio,
+		TradeCount:  result.TradeCount,
+		NonFinite:   result.NonFinite,
+	}
+	if data, err := json.Marshal(config); err == nil {
+		_ = json.Unmarshal(data, &point.Params)
+	}
+
+	searchRecorder.mu.Lock()
+	searchRecorder.points = append(searchRecorder.points, point)
+	searchRecorder.mu.Unlock()
+}
@@ -0,0 +1,110 @@
+// session_filter.go
+// Фильтр торговой сессии для внутридневных свечей: вне заданного времени суток сделки не совершаются
+package internal
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// SessionFilter — торговая сессия по времени суток [Start, End). Если Start больше End,
+// сессия переходит через полночь (например, 22:00–02:00)
+type SessionFilter struct {
+	Start, End time.Duration // смещение от полуночи
+	// Location — часовой пояс, в котором задано время сессии (nil = часовой пояс времени свечи)
+	Location *time.Location
+}
+
+// NewSessionFilter — сессия из строки вида 10:00-18:45 и часового пояса (например, Europe/Moscow);
+// пустая строка сессии — фильтр выключен (nil)
+func NewSessionFilter(spec, timezone string) (*SessionFilter, error) {
+	if spec == "" {
+		if timezone != "" {
+			return nil, fmt.Errorf("--session-tz задает часовой пояс сессии: укажите --session")
+		}
+		return nil, nil
+	}
+
+	bounds := strings.Split(spec, "-")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("неверная сессия '%s' (ожидается ЧЧ:ММ-ЧЧ:ММ, например 10:00-18:45)", spec)
+	}
+	filter := &SessionFilter{}
+	var err error
+	if filter.Start, err = parseTimeOfDay(bounds[0]); err != nil {
+		return nil, err
+	}
+	if filter.End, err = parseTimeOfDay(bounds[1]); err != nil {
+		return nil, err
+	}
+	if filter.Start == filter.End {
+		return nil, fmt.Errorf("пустая сессия '%s': начало совпадает с концом", spec)
+	}
+
+	if timezone != "" {
+		if filter.Location, err = time.LoadLocation(timezone); err != nil {
+			return nil, fmt.Errorf("неизвестный часовой пояс сессии '%s': %w", timezone, err)
+		}
+	}
+	return filter, nil
+}
+
+// parseTimeOfDay — время суток ЧЧ:ММ как смещение от полуночи
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(s))
+	if err != nil {
+		return 0, fmt.Errorf("неверное время сессии '%s' (ожидается ЧЧ:ММ)", s)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
+
+func (f *SessionFilter) String() string {
+	clock := func(d time.Duration) string {
+		return fmt.Sprintf("%02d:%02d", int(d.Hours()), int(d.Minutes())%60)
+	}
+	s := clock(f.Start) + "-" + clock(f.End)
+	if f.Location != nil {
+		s += " " + f.Location.String()
+	}
+	return s
+}
+
+// Contains — попадает ли момент t в сессию; нулевое время (свеча без метки) — вне любой сессии
+func (f *SessionFilter) Contains(t time.Time) bool {
+	if t.IsZero() {
+		return false
+	}
+	if f.Location != nil {
+		t = t.In(f.Location)
+	}
+	tod := time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+	if f.Start < f.End {
+		return tod >= f.Start && tod < f.End
+	}
+	return tod >= f.Start || tod < f.End
+}
+
+// Apply — копия сигналов, в которой сигналы на свечах вне сессии заменены на HOLD.
+// SELL вне сессии не теряется, а переносится на первую свечу сессии без собственного сигнала:
+// иначе позиция, которую стратегия закрыла ночью, оставалась бы открытой до следующего SELL
+func (f *SessionFilter) Apply(candles []Candle, signals []SignalType) []SignalType {
+	masked := make([]SignalType, len(signals))
+	copy(masked, signals)
+
+	pendingExit := false
+	for i := range masked {
+		if i >= len(candles) || !f.Contains(candles[i].ParsedTime) {
+			if masked[i] == SELL {
+				pendingExit = true
+			}
+			masked[i] = HOLD
+			continue
+		}
+		if pendingExit && masked[i] == HOLD {
+			masked[i] = SELL
+		}
+		pendingExit = false
+	}
+	return masked
+}
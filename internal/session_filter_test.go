@@ -0,0 +1,62 @@
+package internal
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSessionFilter_CrossMidnight(t *testing.T) {
+	filter, err := NewSessionFilter("22:00-02:00", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	at := func(hour, minute int) Candle {
+		return Candle{Close: 100, ParsedTime: day.Add(time.Duration(hour)*time.Hour + time.Duration(minute)*time.Minute)}
+	}
+
+	for _, tc := range []struct {
+		candle Candle
+		inside bool
+	}{
+		{at(21, 30), false},
+		{at(22, 0), true},
+		{at(23, 59), true},
+		{at(24, 0), true}, // полночь следующего дня
+		{at(25, 30), true},
+		{at(26, 0), false},
+		{at(12, 0), false},
+		{Candle{Close: 100}, false}, // свеча без времени
+	} {
+		if got := filter.Contains(tc.candle.ParsedTime); got != tc.inside {
+			t.Errorf("Contains(%s) = %v, want %v", tc.candle.ParsedTime.Format("15:04"), got, tc.inside)
+		}
+	}
+
+	candles := []Candle{at(21, 0), at(22, 30), at(26, 30), at(27, 0), at(46, 0), at(46, 30)}
+	signals := []SignalType{BUY, BUY, SELL, HOLD, HOLD, HOLD}
+	masked := filter.Apply(candles, signals)
+
+	// BUY до сессии пропадает, SELL после ее конца переносится на первую свечу следующей сессии
+	expected := []SignalType{HOLD, BUY, HOLD, HOLD, SELL, HOLD}
+	for i := range expected {
+		if masked[i] != expected[i] {
+			t.Errorf("bar %d: expected %v, got %v", i, expected[i], masked[i])
+		}
+	}
+	if signals[0] != BUY {
+		t.Errorf("Expected input signals to be left unchanged")
+	}
+}
+
+func TestNewSessionFilter_RejectsBadSpecs(t *testing.T) {
+	for _, spec := range []string{"10:00", "25:00-18:00", "10:00-10:00"} {
+		if _, err := NewSessionFilter(spec, ""); err == nil {
+			t.Errorf("Expected an error for session '%s'", spec)
+		}
+	}
+	if filter, err := NewSessionFilter("", ""); filter != nil || err != nil {
+		t.Errorf("Expected no filter for an empty session, got %v, %v", filter, err)
+	}
+}
@@ -0,0 +1,83 @@
+// signal_conflict.go
+// Разрешение противоречивых сигналов, когда несколько источников дают BUY и SELL на одном баре
+package internal
+
+// ConflictPolicy — правило выбора сигнала при одновременных BUY и SELL
+type ConflictPolicy int
+
+const (
+	// ConflictPreferHold — при противоречии бар пропускается (HOLD). Поведение по умолчанию:
+	// движок не открывает и не закрывает позицию, если источники сигналов не согласны между собой.
+	ConflictPreferHold ConflictPolicy = iota
+	// ConflictPreferClose — выбирается сигнал, закрывающий открытую позицию: SELL для длинной,
+	// BUY для короткой; без позиции — HOLD
+	ConflictPreferClose
+	// ConflictPreferStrength — побеждает сторона с большим числом голосов; при равенстве — HOLD
+	ConflictPreferStrength
+)
+
+func (p ConflictPolicy) String() string {
+	switch p {
+	case ConflictPreferClose:
+		return "prefer-close"
+	case ConflictPreferStrength:
+		return "prefer-signal-strength"
+	default:
+		return "prefer-hold"
+	}
+}
+
+// PositionSide — сторона открытой позиции движка
+type PositionSide int
+
+const (
+	PositionFlat PositionSide = iota
+	PositionLong
+	PositionShort
+)
+
+// positionSide — сторона позиции по количеству бумаг (отрицательное — короткая позиция)
+func positionSide(holdings float64) PositionSide {
+	switch {
+	case holdings > 0:
+		return PositionLong
+	case holdings < 0:
+		return PositionShort
+	default:
+		return PositionFlat
+	}
+}
+
+// Resolve — возвращает итоговый сигнал бара по числу голосов BUY/SELL и стороне открытой позиции
+func (p ConflictPolicy) Resolve(buyVotes, sellVotes int, side PositionSide) SignalType {
+	switch {
+	case buyVotes == 0 && sellVotes == 0:
+		return HOLD
+	case sellVotes == 0:
+		return BUY
+	case buyVotes == 0:
+		return SELL
+	}
+
+	// Есть и BUY, и SELL
+	switch p {
+	case ConflictPreferClose:
+		switch side {
+		case PositionLong:
+			return SELL
+		case PositionShort:
+			return BUY
+		}
+		return HOLD
+	case ConflictPreferStrength:
+		if buyVotes > sellVotes {
+			return BUY
+		}
+		if sellVotes > buyVotes {
+			return SELL
+		}
+		return HOLD
+	default:
+		return HOLD
+	}
+}
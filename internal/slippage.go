@@ -0,0 +1,57 @@
+// slippage.go
+// Модель проскальзывания стратегии: в абсолютных единицах цены или в долях цены
+package internal
+
+import "fmt"
+
+// SlippageMode — единицы, в которых задано проскальзывание
+type SlippageMode int
+
+const (
+	// SlippageModeAbsolute — единицы цены на каждую сторону сделки (0.01 = копейка на рубль цены)
+	SlippageModeAbsolute SlippageMode = iota
+	// SlippageModePercent — доля цены исполнения на каждую сторону сделки (0.0005 = 0.05%)
+	SlippageModePercent
+)
+
+// Slippage — проскальзывание стратегии: величина Value в единицах Mode.
+// Абсолютное проскальзывание одинаково по цене для дешевых и дорогих инструментов, поэтому
+// для сравнения бумаг с разным уровнем цен нужна доля цены
+type Slippage struct {
+	Mode  SlippageMode
+	Value float64
+}
+
+// SlippageAbsolute — x единиц цены на каждую сторону: покупка дороже, продажа дешевле на x
+func SlippageAbsolute(x float64) Slippage {
+	return Slippage{Mode: SlippageModeAbsolute, Value: x}
+}
+
+// SlippagePercent — доля p цены исполнения на каждую сторону (0.001 = 0.1%)
+func SlippagePercent(p float64) Slippage {
+	return Slippage{Mode: SlippageModePercent, Value: p}
+}
+
+func (s Slippage) String() string {
+	if s.Mode == SlippageModePercent {
+		return fmt.Sprintf("%.4g%%", s.Value*100)
+	}
+	return fmt.Sprintf("%.4g", s.Value)
+}
+
+// apply — параметры движка с проскальзыванием стратегии. Глобальное процентное проскальзывание
+// (BacktestOptions.SlippagePercent из --slippage-pct) сохраняется, проскальзывание стратегии добавляется к нему
+func (s Slippage) apply(opts BacktestOptions) BacktestOptions {
+	if s.Mode == SlippageModePercent {
+		opts.Slippage = 0
+		opts.SlippagePercent += s.Value
+		return opts
+	}
+	opts.Slippage = s.Value
+	return opts
+}
+
+// BacktestWithSlippage — Backtest с явной моделью проскальзывания стратегии
+func BacktestWithSlippage(candles []Candle, signals []SignalType, slippage Slippage) BacktestResult {
+	return BacktestWithOptions(candles, signals, slippage.apply(defaultBacktestOptions))
+}
@@ -0,0 +1,38 @@
+package internal
+
+import (
+	"math"
+	"testing"
+)
+
+func TestSlippage_AbsoluteVsPercent(t *testing.T) {
+	candles := []Candle{{Close: 100}, {Close: 110}}
+	signals := []SignalType{BUY, SELL}
+	capital := DefaultBacktestOptions().Capital()
+
+	// Абсолютное: покупка по 100.5, продажа по 109.5
+	absolute := BacktestWithSlippage(candles, signals, SlippageAbsolute(0.5))
+	if want := capital * 109.5 / 100.5; math.Abs(absolute.FinalPortfolio-want) > 1e-9 {
+		t.Errorf("Absolute: expected final %.6f, got %.6f", want, absolute.FinalPortfolio)
+	}
+
+	// В долях цены: покупка по 100.5, продажа по 110 * 0.995 = 109.45
+	percent := BacktestWithSlippage(candles, signals, SlippagePercent(0.005))
+	if want := capital * 109.45 / 100.5; math.Abs(percent.FinalPortfolio-want) > 1e-9 {
+		t.Errorf("Percent: expected final %.6f, got %.6f", want, percent.FinalPortfolio)
+	}
+
+	// Числовое проскальзывание стратегий (0.01 по умолчанию) — единицы цены, как и раньше
+	provider := NewSlippageProvider(SlippageAbsolute(0.01))
+	legacy := Backtest(candles, signals, provider.GetSlippage())
+	if modeled := BacktestWithSlippage(candles, signals, provider.Slippage()); modeled.FinalPortfolio != legacy.FinalPortfolio {
+		t.Errorf("Expected provider model to match Backtest with units, got %.6f vs %.6f", modeled.FinalPortfolio, legacy.FinalPortfolio)
+	}
+
+	// SetSlippage меняет величину, но не единицы
+	provider = NewSlippageProvider(SlippagePercent(0.001))
+	provider.SetSlippage(0.005)
+	if got := provider.Slippage(); got != SlippagePercent(0.005) {
+		t.Errorf("Expected percent mode to be kept, got %+v", got)
+	}
+}
@@ -2,8 +2,11 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
+	"fmt"
 	"log"
+	"math"
 
 	"github.com/samber/lo"
 
@@ -21,7 +24,8 @@ type Strategy interface {
 
 	Name() string
 	GenerateSignalsWithConfig(candles []Candle, config StrategyConfig) []SignalType
-	OptimizeWithConfig(candles []Candle) StrategyConfig
+	// OptimizeWithConfig — подбор параметров; при отмене ctx перебор прекращается досрочно
+	OptimizeWithConfig(ctx context.Context, candles []Candle) StrategyConfig
 }
 
 type InternalStrategy interface {
@@ -66,8 +70,38 @@ func (s *BaseConfig) LoadConfigFromMap(raw json.RawMessage) StrategyConfig {
 
 var strategies = make(map[string]Strategy)
 
+// RegisterStrategy — регистрирует стратегию V1; при конфликте имени или невалидной конфигурации
+// по умолчанию паникует, чтобы ошибка всплыла при старте, а не во время прогона
 func RegisterStrategy(name string, s Strategy) {
+	var config interface{ Validate() error }
+	if defaultConfig := s.DefaultConfig(); defaultConfig != nil {
+		config = defaultConfig
+	}
+	if err := checkRegistration(name, config); err != nil {
+		panic(err)
+	}
 	strategies[name] = s
+	registerCategory(name, s)
+}
+
+// checkRegistration — имя стратегии должно быть уникальным среди V1 и V2, а конфигурация по умолчанию — проходить Validate()
+func checkRegistration(name string, defaultConfig interface{ Validate() error }) error {
+	if name == "" {
+		return fmt.Errorf("стратегия зарегистрирована с пустым именем")
+	}
+	if _, ok := strategies[name]; ok {
+		return fmt.Errorf("стратегия %s уже зарегистрирована (V1)", name)
+	}
+	if _, ok := strategyRegistryV2[name]; ok {
+		return fmt.Errorf("стратегия %s уже зарегистрирована (V2)", name)
+	}
+	if defaultConfig == nil {
+		return fmt.Errorf("стратегия %s: нет конфигурации по умолчанию", name)
+	}
+	if err := defaultConfig.Validate(); err != nil {
+		return fmt.Errorf("стратегия %s: конфигурация по умолчанию не проходит проверку: %w", name, err)
+	}
+	return nil
 }
 
 func GetStrategy(name string) Strategy {
@@ -78,6 +112,12 @@ func GetStrategy(name string) Strategy {
 	return s
 }
 
+// LookupStrategy — стратегия V1 по имени без завершения программы, если ее нет (в отличие от GetStrategy)
+func LookupStrategy(name string) (Strategy, bool) {
+	s, ok := strategies[name]
+	return s, ok
+}
+
 func GetStrategyNames() []string {
 	names := make([]string, 0, len(strategies))
 	for name := range strategies {
@@ -86,16 +126,27 @@ func GetStrategyNames() []string {
 	return names
 }
 
-func (b *BaseStrategy) ProcessConfigs(cc InternalStrategy, candles []Candle, configs []StrategyConfig) lo.Tuple2[StrategyConfig, float64] {
+// ProcessConfigs — параллельный перебор конфигураций; после отмены ctx оставшиеся конфигурации получают оценку -Inf
+func (b *BaseStrategy) ProcessConfigs(ctx context.Context, cc InternalStrategy, candles []Candle, configs []StrategyConfig) lo.Tuple2[StrategyConfig, float64] {
 	configs = lo.Filter(configs, func(x StrategyConfig, index int) bool {
 		return x.Validate() == nil
 	})
+	configs = FilterByDataRequirement(configs, len(candles))
 
 	configsWithProfit := lop.Map(configs, func(c StrategyConfig, index int) lo.Tuple2[StrategyConfig, float64] {
+		if ctx.Err() != nil {
+			return lo.Tuple2[StrategyConfig, float64]{A: c, B: math.Inf(-1)}
+		}
 
 		signals := cc.GenerateSignalsWithConfig(candles, c)
+		if err := ValidateSignals(candles, signals); err != nil {
+			Log.Debugf("⚠️ Конфигурация %s пропущена: %v", c.DefaultConfigString(), err)
+			return lo.Tuple2[StrategyConfig, float64]{A: c, B: math.Inf(-1)}
+		}
 		result := Backtest(candles, signals, b.GetSlippage())
-		return lo.Tuple2[StrategyConfig, float64]{A: c, B: result.TotalProfit}
+		score := ObjectiveScore(result)
+		recordSearchPoint(c, c.DefaultConfigString(), result, score)
+		return lo.Tuple2[StrategyConfig, float64]{A: c, B: score}
 	})
 
 	max := lo.MaxBy(configsWithProfit, func(
@@ -0,0 +1,69 @@
+package internal
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+type registrationTestConfig struct {
+	Period int
+}
+
+func (c *registrationTestConfig) Validate() error {
+	if c.Period <= 0 {
+		return errors.New("period must be positive")
+	}
+	return nil
+}
+
+func (c *registrationTestConfig) DefaultConfigString() string { return "test" }
+
+type registrationTestStrategy struct {
+	BaseStrategy
+}
+
+func (s *registrationTestStrategy) Name() string { return "registration_test" }
+
+func (s *registrationTestStrategy) GenerateSignalsWithConfig(candles []Candle, config StrategyConfig) []SignalType {
+	return make([]SignalType, len(candles))
+}
+
+func (s *registrationTestStrategy) OptimizeWithConfig(ctx context.Context, candles []Candle) StrategyConfig {
+	return s.DefaultConfig()
+}
+
+func newRegistrationTestStrategy(period int) *registrationTestStrategy {
+	return &registrationTestStrategy{BaseStrategy{BaseConfig{Config: &registrationTestConfig{Period: period}}}}
+}
+
+// registerRecovering — регистрирует стратегию и возвращает панику регистрации, если она была
+func registerRecovering(name string, s Strategy) (recovered interface{}) {
+	defer func() { recovered = recover() }()
+	RegisterStrategy(name, s)
+	return nil
+}
+
+func TestRegisterStrategy_RejectsDuplicateName(t *testing.T) {
+	const name = "registration_test_duplicate"
+	defer delete(strategies, name)
+
+	if r := registerRecovering(name, newRegistrationTestStrategy(10)); r != nil {
+		t.Fatalf("First registration failed: %v", r)
+	}
+	if r := registerRecovering(name, newRegistrationTestStrategy(20)); r == nil {
+		t.Fatal("Expected duplicate registration to be rejected")
+	}
+	if period := strategies[name].DefaultConfig().(*registrationTestConfig).Period; period != 10 {
+		t.Errorf("Duplicate registration overwrote the original strategy (period %d)", period)
+	}
+}
+
+func TestRegisterStrategy_RejectsInvalidDefaultConfig(t *testing.T) {
+	const name = "registration_test_invalid"
+	defer delete(strategies, name)
+
+	if r := registerRecovering(name, newRegistrationTestStrategy(0)); r == nil {
+		t.Fatal("Expected strategy with invalid default config to be rejected")
+	}
+}
@@ -3,9 +3,11 @@
 package internal
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
-	"log"
+	"math"
+	"sync"
 
 	"github.com/samber/lo"
 	lop "github.com/samber/lo/parallel"
@@ -42,9 +44,17 @@ type PredictiveSignalGenerator interface {
 	PredictNextSignal(candles []Candle, config StrategyConfigV2) *FutureSignal
 }
 
+// ExplainingSignalGenerator - генератор, объясняющий, какое условие удержало стратегию от сделки
+type ExplainingSignalGenerator interface {
+	SignalGenerator
+	// ExplainSignals - те же сигналы, что и GenerateSignals, плюс причина HOLD для каждого бара
+	ExplainSignals(candles []Candle, config StrategyConfigV2) ([]SignalType, HoldReasons)
+}
+
 // ConfigOptimizer - оптимизатор конфигурации
+// Перебор прекращается при отмене ctx; тогда Optimize возвращает nil
 type ConfigOptimizer interface {
-	Optimize(candles []Candle, generator SignalGenerator) StrategyConfigV2
+	Optimize(ctx context.Context, candles []Candle, generator SignalGenerator) StrategyConfigV2
 }
 
 // ConfigManager - управление конфигурацией
@@ -67,19 +77,27 @@ type TradingStrategy interface {
 
 // SlippageProvider - провайдер проскальзывания
 type SlippageProvider struct {
-	slippage float64
+	slippage Slippage
 }
 
-func NewSlippageProvider(slippage float64) *SlippageProvider {
+// NewSlippageProvider - провайдер с моделью проскальзывания: SlippageAbsolute или SlippagePercent
+func NewSlippageProvider(slippage Slippage) *SlippageProvider {
 	return &SlippageProvider{slippage: slippage}
 }
 
+// GetSlippage - величина проскальзывания в единицах его модели (см. Slippage)
 func (sp *SlippageProvider) GetSlippage() float64 {
-	return sp.slippage
+	return sp.slippage.Value
 }
 
+// SetSlippage - новая величина проскальзывания; модель (единицы) сохраняется
 func (sp *SlippageProvider) SetSlippage(slippage float64) {
-	sp.slippage = slippage
+	sp.slippage.Value = slippage
+}
+
+// Slippage - модель проскальзывания вместе с величиной
+func (sp *SlippageProvider) Slippage() Slippage {
+	return sp.slippage
 }
 
 // ============================================================================
@@ -117,6 +135,11 @@ func (cm *ConfigManagerImpl) LoadFromJSON(raw json.RawMessage) (StrategyConfigV2
 type GridSearchOptimizer struct {
 	slippageProvider *SlippageProvider
 	configGenerator  func() []StrategyConfigV2 // генератор конфигураций для перебора
+	limit            *GridLimit                // ограничение перебора; nil — DefaultGridLimit
+	plain            bool                      // только перебор сетки, без walk-forward по умолчанию (внутри WalkForwardOptimizer)
+
+	walkForwardOnce sync.Once
+	walkForward     *WalkForwardOptimizer
 }
 
 func NewGridSearchOptimizer(
@@ -129,7 +152,43 @@ func NewGridSearchOptimizer(
 	}
 }
 
-func (gso *GridSearchOptimizer) Optimize(candles []Candle, generator SignalGenerator) StrategyConfigV2 {
+// WithMaxConfigs — проверять не больше maxConfigs случайно выбранных конфигураций сетки (0 = все)
+func (gso *GridSearchOptimizer) WithMaxConfigs(maxConfigs int, seed int64) *GridSearchOptimizer {
+	gso.limit = &GridLimit{MaxConfigs: maxConfigs, Seed: seed}
+	return gso
+}
+
+// Optimize — перебор сетки по всем свечам; с SetDefaultWalkForward — walk-forward оптимизация
+func (gso *GridSearchOptimizer) Optimize(ctx context.Context, candles []Candle, generator SignalGenerator) StrategyConfigV2 {
+	if wfo := gso.walkForwardOptimizer(); wfo != nil {
+		return wfo.Optimize(ctx, candles, generator)
+	}
+	return gso.search(ctx, candles, generator, true)
+}
+
+// WalkForwardReport — отчет последней walk-forward оптимизации (nil, если walk-forward выключен)
+func (gso *GridSearchOptimizer) WalkForwardReport() *WalkForwardReport {
+	if wfo := gso.walkForwardOptimizer(); wfo != nil {
+		return wfo.WalkForwardReport()
+	}
+	return nil
+}
+
+// walkForwardOptimizer — walk-forward поверх той же сетки и лимита перебора, если он включен по умолчанию
+func (gso *GridSearchOptimizer) walkForwardOptimizer() *WalkForwardOptimizer {
+	if gso.plain || defaultWalkForward.Window <= 0 {
+		return nil
+	}
+	gso.walkForwardOnce.Do(func() {
+		gso.walkForward = NewWalkForwardOptimizer(gso.slippageProvider, gso.configGenerator, defaultWalkForward.Window, defaultWalkForward.Step)
+		gso.walkForward.grid.limit = gso.limit
+	})
+	return gso.walkForward
+}
+
+// search — перебор сетки конфигураций по всем свечам; после отмены ctx оставшиеся конфигурации не проверяются.
+// verbose — выводить подвыборку и лучшую конфигурацию (walk-forward выключает вывод для отдельных шагов)
+func (gso *GridSearchOptimizer) search(ctx context.Context, candles []Candle, generator SignalGenerator, verbose bool) StrategyConfigV2 {
 	configs := gso.configGenerator()
 
 	// Фильтруем только валидные конфигурации
@@ -138,23 +197,50 @@ func (gso *GridSearchOptimizer) Optimize(candles []Candle, generator SignalGener
 	})
 
 	if len(validConfigs) == 0 {
-		log.Println("Warning: no valid configs for optimization")
+		Log.Warnf("Warning: no valid configs for optimization")
 		return nil
 	}
+	validConfigs = FilterByDataRequirement(validConfigs, len(candles))
+
+	limit := defaultGridLimit
+	if gso.limit != nil {
+		limit = *gso.limit
+	}
+	if total := len(validConfigs); limit.MaxConfigs > 0 && total > limit.MaxConfigs {
+		validConfigs = subsampleConfigs(validConfigs, limit)
+		if verbose {
+			fmt.Printf("Evaluating %d of %d configs (random subsample, seed %d)\n", len(validConfigs), total, limit.Seed)
+		}
+	}
 
 	// Параллельно тестируем все конфигурации
 	configsWithProfit := lop.Map(validConfigs, func(cfg StrategyConfigV2, _ int) lo.Tuple2[StrategyConfigV2, float64] {
+		if ctx.Err() != nil {
+			return lo.Tuple2[StrategyConfigV2, float64]{A: cfg, B: math.Inf(-1)}
+		}
 		signals := generator.GenerateSignals(candles, cfg)
-		result := Backtest(candles, signals, gso.slippageProvider.GetSlippage())
-		return lo.Tuple2[StrategyConfigV2, float64]{A: cfg, B: result.TotalProfit}
+		if err := ValidateSignals(candles, signals); err != nil {
+			Log.Debugf("⚠️ Конфигурация %s пропущена: %v", cfg.String(), err)
+			return lo.Tuple2[StrategyConfigV2, float64]{A: cfg, B: math.Inf(-1)}
+		}
+		result := BacktestWithSlippage(candles, signals, gso.slippageProvider.Slippage())
+		score := ObjectiveScore(result)
+		recordSearchPoint(cfg, cfg.String(), result, score)
+		return lo.Tuple2[StrategyConfigV2, float64]{A: cfg, B: score}
 	})
 
+	if ctx.Err() != nil {
+		return nil
+	}
+
 	// Находим лучшую конфигурацию
 	best := lo.MaxBy(configsWithProfit, func(a, b lo.Tuple2[StrategyConfigV2, float64]) bool {
 		return a.B > b.B
 	})
 
-	fmt.Printf("Best config found: %s with profit: %.4f\n", best.A.String(), best.B)
+	if verbose {
+		fmt.Printf("Best config found: %s with %s: %.4f\n", best.A.String(), ObjectiveLabel(), best.B)
+	}
 	return best.A
 }
 
@@ -164,6 +250,7 @@ type StrategyBase struct {
 	configManager    ConfigManager
 	configOptimizer  ConfigOptimizer
 	slippageProvider *SlippageProvider
+	category         string
 }
 
 // NewStrategyBase - конструктор с явными зависимостями (Dependency Injection)
@@ -199,8 +286,16 @@ func (sb *StrategyBase) PredictNextSignal(candles []Candle, config StrategyConfi
 	return nil
 }
 
-func (sb *StrategyBase) Optimize(candles []Candle, generator SignalGenerator) StrategyConfigV2 {
-	return sb.configOptimizer.Optimize(candles, generator)
+func (sb *StrategyBase) Optimize(ctx context.Context, candles []Candle, generator SignalGenerator) StrategyConfigV2 {
+	return sb.configOptimizer.Optimize(ctx, candles, generator)
+}
+
+// WalkForwardReport — отчет walk-forward последней оптимизации (nil, если оптимизатор его не ведет)
+func (sb *StrategyBase) WalkForwardReport() *WalkForwardReport {
+	if reporter, ok := sb.configOptimizer.(WalkForwardReporter); ok {
+		return reporter.WalkForwardReport()
+	}
+	return nil
 }
 
 func (sb *StrategyBase) DefaultConfig() StrategyConfigV2 {
@@ -219,10 +314,29 @@ func (sb *StrategyBase) SetSlippage(slippage float64) {
 	sb.slippageProvider.SetSlippage(slippage)
 }
 
+// Category - категория стратегии (см. CategorizedStrategy)
+func (sb *StrategyBase) Category() string {
+	return sb.category
+}
+
+// SetCategory - задает категорию стратегии; вызывается до регистрации
+func (sb *StrategyBase) SetCategory(category string) {
+	sb.category = category
+}
+
 var strategyRegistryV2 = make(map[string]TradingStrategy)
 
+// RegisterStrategyV2 — регистрирует стратегию V2 с теми же проверками, что и RegisterStrategy
 func RegisterStrategyV2(strategy TradingStrategy) {
+	var config interface{ Validate() error }
+	if defaultConfig := strategy.DefaultConfig(); defaultConfig != nil {
+		config = defaultConfig
+	}
+	if err := checkRegistration(strategy.Name(), config); err != nil {
+		panic(err)
+	}
 	strategyRegistryV2[strategy.Name()] = strategy
+	registerCategory(strategy.Name(), strategy)
 }
 
 func GetStrategyV2(name string) (TradingStrategy, bool) {
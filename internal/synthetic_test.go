@@ -0,0 +1,28 @@
+package internal
+
+import "testing"
+
+func TestGenerateSyntheticCandles_Deterministic(t *testing.T) {
+	a := GenerateSyntheticCandles(300, 7)
+	b := GenerateSyntheticCandles(300, 7)
+	if len(a) != 300 {
+		t.Fatalf("expected 300 candles, got %d", len(a))
+	}
+
+	for i := range a {
+		if a[i] != b[i] {
+			t.Fatalf("candle %d differs between runs with the same seed", i)
+		}
+		c := a[i]
+		if c.Low > c.Open || c.Low > c.Close || c.High < c.Open || c.High < c.Close {
+			t.Fatalf("candle %d violates OHLC bounds: %+v", i, c)
+		}
+		if i > 0 && !c.ToTime().After(a[i-1].ToTime()) {
+			t.Fatalf("candle %d is not after candle %d", i, i-1)
+		}
+	}
+
+	if other := GenerateSyntheticCandles(300, 8); other[299].Close == a[299].Close {
+		t.Errorf("expected a different series for a different seed")
+	}
+}
@@ -0,0 +1,33 @@
+// trend_gate.go
+// Фильтр бокового рынка для трендовых стратегий: входы запрещены, пока ADX ниже порога
+package internal
+
+// DefaultTrendGatePeriod — период ADX для фильтра бокового рынка
+const DefaultTrendGatePeriod = 14
+
+// IsTrendFollowing — относится ли стратегия к трендовым (к ним применяется --trend-gate)
+func IsTrendFollowing(name string) bool {
+	return GetStrategyCategory(name) == CategoryTrend
+}
+
+// ApplyTrendGate — копия сигналов, в которой BUY заменен на HOLD на барах с ADX ниже threshold
+// (рынок во флэте, тренду не за что держаться), в том числе до прогрева ADX.
+// SELL не трогается: выход из позиции, открытой в тренде, не должен блокироваться начавшимся флэтом
+func ApplyTrendGate(candles []Candle, signals []SignalType, threshold float64, period int) []SignalType {
+	gated := make([]SignalType, len(signals))
+	copy(gated, signals)
+	if threshold <= 0 {
+		return gated
+	}
+
+	adx := CalculateADX(candles, period)
+	for i, signal := range gated {
+		if signal != BUY {
+			continue
+		}
+		if adx == nil || i >= len(adx) || adx[i] < threshold {
+			gated[i] = HOLD
+		}
+	}
+	return gated
+}
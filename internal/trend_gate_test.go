@@ -0,0 +1,37 @@
+package internal
+
+import "testing"
+
+func TestApplyTrendGate_SuppressesEntriesInRange(t *testing.T) {
+	// 80 баров пилы вокруг 100, затем 80 баров устойчивого роста
+	candles := make([]Candle, 160)
+	for i := range candles {
+		price := 100.0 + float64(i%2)
+		if i >= 80 {
+			price = 100.0 + float64(i-79)
+		}
+		candles[i] = Candle{Open: Price(price), High: Price(price + 0.5), Low: Price(price - 0.5), Close: Price(price)}
+	}
+
+	signals := make([]SignalType, len(candles))
+	signals[60], signals[70] = BUY, SELL   // сделка во флэте
+	signals[130], signals[150] = BUY, SELL // сделка в тренде
+
+	gated := ApplyTrendGate(candles, signals, 25, DefaultTrendGatePeriod)
+	if gated[60] != HOLD {
+		t.Errorf("Expected entry in the ranging segment to be suppressed")
+	}
+	if gated[130] != BUY {
+		t.Errorf("Expected entry in the trend to pass the gate")
+	}
+	if gated[70] != SELL || gated[150] != SELL {
+		t.Errorf("Expected exits to pass the gate unchanged")
+	}
+	if signals[60] != BUY {
+		t.Errorf("Expected input signals to be left unchanged")
+	}
+
+	if before, after := Backtest(candles, signals, 0).TradeCount, Backtest(candles, gated, 0).TradeCount; before != 2 || after != 1 {
+		t.Errorf("Expected 2 trades without the gate and 1 with it, got %d and %d", before, after)
+	}
+}
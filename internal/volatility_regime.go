@@ -0,0 +1,122 @@
+// volatility_regime.go
+// Классификация режимов волатильности: общий фильтр для стратегий и движка
+package internal
+
+import (
+	"fmt"
+	"math"
+	"strings"
+)
+
+const (
+	// RegimeLow — волатильность заметно ниже своего среднего уровня
+	RegimeLow = "LOW"
+	// RegimeNormal — обычная волатильность (а также бары, для которых окно еще не набрано)
+	RegimeNormal = "NORMAL"
+	// RegimeHigh — волатильность заметно выше своего среднего уровня
+	RegimeHigh = "HIGH"
+
+	// DefaultRegimeWindow — окно доходностей для ClassifyVolatilityRegime по умолчанию
+	DefaultRegimeWindow = 20
+
+	highRegimeRatio = 1.5 // текущая волатильность выше средней в 1.5 раза — HIGH
+	lowRegimeRatio  = 0.7 // ниже 70% средней — LOW
+)
+
+// VolatilityRegimeOf — режим по текущей и средней волатильности
+func VolatilityRegimeOf(currentVol, avgVol float64) string {
+	if currentVol > avgVol*highRegimeRatio {
+		return RegimeHigh
+	} else if currentVol < avgVol*lowRegimeRatio {
+		return RegimeLow
+	}
+	return RegimeNormal
+}
+
+// ParseVolatilityRegime — разбирает значение флага --regime-filter (регистр не важен)
+func ParseVolatilityRegime(s string) (string, error) {
+	switch regime := strings.ToUpper(s); regime {
+	case RegimeLow, RegimeNormal, RegimeHigh:
+		return regime, nil
+	default:
+		return "", fmt.Errorf("неизвестный режим волатильности '%s' (ожидается low, normal или high)", s)
+	}
+}
+
+// ClassifyVolatilityRegime — режим волатильности на каждом баре: скользящее стандартное отклонение
+// доходностей за window баров сравнивается со средним значением этого отклонения от начала ряда до бара.
+// Используются только данные до текущего бара включительно; до заполнения окна режим — NORMAL
+func ClassifyVolatilityRegime(prices []float64, window int) []string {
+	regimes := make([]string, len(prices))
+	tracker := newRegimeTracker(window)
+	for i, price := range prices {
+		regimes[i] = tracker.next(price)
+	}
+	return regimes
+}
+
+// regimeTracker — ClassifyVolatilityRegime по одной цене за раз: потоковый бэктест получает бары по одному
+type regimeTracker struct {
+	window    int
+	count     int
+	prevPrice float64
+	// returns — последние window доходностей (кольцевой буфер по номеру бара)
+	returns    []float64
+	sum, sumSq float64
+	volSum     float64
+	volCount   int
+}
+
+func newRegimeTracker(window int) *regimeTracker {
+	t := &regimeTracker{window: window}
+	if window >= 2 {
+		t.returns = make([]float64, window)
+	}
+	return t
+}
+
+// next — режим волатильности на баре с ценой закрытия price
+func (t *regimeTracker) next(price float64) string {
+	i := t.count
+	t.count++
+	prevPrice := t.prevPrice
+	t.prevPrice = price
+	if t.window < 2 || i == 0 {
+		return RegimeNormal
+	}
+
+	ret := 0.0
+	if prevPrice > 0 {
+		ret = price/prevPrice - 1
+	}
+	t.sum += ret
+	t.sumSq += ret * ret
+	slot := i % t.window
+	if i > t.window {
+		// В слоте лежит доходность бара i-window, выходящая из окна
+		t.sum -= t.returns[slot]
+		t.sumSq -= t.returns[slot] * t.returns[slot]
+	}
+	t.returns[slot] = ret
+	if i < t.window {
+		return RegimeNormal
+	}
+
+	n := float64(t.window)
+	vol := math.Sqrt(math.Max(0, (t.sumSq-t.sum*t.sum/n)/(n-1)))
+	t.volSum += vol
+	t.volCount++
+	return VolatilityRegimeOf(vol, t.volSum/float64(t.volCount))
+}
+
+// regimeTracker — классификатор режима для запрета входов (nil, если фильтр выключен)
+func (o BacktestOptions) regimeTracker() *regimeTracker {
+	if o.RegimeFilter == "" {
+		return nil
+	}
+	window := o.RegimeWindow
+	if window <= 0 {
+		window = DefaultRegimeWindow
+	}
+	return newRegimeTracker(window)
+}
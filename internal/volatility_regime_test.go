@@ -0,0 +1,47 @@
+package internal
+
+import "testing"
+
+func TestClassifyVolatilityRegime_DetectsVolatilitySpike(t *testing.T) {
+	// 200 баров с колебаниями ±0.1%, затем 30 баров с колебаниями ±3%
+	prices := []float64{100}
+	for i := 1; i < 230; i++ {
+		move := 0.001
+		if i >= 200 {
+			move = 0.03
+		}
+		if i%2 == 0 {
+			move = -move
+		}
+		prices = append(prices, prices[i-1]*(1+move))
+	}
+
+	regimes := ClassifyVolatilityRegime(prices, 20)
+	if len(regimes) != len(prices) {
+		t.Fatalf("Expected %d regimes, got %d", len(prices), len(regimes))
+	}
+	if regimes[10] != RegimeNormal || regimes[150] != RegimeNormal {
+		t.Errorf("Expected NORMAL during warm-up and the quiet period, got %s and %s", regimes[10], regimes[150])
+	}
+	if regimes[215] != RegimeHigh {
+		t.Errorf("Expected HIGH after the volatility spike, got %s", regimes[215])
+	}
+}
+
+func TestBacktest_RegimeFilterBlocksEntries(t *testing.T) {
+	candles := make([]Candle, 60)
+	signals := make([]SignalType, len(candles))
+	for i := range candles {
+		candles[i] = Candle{Close: Price(100 + float64(i%2))}
+	}
+	signals[40], signals[50] = BUY, SELL
+
+	opts := BacktestOptions{RegimeFilter: RegimeNormal, RegimeWindow: 10}
+	if result := BacktestWithOptions(candles, signals, opts); result.TradeCount != 0 {
+		t.Errorf("Expected entry to be blocked in NORMAL regime, got %d trades", result.TradeCount)
+	}
+	opts.RegimeFilter = RegimeHigh
+	if result := BacktestWithOptions(candles, signals, opts); result.TradeCount != 1 {
+		t.Errorf("Expected one trade with HIGH regime filter, got %d", result.TradeCount)
+	}
+}
@@ -0,0 +1,164 @@
+// walk_forward.go
+// Walk-forward оптимизация: подбор параметров на скользящем окне и проверка на следующих за ним свечах
+package internal
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// walkForwardMinEfficiency — эффективность walk-forward, ниже которой стратегия считается подогнанной:
+// на новых данных она зарабатывает за бар меньше половины того, что на данных подбора
+const walkForwardMinEfficiency = 0.5
+
+// WalkForward — размер окна подбора и шаг walk-forward в свечах (Window = 0 — выключено)
+type WalkForward struct {
+	Window int
+	Step   int
+}
+
+// defaultWalkForward — режим walk-forward для GridSearchOptimizer (задается флагами --wf-window и --wf-step)
+var defaultWalkForward WalkForward
+
+// SetDefaultWalkForward — включает walk-forward во всех GridSearchOptimizer; вызывается один раз при старте,
+// до запуска стратегий
+func SetDefaultWalkForward(wf WalkForward) {
+	defaultWalkForward = wf
+}
+
+// WalkForwardFold — один шаг walk-forward: подбор на [Start, Split), проверка на [Split, End)
+type WalkForwardFold struct {
+	Start, Split, End int
+	Parameters        string
+	InSampleProfit    float64
+	OutOfSampleProfit float64
+	OutOfSampleTrades int
+}
+
+// WalkForwardReport — итоги walk-forward оптимизации по всем шагам
+type WalkForwardReport struct {
+	Folds             []WalkForwardFold
+	InSampleProfit    float64 // средняя прибыль на окнах подбора
+	OutOfSampleProfit float64 // прибыль, последовательно заработанная на всех проверочных отрезках
+	Efficiency        float64 // отношение средней прибыли за бар вне выборки к прибыли за бар в выборке
+}
+
+// Overfit — параметры работают только на данных, на которых подобраны
+func (r *WalkForwardReport) Overfit() bool {
+	return r.InSampleProfit > 0 && r.Efficiency < walkForwardMinEfficiency
+}
+
+// WalkForwardReporter — оптимизатор, сохраняющий отчет последней walk-forward оптимизации
+type WalkForwardReporter interface {
+	WalkForwardReport() *WalkForwardReport
+}
+
+// WalkForwardOptimizer — оптимизатор, который подбирает параметры перебором сетки на окне windowSize свечей,
+// проверяет их на следующих stepSize свечах и сдвигает окно на stepSize. Возвращает конфигурацию,
+// подобранную на последнем окне, — ее и стоит использовать дальше
+type WalkForwardOptimizer struct {
+	grid       *GridSearchOptimizer
+	windowSize int
+	stepSize   int
+
+	mu     sync.Mutex
+	report *WalkForwardReport
+}
+
+func NewWalkForwardOptimizer(
+	slippageProvider *SlippageProvider,
+	configGenerator func() []StrategyConfigV2,
+	windowSize, stepSize int,
+) *WalkForwardOptimizer {
+	grid := NewGridSearchOptimizer(slippageProvider, configGenerator)
+	grid.plain = true
+	return &WalkForwardOptimizer{
+		grid:       grid,
+		windowSize: windowSize,
+		stepSize:   stepSize,
+	}
+}
+
+// Optimize — walk-forward по свечам; если свечей меньше одного окна с проверочным отрезком,
+// выполняется обычный перебор сетки по всем свечам, и отчета нет.
+// Подбор на отдельных шагах идет молча, выводятся только итоги и конфигурация по последнему окну
+func (wfo *WalkForwardOptimizer) Optimize(ctx context.Context, candles []Candle, generator SignalGenerator) StrategyConfigV2 {
+	if wfo.windowSize < 1 || wfo.stepSize < 1 || len(candles) < wfo.windowSize+wfo.stepSize {
+		wfo.setReport(nil)
+		return wfo.grid.search(ctx, candles, generator, true)
+	}
+
+	slippage := wfo.grid.slippageProvider.Slippage()
+	report := &WalkForwardReport{}
+	outOfSampleGrowth, inSampleSum := 1.0, 0.0
+	for start := 0; start+wfo.windowSize+wfo.stepSize <= len(candles); start += wfo.stepSize {
+		split, end := start+wfo.windowSize, start+wfo.windowSize+wfo.stepSize
+		inSample := candles[start:split]
+
+		if ctx.Err() != nil {
+			wfo.setReport(nil)
+			return nil
+		}
+
+		config := wfo.grid.search(ctx, inSample, generator, false)
+		if config == nil {
+			continue
+		}
+		inSampleResult := BacktestWithSlippage(inSample, generator.GenerateSignals(inSample, config), slippage)
+
+		// Сигналы проверочного отрезка считаются вместе с окном подбора, чтобы индикаторы были прогреты;
+		// сделки совершаются только на новых свечах
+		window := candles[start:end]
+		signals := generator.GenerateSignals(window, config)
+		if err := ValidateSignals(window, signals); err != nil {
+			Log.Debugf("⚠️ Walk-forward шаг %d–%d пропущен: %v", start, end, err)
+			continue
+		}
+		outOfSampleResult := BacktestWithSlippage(candles[split:end], signals[wfo.windowSize:], slippage)
+
+		report.Folds = append(report.Folds, WalkForwardFold{
+			Start:             start,
+			Split:             split,
+			End:               end,
+			Parameters:        config.String(),
+			InSampleProfit:    inSampleResult.TotalProfit,
+			OutOfSampleProfit: outOfSampleResult.TotalProfit,
+			OutOfSampleTrades: outOfSampleResult.TradeCount,
+		})
+		inSampleSum += inSampleResult.TotalProfit
+		outOfSampleGrowth *= 1 + outOfSampleResult.TotalProfit
+	}
+
+	// Итоговая конфигурация — по самым свежим windowSize свечам
+	best := wfo.grid.search(ctx, candles[len(candles)-wfo.windowSize:], generator, true)
+
+	if folds := len(report.Folds); folds > 0 {
+		report.InSampleProfit = inSampleSum / float64(folds)
+		report.OutOfSampleProfit = outOfSampleGrowth - 1
+		inSamplePerBar := report.InSampleProfit / float64(wfo.windowSize)
+		outOfSamplePerBar := report.OutOfSampleProfit / float64(folds*wfo.stepSize)
+		if inSamplePerBar != 0 {
+			report.Efficiency = outOfSamplePerBar / inSamplePerBar
+		}
+		fmt.Printf("Walk-forward: %d steps, in-sample %.4f, out-of-sample %.4f, efficiency %.2f\n",
+			folds, report.InSampleProfit, report.OutOfSampleProfit, report.Efficiency)
+		wfo.setReport(report)
+	} else {
+		wfo.setReport(nil)
+	}
+	return best
+}
+
+// WalkForwardReport — отчет последнего вызова Optimize (nil, если walk-forward не выполнялся)
+func (wfo *WalkForwardOptimizer) WalkForwardReport() *WalkForwardReport {
+	wfo.mu.Lock()
+	defer wfo.mu.Unlock()
+	return wfo.report
+}
+
+func (wfo *WalkForwardOptimizer) setReport(report *WalkForwardReport) {
+	wfo.mu.Lock()
+	wfo.report = report
+	wfo.mu.Unlock()
+}
@@ -0,0 +1,101 @@
+package internal
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+// sideConfig — конфигурация-заглушка: всегда в позиции (Long) или всегда вне рынка
+type sideConfig struct {
+	Long bool
+}
+
+func (c *sideConfig) Validate() error { return nil }
+
+func (c *sideConfig) String() string {
+	if c.Long {
+		return "long"
+	}
+	return "flat"
+}
+
+type sideGenerator struct{}
+
+func (sideGenerator) GenerateSignals(candles []Candle, config StrategyConfigV2) []SignalType {
+	signals := make([]SignalType, len(candles))
+	if config.(*sideConfig).Long {
+		for i := range signals {
+			signals[i] = BUY
+		}
+	}
+	return signals
+}
+
+func sideConfigs() []StrategyConfigV2 {
+	return []StrategyConfigV2{&sideConfig{Long: false}, &sideConfig{Long: true}}
+}
+
+func TestWalkForwardOptimizer_RollsWindows(t *testing.T) {
+	// Равномерный рост на 1% за бар: позиция выигрывает и в выборке, и вне ее
+	candles := make([]Candle, 100)
+	for i := range candles {
+		candles[i] = Candle{Close: Price(100 * math.Pow(1.01, float64(i)))}
+	}
+
+	// Кэш общий для параллельно идущих стратегий: walk-forward не должен его сбрасывать
+	Cache.Store("walk-forward-test", true)
+	defer Cache.Delete("walk-forward-test")
+
+	wfo := NewWalkForwardOptimizer(NewSlippageProvider(SlippageAbsolute(0)), sideConfigs, 40, 20)
+	best := wfo.Optimize(context.Background(), candles, sideGenerator{})
+	if best == nil || !best.(*sideConfig).Long {
+		t.Fatalf("Expected long config from the last window, got %v", best)
+	}
+	if _, ok := Cache.Load("walk-forward-test"); !ok {
+		t.Error("Walk-forward cleared the shared indicator cache")
+	}
+
+	report := wfo.WalkForwardReport()
+	if report == nil || len(report.Folds) != 3 {
+		t.Fatalf("Expected 3 walk-forward steps, got %+v", report)
+	}
+	for i, fold := range report.Folds {
+		if fold.Start != i*20 || fold.Split != fold.Start+40 || fold.End != fold.Split+20 {
+			t.Errorf("Step %d: unexpected bounds %d/%d/%d", i, fold.Start, fold.Split, fold.End)
+		}
+	}
+	// Вне выборки покупка на первом баре отрезка: 19 баров роста на каждом из трех отрезков
+	expectedOOS := math.Pow(1.01, 57) - 1
+	if math.Abs(report.OutOfSampleProfit-expectedOOS) > 1e-6 {
+		t.Errorf("Expected out-of-sample profit %.4f, got %.4f", expectedOOS, report.OutOfSampleProfit)
+	}
+	if report.Overfit() {
+		t.Errorf("Steady trend should not be flagged as overfit (efficiency %.2f)", report.Efficiency)
+	}
+}
+
+func TestWalkForwardOptimizer_FlagsOverfitAndFallsBack(t *testing.T) {
+	// Рост на окне подбора и падение на проверочном отрезке
+	candles := make([]Candle, 60)
+	for i := range candles {
+		price := 100.0 + float64(min(i, 40)) - float64(max(i-40, 0))
+		candles[i] = Candle{Close: Price(price)}
+	}
+
+	wfo := NewWalkForwardOptimizer(NewSlippageProvider(SlippageAbsolute(0)), sideConfigs, 40, 20)
+	wfo.Optimize(context.Background(), candles, sideGenerator{})
+	report := wfo.WalkForwardReport()
+	if report == nil || len(report.Folds) != 1 {
+		t.Fatalf("Expected one walk-forward step, got %+v", report)
+	}
+	if report.InSampleProfit <= 0 || report.OutOfSampleProfit >= 0 || !report.Overfit() {
+		t.Errorf("Expected in-sample gain and out-of-sample loss flagged as overfit, got %+v", report)
+	}
+
+	// Свечей меньше одного окна с проверкой — обычный перебор по всем свечам без отчета
+	best := wfo.Optimize(context.Background(), candles[:50], sideGenerator{})
+	if best == nil || !best.(*sideConfig).Long || wfo.WalkForwardReport() != nil {
+		t.Errorf("Expected plain grid search fallback without report, got %v / %+v", best, wfo.WalkForwardReport())
+	}
+}
@@ -0,0 +1,47 @@
+// warmup.go
+// Прогрев индикаторов: с какого бара значения рассчитаны по полному окну и стратегия может давать сигналы
+package internal
+
+// SMAWarmup — индекс первого полного значения SMA(period); до него значения нулевые
+func SMAWarmup(period int) int {
+	return period - 1
+}
+
+// EMAWarmup — индекс первого значения EMA(period): затравка — SMA первых period значений
+func EMAWarmup(period int) int {
+	return period - 1
+}
+
+// MACDWarmup — индекс первого полного значения сигнальной линии MACD. Линия MACD нулевая до прогрева
+// медленной EMA, и сигнальная EMA до бара slow+signal−2 усредняет эти нули
+func MACDWarmup(fast, slow, signal int) int {
+	return WarmupLength(EMAWarmup(fast), EMAWarmup(slow)) + EMAWarmup(signal)
+}
+
+// ATRWarmup — индекс первого полного среднего True Range за period баров, если True Range первой
+// свечи не определен (нет предыдущего закрытия) и окно должно начинаться со второй свечи
+func ATRWarmup(period int) int {
+	return period
+}
+
+// WarmupLength — прогрев набора индикаторов: максимум из их длин прогрева (не меньше 0)
+func WarmupLength(warmups ...int) int {
+	length := 0
+	for _, warmup := range warmups {
+		if warmup > length {
+			length = warmup
+		}
+	}
+	return length
+}
+
+// GenerateAfterWarmup — сигналы стратегии только по полностью рассчитанным индикаторам: signal вызывается
+// по порядку для баров начиная с warmup+lookback, раньше — HOLD. lookback — сколько предыдущих баров читает
+// правило (1 для пересечений по i−1), чтобы и они были после прогрева
+func GenerateAfterWarmup(n, warmup, lookback int, signal func(i int) SignalType) []SignalType {
+	signals := make([]SignalType, n)
+	for i := warmup + lookback; i < n; i++ {
+		signals[i] = signal(i)
+	}
+	return signals
+}
@@ -0,0 +1,67 @@
+package internal
+
+import "testing"
+
+func TestWarmupLength_TakesLongestIndicator(t *testing.T) {
+	if got := WarmupLength(); got != 0 {
+		t.Errorf("WarmupLength() = %d, want 0", got)
+	}
+	if got := WarmupLength(SMAWarmup(5), SMAWarmup(20), EMAWarmup(10)); got != 19 {
+		t.Errorf("WarmupLength(SMA5, SMA20, EMA10) = %d, want 19", got)
+	}
+	if got := MACDWarmup(12, 26, 9); got != 33 {
+		t.Errorf("MACDWarmup(12, 26, 9) = %d, want 33", got)
+	}
+}
+
+func TestWarmupLength_MatchesIndicatorOutput(t *testing.T) {
+	candles := make([]Candle, 60)
+	for i := range candles {
+		candles[i] = Candle{Close: Price(100.0 + float64(i%7))}
+	}
+
+	sma := CalculateSMACommon(candles, 10)
+	if sma[SMAWarmup(10)-1] != 0 || sma[SMAWarmup(10)] == 0 {
+		t.Errorf("SMA(10) warm-up should end at bar %d", SMAWarmup(10))
+	}
+
+	// Сигнальная линия уже ненулевая до MACDWarmup, но еще включает нулевые значения линии MACD
+	macd, signal, _ := CalculateMACDWithSignal(candles, 3, 8, 5)
+	if signal[EMAWarmup(8)] == 0 {
+		t.Fatal("expected signal line to start before the MACD line is warm")
+	}
+	warm := MACDWarmup(3, 8, 5)
+	for i := warm - EMAWarmup(5); i <= warm; i++ {
+		if macd[i] == 0 {
+			t.Errorf("MACD line at bar %d (inside the signal window at warm-up) is zero", i)
+		}
+	}
+}
+
+func TestGenerateAfterWarmup_HoldsUntilIndicatorsAndLookbackReady(t *testing.T) {
+	var visited []int
+	signals := GenerateAfterWarmup(10, 4, 1, func(i int) SignalType {
+		visited = append(visited, i)
+		return BUY
+	})
+
+	if len(signals) != 10 {
+		t.Fatalf("len(signals) = %d, want 10", len(signals))
+	}
+	for i, s := range signals {
+		want := HOLD
+		if i >= 5 {
+			want = BUY
+		}
+		if s != want {
+			t.Errorf("signals[%d] = %v, want %v", i, s, want)
+		}
+	}
+	if len(visited) != 5 || visited[0] != 5 {
+		t.Errorf("signal called for bars %v, want 5..9 in order", visited)
+	}
+
+	if signals := GenerateAfterWarmup(3, 4, 1, func(i int) SignalType { return BUY }); signals[2] != HOLD {
+		t.Error("expected all HOLD when warm-up exceeds data")
+	}
+}
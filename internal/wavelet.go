@@ -0,0 +1,125 @@
+// wavelet.go
+// Шумоподавление ценового ряда вейвлет-преобразованием Хаара
+package internal
+
+import (
+	"math"
+	"sort"
+)
+
+// WaveletDenoise — убирает высокочастотный шум из ряда: раскладывает его дискретным вейвлет-преобразованием
+// Хаара на level уровней, мягко обнуляет детализирующие коэффициенты ниже threshold и собирает ряд обратно.
+// В отличие от MA/EMA не запаздывает и сохраняет резкие уровни (ступеньки) цены.
+// threshold <= 0 — универсальный порог Донохо: σ·√(2·ln n), σ оценивается по медиане |d| первого уровня.
+// Чтобы результат не состоял из ступенек длиной 2^level, он усредняется по сдвигам ряда (cycle spinning).
+// Ряд обрабатывается целиком, поэтому результат на баре i зависит и от последующих баров
+func WaveletDenoise(prices []float64, level int, threshold float64) []float64 {
+	result := make([]float64, len(prices))
+	copy(result, prices)
+	if level <= 0 || len(prices) < 2 {
+		return result
+	}
+
+	if threshold <= 0 {
+		_, detail := haarForward(prices)
+		threshold = universalThreshold(detail, len(prices))
+	}
+
+	// Усреднение по сдвигам 0..2^level−1: сдвинутый ряд начинается с бара shift
+	shifts := 1 << uint(level)
+	if shifts > len(prices)/2 {
+		shifts = len(prices) / 2
+	}
+	sums := make([]float64, len(prices))
+	counts := make([]int, len(prices))
+	for shift := 0; shift < shifts; shift++ {
+		denoised := haarDenoise(prices[shift:], level, threshold)
+		for i, v := range denoised {
+			sums[shift+i] += v
+			counts[shift+i]++
+		}
+	}
+
+	for i := range result {
+		result[i] = sums[i] / float64(counts[i])
+	}
+	return result
+}
+
+// haarDenoise — одно разложение Хаара с мягким порогом для деталей всех уровней
+func haarDenoise(prices []float64, level int, threshold float64) []float64 {
+	approx := prices
+	details := make([][]float64, 0, level)
+	lengths := make([]int, 0, level)
+	for l := 0; l < level && len(approx) >= 2; l++ {
+		lengths = append(lengths, len(approx))
+		var d []float64
+		approx, d = haarForward(approx)
+		details = append(details, d)
+	}
+
+	for _, d := range details {
+		for k := range d {
+			d[k] = softThreshold(d[k], threshold)
+		}
+	}
+
+	// Обратное преобразование от самого грубого уровня к исходному
+	for l := len(details) - 1; l >= 0; l-- {
+		approx = haarInverse(approx, details[l], lengths[l])
+	}
+	return approx
+}
+
+// haarForward — один уровень преобразования Хаара; ряд нечетной длины дополняется последним значением
+func haarForward(x []float64) (approx, detail []float64) {
+	n := (len(x) + 1) / 2
+	approx = make([]float64, n)
+	detail = make([]float64, n)
+	for k := 0; k < n; k++ {
+		a, b := x[2*k], x[len(x)-1]
+		if 2*k+1 < len(x) {
+			b = x[2*k+1]
+		}
+		approx[k] = (a + b) / math.Sqrt2
+		detail[k] = (a - b) / math.Sqrt2
+	}
+	return approx, detail
+}
+
+// haarInverse — восстанавливает ряд длины length из аппроксимации и деталей
+func haarInverse(approx, detail []float64, length int) []float64 {
+	x := make([]float64, 2*len(approx))
+	for k := range approx {
+		x[2*k] = (approx[k] + detail[k]) / math.Sqrt2
+		x[2*k+1] = (approx[k] - detail[k]) / math.Sqrt2
+	}
+	return x[:length]
+}
+
+// universalThreshold — порог VisuShrink по робастной оценке уровня шума
+func universalThreshold(detail []float64, n int) float64 {
+	abs := make([]float64, len(detail))
+	for i, d := range detail {
+		abs[i] = math.Abs(d)
+	}
+	sort.Float64s(abs)
+
+	median := abs[len(abs)/2]
+	if len(abs)%2 == 0 {
+		median = (abs[len(abs)/2-1] + abs[len(abs)/2]) / 2
+	}
+	sigma := median / 0.6745
+	return sigma * math.Sqrt(2*math.Log(float64(n)))
+}
+
+func softThreshold(x, threshold float64) float64 {
+	switch {
+	case x > threshold:
+		return x - threshold
+	case x < -threshold:
+		return x + threshold
+	default:
+		return 0
+	}
+}
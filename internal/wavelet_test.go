@@ -0,0 +1,54 @@
+package internal
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestWaveletDenoise_RemovesNoiseKeepsStep(t *testing.T) {
+	const n = 256
+	rng := rand.New(rand.NewSource(42))
+
+	clean := make([]float64, n)
+	noisy := make([]float64, n)
+	for i := range clean {
+		clean[i] = 100
+		if i >= n/2 {
+			clean[i] = 110
+		}
+		noisy[i] = clean[i] + rng.NormFloat64()*0.5
+	}
+
+	denoised := WaveletDenoise(noisy, 4, 0)
+	if len(denoised) != n {
+		t.Fatalf("expected %d values, got %d", n, len(denoised))
+	}
+
+	rmse := func(x []float64) float64 {
+		sum := 0.0
+		for i := range x {
+			sum += (x[i] - clean[i]) * (x[i] - clean[i])
+		}
+		return math.Sqrt(sum / float64(len(x)))
+	}
+	if before, after := rmse(noisy), rmse(denoised); after >= before*0.6 {
+		t.Errorf("expected noise to drop substantially: RMSE %.4f -> %.4f", before, after)
+	}
+
+	// Ступенька остается резкой: за 2 бара до и после нее значения у своих уровней
+	if v := denoised[n/2-2]; math.Abs(v-100) > 1 {
+		t.Errorf("expected level 100 before the step, got %.4f", v)
+	}
+	if v := denoised[n/2+1]; math.Abs(v-110) > 1 {
+		t.Errorf("expected level 110 after the step, got %.4f", v)
+	}
+
+	// Ряд нечетной длины и нулевой уровень
+	if odd := WaveletDenoise(noisy[:101], 3, 0); len(odd) != 101 {
+		t.Errorf("expected odd-length output of 101, got %d", len(odd))
+	}
+	if same := WaveletDenoise(noisy, 0, 0); same[10] != noisy[10] {
+		t.Errorf("level 0 must return the input unchanged")
+	}
+}
@@ -21,6 +21,7 @@
 // - MinExtremaDistance: минимальное расстояние между экстремумами (избегаем шума)
 // - LookbackWindow: окно анализа вокруг экстремумов
 // - ConfidenceThreshold: порог уверенности для генерации сигнала
+// - TradeShorts: торговля в обе стороны — шорт на пиках, закрытие шорта на впадинах (только с --allow-shorts)
 //
 // Сильные стороны:
 // - Использует реальные исторические экстремумы как ориентиры
@@ -44,9 +45,9 @@ package extrema
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"sort"
 )
@@ -58,6 +59,9 @@ type ExtremaConfig struct {
 	LookbackPeriod  int     `json:"lookback_period"`
 	SmoothingType   string  `json:"smoothing_type"`
 	SmoothingPeriod int     `json:"smoothing_period"`
+	// TradeShorts — торговать в обе стороны: SELL без позиции открывает шорт на пике, BUY закрывает его на впадине.
+	// Допустим только при AllowShorts движка: иначе SELL без позиции игнорируется, а BUY «закрытия» открывает лонг
+	TradeShorts bool `json:"trade_shorts"`
 }
 
 func (c *ExtremaConfig) Validate() error {
@@ -73,18 +77,21 @@ func (c *ExtremaConfig) Validate() error {
 	if c.LookbackPeriod <= 0 {
 		return errors.New("lookback period must be positive")
 	}
-	if c.SmoothingType != "ma" && c.SmoothingType != "ema" {
-		return errors.New("smoothing type must be 'ma' or 'ema'")
+	if c.SmoothingType != "ma" && c.SmoothingType != "ema" && c.SmoothingType != "wavelet" {
+		return errors.New("smoothing type must be 'ma', 'ema' or 'wavelet'")
 	}
 	if c.SmoothingPeriod <= 0 {
 		return errors.New("smoothing period must be positive")
 	}
+	if c.TradeShorts && !internal.DefaultBacktestOptions().AllowShorts {
+		return errors.New("trade shorts requires --allow-shorts")
+	}
 	return nil
 }
 
 func (c *ExtremaConfig) DefaultConfigString() string {
-	return fmt.Sprintf("Extrema(min_dist=%d, win=%d, strength=%.1f, smooth=%s:%d)",
-		c.MinDistance, c.WindowSize, c.MinStrength, c.SmoothingType, c.SmoothingPeriod)
+	return fmt.Sprintf("Extrema(min_dist=%d, win=%d, strength=%.1f, smooth=%s:%d, shorts=%t)",
+		c.MinDistance, c.WindowSize, c.MinStrength, c.SmoothingType, c.SmoothingPeriod, c.TradeShorts)
 }
 
 // ExtremaPoint — точка экстремума
@@ -104,8 +111,8 @@ type ExtremaModel struct {
 	windowSize      int
 	minStrength     float64
 	lookbackPeriod  int
-	smoothingType   string // "ma" или "ema"
-	smoothingPeriod int
+	smoothingType   string // "ma", "ema" или "wavelet"
+	smoothingPeriod int    // период MA/EMA или число уровней вейвлет-разложения
 }
 
 // NewExtremaModel создает новую модель экстремумов
@@ -121,13 +128,16 @@ func NewExtremaModel(minDistance, windowSize int, minStrength float64, lookbackP
 	}
 }
 
-// smoothPrices сглаживает ценовые данные с помощью MA или EMA
+// smoothPrices сглаживает ценовые данные с помощью MA, EMA или вейвлет-шумоподавления
 func (em *ExtremaModel) smoothPrices(prices []float64) []float64 {
 	if em.smoothingPeriod <= 0 || em.smoothingPeriod >= len(prices) {
 		return prices // Не сглаживаем если параметры некорректны
 	}
 
 	switch em.smoothingType {
+	case "wavelet":
+		// Без запаздывания и с сохранением резких уровней, что важно для поиска экстремумов
+		return internal.WaveletDenoise(prices, em.smoothingPeriod, 0)
 	case "ema":
 		smoothed := internal.CalculateEMAForValues(prices, em.smoothingPeriod)
 		if smoothed == nil {
@@ -540,6 +550,10 @@ func (s *ExtremaStrategy) Name() string {
 	return "extrema_strategy"
 }
 
+func (s *ExtremaStrategy) Category() string {
+	return internal.CategoryExtrema
+}
+
 func (s *ExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	extremaConfig, ok := config.(*ExtremaConfig)
 	if !ok {
@@ -551,7 +565,7 @@ func (s *ExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Candle, c
 	}
 
 	if len(candles) < 50 {
-		log.Printf("⚠️ Недостаточно данных для анализа экстремумов: получено %d свечей, требуется минимум 50", len(candles))
+		internal.Log.Infof("⚠️ Недостаточно данных для анализа экстремумов: получено %d свечей, требуется минимум 50", len(candles))
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -566,31 +580,45 @@ func (s *ExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Candle, c
 	model.train(prices)
 
 	// Генерируем сигналы
-	signals := make([]internal.SignalType, len(candles))
-	inPosition := false
+	signals := positionSignals(model, prices, extremaConfig.TradeShorts)
 
-	for i := 20; i < len(candles); i++ { // начинаем после достаточного количества данных
+	internal.Log.Infof("✅ Анализ экстремумов завершен")
+	return signals
+}
+
+// positionSignals — переводит прогноз модели в сигналы с учетом текущей позиции.
+// Лонг: BUY на впадине, SELL на пике. С tradeShorts SELL без позиции открывает шорт, а BUY в шорте его закрывает
+func positionSignals(model *ExtremaModel, prices []float64, tradeShorts bool) []internal.SignalType {
+	signals := make([]internal.SignalType, len(prices))
+	position := 0 // 1 — лонг, -1 — шорт, 0 — без позиции
+
+	for i := 20; i < len(prices); i++ { // начинаем после достаточного количества данных
 		signal := model.predictSignal(i, prices)
 
-		// Применяем логику позиционирования
-		if !inPosition && signal == internal.BUY {
+		switch {
+		case signal == internal.BUY && position == 0:
 			signals[i] = internal.BUY
-			inPosition = true
-		} else if inPosition && signal == internal.SELL {
+			position = 1
+		case signal == internal.BUY && position < 0:
+			signals[i] = internal.BUY // закрываем шорт
+			position = 0
+		case signal == internal.SELL && position > 0:
 			signals[i] = internal.SELL
-			inPosition = false
-		} else {
+			position = 0
+		case signal == internal.SELL && position == 0 && tradeShorts:
+			signals[i] = internal.SELL // открываем шорт
+			position = -1
+		default:
 			signals[i] = internal.HOLD
 		}
 	}
 
-	log.Printf("✅ Анализ экстремумов завершен")
 	return signals
 }
 
-func (s *ExtremaStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *ExtremaStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*ExtremaConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Extract prices once
 	prices := make([]float64, len(candles))
@@ -600,50 +628,46 @@ func (s *ExtremaStrategy) OptimizeWithConfig(candles []internal.Candle) internal
 
 	// Grid search для параметров экстремумов
 	smoothingTypes := []string{"ma", "ema"}
-	for _, smoothType := range smoothingTypes {
-		for smoothPeriod := 8; smoothPeriod <= 15; smoothPeriod += 2 {
-			for minDist := 30; minDist <= 50; minDist += 10 {
-				for winSize := 15; winSize <= 25; winSize += 5 {
-					for minStr := 1.0; minStr <= 2.0; minStr += 0.5 {
-						config := &ExtremaConfig{
-							MinDistance:     minDist,
-							WindowSize:      winSize,
-							MinStrength:     minStr,
-							LookbackPeriod:  winSize * 3,
-							SmoothingType:   smoothType,
-							SmoothingPeriod: smoothPeriod,
-						}
-						if config.Validate() != nil {
-							continue
-						}
-
-						// Create model with these parameters
-						model := NewExtremaModel(minDist, winSize, minStr, winSize*3, smoothType, smoothPeriod)
-						model.train(prices)
-
-						// Generate signals
-						signals := make([]internal.SignalType, len(candles))
-						inPosition := false
-
-						for i := 20; i < len(candles); i++ {
-							signal := model.predictSignal(i, prices)
-
-							if !inPosition && signal == internal.BUY {
-								signals[i] = internal.BUY
-								inPosition = true
-							} else if inPosition && signal == internal.SELL {
-								signals[i] = internal.SELL
-								inPosition = false
-							} else {
-								signals[i] = internal.HOLD
+	// Шорты перебираются, только если движок их исполняет
+	shortModes := []bool{false}
+	if internal.DefaultBacktestOptions().AllowShorts {
+		shortModes = append(shortModes, true)
+	}
+	for _, tradeShorts := range shortModes {
+		for _, smoothType := range smoothingTypes {
+			for smoothPeriod := 8; smoothPeriod <= 15; smoothPeriod += 2 {
+				for minDist := 30; minDist <= 50; minDist += 10 {
+					for winSize := 15; winSize <= 25; winSize += 5 {
+						for minStr := 1.0; minStr <= 2.0; minStr += 0.5 {
+							config := &ExtremaConfig{
+								MinDistance:     minDist,
+								WindowSize:      winSize,
+								MinStrength:     minStr,
+								LookbackPeriod:  winSize * 3,
+								SmoothingType:   smoothType,
+								SmoothingPeriod: smoothPeriod,
+								TradeShorts:     tradeShorts,
 							}
-						}
+							if ctx.Err() != nil {
+								return bestConfig
+							}
+							if config.Validate() != nil {
+								continue
+							}
+
+							// Create model with these parameters
+							model := NewExtremaModel(minDist, winSize, minStr, winSize*3, smoothType, smoothPeriod)
+							model.train(prices)
 
-						// Backtest
-						result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-						if result.TotalProfit >= bestProfit {
-							bestProfit = result.TotalProfit
-							bestConfig = config
+							// Generate signals
+							signals := positionSignals(model, prices, tradeShorts)
+
+							// Backtest
+							result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
+							if score := internal.ObjectiveScore(result); score >= bestProfit {
+								bestProfit = score
+								bestConfig = config
+							}
 						}
 					}
 				}
@@ -651,9 +675,9 @@ func (s *ExtremaStrategy) OptimizeWithConfig(candles []internal.Candle) internal
 		}
 	}
 
-	fmt.Printf("Лучшие параметры Extrema: min_dist=%d, win=%d, strength=%.1f, smooth=%s:%d, профит=%.4f\n",
+	fmt.Printf("Лучшие параметры Extrema: min_dist=%d, win=%d, strength=%.1f, smooth=%s:%d, shorts=%t, профит=%.4f\n",
 		bestConfig.MinDistance, bestConfig.WindowSize, bestConfig.MinStrength,
-		bestConfig.SmoothingType, bestConfig.SmoothingPeriod, bestProfit)
+		bestConfig.SmoothingType, bestConfig.SmoothingPeriod, bestConfig.TradeShorts, bestProfit)
 
 	return bestConfig
 }
@@ -23,7 +23,8 @@ package extrema
 
 import (
 	"bt/internal"
-	"log"
+	"context"
+	"math"
 )
 
 type OptimalExtremaConfig struct {
@@ -54,6 +55,10 @@ func (s *OptimalExtremaStrategy) Name() string {
 	return "optimal_extrema_strategy"
 }
 
+func (s *OptimalExtremaStrategy) Category() string {
+	return internal.CategoryExtrema
+}
+
 // findPotentialExtrema находит потенциальные локальные экстремумы
 func (s *OptimalExtremaStrategy) findPotentialExtrema(candles []internal.Candle) ([]OptimalExtremaPoint, []OptimalExtremaPoint) {
 	var potentialMinima []OptimalExtremaPoint
@@ -216,14 +221,14 @@ func (s *OptimalExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 
 	// Шаг 1: Подготовка данных
 	if len(candles) < 3 {
-		log.Printf("⚠️ Недостаточно данных для анализа: получено %d свечей, требуется минимум 3", len(candles))
+		internal.Log.Infof("⚠️ Недостаточно данных для анализа: получено %d свечей, требуется минимум 3", len(candles))
 		return make([]internal.SignalType, len(candles))
 	}
 
 	// Шаг 2: Поиск потенциальных экстремумов
 	potentialMinima, potentialMaxima := s.findPotentialExtrema(candles)
 
-	log.Printf("🔍 Найдено потенциальных минимумов: %d, максимумов: %d", len(potentialMinima), len(potentialMaxima))
+	internal.Log.Debugf("🔍 Найдено потенциальных минимумов: %d, максимумов: %d", len(potentialMinima), len(potentialMaxima))
 
 	// Шаг 3: Фильтрация и чередование экстремумов
 	sequence := s.createAlternatingSequence(potentialMinima, potentialMaxima)
@@ -233,7 +238,7 @@ func (s *OptimalExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 		sequence = sequence[1:]
 	}
 
-	log.Printf("📊 Сформирована последовательность из %d экстремумов", len(sequence))
+	internal.Log.Debugf("📊 Сформирована последовательность из %d экстремумов", len(sequence))
 
 	// Шаг 4: Проверка оптимальности интервалов
 	var optimalPairs []OptimalExtremaPoint
@@ -246,7 +251,7 @@ func (s *OptimalExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 		}
 	}
 
-	log.Printf("✅ Найдено %d оптимальных пар (покупка -> продажа)", len(optimalPairs)/2)
+	internal.Log.Debugf("✅ Найдено %d оптимальных пар (покупка -> продажа)", len(optimalPairs)/2)
 
 	// Шаг 5: Устранение пересечений и повторов
 	optimalPairs = s.removeOverlapsAndDuplicates(optimalPairs)
@@ -278,28 +283,28 @@ func (s *OptimalExtremaStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 		}
 	}
 
-	log.Printf("📈 Сгенерировано сигналов: BUY=%d, SELL=%d", buyCount, sellCount)
+	internal.Log.Debugf("📈 Сгенерировано сигналов: BUY=%d, SELL=%d", buyCount, sellCount)
 
 	return signals
 }
 
-func (s *OptimalExtremaStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
-	log.Printf("🔧 Оптимизация параметров для optimal_extrema_strategy (параметры не требуются)")
+func (s *OptimalExtremaStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	internal.Log.Infof("🔧 Оптимизация параметров для optimal_extrema_strategy (параметры не требуются)")
 	var bestConfig *OptimalExtremaConfig
-	var bestProfit float64 = -1.0
+	bestProfit := math.Inf(-1)
 
 	// Single configuration since no parameters
 	config := &OptimalExtremaConfig{}
 	if config.Validate() == nil {
 		signals := s.GenerateSignalsWithConfig(candles, config)
 		result := internal.Backtest(candles, signals, s.GetSlippage())
-		if result.TotalProfit >= bestProfit {
-			bestProfit = result.TotalProfit
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
 			bestConfig = config
 		}
 	}
 
-	log.Printf("Лучшие параметры OptimalExtrema: профит=%.4f", bestProfit)
+	internal.Log.Infof("Лучшие параметры OptimalExtrema: профит=%.4f", bestProfit)
 	return bestConfig
 }
 
@@ -45,6 +45,7 @@ package lines
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 
@@ -88,6 +89,10 @@ func (s *SupportLineStrategy) Name() string {
 	return "support_line"
 }
 
+func (s *SupportLineStrategy) Category() string {
+	return internal.CategoryLines
+}
+
 func (s *SupportLineStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	supportConfig, ok := config.(*SupportLineConfig)
 	if !ok {
@@ -142,7 +147,7 @@ func (s *SupportLineStrategy) GenerateSignalsWithConfig(candles []internal.Candl
 	return signals
 }
 
-func (s *SupportLineStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *SupportLineStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	configs := lo.CrossJoinBy3(
 		lo.RangeWithSteps[int](40, 50, 1),
@@ -156,7 +161,7 @@ func (s *SupportLineStrategy) OptimizeWithConfig(candles []internal.Candle) inte
 			}
 		})
 
-	max := s.ProcessConfigs(s, candles, configs)
+	max := s.ProcessConfigs(ctx, s, candles, configs)
 
 	bestConfig := max.A.(*SupportLineConfig)
 	bestProfit := max.B
@@ -42,8 +42,10 @@ package momentum
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type MAChannelConfig struct {
@@ -81,6 +83,10 @@ func (s *MAChannelStrategy) Name() string {
 	return "ma_channel"
 }
 
+func (s *MAChannelStrategy) Category() string {
+	return internal.CategoryMomentum
+}
+
 func (s *MAChannelStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	maConfig, ok := config.(*MAChannelConfig)
 	if !ok {
@@ -96,45 +102,37 @@ func (s *MAChannelStrategy) GenerateSignalsWithConfig(candles []internal.Candle,
 		return make([]internal.SignalType, len(candles))
 	}
 
-	signals := make([]internal.SignalType, len(candles))
 	inPosition := false
 
-	for i := maConfig.SlowPeriod; i < len(candles); i++ {
+	// Канал определен, когда рассчитаны обе средние
+	warmup := internal.WarmupLength(internal.SMAWarmup(maConfig.FastPeriod), internal.SMAWarmup(maConfig.SlowPeriod))
+	signals := internal.GenerateAfterWarmup(len(candles), warmup, 0, func(i int) internal.SignalType {
 		closePrice := candles[i].Close.ToFloat64()
-		upper := upperChannel[i]
-		lower := lowerChannel[i]
-
-		if upper == 0 || lower == 0 {
-			signals[i] = internal.HOLD
-			continue
-		}
 
 		if !inPosition {
 			// Buy when price breaks above upper channel
-			if closePrice > upper {
-				signals[i] = internal.BUY
+			if closePrice > upperChannel[i] {
 				inPosition = true
-				continue
+				return internal.BUY
 			}
 		} else {
 			// Sell when price breaks below lower channel
-			if closePrice < lower {
-				signals[i] = internal.SELL
+			if closePrice < lowerChannel[i] {
 				inPosition = false
-				continue
+				return internal.SELL
 			}
 		}
 
-		signals[i] = internal.HOLD
-	}
+		return internal.HOLD
+	})
 
 	return signals
 }
 
-func (s *MAChannelStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MAChannelStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	bestConfig := s.DefaultConfig().(*MAChannelConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search по параметрам
 	for fast := 5; fast <= 15; fast += 2 {
@@ -149,14 +147,17 @@ func (s *MAChannelStrategy) OptimizeWithConfig(candles []internal.Candle) intern
 					SlowPeriod: slow,
 					Multiplier: mult,
 				}
+				if ctx.Err() != nil {
+					return bestConfig
+				}
 				if config.Validate() != nil {
 					continue
 				}
 
 				signals := s.GenerateSignalsWithConfig(candles, config)
 				result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-				if result.TotalProfit >= bestProfit {
-					bestProfit = result.TotalProfit
+				if score := internal.ObjectiveScore(result); score >= bestProfit {
+					bestProfit = score
 					bestConfig = config
 				}
 			}
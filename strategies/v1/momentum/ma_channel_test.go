@@ -0,0 +1,28 @@
+package momentum
+
+import (
+	"testing"
+
+	"bt/internal"
+)
+
+func TestMAChannel_SignalsStartAfterWarmup(t *testing.T) {
+	// Устойчивый рост: цена выше верхней границы канала, как только он определен
+	candles := make([]internal.Candle, 60)
+	for i := range candles {
+		candles[i] = internal.Candle{Close: internal.Price(100.0 + 2*float64(i))}
+	}
+	config := &MAChannelConfig{FastPeriod: 5, SlowPeriod: 20, Multiplier: 1}
+
+	signals := (&MAChannelStrategy{}).GenerateSignalsWithConfig(candles, config)
+
+	warmup := internal.WarmupLength(internal.SMAWarmup(config.FastPeriod), internal.SMAWarmup(config.SlowPeriod))
+	for i := 0; i < warmup; i++ {
+		if signals[i] != internal.HOLD {
+			t.Errorf("signal %v at bar %d before warm-up ends at %d", signals[i], i, warmup)
+		}
+	}
+	if signals[warmup] != internal.BUY {
+		t.Errorf("expected BUY on the first warm bar %d, got %v", warmup, signals[warmup])
+	}
+}
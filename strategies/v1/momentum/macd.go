@@ -42,6 +42,7 @@ package momentum
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -105,6 +106,10 @@ func (s *MACDStrategy) Name() string {
 	return "macd"
 }
 
+func (s *MACDStrategy) Category() string {
+	return internal.CategoryMomentum
+}
+
 func (s *MACDStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	macdConfig, ok := config.(*MACDConfig)
 	if !ok {
@@ -268,9 +273,9 @@ func (s *MACDStrategy) checkExitConditions(currentPrice, stopLoss, takeProfit fl
 	return false
 }
 
-func (s *MACDStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MACDStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*MACDConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Расширенный grid search по параметрам
 	for fast := 8; fast <= 20; fast += 2 {
@@ -290,6 +295,9 @@ func (s *MACDStrategy) OptimizeWithConfig(candles []internal.Candle) internal.St
 								TakeProfitPercent: takeProfit,
 							}
 
+							if ctx.Err() != nil {
+								return bestConfig
+							}
 							if config.Validate() != nil {
 								continue
 							}
@@ -297,9 +305,9 @@ func (s *MACDStrategy) OptimizeWithConfig(candles []internal.Candle) internal.St
 							signals := s.GenerateSignalsWithConfig(candles, config)
 							result := internal.Backtest(candles, signals, s.GetSlippage()) // Уменьшенное проскальзывание
 
-							// Оцениваем только по прибыли
-							if result.TotalProfit >= bestProfit {
-								bestProfit = result.TotalProfit
+							// Оцениваем по целевой функции (--objective)
+							if score := internal.ObjectiveScore(result); score >= bestProfit {
+								bestProfit = score
 								bestConfig = config
 							}
 						}
@@ -0,0 +1,155 @@
+// strategies/hma_crossover.go
+
+// HMA Crossover Strategy
+//
+// Описание стратегии:
+// Пересечение двух скользящих средних Халла (Hull Moving Average). HMA строится из взвешенных
+// скользящих средних: WMA(2×WMA(n/2) − WMA(n), √n) — и почти не запаздывает на тренде,
+// поэтому пересечения происходят раньше, чем у SMA/EMA с теми же периодами.
+//
+// Как работает:
+// - Рассчитываются быстрая и медленная HMA по ценам закрытия
+// - BUY: быстрая HMA пересекает медленную снизу вверх
+// - SELL: быстрая HMA пересекает медленную сверху вниз
+// - HOLD: в остальных случаях
+//
+// Параметры:
+// - FastPeriod: период быстрой HMA (обычно 5-20)
+// - SlowPeriod: период медленной HMA (обычно 20-80)
+//
+// Сильные стороны:
+// - Малое запаздывание по сравнению с SMA/EMA
+// - Гладкая линия при сохранении чувствительности
+//
+// Слабые стороны:
+// - Перерегулирование на резких разворотах
+// - Больше ложных пересечений в боковом рынке, чем у медленных средних
+//
+// Лучшие условия для применения:
+// - Трендовые рынки с частой сменой направления
+// - Внутридневная и краткосрочная торговля
+
+package moving_averages
+
+import (
+	"bt/internal"
+	"context"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/samber/lo"
+)
+
+type HMACrossoverConfig struct {
+	FastPeriod int `json:"fast_period"`
+	SlowPeriod int `json:"slow_period"`
+}
+
+func (c *HMACrossoverConfig) Validate() error {
+	if c.FastPeriod < 2 {
+		return errors.New("fast period must be at least 2")
+	}
+	if c.SlowPeriod <= c.FastPeriod {
+		return errors.New("slow period must be greater than fast period")
+	}
+	return nil
+}
+
+// MinCandles — прогрев медленной HMA плюс один бар для поиска пересечения
+func (c *HMACrossoverConfig) MinCandles() int {
+	return c.SlowPeriod + int(math.Sqrt(float64(c.SlowPeriod)))
+}
+
+func (c *HMACrossoverConfig) DefaultConfigString() string {
+	return fmt.Sprintf("HMACrossover(fast=%d, slow=%d)", c.FastPeriod, c.SlowPeriod)
+}
+
+type HMACrossoverStrategy struct {
+	internal.BaseConfig
+	internal.BaseStrategy
+}
+
+func (s *HMACrossoverStrategy) Name() string {
+	return "hma_crossover"
+}
+
+func (s *HMACrossoverStrategy) Category() string {
+	return internal.CategoryMovingAverages
+}
+
+func (s *HMACrossoverStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	hmaConfig, ok := config.(*HMACrossoverConfig)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := hmaConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	prices := internal.ExtractPrices(candles, internal.DefaultPriceSource())
+
+	fastHMA := internal.CalculateHMA(prices, hmaConfig.FastPeriod)
+	slowHMA := internal.CalculateHMA(prices, hmaConfig.SlowPeriod)
+	if fastHMA == nil || slowHMA == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	// Первое значение медленной HMA — на индексе slow + ⌊√slow⌋ − 2, пересечение ищем со следующего
+	start := hmaConfig.MinCandles() - 1
+	for i := start; i < len(candles); i++ {
+		// Быстрая HMA пересекает медленную снизу вверх
+		if !inPosition && fastHMA[i-1] <= slowHMA[i-1] && fastHMA[i] > slowHMA[i] {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		// Быстрая HMA пересекает медленную сверху вниз
+		if inPosition && fastHMA[i-1] >= slowHMA[i-1] && fastHMA[i] < slowHMA[i] {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+func (s *HMACrossoverStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	configs := lo.CrossJoinBy2(
+		lo.RangeWithSteps[int](4, 32, 2),
+		lo.RangeWithSteps[int](20, 125, 5),
+		func(fast int, slow int) internal.StrategyConfig {
+			return &HMACrossoverConfig{
+				FastPeriod: fast,
+				SlowPeriod: slow,
+			}
+		})
+
+	max := s.ProcessConfigs(ctx, s, candles, configs)
+
+	bestConfig := max.A.(*HMACrossoverConfig)
+	bestProfit := max.B
+	fmt.Printf("Лучшие параметры HMA Crossover: fast=%d, slow=%d, профит=%.4f\n",
+		bestConfig.FastPeriod, bestConfig.SlowPeriod, bestProfit)
+
+	return bestConfig
+}
+
+func init() {
+	internal.RegisterStrategy("hma_crossover", &HMACrossoverStrategy{
+		BaseConfig: internal.BaseConfig{
+			Config: &HMACrossoverConfig{
+				FastPeriod: 9,
+				SlowPeriod: 36,
+			},
+		},
+	})
+}
@@ -39,8 +39,10 @@ package moving_averages
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type MAEmaCorrelationConfig struct {
@@ -79,6 +81,10 @@ func (s *MaEmaCorrelationStrategy) Name() string {
 	return "ma_ema_correlation"
 }
 
+func (s *MaEmaCorrelationStrategy) Category() string {
+	return internal.CategoryMovingAverages
+}
+
 func (s *MaEmaCorrelationStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	maEmaConfig, ok := config.(*MAEmaCorrelationConfig)
 	if !ok {
@@ -138,10 +144,10 @@ func (s *MaEmaCorrelationStrategy) GenerateSignalsWithConfig(candles []internal.
 	return signals
 }
 
-func (s *MaEmaCorrelationStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MaEmaCorrelationStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*MAEmaCorrelationConfig)
 
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем параметры
 	for maPeriod := 10; maPeriod <= 30; maPeriod += 5 {
@@ -154,14 +160,17 @@ func (s *MaEmaCorrelationStrategy) OptimizeWithConfig(candles []internal.Candle)
 						Lookback:  lookback,
 						Threshold: threshold,
 					}
+					if ctx.Err() != nil {
+						return bestConfig
+					}
 					if config.Validate() != nil {
 						continue
 					}
 
 					signals := s.GenerateSignalsWithConfig(candles, config)
 					result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-					if result.TotalProfit >= bestProfit {
-						bestProfit = result.TotalProfit
+					if score := internal.ObjectiveScore(result); score >= bestProfit {
+						bestProfit = score
 						bestConfig = config
 					}
 				}
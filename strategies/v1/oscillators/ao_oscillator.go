@@ -34,9 +34,10 @@ package oscillators
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"math"
 )
 
 type AOConfig struct {
@@ -73,6 +74,10 @@ func (s *AwesomeOscillatorStrategy) Name() string {
 	return "awesome_oscillator"
 }
 
+func (s *AwesomeOscillatorStrategy) Category() string {
+	return internal.CategoryOscillators
+}
+
 // calculateMedianPrice возвращает медианную цену для одной свечи: (High + Low) / 2
 func calculateMedianPrice(c internal.Candle) float64 {
 	h := c.High.ToFloat64()
@@ -85,7 +90,7 @@ func calculateMedianPrice(c internal.Candle) float64 {
 // Первые slowPeriod значений будут 0 (недостаточно данных).
 func calculateAO(candles []internal.Candle, fastPeriod, slowPeriod int) []float64 {
 	if len(candles) < slowPeriod {
-		log.Printf("Недостаточно данных для расчета AO (нужно минимум %d свечей)", slowPeriod)
+		internal.Log.Infof("Недостаточно данных для расчета AO (нужно минимум %d свечей)", slowPeriod)
 		return nil
 	}
 
@@ -139,18 +144,18 @@ func calculateAO(candles []internal.Candle, fastPeriod, slowPeriod int) []float6
 func (s *AwesomeOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	aoConfig, ok := config.(*AOConfig)
 	if !ok {
-		log.Println("Invalid AO config type")
+		internal.Log.Warnf("Invalid AO config type")
 		return make([]internal.SignalType, len(candles))
 	}
 
 	if err := aoConfig.Validate(); err != nil {
-		log.Printf("AO config validation error: %v", err)
+		internal.Log.Warnf("AO config validation error: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
 	aoValues := calculateAO(candles, aoConfig.FastPeriod, aoConfig.SlowPeriod)
 	if aoValues == nil {
-		log.Println("Не удалось рассчитать AO — возвращаем пустые сигналы")
+		internal.Log.Infof("Не удалось рассчитать AO — возвращаем пустые сигналы")
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -201,9 +206,9 @@ func (s *AwesomeOscillatorStrategy) GenerateSignalsWithConfig(candles []internal
 	return signals
 }
 
-func (s *AwesomeOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *AwesomeOscillatorStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*AOConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Перебираем параметры
 	fastOptions := []int{3, 5, 7}
@@ -222,6 +227,9 @@ func (s *AwesomeOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle
 					SlowPeriod:          slow,
 					ConfirmByTwoCandles: confirm,
 				}
+				if ctx.Err() != nil {
+					return bestConfig
+				}
 				if config.Validate() != nil {
 					continue
 				}
@@ -229,8 +237,8 @@ func (s *AwesomeOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle
 				signals := s.GenerateSignalsWithConfig(candles, config)
 				result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
 
-				if result.TotalProfit >= bestProfit {
-					bestProfit = result.TotalProfit
+				if score := internal.ObjectiveScore(result); score >= bestProfit {
+					bestProfit = score
 					bestConfig = config
 				}
 			}
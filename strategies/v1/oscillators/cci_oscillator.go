@@ -42,9 +42,9 @@ package oscillators
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"math"
 
 	"github.com/samber/lo"
 )
@@ -85,57 +85,8 @@ func (s *CCIOscillatorStrategy) Name() string {
 	return "cci_oscillator"
 }
 
-// calculateTypicalPrice — (High + Low + Close) / 3
-func calculateTypicalPrice(c internal.Candle) float64 {
-	h := c.High.ToFloat64()
-	l := c.Low.ToFloat64()
-	clo := c.Close.ToFloat64()
-	return (h + l + clo) / 3.0
-}
-
-// calculateCCI — возвращает массив значений CCI
-func calculateCCI(candles []internal.Candle, period int) []float64 {
-	if len(candles) < period {
-		return nil
-	}
-
-	cci := make([]float64, len(candles))
-
-	// Первые period-1 значений — не определены
-	for i := 0; i < period-1; i++ {
-		cci[i] = 0
-	}
-
-	for i := period - 1; i < len(candles); i++ {
-		// Берём окно из `period` свечей, заканчивающееся на i
-		var tpSum float64
-		typicalPrices := make([]float64, 0, period)
-
-		for j := i - period + 1; j <= i; j++ {
-			tp := calculateTypicalPrice(candles[j])
-			typicalPrices = append(typicalPrices, tp)
-			tpSum += tp
-		}
-
-		ma := tpSum / float64(period)
-
-		// Рассчитываем Mean Deviation
-		var mdSum float64
-		for _, tp := range typicalPrices {
-			mdSum += math.Abs(tp - ma)
-		}
-		meanDeviation := mdSum / float64(period)
-
-		// CCI = (TP - MA) / (0.015 * Mean Deviation)
-		currentTp := calculateTypicalPrice(candles[i])
-		if meanDeviation == 0 {
-			cci[i] = 0
-		} else {
-			cci[i] = (currentTp - ma) / (0.015 * meanDeviation)
-		}
-	}
-
-	return cci
+func (s *CCIOscillatorStrategy) Category() string {
+	return internal.CategoryOscillators
 }
 
 func (s *CCIOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
@@ -148,7 +99,7 @@ func (s *CCIOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Can
 		return make([]internal.SignalType, len(candles))
 	}
 
-	cciValues := calculateCCI(candles, cciConfig.Period)
+	cciValues := internal.CalculateCCI(candles, cciConfig.Period)
 	if cciValues == nil {
 		return make([]internal.SignalType, len(candles))
 	}
@@ -185,7 +136,7 @@ func (s *CCIOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Can
 	return signals
 }
 
-func (s *CCIOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *CCIOscillatorStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	configs := lo.CrossJoinBy3(
 		lo.RangeWithSteps[int](5, 10, 1),
@@ -199,7 +150,7 @@ func (s *CCIOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) in
 			}
 		})
 
-	max := s.ProcessConfigs(s, candles, configs)
+	max := s.ProcessConfigs(ctx, s, candles, configs)
 
 	bestConfig := max.A.(*CCIConfig)
 	bestProfit := max.B
@@ -52,6 +52,7 @@ package oscillators
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 
@@ -100,6 +101,10 @@ func (s *QstickOscillatorStrategy) Name() string {
 	return "qstick_oscillator"
 }
 
+func (s *QstickOscillatorStrategy) Category() string {
+	return internal.CategoryOscillators
+}
+
 // calculateQstickValues рассчитывает значения Qstick индикатора
 // Qstick = SMA(Close - Open) за период
 func calculateQstickValues(candles []internal.Candle, period int) []float64 {
@@ -138,36 +143,15 @@ func calculateTrendDirection(candles []internal.Candle, period int) []float64 {
 		return nil
 	}
 
-	trend := make([]float64, len(candles))
-
-	// Первые period-1 значений — не определены
-	for i := 0; i < period-1; i++ {
-		trend[i] = 0
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close.ToFloat64()
 	}
 
+	// Первые period-1 значений — не определены (0)
+	trend := make([]float64, len(candles))
 	for i := period - 1; i < len(candles); i++ {
-		var sumX, sumY, sumXY, sumXX float64
-		n := float64(period)
-
-		// Рассчитываем линейную регрессию
-		for j := i - period + 1; j <= i; j++ {
-			x := float64(j - (i - period + 1))
-			y := candles[j].Close.ToFloat64()
-
-			sumX += x
-			sumY += y
-			sumXY += x * y
-			sumXX += x * x
-		}
-
-		// Наклон линии тренда (slope)
-		denominator := n*sumXX - sumX*sumX
-		if denominator == 0 {
-			trend[i] = 0
-		} else {
-			slope := (n*sumXY - sumX*sumY) / denominator
-			trend[i] = slope
-		}
+		trend[i], _, _ = internal.LinearRegression(closes[i-period+1 : i+1])
 	}
 
 	return trend
@@ -272,7 +256,7 @@ func (s *QstickOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.
 	return signals
 }
 
-func (s *QstickOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *QstickOscillatorStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	configs := lo.CrossJoinBy6(
 		lo.RangeWithSteps[int](12, 19, 1),
@@ -292,7 +276,7 @@ func (s *QstickOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle)
 			}
 		})
 
-	max := s.ProcessConfigs(s, candles, configs)
+	max := s.ProcessConfigs(ctx, s, candles, configs)
 
 	bestConfig := max.A.(*QStickConfig)
 	bestProfit := max.B
@@ -39,6 +39,7 @@ package oscillators
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 
@@ -75,6 +76,10 @@ func (s *RSIOscillatorStrategy) Name() string {
 	return "rsi_oscillator"
 }
 
+func (s *RSIOscillatorStrategy) Category() string {
+	return internal.CategoryOscillators
+}
+
 func (s *RSIOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	rsiConfig, ok := config.(*RSIConfig)
 	if !ok {
@@ -114,7 +119,7 @@ func (s *RSIOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Can
 	return signals
 }
 
-func (s *RSIOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *RSIOscillatorStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	configs := lo.CrossJoinBy3(
 		lo.RangeWithSteps[int](10, 20, 1),
@@ -128,7 +133,7 @@ func (s *RSIOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) in
 			}
 		})
 
-	max := s.ProcessConfigs(s, candles, configs)
+	max := s.ProcessConfigs(ctx, s, candles, configs)
 
 	bestConfig := max.A.(*RSIConfig)
 	bestProfit := max.B
@@ -0,0 +1,181 @@
+// strategies/stoch_rsi.go
+
+// Stochastic RSI Strategy
+//
+// Описание стратегии:
+// Stochastic RSI применяет формулу стохастика не к ценам, а к значениям RSI: показывает, где текущий RSI
+// находится в диапазоне своих значений за период. Индикатор чувствительнее обычного RSI и чаще
+// доходит до крайних зон, поэтому сигналы подтверждаются пересечением сглаженных линий %K и %D.
+//
+// Как работает:
+// - Рассчитывается RSI за RSIPeriod
+// - StochRSI = 100 * (RSI - min RSI) / (max RSI - min RSI) за StochPeriod
+// - %K: SMA от StochRSI за KSmooth, %D: SMA от %K за DSmooth
+// - Покупка: %K пересекает %D снизу вверх в зоне перепроданности
+// - Продажа: %K пересекает %D сверху вниз в зоне перекупленности
+//
+// Параметры:
+// - RSIPeriod: период RSI (обычно 14)
+// - StochPeriod: окно стохастика по RSI (обычно 14)
+// - KSmooth: сглаживание %K (обычно 3)
+// - DSmooth: сглаживание %D (обычно 3)
+// - Oversold: уровень перепроданности (обычно 20)
+// - Overbought: уровень перекупленности (обычно 80)
+//
+// Сильные стороны:
+// - Реагирует на смену импульса раньше RSI
+// - Пересечение %K/%D отсекает часть случайных касаний уровней
+//
+// Слабые стороны:
+// - Много сигналов и whipsaws на шумных данных
+// - В сильном тренде долго остается в крайней зоне
+//
+// Лучшие условия для применения:
+// - Боковые/осциллирующие рынки
+// - Краткосрочная торговля на откатах
+
+package oscillators
+
+import (
+	"bt/internal"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+)
+
+type StochRSIConfig struct {
+	RSIPeriod   int     `json:"rsi_period"`
+	StochPeriod int     `json:"stoch_period"`
+	KSmooth     int     `json:"k_smooth"`
+	DSmooth     int     `json:"d_smooth"`
+	Oversold    float64 `json:"oversold"`
+	Overbought  float64 `json:"overbought"`
+}
+
+func (c *StochRSIConfig) Validate() error {
+	if c.RSIPeriod <= 0 {
+		return errors.New("rsi period must be positive")
+	}
+	if c.StochPeriod <= 0 {
+		return errors.New("stoch period must be positive")
+	}
+	if c.KSmooth <= 0 || c.DSmooth <= 0 {
+		return errors.New("smoothing periods must be positive")
+	}
+	if c.Oversold >= c.Overbought {
+		return errors.New("oversold level must be less than overbought level")
+	}
+	return nil
+}
+
+// MinCandles — прогрев RSI, окна стохастика и обоих сглаживаний плюс один бар для пересечения
+func (c *StochRSIConfig) MinCandles() int {
+	return c.RSIPeriod + c.StochPeriod + c.KSmooth + c.DSmooth - 1
+}
+
+func (c *StochRSIConfig) DefaultConfigString() string {
+	return fmt.Sprintf("StochRSI(rsi=%d, stoch=%d, k=%d, d=%d, oversold=%.1f, overbought=%.1f)",
+		c.RSIPeriod, c.StochPeriod, c.KSmooth, c.DSmooth, c.Oversold, c.Overbought)
+}
+
+type StochRSIStrategy struct {
+	internal.BaseConfig
+	internal.BaseStrategy
+}
+
+func (s *StochRSIStrategy) Name() string {
+	return "stoch_rsi"
+}
+
+func (s *StochRSIStrategy) Category() string {
+	return internal.CategoryOscillators
+}
+
+func (s *StochRSIStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	stochConfig, ok := config.(*StochRSIConfig)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := stochConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	kValues, dValues := internal.CalculateStochRSI(candles, stochConfig.RSIPeriod, stochConfig.StochPeriod,
+		stochConfig.KSmooth, stochConfig.DSmooth)
+	if kValues == nil || dValues == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	for i := stochConfig.MinCandles() - 1; i < len(candles); i++ {
+		k := kValues[i]
+		d := dValues[i]
+		kPrev := kValues[i-1]
+		dPrev := dValues[i-1]
+
+		// %K пересекает %D снизу вверх из зоны перепроданности
+		if !inPosition && kPrev <= dPrev && k > d && kPrev < stochConfig.Oversold {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		// %K пересекает %D сверху вниз из зоны перекупленности
+		if inPosition && kPrev >= dPrev && k < d && kPrev > stochConfig.Overbought {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+func (s *StochRSIStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	configs := lo.CrossJoinBy4(
+		lo.RangeWithSteps[int](6, 26, 4),
+		lo.RangeWithSteps[int](6, 26, 4),
+		[]int{1, 3, 5},
+		[]int{2, 3, 5},
+		func(rsiPeriod, stochPeriod, kSmooth, dSmooth int) internal.StrategyConfig {
+			return &StochRSIConfig{
+				RSIPeriod:   rsiPeriod,
+				StochPeriod: stochPeriod,
+				KSmooth:     kSmooth,
+				DSmooth:     dSmooth,
+				Oversold:    20.0,
+				Overbought:  80.0,
+			}
+		})
+
+	max := s.ProcessConfigs(ctx, s, candles, configs)
+
+	bestConfig := max.A.(*StochRSIConfig)
+	bestProfit := max.B
+	fmt.Printf("Лучшие параметры StochRSI: rsi=%d, stoch=%d, k=%d, d=%d, профит=%.4f\n",
+		bestConfig.RSIPeriod, bestConfig.StochPeriod, bestConfig.KSmooth, bestConfig.DSmooth, bestProfit)
+
+	return bestConfig
+}
+
+func init() {
+	internal.RegisterStrategy("stoch_rsi", &StochRSIStrategy{
+		BaseConfig: internal.BaseConfig{
+			Config: &StochRSIConfig{
+				RSIPeriod:   14,
+				StochPeriod: 14,
+				KSmooth:     3,
+				DSmooth:     3,
+				Oversold:    20.0,
+				Overbought:  80.0,
+			},
+		},
+	})
+}
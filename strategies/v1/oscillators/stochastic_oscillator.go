@@ -40,8 +40,10 @@ package oscillators
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type StochasticConfig struct {
@@ -77,6 +79,10 @@ func (s *StochasticOscillatorStrategy) Name() string {
 	return "stochastic_oscillator"
 }
 
+func (s *StochasticOscillatorStrategy) Category() string {
+	return internal.CategoryOscillators
+}
+
 func (s *StochasticOscillatorStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	stochConfig, ok := config.(*StochasticConfig)
 	if !ok {
@@ -123,9 +129,9 @@ func (s *StochasticOscillatorStrategy) GenerateSignalsWithConfig(candles []inter
 	return signals
 }
 
-func (s *StochasticOscillatorStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *StochasticOscillatorStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*StochasticConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search по параметрам
 	for kPeriod := 10; kPeriod <= 20; kPeriod += 2 {
@@ -138,14 +144,17 @@ func (s *StochasticOscillatorStrategy) OptimizeWithConfig(candles []internal.Can
 						BuyLevel:  buyLevel,
 						SellLevel: sellLevel,
 					}
+					if ctx.Err() != nil {
+						return bestConfig
+					}
 					if config.Validate() != nil {
 						continue
 					}
 
 					signals := s.GenerateSignalsWithConfig(candles, config)
 					result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-					if result.TotalProfit >= bestProfit {
-						bestProfit = result.TotalProfit
+					if score := internal.ObjectiveScore(result); score >= bestProfit {
+						bestProfit = score
 						bestConfig = config
 					}
 				}
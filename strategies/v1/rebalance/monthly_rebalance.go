@@ -2,7 +2,7 @@ package rebalance
 
 import (
 	"bt/internal"
-	"log"
+	"context"
 	"time"
 )
 
@@ -22,6 +22,10 @@ func (s *MonthlyRebalanceStrategy) Name() string {
 	return "monthly_rebalance"
 }
 
+func (s *MonthlyRebalanceStrategy) Category() string {
+	return internal.CategoryRebalance
+}
+
 func (s *MonthlyRebalanceStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	mrConfig, ok := config.(*MonthlyRebalanceConfig)
 	if !ok {
@@ -115,7 +119,7 @@ func (s *MonthlyRebalanceStrategy) GenerateSignalsWithConfig(candles []internal.
 			buyIdx := workingDays[0] // First working day
 			signals[buyIdx] = internal.BUY
 			buyCandle := candles[buyIdx]
-			log.Printf("📉 BUY: %s at price %.4f (first working day of first month)", buyCandle.Time, buyCandle.Close.ToFloat64())
+			internal.Log.Debugf("📉 BUY: %s at price %.4f (first working day of first month)", buyCandle.Time, buyCandle.Close.ToFloat64())
 		}
 
 		// Need at least 2 working days for sell signal
@@ -123,7 +127,7 @@ func (s *MonthlyRebalanceStrategy) GenerateSignalsWithConfig(candles []internal.
 			sellIdx = &workingDays[len(workingDays)-2] // Second-to-last working day
 			signals[*sellIdx] = internal.SELL
 			sellCandle := candles[*sellIdx]
-			log.Printf("📈 SELL: %s at price %.4f (first candle of second-to-last working day)", sellCandle.Time, sellCandle.Close.ToFloat64())
+			internal.Log.Debugf("📈 SELL: %s at price %.4f (first candle of second-to-last working day)", sellCandle.Time, sellCandle.Close.ToFloat64())
 		}
 
 		// Check if there's a next month for buy signal
@@ -145,7 +149,7 @@ func (s *MonthlyRebalanceStrategy) GenerateSignalsWithConfig(candles []internal.
 			if firstWorkingDay != nil {
 				signals[*firstWorkingDay] = internal.BUY
 				buyCandle := candles[*firstWorkingDay]
-				log.Printf("📉 BUY: %s at price %.4f", buyCandle.Time, buyCandle.Close.ToFloat64())
+				internal.Log.Debugf("📉 BUY: %s at price %.4f", buyCandle.Time, buyCandle.Close.ToFloat64())
 			}
 		}
 
@@ -155,7 +159,7 @@ func (s *MonthlyRebalanceStrategy) GenerateSignalsWithConfig(candles []internal.
 	return signals
 }
 
-func (s *MonthlyRebalanceStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MonthlyRebalanceStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	// This strategy doesn't have parameters to optimize, but return best config
 	return &MonthlyRebalanceConfig{}
 }
@@ -39,8 +39,10 @@ package strategies
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type PullbackSellConfig struct {
@@ -65,6 +67,10 @@ func (s *PullbackSellStrategy) Name() string {
 	return "pullback_sell"
 }
 
+func (s *PullbackSellStrategy) Category() string {
+	return internal.CategorySell
+}
+
 func (s *PullbackSellStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	psConfig, ok := config.(*PullbackSellConfig)
 	if !ok {
@@ -106,22 +112,25 @@ func (s *PullbackSellStrategy) GenerateSignalsWithConfig(candles []internal.Cand
 	return signals
 }
 
-func (s *PullbackSellStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *PullbackSellStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*PullbackSellConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	for sens := 1; sens <= 3; sens++ {
 		config := &PullbackSellConfig{
 			Sensitivity: sens,
 		}
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 		if config.Validate() != nil {
 			continue
 		}
 
 		signals := s.GenerateSignalsWithConfig(candles, config)
 		result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-		if result.TotalProfit >= bestProfit {
-			bestProfit = result.TotalProfit
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
 			bestConfig = config
 		}
 	}
@@ -3,6 +3,7 @@ package simple
 
 import (
 	"bt/internal"
+	"context"
 )
 
 type BuyAndHoldConfig struct{}
@@ -21,6 +22,10 @@ func (s *BuyAndHoldStrategy) Name() string {
 	return "buy_and_hold"
 }
 
+func (s *BuyAndHoldStrategy) Category() string {
+	return internal.CategorySimple
+}
+
 func (s *BuyAndHoldStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	bhConfig, ok := config.(*BuyAndHoldConfig)
 	if !ok {
@@ -47,7 +52,7 @@ func (s *BuyAndHoldStrategy) GenerateSignalsWithConfig(candles []internal.Candle
 	return signals
 }
 
-func (s *BuyAndHoldStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *BuyAndHoldStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	// Нет параметров для оптимизации, возврат оптимизированного конфига
 	return &BuyAndHoldConfig{}
 }
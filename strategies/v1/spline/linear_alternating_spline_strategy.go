@@ -2,7 +2,9 @@ package spline
 
 import (
 	"bt/internal"
+	"context"
 	"fmt"
+	"math"
 )
 
 type LinearAlternatingSplineConfig struct {
@@ -18,6 +20,10 @@ func (c *LinearAlternatingSplineConfig) Validate() error {
 	return nil
 }
 
+func (c *LinearAlternatingSplineConfig) MinCandles() int {
+	return c.MinSegmentLength * 2
+}
+
 func (c *LinearAlternatingSplineConfig) DefaultConfigString() string {
 	return fmt.Sprintf("LinearAlternatingSpline(min_segment_length=%d, max_segment_length=%d)",
 		c.MinSegmentLength, c.MaxSegmentLength)
@@ -37,6 +43,10 @@ func (s *LinearAlternatingSplineStrategy) Name() string {
 	return "linear_alternating_spline"
 }
 
+func (s *LinearAlternatingSplineStrategy) Category() string {
+	return internal.CategorySpline
+}
+
 func (s *LinearAlternatingSplineStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	splineConfig, ok := config.(*LinearAlternatingSplineConfig)
 	if !ok {
@@ -47,7 +57,7 @@ func (s *LinearAlternatingSplineStrategy) GenerateSignalsWithConfig(candles []in
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < splineConfig.MinSegmentLength*2 {
+	if len(candles) < splineConfig.MinCandles() {
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -134,8 +144,7 @@ func (s *LinearAlternatingSplineStrategy) fitSegment(prices []float64, startIdx,
 			break
 		}
 
-		slope, intercept := s.linearRegression(prices[startIdx:endIdx])
-		r2 := s.calculateR2(prices[startIdx:endIdx], slope, intercept)
+		slope, intercept, r2 := internal.LinearRegression(prices[startIdx:endIdx])
 
 		// Check if slope direction matches required direction
 		slopeMatches := (isAscending && slope > 0) || (!isAscending && slope < 0)
@@ -155,83 +164,37 @@ func (s *LinearAlternatingSplineStrategy) fitSegment(prices []float64, startIdx,
 	return bestSegment
 }
 
-func (s *LinearAlternatingSplineStrategy) linearRegression(y []float64) (slope, intercept float64) {
-	n := float64(len(y))
-	if n < 2 {
-		return 0, y[0]
-	}
-
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, yi := range y {
-		xi := float64(i)
-		sumX += xi
-		sumY += yi
-		sumXY += xi * yi
-		sumXX += xi * xi
-	}
-
-	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-	intercept = (sumY - slope*sumX) / n
-
-	return slope, intercept
-}
-
-func (s *LinearAlternatingSplineStrategy) calculateR2(y []float64, slope, intercept float64) float64 {
-	if len(y) < 2 {
-		return 0
-	}
-
-	mean := 0.0
-	for _, yi := range y {
-		mean += yi
-	}
-	mean /= float64(len(y))
-
-	ssRes := 0.0
-	ssTot := 0.0
-
-	for i, yi := range y {
-		predicted := slope*float64(i) + intercept
-		ssRes += (yi - predicted) * (yi - predicted)
-		ssTot += (yi - mean) * (yi - mean)
-	}
-
-	if ssTot == 0 {
-		return 0
-	}
-
-	return 1 - ssRes/ssTot
-}
-
-func (s *LinearAlternatingSplineStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *LinearAlternatingSplineStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*LinearAlternatingSplineConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search over parameter combinations
-
+	var configs []*LinearAlternatingSplineConfig
 	for maxLen := 300; maxLen < 500; maxLen += 5 {
 		for minLen := 5; minLen < 130; minLen += 5 {
 			config := &LinearAlternatingSplineConfig{
 				MaxSegmentLength: maxLen,
 				MinSegmentLength: minLen,
 			}
-
-			if config.Validate() != nil {
-				continue
+			if config.Validate() == nil {
+				configs = append(configs, config)
 			}
+		}
+	}
 
-			signals := s.GenerateSignalsWithConfig(candles, config)
-			result := internal.Backtest(candles, signals, s.GetSlippage())
+	// Skip configs whose segments do not fit into the available candles
+	for _, config := range internal.FilterByDataRequirement(configs, len(candles)) {
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 
-			// fmt.Printf("Параметры Linear Alternating Spline: max_length=%d, min_length=%d, профит=%.4f\n",
-			// 	config.MaxSegmentLength, config.MinSegmentLength, result.TotalProfit)
+		signals := s.GenerateSignalsWithConfig(candles, config)
+		result := internal.Backtest(candles, signals, s.GetSlippage())
 
-			// Select configuration with highest profit
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
-				bestConfig = config
-			}
+		// Select configuration with highest profit
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
+			bestConfig = config
 		}
 	}
 
@@ -2,6 +2,7 @@ package spline
 
 import (
 	"bt/internal"
+	"context"
 	"encoding/json"
 	"fmt"
 	"math"
@@ -24,6 +25,10 @@ func (c *QuadraticVariableTrendSplineConfig) Validate() error {
 	return nil
 }
 
+func (c *QuadraticVariableTrendSplineConfig) MinCandles() int {
+	return c.MinSegmentLength * 2
+}
+
 func (c *QuadraticVariableTrendSplineConfig) DefaultConfigString() string {
 	return fmt.Sprintf("QuadraticVariableTrendSpline(min_segment_length=%d, max_segment_length=%d)",
 		c.MinSegmentLength, c.MaxSegmentLength)
@@ -45,6 +50,10 @@ func (s *QuadraticVariableTrendSplineStrategy) Name() string {
 	return "quadratic_variable_trend_spline"
 }
 
+func (s *QuadraticVariableTrendSplineStrategy) Category() string {
+	return internal.CategorySpline
+}
+
 func (s *QuadraticVariableTrendSplineStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	splineConfig, ok := config.(*QuadraticVariableTrendSplineConfig)
 	if !ok {
@@ -55,7 +64,7 @@ func (s *QuadraticVariableTrendSplineStrategy) GenerateSignalsWithConfig(candles
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < splineConfig.MinSegmentLength*2 {
+	if len(candles) < splineConfig.MinCandles() {
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -220,7 +229,7 @@ func (s *QuadraticVariableTrendSplineStrategy) quadraticRegression(y []float64)
 
 	if math.Abs(detA) < 1e-10 {
 		// Degenerate case, fall back to linear regression
-		slope, intercept := s.linearRegression(y)
+		slope, intercept, _ := internal.LinearRegression(y)
 		return 0, slope, intercept
 	}
 
@@ -240,28 +249,6 @@ func (s *QuadraticVariableTrendSplineStrategy) quadraticRegression(y []float64)
 	return a, b, c
 }
 
-func (s *QuadraticVariableTrendSplineStrategy) linearRegression(y []float64) (slope, intercept float64) {
-	n := float64(len(y))
-	if n < 2 {
-		return 0, y[0]
-	}
-
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, yi := range y {
-		xi := float64(i)
-		sumX += xi
-		sumY += yi
-		sumXY += xi * yi
-		sumXX += xi * xi
-	}
-
-	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-	intercept = (sumY - slope*sumX) / n
-
-	return slope, intercept
-}
-
 func (s *QuadraticVariableTrendSplineStrategy) calculateQuadraticR2(y []float64, a, b, c float64) float64 {
 	if len(y) < 3 {
 		return 0
@@ -289,12 +276,11 @@ func (s *QuadraticVariableTrendSplineStrategy) calculateQuadraticR2(y []float64,
 	return 1 - ssRes/ssTot
 }
 
-func (s *QuadraticVariableTrendSplineStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *QuadraticVariableTrendSplineStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*QuadraticVariableTrendSplineConfig)
-	bestProfit := -1.0
-
-	var results []internal.GridSearchResult
+	bestProfit := math.Inf(-1)
 
+	var configs []*QuadraticVariableTrendSplineConfig
 	for minLen := 5; minLen < 80; minLen += 5 {
 		for maxLen := 40; maxLen < 420; maxLen += 5 {
 			if maxLen < minLen {
@@ -305,26 +291,34 @@ func (s *QuadraticVariableTrendSplineStrategy) OptimizeWithConfig(candles []inte
 				MinSegmentLength: minLen,
 				MaxSegmentLength: maxLen,
 			}
-
-			if config.Validate() != nil {
-				continue
+			if config.Validate() == nil {
+				configs = append(configs, config)
 			}
+		}
+	}
 
-			signals := s.GenerateSignalsWithConfig(candles, config)
-			result := internal.Backtest(candles, signals, s.GetSlippage())
+	var results []internal.GridSearchResult
 
-			// Collect results for mesh format
-			results = append(results, internal.GridSearchResult{
-				X:      minLen,
-				Y:      maxLen,
-				Profit: result.TotalProfit,
-			})
+	// Skip configs whose segments do not fit into the available candles
+	for _, config := range internal.FilterByDataRequirement(configs, len(candles)) {
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 
-			// Select configuration with highest profit
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
-				bestConfig = config
-			}
+		signals := s.GenerateSignalsWithConfig(candles, config)
+		result := internal.Backtest(candles, signals, s.GetSlippage())
+
+		// Collect results for mesh format
+		results = append(results, internal.GridSearchResult{
+			X:      config.MinSegmentLength,
+			Y:      config.MaxSegmentLength,
+			Profit: result.TotalProfit,
+		})
+
+		// Select configuration with highest profit
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
+			bestConfig = config
 		}
 	}
 
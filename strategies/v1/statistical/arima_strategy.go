@@ -11,9 +11,9 @@ package statistical
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -367,6 +367,10 @@ func (s *ARIMAStrategy) Name() string {
 	return "arima_strategy"
 }
 
+func (s *ARIMAStrategy) Category() string {
+	return internal.CategoryStatistical
+}
+
 // validateModel проверяет качество обученной модели
 func (s *ARIMAStrategy) validateModel(model *ARIMAModel, data []float64) bool {
 	if len(data) < 20 {
@@ -400,26 +404,11 @@ func (s *ARIMAStrategy) calculateTrendStrength(prices []float64) float64 {
 		return 0.0
 	}
 
-	n := float64(len(prices))
-	sumX := n * (n - 1) / 2
-	sumY := 0.0
-	sumXY := 0.0
-	sumXX := n * (n - 1) * (2*n - 1) / 6
-
-	for i, price := range prices {
-		x := float64(i)
-		sumY += price
-		sumXY += x * price
-	}
+	slope, intercept, _ := internal.LinearRegression(prices)
 
-	// Коэффициент наклона (slope)
-	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-
-	// Нормализуем силу тренда
-	avgPrice := sumY / n
-	trendStrength := slope / avgPrice
-
-	return trendStrength
+	// Нормализуем силу тренда: прямая МНК проходит через среднюю цену в середине окна
+	avgPrice := intercept + slope*float64(len(prices)-1)/2
+	return slope / avgPrice
 }
 
 // generateEnhancedSignal генерирует улучшенный сигнал с учетом рыночных условий
@@ -455,7 +444,7 @@ func (s *ARIMAStrategy) GenerateSignalsWithConfig(candles []internal.Candle, con
 	}
 
 	if len(candles) < 100 {
-		log.Printf("⚠️ Недостаточно данных для улучшенной ARIMA: получено %d свечей, требуется минимум 100", len(candles))
+		internal.Log.Infof("⚠️ Недостаточно данных для улучшенной ARIMA: получено %d свечей, требуется минимум 100", len(candles))
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -474,10 +463,10 @@ func (s *ARIMAStrategy) GenerateSignalsWithConfig(candles []internal.Candle, con
 	windowSize := 300
 	baseThreshold := 0.005 // 0.5%
 
-	log.Printf("🚀 ЗАПУСК УЛУЧШЕННОЙ ARIMA СТРАТЕГИИ:")
-	log.Printf("   Параметры: AR(%d,%d,%d)", arOrder, diffOrder, maOrder)
-	log.Printf("   Окно обучения: %d свечей", windowSize)
-	log.Printf("   Базовый порог: %.2f%%", baseThreshold*100)
+	internal.Log.Infof("🚀 ЗАПУСК УЛУЧШЕННОЙ ARIMA СТРАТЕГИИ:")
+	internal.Log.Infof("   Параметры: AR(%d,%d,%d)", arOrder, diffOrder, maOrder)
+	internal.Log.Infof("   Окно обучения: %d свечей", windowSize)
+	internal.Log.Infof("   Базовый порог: %.2f%%", baseThreshold*100)
 
 	// Генерируем сигналы с использованием улучшенной логики
 	signals := make([]internal.SignalType, len(candles))
@@ -539,13 +528,13 @@ func (s *ARIMAStrategy) GenerateSignalsWithConfig(candles []internal.Candle, con
 		}
 	}
 
-	log.Printf("✅ Улучшенный ARIMA анализ завершен")
+	internal.Log.Infof("✅ Улучшенный ARIMA анализ завершен")
 	return signals
 }
 
-func (s *ARIMAStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *ARIMAStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*ARIMAConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем параметры ARIMA
 	for arOrder := 1; arOrder <= 5; arOrder++ {
@@ -555,14 +544,17 @@ func (s *ARIMAStrategy) OptimizeWithConfig(candles []internal.Candle) internal.S
 				DiffOrder: diffOrder,
 				MaOrder:   0, // MA отключена для простоты
 			}
+			if ctx.Err() != nil {
+				return bestConfig
+			}
 			if config.Validate() != nil {
 				continue
 			}
 
 			signals := s.GenerateSignalsWithConfig(candles, config)
 			result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
+			if score := internal.ObjectiveScore(result); score >= bestProfit {
+				bestProfit = score
 				bestConfig = config
 			}
 		}
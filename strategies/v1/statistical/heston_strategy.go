@@ -19,9 +19,9 @@ package statistical
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 	"math/rand"
 )
@@ -49,6 +49,11 @@ func (c *HestonConfig) Validate() error {
 	return nil
 }
 
+// MinCandles — окно калибровки плюс запас на прогрев модели
+func (c *HestonConfig) MinCandles() int {
+	return c.WindowSize + 50
+}
+
 func (c *HestonConfig) DefaultConfigString() string {
 	return fmt.Sprintf("Heston(window=%d, sims=%d)",
 		c.WindowSize, c.NumSimulations)
@@ -169,6 +174,10 @@ func (s *HestonStrategy) Name() string {
 	return "heston_strategy"
 }
 
+func (s *HestonStrategy) Category() string {
+	return internal.CategoryStatistical
+}
+
 func (s *HestonStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	hestonConfig, ok := config.(*HestonConfig)
 	if !ok {
@@ -176,13 +185,13 @@ func (s *HestonStrategy) GenerateSignalsWithConfig(candles []internal.Candle, co
 	}
 
 	if err := hestonConfig.Validate(); err != nil {
-		log.Printf("❌ Ошибка конфигурации Heston: %v", err)
+		internal.Log.Warnf("❌ Ошибка конфигурации Heston: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < hestonConfig.WindowSize+50 {
-		log.Printf("⚠️ Недостаточно данных для Heston: получено %d свечей, требуется минимум %d",
-			len(candles), hestonConfig.WindowSize+50)
+	if len(candles) < hestonConfig.MinCandles() {
+		internal.Log.Infof("⚠️ Недостаточно данных для Heston: получено %d свечей, требуется минимум %d",
+			len(candles), hestonConfig.MinCandles())
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -192,11 +201,11 @@ func (s *HestonStrategy) GenerateSignalsWithConfig(candles []internal.Candle, co
 		prices[i] = candle.Close.ToFloat64()
 	}
 
-	log.Printf("🚀 ЗАПУСК СТРАТЕГИИ HESTON:")
-	log.Printf("   Окно калибровки: %d свечей", hestonConfig.WindowSize)
-	log.Printf("   Шагов прогноза: %d", hestonConfig.PredictionSteps)
-	log.Printf("   Симуляций: %d", hestonConfig.NumSimulations)
-	log.Printf("   Порог сигнала: %.2f%%", hestonConfig.Threshold*100)
+	internal.Log.Infof("🚀 ЗАПУСК СТРАТЕГИИ HESTON:")
+	internal.Log.Infof("   Окно калибровки: %d свечей", hestonConfig.WindowSize)
+	internal.Log.Infof("   Шагов прогноза: %d", hestonConfig.PredictionSteps)
+	internal.Log.Infof("   Симуляций: %d", hestonConfig.NumSimulations)
+	internal.Log.Infof("   Порог сигнала: %.2f%%", hestonConfig.Threshold*100)
 
 	signals := make([]internal.SignalType, len(candles))
 	dt := 1.0 / 252.0 // дневной шаг (252 торговых дня в году)
@@ -279,21 +288,22 @@ func (s *HestonStrategy) GenerateSignalsWithConfig(candles []internal.Candle, co
 		signals[i] = signal
 	}
 
-	log.Printf("📊 Статистика сигналов: BUY=%d, SELL=%d, Всего=%d", buySignals, sellSignals, buySignals+sellSignals)
+	internal.Log.Infof("📊 Статистика сигналов: BUY=%d, SELL=%d, Всего=%d", buySignals, sellSignals, buySignals+sellSignals)
 
-	log.Printf("✅ Анализ Heston завершен")
+	internal.Log.Infof("✅ Анализ Heston завершен")
 	return signals
 }
 
-func (s *HestonStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *HestonStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*HestonConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем параметры для более активной торговли
 	windowSizes := []int{50, 80, 120}
 	predictionSteps := []int{2, 3, 5}
 	thresholds := []float64{0.008, 0.012, 0.018, 0.025}
 
+	var configs []*HestonConfig
 	for _, windowSize := range windowSizes {
 		for _, steps := range predictionSteps {
 			for _, threshold := range thresholds {
@@ -303,19 +313,25 @@ func (s *HestonStrategy) OptimizeWithConfig(candles []internal.Candle) internal.
 					NumSimulations:  300, // уменьшаем для оптимизации
 					Threshold:       threshold,
 				}
-
-				if config.Validate() != nil {
-					continue
+				if config.Validate() == nil {
+					configs = append(configs, config)
 				}
+			}
+		}
+	}
+
+	// Окна длиннее истории дали бы одни HOLD — такие конфигурации не оцениваются
+	for _, config := range internal.FilterByDataRequirement(configs, len(candles)) {
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 
-				signals := s.GenerateSignalsWithConfig(candles, config)
-				result := internal.Backtest(candles, signals, s.GetSlippage())
+		signals := s.GenerateSignalsWithConfig(candles, config)
+		result := internal.Backtest(candles, signals, s.GetSlippage())
 
-				if result.TotalProfit >= bestProfit {
-					bestProfit = result.TotalProfit
-					bestConfig = config
-				}
-			}
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
+			bestConfig = config
 		}
 	}
 
@@ -2,6 +2,7 @@ package trend
 
 import (
 	"bt/internal"
+	"context"
 	"fmt"
 	"log"
 	"math"
@@ -65,6 +66,10 @@ func (s *FOMOStrategy) Name() string {
 	return "FOMO"
 }
 
+func (s *FOMOStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
 // FOMOState tracks psychological state for FOMO calculation
 type FOMOState struct {
 	consecutiveUp   int     // Consecutive up moves
@@ -321,7 +326,7 @@ func (s *FOMOStrategy) generateFOMOSignal(candles []internal.Candle, i int, fomo
 	return internal.HOLD
 }
 
-func (s *FOMOStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *FOMOStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*FOMOConfig)
 
 	// Test different parameter combinations for psychological FOMO factors
@@ -329,6 +334,9 @@ func (s *FOMOStrategy) OptimizeWithConfig(candles []internal.Candle) internal.St
 		for _, momentumThreshold := range []float64{0.01, 0.02, 0.03, 0.05} {
 			for _, consecutiveBars := range []int{2, 3, 4, 5} {
 				for _, fearDecay := range []float64{0.7, 0.8, 0.9} {
+					if ctx.Err() != nil {
+						return bestConfig
+					}
 					config := &FOMOConfig{
 						VolumeLookback:     20,
 						MomentumLookback:   10,
@@ -43,6 +43,7 @@ package trend
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 
@@ -81,6 +82,10 @@ func (s *GoldenCrossStrategy) Name() string {
 	return "golden_cross"
 }
 
+func (s *GoldenCrossStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
 func (s *GoldenCrossStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	gcConfig, ok := config.(*GoldenCrossConfig)
 	if !ok {
@@ -91,11 +96,8 @@ func (s *GoldenCrossStrategy) GenerateSignalsWithConfig(candles []internal.Candl
 		return make([]internal.SignalType, len(candles))
 	}
 
-	// Извлекаем цены закрытия для расчета EMA
-	prices := make([]float64, len(candles))
-	for i, candle := range candles {
-		prices[i] = candle.Close.ToFloat64()
-	}
+	// Извлекаем цены (по умолчанию закрытия) для расчета EMA
+	prices := internal.ExtractPrices(candles, internal.DefaultPriceSource())
 
 	// Рассчитываем экспоненциальные скользящие средние
 	fastEMA := internal.CalculateEMAForValues(prices, gcConfig.FastPeriod)
@@ -146,7 +148,7 @@ func (s *GoldenCrossStrategy) GenerateSignalsWithConfig(candles []internal.Candl
 	return signals
 }
 
-func (s *GoldenCrossStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *GoldenCrossStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 
 	configs := lo.CrossJoinBy2(
 		lo.RangeWithSteps[int](5, 240, 5),
@@ -158,7 +160,7 @@ func (s *GoldenCrossStrategy) OptimizeWithConfig(candles []internal.Candle) inte
 			}
 		})
 
-	max := s.ProcessConfigs(s, candles, configs)
+	max := s.ProcessConfigs(ctx, s, candles, configs)
 
 	bestConfig := max.A.(*GoldenCrossConfig)
 	bestProfit := max.B
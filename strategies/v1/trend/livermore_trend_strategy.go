@@ -2,9 +2,10 @@ package trend
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
+	"math"
 )
 
 type LivermoreConfig struct {
@@ -26,6 +27,10 @@ func (c *LivermoreConfig) Validate() error {
 	return nil
 }
 
+func (c *LivermoreConfig) MinCandles() int {
+	return max(c.EMAPeriod, c.AvgVolumePeriod)
+}
+
 func (c *LivermoreConfig) DefaultConfigString() string {
 	return fmt.Sprintf("Livermore(EMA=%d, VolMult=%.2f, VolAvg=%d)",
 		c.EMAPeriod, c.VolumeMultiplier, c.AvgVolumePeriod)
@@ -41,6 +46,10 @@ func (s *LivermoreTrendStrategy) Name() string {
 	return "livermore_trend"
 }
 
+func (s *LivermoreTrendStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
 // calculateEMA calculates Exponential Moving Average for given period.
 func calculateEMA(prices []float64, period int) []float64 {
 	if len(prices) < period {
@@ -85,17 +94,17 @@ func calculateAvgVolume(volumes []float64, period int) []float64 {
 func (s *LivermoreTrendStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	liveConfig, ok := config.(*LivermoreConfig)
 	if !ok {
-		log.Println("Invalid Livermore config type")
+		internal.Log.Warnf("Invalid Livermore config type")
 		return make([]internal.SignalType, len(candles))
 	}
 
 	if err := liveConfig.Validate(); err != nil {
-		log.Printf("Livermore config validation error: %v", err)
+		internal.Log.Warnf("Livermore config validation error: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < liveConfig.EMAPeriod || len(candles) < liveConfig.AvgVolumePeriod {
-		log.Println("Not enough candles for Livermore strategy")
+	if len(candles) < liveConfig.MinCandles() {
+		internal.Log.Infof("Not enough candles for Livermore strategy")
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -168,15 +177,16 @@ func (s *LivermoreTrendStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 	return signals
 }
 
-func (s *LivermoreTrendStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *LivermoreTrendStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*LivermoreConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Test different parameters
 	emaOptions := []int{10, 20, 50}
 	volMultOptions := []float64{1.0, 1.5, 2.0}
 	avgVolOptions := []int{10, 20}
 
+	var configs []*LivermoreConfig
 	for _, emaPeriod := range emaOptions {
 		for _, volMult := range volMultOptions {
 			for _, avgVolPeriod := range avgVolOptions {
@@ -185,18 +195,25 @@ func (s *LivermoreTrendStrategy) OptimizeWithConfig(candles []internal.Candle) i
 					VolumeMultiplier: volMult,
 					AvgVolumePeriod:  avgVolPeriod,
 				}
-				if config.Validate() != nil {
-					continue
+				if config.Validate() == nil {
+					configs = append(configs, config)
 				}
+			}
+		}
+	}
 
-				signals := s.GenerateSignalsWithConfig(candles, config)
-				result := internal.Backtest(candles, signals, s.GetSlippage())
+	// Skip configs whose look-back exceeds the available candles
+	for _, config := range internal.FilterByDataRequirement(configs, len(candles)) {
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 
-				if result.TotalProfit >= bestProfit {
-					bestProfit = result.TotalProfit
-					bestConfig = config
-				}
-			}
+		signals := s.GenerateSignalsWithConfig(candles, config)
+		result := internal.Backtest(candles, signals, s.GetSlippage())
+
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
+			bestConfig = config
 		}
 	}
 
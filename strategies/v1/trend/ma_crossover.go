@@ -39,8 +39,10 @@ package trend
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type MACrossoverConfig struct {
@@ -72,6 +74,10 @@ func (s *MACrossoverStrategy) Name() string {
 	return "ma_crossover"
 }
 
+func (s *MACrossoverStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
 func (s *MACrossoverStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	maConfig, ok := config.(*MACrossoverConfig)
 	if !ok {
@@ -90,47 +96,37 @@ func (s *MACrossoverStrategy) GenerateSignalsWithConfig(candles []internal.Candl
 		return make([]internal.SignalType, len(candles))
 	}
 
-	signals := make([]internal.SignalType, len(candles))
 	inPosition := false
 
-	// Начинаем анализ с максимального из двух периодов
-	startIndex := maConfig.SlowPeriod - 1
-	if maConfig.FastPeriod > maConfig.SlowPeriod {
-		startIndex = maConfig.FastPeriod - 1
-	}
-
-	for i := startIndex; i < len(candles); i++ {
-		// Проверяем пересечение скользящих средних
-		if i > startIndex {
-			prevFast := fastMA[i-1]
-			prevSlow := slowMA[i-1]
-			currFast := fastMA[i]
-			currSlow := slowMA[i]
-
-			// Быстрая MA пересекает медленную MA снизу вверх - сигнал на покупку
-			if !inPosition && prevFast <= prevSlow && currFast > currSlow {
-				signals[i] = internal.BUY
-				inPosition = true
-				continue
-			}
+	// Пересечение сравнивает текущий бар с предыдущим, поэтому обе средние должны быть рассчитаны и на нем
+	warmup := internal.WarmupLength(internal.SMAWarmup(maConfig.FastPeriod), internal.SMAWarmup(maConfig.SlowPeriod))
+	signals := internal.GenerateAfterWarmup(len(candles), warmup, 1, func(i int) internal.SignalType {
+		prevFast := fastMA[i-1]
+		prevSlow := slowMA[i-1]
+		currFast := fastMA[i]
+		currSlow := slowMA[i]
+
+		// Быстрая MA пересекает медленную MA снизу вверх - сигнал на покупку
+		if !inPosition && prevFast <= prevSlow && currFast > currSlow {
+			inPosition = true
+			return internal.BUY
+		}
 
-			// Быстрая MA пересекает медленную MA сверху вниз - сигнал на продажу
-			if inPosition && prevFast >= prevSlow && currFast < currSlow {
-				signals[i] = internal.SELL
-				inPosition = false
-				continue
-			}
+		// Быстрая MA пересекает медленную MA сверху вниз - сигнал на продажу
+		if inPosition && prevFast >= prevSlow && currFast < currSlow {
+			inPosition = false
+			return internal.SELL
 		}
 
-		signals[i] = internal.HOLD
-	}
+		return internal.HOLD
+	})
 
 	return signals
 }
 
-func (s *MACrossoverStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MACrossoverStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*MACrossoverConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем периоды скользящих средних
 	for fast := 5; fast <= 15; fast += 2 {
@@ -139,14 +135,17 @@ func (s *MACrossoverStrategy) OptimizeWithConfig(candles []internal.Candle) inte
 				FastPeriod: fast,
 				SlowPeriod: slow,
 			}
+			if ctx.Err() != nil {
+				return bestConfig
+			}
 			if config.Validate() != nil {
 				continue
 			}
 
 			signals := s.GenerateSignalsWithConfig(candles, config)
 			result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
+			if score := internal.ObjectiveScore(result); score >= bestProfit {
+				bestProfit = score
 				bestConfig = config
 			}
 		}
@@ -0,0 +1,88 @@
+package trend
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestMACrossover_SignalsStartAfterWarmup(t *testing.T) {
+	// Падение, затем рост: быстрая средняя пересекает медленную снизу вверх после разворота
+	candles := make([]internal.Candle, 80)
+	for i := range candles {
+		price := 200.0 - 2*float64(i)
+		if i >= 30 {
+			price = 140.0 + 3*float64(i-30)
+		}
+		candles[i] = internal.Candle{Close: internal.Price(price)}
+	}
+	config := &MACrossoverConfig{FastPeriod: 5, SlowPeriod: 15}
+
+	signals := (&MACrossoverStrategy{}).GenerateSignalsWithConfig(candles, config)
+
+	// Пересечение читает предыдущий бар, поэтому первый возможный сигнал — на баре после прогрева
+	first := internal.WarmupLength(internal.SMAWarmup(config.FastPeriod), internal.SMAWarmup(config.SlowPeriod)) + 1
+	buys := 0
+	for i, signal := range signals {
+		if i < first && signal != internal.HOLD {
+			t.Errorf("signal %v at bar %d before the first warm crossover bar %d", signal, i, first)
+		}
+		if signal == internal.BUY {
+			buys++
+			if i < 30 {
+				t.Errorf("BUY at bar %d during the decline", i)
+			}
+		}
+	}
+	if buys != 1 {
+		t.Errorf("expected one BUY after the reversal, got %d", buys)
+	}
+}
+
+func TestMACrossoverOptimize_UsesObjectiveAndRecency(t *testing.T) {
+	// Волны разной длины и амплитуды: по прибыли лучшая пара 7/22, по UPI — 5/20 с меньшими просадками
+	candles := make([]internal.Candle, 300)
+	for i := range candles {
+		x := float64(i)
+		price := 100 + 0.05*x + 8*math.Sin(x/8) + 3*math.Sin(x/2)
+		candles[i] = internal.Candle{Close: internal.Price(price)}
+	}
+
+	objective, halfLife := internal.DefaultObjective(), internal.DefaultRecencyHalfLife()
+	defer func() {
+		internal.SetDefaultObjective(objective)
+		internal.SetDefaultRecencyHalfLife(halfLife)
+	}()
+
+	strategy := &MACrossoverStrategy{}
+	strategy.Config = &MACrossoverConfig{FastPeriod: 5, SlowPeriod: 15}
+	score := func(config *MACrossoverConfig) float64 {
+		signals := strategy.GenerateSignalsWithConfig(candles, config)
+		return internal.ObjectiveScore(internal.Backtest(candles, signals, strategy.GetSlippage()))
+	}
+
+	// Собственный цикл перебора учитывает и --objective, и --recency-half-life
+	for _, tc := range []struct {
+		objective internal.Objective
+		halfLife  int
+	}{
+		{internal.ObjectiveUPI, 0},
+		{internal.ObjectiveProfit, 20},
+	} {
+		internal.SetDefaultObjective(tc.objective)
+		internal.SetDefaultRecencyHalfLife(tc.halfLife)
+
+		best := strategy.OptimizeWithConfig(context.Background(), candles).(*MACrossoverConfig)
+		bestScore := score(best)
+		for fast := 5; fast <= 15; fast += 2 {
+			for slow := fast + 5; slow <= 30; slow += 5 {
+				if s := score(&MACrossoverConfig{FastPeriod: fast, SlowPeriod: slow}); s > bestScore+1e-12 {
+					t.Errorf("%s, half-life %d: fast=%d slow=%d scores %.4f, above the chosen fast=%d slow=%d (%.4f)",
+						tc.objective, tc.halfLife, fast, slow, s, best.FastPeriod, best.SlowPeriod, bestScore)
+				}
+			}
+		}
+	}
+}
@@ -42,6 +42,7 @@ package trend
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -73,6 +74,10 @@ func (s *SuperTrendStrategy) Name() string {
 	return "supertrend"
 }
 
+func (s *SuperTrendStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
 // calculateATR рассчитывает Average True Range
 func calculateATR(candles []internal.Candle, period int) []float64 {
 	if len(candles) < period+1 {
@@ -80,36 +85,18 @@ func calculateATR(candles []internal.Candle, period int) []float64 {
 	}
 
 	atr := make([]float64, len(candles))
-
-	// Первые period+1 значений рассчитываем простым средним
-	for i := 1; i < len(candles); i++ {
-		if i < period+1 {
-			high := candles[i].High.ToFloat64()
-			low := candles[i].Low.ToFloat64()
-			prevClose := candles[i-1].Close.ToFloat64()
-
-			tr := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
-			atr[i] = tr
-		} else {
-			break
-		}
-	}
+	trueRanges := internal.CalculateTrueRange(candles)
 
 	// Рассчитываем первое ATR как простое среднее
 	sum := 0.0
 	for i := 1; i < period+1 && i < len(candles); i++ {
-		sum += atr[i]
+		sum += trueRanges[i]
 	}
 	atr[period] = sum / float64(period)
 
 	// Рассчитываем остальные ATR с использованием smoothing
 	for i := period + 1; i < len(candles); i++ {
-		high := candles[i].High.ToFloat64()
-		low := candles[i].Low.ToFloat64()
-		prevClose := candles[i-1].Close.ToFloat64()
-
-		tr := math.Max(high-low, math.Max(math.Abs(high-prevClose), math.Abs(low-prevClose)))
-		atr[i] = (atr[i-1]*float64(period-1) + tr) / float64(period)
+		atr[i] = (atr[i-1]*float64(period-1) + trueRanges[i]) / float64(period)
 	}
 
 	return atr
@@ -229,9 +216,9 @@ func (s *SuperTrendStrategy) GenerateSignalsWithConfig(candles []internal.Candle
 	return signals
 }
 
-func (s *SuperTrendStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *SuperTrendStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*SupertrendConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search по параметрам
 	for period := 7; period <= 20; period += 1 {
@@ -240,14 +227,17 @@ func (s *SuperTrendStrategy) OptimizeWithConfig(candles []internal.Candle) inter
 				Period:     period,
 				Multiplier: multiplier,
 			}
+			if ctx.Err() != nil {
+				return bestConfig
+			}
 			if config.Validate() != nil {
 				continue
 			}
 
 			signals := s.GenerateSignalsWithConfig(candles, config)
 			result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
+			if score := internal.ObjectiveScore(result); score >= bestProfit {
+				bestProfit = score
 				bestConfig = config
 			}
 		}
@@ -0,0 +1,137 @@
+// strategies/trend/vortex_strategy.go
+
+// VortexStrategy - стратегия на индикаторе Vortex
+//
+// Описание стратегии:
+// Индикатор Vortex (Botes & Siepman, 2010) сравнивает восходящее и нисходящее «вихревое» движение цены,
+// нормированное на истинный диапазон (True Range), и определяет начало и смену тренда.
+//
+// Как работает:
+// - VM+ = |High − предыдущий Low| — сила движения вверх
+// - VM− = |Low − предыдущий High| — сила движения вниз
+// - VI+ и VI− — суммы VM+ и VM− за период, деленные на сумму True Range за тот же период
+// - Покупка: VI+ пересекает VI− снизу вверх (начало восходящего тренда)
+// - Продажа: VI− пересекает VI+ снизу вверх (разворот вниз)
+//
+// Параметры:
+// - Period: период суммирования (обычно 14, диапазон 7-30)
+//
+// Сильные стороны:
+// - Учитывает весь диапазон свечей, а не только цены закрытия
+// - Нормировка на True Range делает индикатор независимым от уровня цены
+// - Один параметр, простая интерпретация
+//
+// Слабые стороны:
+// - Частые пересечения и ложные сигналы в боковом рынке
+// - Запаздывание при коротких резких движениях
+//
+// Лучшие условия для применения:
+// - Трендовые рынки с выраженными фазами роста и падения
+// - Среднесрочная торговля
+
+package trend
+
+import (
+	"bt/internal"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+)
+
+type VortexConfig struct {
+	Period int `json:"period"`
+}
+
+func (c *VortexConfig) Validate() error {
+	if c.Period <= 1 {
+		return errors.New("period must be greater than 1")
+	}
+	return nil
+}
+
+// MinCandles — прогрев индикатора плюс один бар для поиска пересечения
+func (c *VortexConfig) MinCandles() int {
+	return c.Period + 2
+}
+
+func (c *VortexConfig) DefaultConfigString() string {
+	return fmt.Sprintf("Vortex(period=%d)", c.Period)
+}
+
+type VortexStrategy struct {
+	internal.BaseConfig
+	internal.BaseStrategy
+}
+
+func (s *VortexStrategy) Name() string {
+	return "vortex"
+}
+
+func (s *VortexStrategy) Category() string {
+	return internal.CategoryTrend
+}
+
+func (s *VortexStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	vortexConfig, ok := config.(*VortexConfig)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := vortexConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	viPlus, viMinus := internal.CalculateVortex(candles, vortexConfig.Period)
+	if viPlus == nil || viMinus == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	for i := vortexConfig.Period + 1; i < len(candles); i++ {
+		// VI+ пересекает VI− снизу вверх — покупка
+		if !inPosition && viPlus[i-1] <= viMinus[i-1] && viPlus[i] > viMinus[i] {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		// VI− пересекает VI+ снизу вверх — продажа
+		if inPosition && viMinus[i-1] <= viPlus[i-1] && viMinus[i] > viPlus[i] {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+func (s *VortexStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	configs := lo.Map(lo.RangeWithSteps[int](5, 41, 1), func(period int, _ int) internal.StrategyConfig {
+		return &VortexConfig{Period: period}
+	})
+
+	max := s.ProcessConfigs(ctx, s, candles, configs)
+
+	bestConfig := max.A.(*VortexConfig)
+	bestProfit := max.B
+	fmt.Printf("Лучшие параметры Vortex: period=%d, profit=%.4f\n", bestConfig.Period, bestProfit)
+
+	return bestConfig
+}
+
+func init() {
+	internal.RegisterStrategy("vortex", &VortexStrategy{
+		BaseConfig: internal.BaseConfig{
+			Config: &VortexConfig{
+				Period: 14,
+			},
+		},
+	})
+}
@@ -40,6 +40,7 @@ package volatility
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -71,92 +72,8 @@ func (s *BollingerBandsStrategy) Name() string {
 	return "bollinger_bands"
 }
 
-// calculateBBMiddle — рассчитывает среднюю линию Bollinger Bands (SMA)
-func calculateBBMiddle(candles []internal.Candle, period int) []float64 {
-	if len(candles) < period {
-		return nil
-	}
-
-	middle := make([]float64, len(candles))
-
-	// Первые period-1 значений — не определены
-	for i := 0; i < period-1; i++ {
-		middle[i] = 0
-	}
-
-	for i := period - 1; i < len(candles); i++ {
-		var sum float64
-		// Суммируем цены закрытия за период
-		for j := i - period + 1; j <= i; j++ {
-			sum += candles[j].Close.ToFloat64()
-		}
-		middle[i] = sum / float64(period)
-	}
-
-	return middle
-}
-
-// calculateBBStdDev — рассчитывает стандартное отклонение для Bollinger Bands
-func calculateBBStdDev(candles []internal.Candle, middle []float64, period int) []float64 {
-	if len(middle) == 0 || len(candles) < period {
-		return nil
-	}
-
-	stdDev := make([]float64, len(candles))
-
-	// Первые period-1 значений — не определены
-	for i := 0; i < period-1; i++ {
-		stdDev[i] = 0
-	}
-
-	for i := period - 1; i < len(candles); i++ {
-		var sumSquares float64
-		midValue := middle[i]
-
-		// Суммируем квадраты отклонений от среднего
-		for j := i - period + 1; j <= i; j++ {
-			price := candles[j].Close.ToFloat64()
-			diff := price - midValue
-			sumSquares += diff * diff
-		}
-
-		// Стандартное отклонение
-		stdDev[i] = math.Sqrt(sumSquares / float64(period))
-	}
-
-	return stdDev
-}
-
-// calculateBollingerBands — возвращает верхнюю, среднюю и нижнюю полосы
-func calculateBollingerBands(candles []internal.Candle, period int, multiplier float64) (upper []float64, middle []float64, lower []float64) {
-	middle = calculateBBMiddle(candles, period)
-	if middle == nil {
-		return nil, nil, nil
-	}
-
-	stdDev := calculateBBStdDev(candles, middle, period)
-	if stdDev == nil {
-		return nil, middle, nil
-	}
-
-	length := len(candles)
-	upper = make([]float64, length)
-	lower = make([]float64, length)
-
-	for i := 0; i < period-1; i++ {
-		upper[i] = 0
-		lower[i] = 0
-	}
-
-	for i := period - 1; i < length; i++ {
-		midValue := middle[i]
-		dev := stdDev[i] * multiplier
-
-		upper[i] = midValue + dev
-		lower[i] = midValue - dev
-	}
-
-	return upper, middle, lower
+func (s *BollingerBandsStrategy) Category() string {
+	return internal.CategoryVolatility
 }
 
 func (s *BollingerBandsStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
@@ -169,7 +86,7 @@ func (s *BollingerBandsStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 		return make([]internal.SignalType, len(candles))
 	}
 
-	upper, _, lower := calculateBollingerBands(candles, bbConfig.Period, bbConfig.Multiplier)
+	upper, _, lower := internal.CalculateBollingerBands(candles, bbConfig.Period, bbConfig.Multiplier)
 	if upper == nil || lower == nil {
 		return make([]internal.SignalType, len(candles))
 	}
@@ -212,9 +129,9 @@ func (s *BollingerBandsStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 	return signals
 }
 
-func (s *BollingerBandsStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *BollingerBandsStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*BollingerBandsConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search по параметрам
 	for period := 10; period <= 50; period += 5 {
@@ -223,14 +140,17 @@ func (s *BollingerBandsStrategy) OptimizeWithConfig(candles []internal.Candle) i
 				Period:     period,
 				Multiplier: multiplier,
 			}
+			if ctx.Err() != nil {
+				return bestConfig
+			}
 			if config.Validate() != nil {
 				continue
 			}
 
 			signals := s.GenerateSignalsWithConfig(candles, config)
 			result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
+			if score := internal.ObjectiveScore(result); score >= bestProfit {
+				bestProfit = score
 				bestConfig = config
 			}
 		}
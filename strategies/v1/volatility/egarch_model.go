@@ -0,0 +1,181 @@
+// strategies/volatility/egarch_model.go
+//
+// EGARCH(1,1) — асимметричная модель волатильности (Nelson, 1991):
+// ln σ²_t = ω + β*ln σ²_{t-1} + α*(|z_{t-1}| − E|z|) + γ*z_{t-1}, где z_t = ε_t / σ_t, E|z| = √(2/π)
+//
+// При γ < 0 падение цены повышает волатильность сильнее, чем такой же рост (эффект рычага).
+// Логарифм дисперсии не требует ограничений на знаки параметров, кроме |β| < 1 для стационарности.
+
+package volatility
+
+import (
+	"errors"
+	"math"
+)
+
+const (
+	// GARCHModel — симметричная GARCH(1,1) (по умолчанию)
+	GARCHModel = "garch"
+	// EGARCHModel — асимметричная EGARCH(1,1) с эффектом рычага
+	EGARCHModel = "egarch"
+
+	// expectedAbsNormal — E|z| для z ~ N(0,1)
+	expectedAbsNormal = 0.7978845608028654 // √(2/π)
+	// egarchCalibrationRounds — число проходов покоординатного поиска максимума правдоподобия
+	egarchCalibrationRounds = 6
+)
+
+// volatilityModel — модель условной дисперсии, откалиброванная на окне цен
+type volatilityModel interface {
+	calibrate(prices []float64) error
+	forecast(steps int) []float64
+	variances() []float64
+}
+
+// newVolatilityModel — модель по значению ModelType конфигурации
+func newVolatilityModel(modelType string) volatilityModel {
+	if modelType == EGARCHModel {
+		return NewEGARCHVolModel()
+	}
+	return NewGARCHVolModel()
+}
+
+// variances — условная дисперсия GARCH на окне калибровки
+func (model *GARCHVolModel) variances() []float64 {
+	return model.Sigma2
+}
+
+// EGARCHVolModel представляет модель EGARCH для волатильности
+type EGARCHVolModel struct {
+	Omega   float64   // константа (ω)
+	Alpha   float64   // реакция на величину шока (α)
+	Gamma   float64   // реакция на знак шока (γ < 0 — эффект рычага)
+	Beta    float64   // устойчивость логарифма дисперсии (β)
+	Mu      float64   // средняя доходность (μ)
+	Sigma2  []float64 // условная дисперсия
+	Returns []float64 // доходности
+}
+
+// NewEGARCHVolModel создает новую модель EGARCH
+func NewEGARCHVolModel() *EGARCHVolModel {
+	return &EGARCHVolModel{
+		Sigma2:  make([]float64, 0),
+		Returns: make([]float64, 0),
+	}
+}
+
+// calibrate оценивает параметры EGARCH максимизацией гауссова правдоподобия.
+// ω задается через логарифм выборочной дисперсии (variance targeting), α, γ и β подбираются
+// покоординатным поиском с уменьшающимся шагом
+func (model *EGARCHVolModel) calibrate(prices []float64) error {
+	if len(prices) < 10 {
+		return errors.New("insufficient data for EGARCH calibration")
+	}
+
+	model.Returns = make([]float64, len(prices)-1)
+	for i := 1; i < len(prices); i++ {
+		model.Returns[i-1] = math.Log(prices[i] / prices[i-1])
+	}
+	model.Mu = calculateMean(model.Returns)
+
+	centered := make([]float64, len(model.Returns))
+	for i, ret := range model.Returns {
+		centered[i] = ret - model.Mu
+	}
+	unconditionalVar := calculateVariance(centered, 0)
+	if unconditionalVar <= 0 {
+		return errors.New("zero variance in EGARCH calibration window")
+	}
+	logVar := math.Log(unconditionalVar)
+
+	// params: α, γ, β; ω = (1 − β)·ln σ² держит среднюю лог-дисперсию у выборочной
+	params := [3]float64{0.1, 0, 0.9}
+	lower := [3]float64{0, -0.5, 0}
+	upper := [3]float64{0.5, 0.5, 0.99}
+	steps := [3]float64{0.05, 0.05, 0.05}
+
+	bestLL := egarchLogLikelihood(centered, params, logVar)
+	for round := 0; round < egarchCalibrationRounds; round++ {
+		for k := range params {
+			for _, direction := range []float64{1, -1} {
+				for {
+					candidate := params
+					candidate[k] = math.Max(lower[k], math.Min(upper[k], params[k]+direction*steps[k]))
+					if candidate[k] == params[k] {
+						break
+					}
+					ll := egarchLogLikelihood(centered, candidate, logVar)
+					if ll <= bestLL {
+						break
+					}
+					params, bestLL = candidate, ll
+				}
+			}
+			steps[k] /= 2
+		}
+	}
+
+	model.Alpha, model.Gamma, model.Beta = params[0], params[1], params[2]
+	model.Omega = (1 - model.Beta) * logVar
+	model.Sigma2 = egarchVariances(centered, model.Omega, model.Alpha, model.Gamma, model.Beta, unconditionalVar)
+	return nil
+}
+
+// egarchVariances — рекурсия условной дисперсии EGARCH по центрированным доходностям
+func egarchVariances(centered []float64, omega, alpha, gamma, beta, initialVar float64) []float64 {
+	sigma2 := make([]float64, len(centered))
+	sigma2[0] = initialVar
+	for i := 1; i < len(centered); i++ {
+		sigma2[i] = egarchStep(sigma2[i-1], centered[i-1], omega, alpha, gamma, beta)
+	}
+	return sigma2
+}
+
+// egarchStep — дисперсия следующего бара по текущей дисперсии и шоку
+func egarchStep(sigma2, shock, omega, alpha, gamma, beta float64) float64 {
+	z := shock / math.Sqrt(sigma2)
+	logSigma2 := omega + beta*math.Log(sigma2) + alpha*(math.Abs(z)-expectedAbsNormal) + gamma*z
+	// Ограничение защищает от переполнения exp на вырожденных параметрах
+	return math.Exp(math.Max(-50, math.Min(50, logSigma2)))
+}
+
+// egarchLogLikelihood — гауссово лог-правдоподобие (без констант) параметров α, γ, β
+func egarchLogLikelihood(centered []float64, params [3]float64, logVar float64) float64 {
+	alpha, gamma, beta := params[0], params[1], params[2]
+	omega := (1 - beta) * logVar
+	sigma2 := math.Exp(logVar)
+
+	ll := 0.0
+	for i := 1; i < len(centered); i++ {
+		sigma2 = egarchStep(sigma2, centered[i-1], omega, alpha, gamma, beta)
+		ll -= math.Log(sigma2) + centered[i]*centered[i]/sigma2
+	}
+	return ll / 2
+}
+
+// forecast прогнозирует дисперсию: первый шаг учитывает последний шок, дальше ожидаемые
+// шоковые слагаемые равны нулю и логарифм дисперсии сходится к ω / (1 − β)
+func (model *EGARCHVolModel) forecast(steps int) []float64 {
+	if len(model.Sigma2) == 0 || len(model.Returns) == 0 {
+		return nil
+	}
+
+	forecasts := make([]float64, steps)
+	currentSigma2 := model.Sigma2[len(model.Sigma2)-1]
+	currentShock := model.Returns[len(model.Returns)-1] - model.Mu
+
+	for i := 0; i < steps; i++ {
+		if i == 0 {
+			forecasts[i] = egarchStep(currentSigma2, currentShock, model.Omega, model.Alpha, model.Gamma, model.Beta)
+		} else {
+			forecasts[i] = math.Exp(model.Omega + model.Beta*math.Log(forecasts[i-1]))
+		}
+	}
+
+	return forecasts
+}
+
+// variances — условная дисперсия EGARCH на окне калибровки
+func (model *EGARCHVolModel) variances() []float64 {
+	return model.Sigma2
+}
@@ -0,0 +1,95 @@
+package volatility
+
+import (
+	"math"
+	"math/rand"
+	"testing"
+)
+
+// simulateEGARCH — цены по процессу EGARCH(1,1) с заданными параметрами
+func simulateEGARCH(n int, omega, alpha, gamma, beta float64, seed int64) []float64 {
+	rng := rand.New(rand.NewSource(seed))
+	prices := make([]float64, n+1)
+	prices[0] = 100
+	sigma2 := math.Exp(omega / (1 - beta))
+	for i := 1; i <= n; i++ {
+		z := rng.NormFloat64()
+		shock := math.Sqrt(sigma2) * z
+		prices[i] = prices[i-1] * math.Exp(shock)
+		sigma2 = egarchStep(sigma2, shock, omega, alpha, gamma, beta)
+	}
+	return prices
+}
+
+func TestEGARCH_RecoversLeverageEffect(t *testing.T) {
+	// Дневная волатильность ~1%, выраженный эффект рычага γ = -0.15
+	beta := 0.9
+	omega := (1 - beta) * math.Log(1e-4)
+	prices := simulateEGARCH(3000, omega, 0.15, -0.15, beta, 7)
+
+	model := NewEGARCHVolModel()
+	if err := model.calibrate(prices); err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+	if model.Gamma >= -0.05 {
+		t.Errorf("expected negative gamma, got %.4f", model.Gamma)
+	}
+	if model.Beta < 0.7 || model.Beta >= 1 {
+		t.Errorf("expected persistent beta, got %.4f", model.Beta)
+	}
+
+	// Одинаковые по величине шоки: падение должно дать больший прогноз дисперсии
+	sigma2 := model.Sigma2[len(model.Sigma2)-1]
+	shock := 2 * math.Sqrt(sigma2)
+	down := egarchStep(sigma2, -shock, model.Omega, model.Alpha, model.Gamma, model.Beta)
+	up := egarchStep(sigma2, shock, model.Omega, model.Alpha, model.Gamma, model.Beta)
+	if down <= up {
+		t.Errorf("expected negative shock to raise variance more: down=%.3g up=%.3g", down, up)
+	}
+}
+
+func TestEGARCH_SymmetricDataHasNoLeverage(t *testing.T) {
+	beta := 0.9
+	omega := (1 - beta) * math.Log(1e-4)
+	prices := simulateEGARCH(3000, omega, 0.15, 0, beta, 11)
+
+	model := NewEGARCHVolModel()
+	if err := model.calibrate(prices); err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+	if math.Abs(model.Gamma) > 0.08 {
+		t.Errorf("expected gamma near zero on symmetric data, got %.4f", model.Gamma)
+	}
+}
+
+func TestEGARCH_ForecastRevertsToLongRunVariance(t *testing.T) {
+	prices := simulateEGARCH(1000, 0.1*math.Log(1e-4), 0.15, -0.1, 0.9, 3)
+	model := NewEGARCHVolModel()
+	if err := model.calibrate(prices); err != nil {
+		t.Fatalf("calibrate: %v", err)
+	}
+	forecasts := model.forecast(200)
+	longRun := math.Exp(model.Omega / (1 - model.Beta))
+	if got := forecasts[len(forecasts)-1]; math.Abs(got-longRun)/longRun > 0.01 {
+		t.Errorf("forecast should revert to %.3g, got %.3g", longRun, got)
+	}
+}
+
+func TestGARCHVolatilityConfig_ValidatesModelType(t *testing.T) {
+	config := &GARCHVolatilityConfig{
+		WindowSize:          50,
+		ForecastHorizon:     5,
+		VolatilityThreshold: 0.02,
+		TrendThreshold:      0.01,
+		ModelType:           "tgarch",
+	}
+	if err := config.Validate(); err == nil {
+		t.Error("expected error for unknown model type")
+	}
+	for _, modelType := range []string{"", GARCHModel, EGARCHModel} {
+		config.ModelType = modelType
+		if err := config.Validate(); err != nil {
+			t.Errorf("model %q: unexpected error %v", modelType, err)
+		}
+	}
+}
@@ -40,9 +40,11 @@ package volatility
 
 import (
 	"bt/internal"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"os"
 )
 
@@ -72,6 +74,10 @@ func (s *EnvelopesStrategy) Name() string {
 	return "envelopes"
 }
 
+func (s *EnvelopesStrategy) Category() string {
+	return internal.CategoryVolatility
+}
+
 // calculateEnvelopes вычисляет верхнюю, среднюю и нижнюю полосы Envelopes
 func calculateEnvelopes(candles []internal.Candle, period int, percentage float64) (upper []float64, middle []float64, lower []float64) {
 	middle = internal.CalculateSMACommon(candles, period)
@@ -139,9 +145,9 @@ func (s *EnvelopesStrategy) GenerateSignalsWithConfig(candles []internal.Candle,
 	return signals
 }
 
-func (s *EnvelopesStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *EnvelopesStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*EnvelopesConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	var results []internal.GridSearchResult
 	// Grid search по параметрам
@@ -151,6 +157,9 @@ func (s *EnvelopesStrategy) OptimizeWithConfig(candles []internal.Candle) intern
 				Period:     period,
 				Percentage: percentage,
 			}
+			if ctx.Err() != nil {
+				return bestConfig
+			}
 			if config.Validate() != nil {
 				continue
 			}
@@ -165,8 +174,8 @@ func (s *EnvelopesStrategy) OptimizeWithConfig(candles []internal.Candle) intern
 				Profit: result.TotalProfit,
 			})
 
-			if result.TotalProfit >= bestProfit {
-				bestProfit = result.TotalProfit
+			if score := internal.ObjectiveScore(result); score >= bestProfit {
+				bestProfit = score
 				bestConfig = config
 			}
 		}
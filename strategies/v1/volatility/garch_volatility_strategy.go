@@ -7,6 +7,8 @@
 // ε_t = σ_t * z_t, где z_t ~ N(0,1)
 // σ²_t = ω + α*ε²_{t-1} + β*σ²_{t-1}
 //
+// С ModelType = "egarch" используется асимметричная EGARCH(1,1) (см. egarch_model.go)
+//
 // Стратегия использует прогнозы волатильности для:
 // 1. Определения периодов высокой/низкой волатильности
 // 2. Адаптации размера позиций
@@ -16,9 +18,9 @@ package volatility
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -28,6 +30,7 @@ type GARCHVolatilityConfig struct {
 	VolatilityThreshold float64 `json:"volatility_threshold"`  // порог волатильности для сигналов
 	TrendThreshold      float64 `json:"trend_threshold"`       // порог тренда
 	UseVolatilityRegime bool    `json:"use_volatility_regime"` // использовать режимы волатильности
+	ModelType           string  `json:"model_type,omitempty"`  // модель волатильности: garch (по умолчанию) или egarch
 }
 
 func (c *GARCHVolatilityConfig) Validate() error {
@@ -43,12 +46,24 @@ func (c *GARCHVolatilityConfig) Validate() error {
 	if c.TrendThreshold <= 0 {
 		return errors.New("trend threshold must be positive")
 	}
+	if c.ModelType != "" && c.ModelType != GARCHModel && c.ModelType != EGARCHModel {
+		return fmt.Errorf("unknown volatility model %q (expected garch or egarch)", c.ModelType)
+	}
 	return nil
 }
 
+// MinCandles — окно калибровки плюс запас на прогрев модели
+func (c *GARCHVolatilityConfig) MinCandles() int {
+	return c.WindowSize + 50
+}
+
 func (c *GARCHVolatilityConfig) DefaultConfigString() string {
-	return fmt.Sprintf("GARCH_Vol(window=%d, horizon=%d, vol_thresh=%.3f)",
-		c.WindowSize, c.ForecastHorizon, c.VolatilityThreshold)
+	model := c.ModelType
+	if model == "" {
+		model = GARCHModel
+	}
+	return fmt.Sprintf("GARCH_Vol(model=%s, window=%d, horizon=%d, vol_thresh=%.3f)",
+		model, c.WindowSize, c.ForecastHorizon, c.VolatilityThreshold)
 }
 
 // GARCHVolModel представляет модель GARCH для волатильности
@@ -178,22 +193,16 @@ func (model *GARCHVolModel) forecast(steps int) []float64 {
 	return forecasts
 }
 
-// getVolatilityRegime определяет текущий режим волатильности
-func (model *GARCHVolModel) getVolatilityRegime(currentVol, avgVol float64) string {
-	if currentVol > avgVol*1.5 {
-		return "HIGH"
-	} else if currentVol < avgVol*0.7 {
-		return "LOW"
-	}
-	return "NORMAL"
-}
-
 type GARCHVolatilityStrategy struct{ internal.BaseConfig }
 
 func (s *GARCHVolatilityStrategy) Name() string {
 	return "garch_volatility_strategy"
 }
 
+func (s *GARCHVolatilityStrategy) Category() string {
+	return internal.CategoryVolatility
+}
+
 // calculateTrendStrength вычисляет силу тренда
 func (s *GARCHVolatilityStrategy) calculateTrendStrength(prices []float64, window int) float64 {
 	if len(prices) < window {
@@ -201,22 +210,8 @@ func (s *GARCHVolatilityStrategy) calculateTrendStrength(prices []float64, windo
 	}
 
 	recentPrices := prices[len(prices)-window:]
-	n := float64(len(recentPrices))
-
-	// Линейная регрессия для определения тренда
-	sumX := n * (n - 1) / 2
-	sumY := 0.0
-	sumXY := 0.0
-	sumXX := n * (n - 1) * (2*n - 1) / 6
-
-	for i, price := range recentPrices {
-		x := float64(i)
-		sumY += price
-		sumXY += x * price
-	}
-
-	slope := (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-	avgPrice := sumY / n
+	slope, intercept, _ := internal.LinearRegression(recentPrices)
+	avgPrice := intercept + slope*float64(window-1)/2 // прямая МНК проходит через среднюю цену
 
 	return slope / avgPrice // нормализованный наклон
 }
@@ -228,13 +223,13 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 	}
 
 	if err := garchConfig.Validate(); err != nil {
-		log.Printf("❌ Ошибка конфигурации GARCH Volatility: %v", err)
+		internal.Log.Warnf("❌ Ошибка конфигурации GARCH Volatility: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < garchConfig.WindowSize+50 {
-		log.Printf("⚠️ Недостаточно данных для GARCH Volatility: получено %d свечей, требуется минимум %d",
-			len(candles), garchConfig.WindowSize+50)
+	if len(candles) < garchConfig.MinCandles() {
+		internal.Log.Infof("⚠️ Недостаточно данных для GARCH Volatility: получено %d свечей, требуется минимум %d",
+			len(candles), garchConfig.MinCandles())
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -244,11 +239,12 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 		prices[i] = candle.Close.ToFloat64()
 	}
 
-	log.Printf("🚀 ЗАПУСК GARCH VOLATILITY СТРАТЕГИИ:")
-	log.Printf("   Окно калибровки: %d свечей", garchConfig.WindowSize)
-	log.Printf("   Горизонт прогноза: %d шагов", garchConfig.ForecastHorizon)
-	log.Printf("   Порог волатильности: %.3f", garchConfig.VolatilityThreshold)
-	log.Printf("   Режимы волатильности: %v", garchConfig.UseVolatilityRegime)
+	internal.Log.Infof("🚀 ЗАПУСК GARCH VOLATILITY СТРАТЕГИИ:")
+	internal.Log.Infof("   Окно калибровки: %d свечей", garchConfig.WindowSize)
+	internal.Log.Infof("   Горизонт прогноза: %d шагов", garchConfig.ForecastHorizon)
+	internal.Log.Infof("   Порог волатильности: %.3f", garchConfig.VolatilityThreshold)
+	internal.Log.Infof("   Режимы волатильности: %v", garchConfig.UseVolatilityRegime)
+	internal.Log.Infof("   Модель: %s", garchConfig.DefaultConfigString())
 
 	signals := make([]internal.SignalType, len(candles))
 
@@ -265,8 +261,8 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 		windowStart := i - garchConfig.WindowSize
 		windowData := prices[windowStart:i]
 
-		// Калибруем GARCH модель
-		model := NewGARCHVolModel()
+		// Калибруем модель волатильности (GARCH или EGARCH)
+		model := newVolatilityModel(garchConfig.ModelType)
 		if err := model.calibrate(windowData); err != nil {
 			signals[i] = internal.HOLD
 			continue
@@ -280,12 +276,13 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 		}
 
 		// Текущая и прогнозируемая волатильность
-		currentVol := math.Sqrt(model.Sigma2[len(model.Sigma2)-1])
+		sigma2 := model.variances()
+		currentVol := math.Sqrt(sigma2[len(sigma2)-1])
 		forecastVol := math.Sqrt(volForecasts[0])
-		avgVol := math.Sqrt(calculateMean(model.Sigma2))
+		avgVol := math.Sqrt(calculateMean(sigma2))
 
 		// Определяем режим волатильности
-		volRegime := model.getVolatilityRegime(currentVol, avgVol)
+		volRegime := internal.VolatilityRegimeOf(currentVol, avgVol)
 
 		// Вычисляем силу тренда
 		trendStrength := s.calculateTrendStrength(prices, 20)
@@ -295,7 +292,7 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 
 		// Отладочная информация только в начале
 		if i == startIndex {
-			log.Printf("🔍 Начало анализа: порог_тренда=%.4f, порог_волат=%.4f",
+			internal.Log.Debugf("🔍 Начало анализа: порог_тренда=%.4f, порог_волат=%.4f",
 				garchConfig.TrendThreshold, garchConfig.VolatilityThreshold)
 		}
 
@@ -305,7 +302,7 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 		if garchConfig.UseVolatilityRegime {
 			// Стратегия на основе режимов волатильности
 			switch volRegime {
-			case "LOW":
+			case internal.RegimeLow:
 				// В периоды низкой волатильности следуем тренду (более мягкие условия)
 				if !inPosition && trendStrength > garchConfig.TrendThreshold &&
 					i-lastTradeIndex >= minHoldBars {
@@ -315,7 +312,7 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 					// log.Printf("📈 BUY (низкая волатильность, тренд=%.4f) на свече %d", trendStrength, i)
 				}
 
-			case "HIGH":
+			case internal.RegimeHigh:
 				// В периоды высокой волатильности - осторожность
 				if inPosition && i-lastTradeIndex >= minHoldBars {
 					signal = internal.SELL
@@ -324,7 +321,7 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 					// log.Printf("📉 SELL (высокая волатильность) на свече %d", i)
 				}
 
-			case "NORMAL":
+			case internal.RegimeNormal:
 				// В нормальные периоды используем прогноз волатильности (упрощенные условия)
 				if !inPosition && volChange < -garchConfig.VolatilityThreshold &&
 					i-lastTradeIndex >= minHoldBars {
@@ -360,13 +357,13 @@ func (s *GARCHVolatilityStrategy) GenerateSignalsWithConfig(candles []internal.C
 		signals[i] = signal
 	}
 
-	log.Printf("✅ GARCH Volatility анализ завершен")
+	internal.Log.Infof("✅ GARCH Volatility анализ завершен")
 	return signals
 }
 
-func (s *GARCHVolatilityStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *GARCHVolatilityStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*GARCHVolatilityConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем параметры
 	windowSizes := []int{50, 100, 150}
@@ -374,30 +371,26 @@ func (s *GARCHVolatilityStrategy) OptimizeWithConfig(candles []internal.Candle)
 	volThresholds := []float64{0.01, 0.02, 0.03}
 	trendThresholds := []float64{0.005, 0.01, 0.02}
 	regimeModes := []bool{true, false}
+	modelTypes := []string{GARCHModel, EGARCHModel}
 
+	var configs []*GARCHVolatilityConfig
 	for _, windowSize := range windowSizes {
 		for _, horizon := range horizons {
 			for _, volThresh := range volThresholds {
 				for _, trendThresh := range trendThresholds {
 					for _, useRegime := range regimeModes {
-						config := &GARCHVolatilityConfig{
-							WindowSize:          windowSize,
-							ForecastHorizon:     horizon,
-							VolatilityThreshold: volThresh,
-							TrendThreshold:      trendThresh,
-							UseVolatilityRegime: useRegime,
-						}
-
-						if config.Validate() != nil {
-							continue
-						}
-
-						signals := s.GenerateSignalsWithConfig(candles, config)
-						result := internal.Backtest(candles, signals, s.GetSlippage())
-
-						if result.TotalProfit >= bestProfit {
-							bestProfit = result.TotalProfit
-							bestConfig = config
+						for _, modelType := range modelTypes {
+							config := &GARCHVolatilityConfig{
+								WindowSize:          windowSize,
+								ForecastHorizon:     horizon,
+								VolatilityThreshold: volThresh,
+								TrendThreshold:      trendThresh,
+								UseVolatilityRegime: useRegime,
+								ModelType:           modelType,
+							}
+							if config.Validate() == nil {
+								configs = append(configs, config)
+							}
 						}
 					}
 				}
@@ -405,8 +398,23 @@ func (s *GARCHVolatilityStrategy) OptimizeWithConfig(candles []internal.Candle)
 		}
 	}
 
-	fmt.Printf("Лучшие параметры GARCH Volatility: окно=%d, горизонт=%d, vol_thresh=%.3f, trend_thresh=%.3f, режимы=%v, профит=%.4f\n",
-		bestConfig.WindowSize, bestConfig.ForecastHorizon, bestConfig.VolatilityThreshold,
+	// Окна длиннее истории дали бы одни HOLD — такие конфигурации не оцениваются
+	for _, config := range internal.FilterByDataRequirement(configs, len(candles)) {
+		if ctx.Err() != nil {
+			return bestConfig
+		}
+
+		signals := s.GenerateSignalsWithConfig(candles, config)
+		result := internal.Backtest(candles, signals, s.GetSlippage())
+
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
+			bestConfig = config
+		}
+	}
+
+	fmt.Printf("Лучшие параметры GARCH Volatility: модель=%s, окно=%d, горизонт=%d, vol_thresh=%.3f, trend_thresh=%.3f, режимы=%v, профит=%.4f\n",
+		bestConfig.ModelType, bestConfig.WindowSize, bestConfig.ForecastHorizon, bestConfig.VolatilityThreshold,
 		bestConfig.TrendThreshold, bestConfig.UseVolatilityRegime, bestProfit)
 
 	return bestConfig
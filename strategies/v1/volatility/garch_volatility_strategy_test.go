@@ -0,0 +1,42 @@
+package volatility
+
+import (
+	"bytes"
+	"context"
+	"log"
+	"os"
+	"strings"
+	"testing"
+
+	"bt/internal"
+)
+
+func TestGARCHOptimize_SkipsWindowsLongerThanData(t *testing.T) {
+	// 120 свечей: хватает только окну 50 (нужно 100), окна 100 и 150 дали бы одни HOLD.
+	// На падающем рынке длинные позиции не зарабатывают, и пустой прогон с нулевой прибылью иначе выиграл бы
+	candles := make([]internal.Candle, 120)
+	for i := range candles {
+		wiggle := float64(i%3) * 0.4
+		candles[i] = internal.Candle{Close: internal.Price(200 - float64(i)*0.5 + wiggle)}
+	}
+
+	var logs bytes.Buffer
+	log.SetOutput(&logs)
+	internal.SetLogLevel(internal.LogInfo)
+	defer func() {
+		log.SetOutput(os.Stderr)
+		internal.SetLogLevel(internal.LogWarn)
+	}()
+
+	strategy := &GARCHVolatilityStrategy{BaseConfig: internal.BaseConfig{Config: &GARCHVolatilityConfig{
+		WindowSize: 50, ForecastHorizon: 5, VolatilityThreshold: 0.02, TrendThreshold: 0.01, ModelType: GARCHModel,
+	}}}
+	best := strategy.OptimizeWithConfig(context.Background(), candles).(*GARCHVolatilityConfig)
+
+	if best.WindowSize != 50 {
+		t.Errorf("Expected the only window that fits the data (50), got %d", best.WindowSize)
+	}
+	if !strings.Contains(logs.String(), "Пропущено 216 из 324 конфигураций") {
+		t.Errorf("Expected the skipped config count to be reported, got logs:\n%s", logs.String())
+	}
+}
@@ -40,9 +40,9 @@ package volatility
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -82,6 +82,10 @@ func (s *MomentumBreakoutStrategy) Name() string {
 	return "momentum_breakout"
 }
 
+func (s *MomentumBreakoutStrategy) Category() string {
+	return internal.CategoryVolatility
+}
+
 // calculateMomentum рассчитывает моментум как скорость изменения цены
 func calculateMomentum(prices []float64, period int) []float64 {
 	if len(prices) < period+1 {
@@ -145,7 +149,7 @@ func (s *MomentumBreakoutStrategy) GenerateSignalsWithConfig(candles []internal.
 	}
 
 	if len(candles) < 50 {
-		log.Printf("⚠️ Недостаточно данных для momentum breakout: получено %d свечей, требуется минимум 50", len(candles))
+		internal.Log.Infof("⚠️ Недостаточно данных для momentum breakout: получено %d свечей, требуется минимум 50", len(candles))
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -164,7 +168,7 @@ func (s *MomentumBreakoutStrategy) GenerateSignalsWithConfig(candles []internal.
 	volatility := internal.CalculateRollingStdDevOfReturns(prices, 20) // фиксированный период для волатильности
 
 	if momentum == nil || support == nil || resistance == nil {
-		log.Println("❌ Ошибка расчета индикаторов для momentum breakout")
+		internal.Log.Warnf("❌ Ошибка расчета индикаторов для momentum breakout")
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -248,9 +252,9 @@ func (s *MomentumBreakoutStrategy) GenerateSignalsWithConfig(candles []internal.
 	return signals
 }
 
-func (s *MomentumBreakoutStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *MomentumBreakoutStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*MomentumBreakoutConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Grid search по параметрам
 	for momentumPeriod := 5; momentumPeriod <= 20; momentumPeriod += 5 {
@@ -263,6 +267,9 @@ func (s *MomentumBreakoutStrategy) OptimizeWithConfig(candles []internal.Candle)
 						VolumeMultiplier:  volumeMultiplier,
 						VolatilityFilter:  volatilityFilter,
 					}
+					if ctx.Err() != nil {
+						return bestConfig
+					}
 					if config.Validate() != nil {
 						continue
 					}
@@ -270,8 +277,8 @@ func (s *MomentumBreakoutStrategy) OptimizeWithConfig(candles []internal.Candle)
 					signals := s.GenerateSignalsWithConfig(candles, config)
 					result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
 
-					if result.TotalProfit >= bestProfit {
-						bestProfit = result.TotalProfit
+					if score := internal.ObjectiveScore(result); score >= bestProfit {
+						bestProfit = score
 						bestConfig = config
 					}
 				}
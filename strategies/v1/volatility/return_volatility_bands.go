@@ -0,0 +1,185 @@
+// strategies/return_volatility_bands.go
+
+// Return Volatility Bands Mean-Reversion Strategy
+//
+// Описание стратегии:
+// Процентные полосы волатильности вокруг EMA. В отличие от Bollinger Bands (SMA ± σ цены),
+// ширина полос задаётся стандартным отклонением доходностей и потому масштабируется вместе с ценой.
+// Стратегия торгует возврат к среднему и входит только в нетрендовом режиме (фильтр по ADX).
+//
+// Как работает:
+// - Рассчитывается EMA цен закрытия за заданный период
+// - Вычисляется скользящее стандартное отклонение доходностей σ за тот же период
+// - Верхняя полоса = EMA × (1 + множитель × σ)
+// - Нижняя полоса = EMA × (1 − множитель × σ)
+// - Покупка: цена касается нижней полосы, при этом ADX ниже порога (нет выраженного тренда)
+// - Продажа: цена возвращается к EMA (центру полос)
+//
+// Параметры:
+// - Period: период EMA и волатильности доходностей (обычно 20)
+// - Multiplier: множитель σ для ширины полос (обычно 2.0)
+// - ADXPeriod: период ADX (обычно 14)
+// - MaxADX: максимальный ADX, при котором разрешён вход (обычно 20-25)
+//
+// Сильные стороны:
+// - Ширина полос не зависит от уровня цены
+// - Фильтр ADX отсекает входы против сильного тренда
+// - Чёткая цель выхода — возврат к среднему
+//
+// Слабые стороны:
+// - В начале нового тренда ADX запаздывает и вход может оказаться ложным
+// - Нет защитного стоп-лосса — позиция держится до возврата к EMA
+// - Требует достаточной истории для прогрева ADX
+//
+// Лучшие условия для применения:
+// - Боковые рынки с регулярными колебаниями вокруг среднего
+// - Активы с устойчивой волатильностью доходностей
+
+package volatility
+
+import (
+	"bt/internal"
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+)
+
+type ReturnVolatilityBandsConfig struct {
+	Period     int     `json:"period"`
+	Multiplier float64 `json:"multiplier"`
+	ADXPeriod  int     `json:"adx_period"`
+	MaxADX     float64 `json:"max_adx"`
+}
+
+func (c *ReturnVolatilityBandsConfig) Validate() error {
+	if c.Period <= 1 {
+		return errors.New("period must be greater than 1")
+	}
+	if c.Multiplier <= 0 {
+		return errors.New("multiplier must be positive")
+	}
+	if c.ADXPeriod <= 0 {
+		return errors.New("adx period must be positive")
+	}
+	if c.MaxADX <= 0 || c.MaxADX > 100 {
+		return errors.New("max adx must be between 0 and 100")
+	}
+	return nil
+}
+
+// MinCandles — прогрев полос и ADX плюс хотя бы один бар для торговли
+func (c *ReturnVolatilityBandsConfig) MinCandles() int {
+	return max(c.Period, 2*c.ADXPeriod-1) + 1
+}
+
+func (c *ReturnVolatilityBandsConfig) DefaultConfigString() string {
+	return fmt.Sprintf("ReturnVolBands(period=%d, mult=%.2f, adx_period=%d, max_adx=%.1f)",
+		c.Period, c.Multiplier, c.ADXPeriod, c.MaxADX)
+}
+
+type ReturnVolatilityBandsStrategy struct {
+	internal.BaseConfig
+	internal.BaseStrategy
+}
+
+func (s *ReturnVolatilityBandsStrategy) Name() string {
+	return "return_volatility_bands"
+}
+
+func (s *ReturnVolatilityBandsStrategy) Category() string {
+	return internal.CategoryVolatility
+}
+
+func (s *ReturnVolatilityBandsStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
+	rvbConfig, ok := config.(*ReturnVolatilityBandsConfig)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := rvbConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	prices := make([]float64, len(candles))
+	for i, candle := range candles {
+		prices[i] = candle.Close.ToFloat64()
+	}
+
+	_, middle, lower := internal.CalculateReturnVolatilityBands(prices, rvbConfig.Period, rvbConfig.Multiplier)
+	adx := internal.CalculateADX(candles, rvbConfig.ADXPeriod)
+	if middle == nil || lower == nil || adx == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	start := rvbConfig.Period
+	if adxStart := 2*rvbConfig.ADXPeriod - 1; adxStart > start {
+		start = adxStart
+	}
+
+	for i := start; i < len(candles); i++ {
+		if lower[i] == 0 {
+			continue
+		}
+
+		// BUY: касание нижней полосы в нетрендовом режиме
+		if !inPosition && candles[i].Low.ToFloat64() <= lower[i] && adx[i] < rvbConfig.MaxADX {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		// SELL: возврат к среднему
+		if inPosition && prices[i] >= middle[i] {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+func (s *ReturnVolatilityBandsStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
+	configs := lo.CrossJoinBy4(
+		lo.RangeWithSteps[int](10, 45, 5),
+		lo.RangeWithSteps[float64](1.0, 3.25, 0.25),
+		[]int{7, 14, 21},
+		lo.RangeWithSteps[float64](15, 35, 5),
+		func(period int, mult float64, adxPeriod int, maxADX float64) internal.StrategyConfig {
+			return &ReturnVolatilityBandsConfig{
+				Period:     period,
+				Multiplier: mult,
+				ADXPeriod:  adxPeriod,
+				MaxADX:     maxADX,
+			}
+		})
+
+	max := s.ProcessConfigs(ctx, s, candles, configs)
+
+	bestConfig := max.A.(*ReturnVolatilityBandsConfig)
+	bestProfit := max.B
+	fmt.Printf("Лучшие параметры Return Volatility Bands: period=%d, multiplier=%.2f, adx_period=%d, max_adx=%.1f, profit=%.4f\n",
+		bestConfig.Period, bestConfig.Multiplier, bestConfig.ADXPeriod, bestConfig.MaxADX, bestProfit)
+
+	return bestConfig
+}
+
+func init() {
+	internal.RegisterStrategy("return_volatility_bands", &ReturnVolatilityBandsStrategy{
+		BaseConfig: internal.BaseConfig{
+			Config: &ReturnVolatilityBandsConfig{
+				Period:     20,
+				Multiplier: 2.0,
+				ADXPeriod:  14,
+				MaxADX:     25,
+			},
+		},
+	})
+}
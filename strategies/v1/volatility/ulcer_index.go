@@ -7,14 +7,16 @@
 // как цена уходит все дальше от недавнего максимума, и падает по мере достижения новых максимумов.
 //
 // Как рассчитывается:
-// 1. Определяем максимальную цену за период (running maximum)
+// 1. Для каждой свечи определяем максимальную цену закрытия за предыдущие period свечей
 // 2. Для каждой свечи рассчитываем drawdown: (текущая цена - максимум) / максимум * 100%
 // 3. Возводим drawdown в квадрат для каждой свечи
 // 4. Берем среднее значение квадратов drawdown'ов
 // 5. Берем квадратный корень от среднего
 //
 // Формула:
-// UI = √(Σ((Close - MaxHigh) / MaxHigh)² / n)
+// UI = √(Σ((Close - MaxClose) / MaxClose)² / n)
+//
+// Расчет общий: internal.CalculateUlcerIndex
 //
 // Параметры:
 // - Period: период расчета (обычно 14 дней)
@@ -44,6 +46,7 @@ package volatility
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
 	"math"
@@ -79,48 +82,8 @@ func (s *UlcerIndexStrategy) Name() string {
 	return "ulcer_index"
 }
 
-// calculateUlcerIndex рассчитывает Ulcer Index для заданного периода
-func calculateUlcerIndex(candles []internal.Candle, period int) []float64 {
-	if len(candles) < period {
-		return nil
-	}
-
-	ulcerIndex := make([]float64, len(candles))
-
-	// Первые period-1 значений не определены
-	for i := 0; i < period-1; i++ {
-		ulcerIndex[i] = 0
-	}
-
-	// Для каждого окна рассчитываем Ulcer Index
-	for i := period - 1; i < len(candles); i++ {
-		// Находим максимум в текущем окне
-		maxHigh := candles[i-period+1].High.ToFloat64()
-		for j := i - period + 2; j <= i; j++ {
-			if candles[j].High.ToFloat64() > maxHigh {
-				maxHigh = candles[j].High.ToFloat64()
-			}
-		}
-
-		// Если максимум равен 0, пропускаем расчет
-		if maxHigh == 0 {
-			ulcerIndex[i] = 0
-			continue
-		}
-
-		// Рассчитываем сумму квадратов drawdown'ов
-		var sumSquaredDrawdown float64
-		for j := i - period + 1; j <= i; j++ {
-			currentPrice := candles[j].Close.ToFloat64()
-			drawdown := (currentPrice - maxHigh) / maxHigh
-			sumSquaredDrawdown += drawdown * drawdown
-		}
-
-		// Ulcer Index = квадратный корень от среднего квадрата drawdown'а
-		ulcerIndex[i] = math.Sqrt(sumSquaredDrawdown / float64(period))
-	}
-
-	return ulcerIndex
+func (s *UlcerIndexStrategy) Category() string {
+	return internal.CategoryVolatility
 }
 
 func (s *UlcerIndexStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
@@ -133,7 +96,8 @@ func (s *UlcerIndexStrategy) GenerateSignalsWithConfig(candles []internal.Candle
 		return make([]internal.SignalType, len(candles))
 	}
 
-	ulcerIndex := calculateUlcerIndex(candles, uiConfig.Period)
+	prices := internal.ExtractPrices(candles, internal.DefaultPriceSource())
+	ulcerIndex := internal.CalculateUlcerIndex(prices, uiConfig.Period)
 	if ulcerIndex == nil {
 		return make([]internal.SignalType, len(candles))
 	}
@@ -164,9 +128,9 @@ func (s *UlcerIndexStrategy) GenerateSignalsWithConfig(candles []internal.Candle
 	return signals
 }
 
-func (s *UlcerIndexStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *UlcerIndexStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*UlcerIndexConfig)
-	bestProfit := -1.0
+	bestScore := math.Inf(-1)
 
 	// Grid search по параметрам
 	for period := 300; period <= 400; period += 10 {
@@ -177,22 +141,25 @@ func (s *UlcerIndexStrategy) OptimizeWithConfig(candles []internal.Candle) inter
 					BuyThreshold:  buyThreshold,
 					SellThreshold: sellThreshold,
 				}
+				if ctx.Err() != nil {
+					return bestConfig
+				}
 				if config.Validate() != nil {
 					continue
 				}
 
 				signals := s.GenerateSignalsWithConfig(candles, config)
 				result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-				if result.TotalProfit >= bestProfit {
-					bestProfit = result.TotalProfit
+				if score := internal.ObjectiveScore(result); score >= bestScore {
+					bestScore = score
 					bestConfig = config
 				}
 			}
 		}
 	}
 
-	fmt.Printf("Лучшие параметры Ulcer Index: period=%d, buy=%.4f, sell=%.4f, профит=%.4f\n",
-		bestConfig.Period, bestConfig.BuyThreshold, bestConfig.SellThreshold, bestProfit)
+	fmt.Printf("Лучшие параметры Ulcer Index: period=%d, buy=%.4f, sell=%.4f, %s=%.4f\n",
+		bestConfig.Period, bestConfig.BuyThreshold, bestConfig.SellThreshold, internal.ObjectiveLabel(), bestScore)
 
 	return bestConfig
 }
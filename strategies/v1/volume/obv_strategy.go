@@ -38,8 +38,10 @@ package volume
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type OBVConfig struct {
@@ -81,6 +83,10 @@ func (s *OBVStrategy) Name() string {
 	return "obv_strategy"
 }
 
+func (s *OBVStrategy) Category() string {
+	return internal.CategoryVolume
+}
+
 // detectOBVDivergence обнаруживает дивергенции между OBV и ценой
 func detectOBVDivergence(candles []internal.Candle, obv []float64, lookback int) (bool, bool) {
 	if len(candles) < lookback+2 || len(obv) < lookback+2 {
@@ -216,9 +222,9 @@ func (s *OBVStrategy) GenerateSignalsWithConfig(candles []internal.Candle, confi
 	return signals
 }
 
-func (s *OBVStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *OBVStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*OBVConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	// Оптимизируем период OBV
 	for period := 25; period <= 90; period += 10 {
@@ -240,6 +246,9 @@ func (s *OBVStrategy) OptimizeWithConfig(candles []internal.Candle) internal.Str
 								PriceDropThreshold: priceDrop,
 								OBVDropMultiplier:  obvDropMult,
 							}
+							if ctx.Err() != nil {
+								return bestConfig
+							}
 							if config.Validate() != nil {
 								continue
 							}
@@ -247,8 +256,8 @@ func (s *OBVStrategy) OptimizeWithConfig(candles []internal.Candle) internal.Str
 							signals := s.GenerateSignalsWithConfig(candles, config)
 							result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
 
-							if result.TotalProfit >= bestProfit {
-								bestProfit = result.TotalProfit
+							if score := internal.ObjectiveScore(result); score >= bestProfit {
+								bestProfit = score
 								bestConfig = config
 							}
 						}
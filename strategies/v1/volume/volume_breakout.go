@@ -40,8 +40,10 @@ package volume
 
 import (
 	"bt/internal"
+	"context"
 	"errors"
 	"fmt"
+	"math"
 )
 
 type VolumeBreakoutConfig struct {
@@ -66,6 +68,10 @@ func (s *VolumeBreakoutStrategy) Name() string {
 	return "volume_breakout"
 }
 
+func (s *VolumeBreakoutStrategy) Category() string {
+	return internal.CategoryVolume
+}
+
 func (s *VolumeBreakoutStrategy) GenerateSignalsWithConfig(candles []internal.Candle, config internal.StrategyConfig) []internal.SignalType {
 	vbConfig, ok := config.(*VolumeBreakoutConfig)
 	if !ok {
@@ -117,22 +123,25 @@ func (s *VolumeBreakoutStrategy) GenerateSignalsWithConfig(candles []internal.Ca
 	return signals
 }
 
-func (s *VolumeBreakoutStrategy) OptimizeWithConfig(candles []internal.Candle) internal.StrategyConfig {
+func (s *VolumeBreakoutStrategy) OptimizeWithConfig(ctx context.Context, candles []internal.Candle) internal.StrategyConfig {
 	bestConfig := s.DefaultConfig().(*VolumeBreakoutConfig)
-	bestProfit := -1.0
+	bestProfit := math.Inf(-1)
 
 	for mult := 0.5; mult <= 30.0; mult += 0.1 {
 		config := &VolumeBreakoutConfig{
 			Multiplier: mult,
 		}
+		if ctx.Err() != nil {
+			return bestConfig
+		}
 		if config.Validate() != nil {
 			continue
 		}
 
 		signals := s.GenerateSignalsWithConfig(candles, config)
 		result := internal.Backtest(candles, signals, s.GetSlippage()) // проскальзывание
-		if result.TotalProfit >= bestProfit {
-			bestProfit = result.TotalProfit
+		if score := internal.ObjectiveScore(result); score >= bestProfit {
+			bestProfit = score
 			bestConfig = config
 		}
 	}
@@ -164,14 +164,18 @@ func (s *SupportLineConfigGenerator) Generate() []internal.StrategyConfigV2 {
 
 func NewSupportLineStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewSupportLineSignalGenerator()
 
 	// 3. Создаем менеджер конфигурации
 	configManager := internal.NewConfigManager(
-		&SupportLineConfig{},
+		&SupportLineConfig{
+			LookbackPeriod: 40,
+			BuyThreshold:   0.005,
+			SellThreshold:  0.01,
+		},
 		func() internal.StrategyConfigV2 {
 			return &SupportLineConfig{}
 		},
@@ -187,13 +191,16 @@ func NewSupportLineStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"support_line_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryLines)
+
+	return strategy
 }
 
 func init() {
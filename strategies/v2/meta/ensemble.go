@@ -0,0 +1,246 @@
+// Ensemble Strategy V2
+//
+// Описание стратегии:
+// Мета-стратегия голосования: на каждой свече каждый участник ансамбля «голосует» своей позицией —
+// в рынке (после его BUY) или вне рынка (после его SELL). Ансамбль покупает, когда в рынке не меньше
+// доли Threshold участников, и продает, когда вне рынка не меньше той же доли. Голосуют позиции, а не
+// сами сигналы: BUY/SELL разных стратегий редко приходятся на одну и ту же свечу.
+//
+// Участники — любые зарегистрированные стратегии V1 и V2 с конфигурацией по умолчанию.
+// Если участник вернул срез сигналов не той длины, недостающие свечи считаются HOLD.
+//
+// Параметры:
+// - Members: имена стратегий-участников (не меньше двух)
+// - Threshold: доля согласных участников для сделки, (0.5; 1]
+
+package meta
+
+import (
+	"bt/internal"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+
+	"github.com/samber/lo"
+)
+
+const ensembleName = "ensemble"
+
+// ensemblePool — участники, из которых оптимизатор собирает подмножества: быстрые стратегии разных семейств
+var ensemblePool = []string{"rsi_oscillator", "golden_cross", "bollinger_bands", "stochastic_oscillator", "cci_oscillator_v2"}
+
+// ensembleThresholds — доли голосов, перебираемые оптимизатором
+var ensembleThresholds = []float64{0.6, 0.75, 1.0}
+
+type EnsembleConfigV2 struct {
+	Members   []string `json:"members"`
+	Threshold float64  `json:"threshold"`
+}
+
+// Validate — проверяет только форму конфигурации: наличие участников в реестрах проверяется
+// при генерации сигналов, так как при регистрации ансамбля остальные стратегии могут быть еще не зарегистрированы
+func (c *EnsembleConfigV2) Validate() error {
+	if len(c.Members) < 2 {
+		return errors.New("ensemble needs at least two members")
+	}
+	if len(lo.Uniq(c.Members)) != len(c.Members) {
+		return errors.New("ensemble members must be unique")
+	}
+	if lo.Contains(c.Members, ensembleName) {
+		return errors.New("ensemble cannot include itself")
+	}
+	if c.Threshold <= 0.5 || c.Threshold > 1 {
+		return errors.New("threshold must be in (0.5, 1]")
+	}
+	return nil
+}
+
+func (c *EnsembleConfigV2) String() string {
+	return fmt.Sprintf("Ensemble(members=%s, threshold=%.2f)", strings.Join(c.Members, "+"), c.Threshold)
+}
+
+// votesNeeded — сколько участников должно согласиться при заданной доле
+func (c *EnsembleConfigV2) votesNeeded() int {
+	return int(math.Ceil(c.Threshold*float64(len(c.Members)) - 1e-9))
+}
+
+type EnsembleSignalGeneratorV2 struct {
+	mu sync.Mutex
+	// cache — сигналы участников по отпечатку свечей (internal.FingerprintCandles): оптимизатор
+	// перебирает подмножества одних и тех же участников
+	cache map[uint64]map[string][]internal.SignalType
+}
+
+func NewEnsembleSignalGeneratorV2() *EnsembleSignalGeneratorV2 {
+	return &EnsembleSignalGeneratorV2{cache: make(map[uint64]map[string][]internal.SignalType)}
+}
+
+// runMember — сигналы зарегистрированной стратегии с ее конфигурацией по умолчанию
+func runMember(name string, candles []internal.Candle) ([]internal.SignalType, error) {
+	if s, ok := internal.GetStrategyV2(name); ok {
+		return s.GenerateSignals(candles, s.DefaultConfig()), nil
+	}
+	if s, ok := internal.LookupStrategy(name); ok {
+		return s.GenerateSignalsWithConfig(candles, s.DefaultConfig()), nil
+	}
+	return nil, fmt.Errorf("неизвестная стратегия в ансамбле: %s", name)
+}
+
+// memberSignals — сигналы участников, посчитанные один раз на набор свечей
+func (sg *EnsembleSignalGeneratorV2) memberSignals(candles []internal.Candle, members []string) ([][]internal.SignalType, error) {
+	sg.mu.Lock()
+	defer sg.mu.Unlock()
+
+	key := internal.FingerprintCandles(candles)
+	bySeries, ok := sg.cache[key]
+	if !ok {
+		// Walk-forward дает много разных окон — держим кеш небольшим
+		if len(sg.cache) >= 16 {
+			sg.cache = make(map[uint64]map[string][]internal.SignalType)
+		}
+		bySeries = make(map[string][]internal.SignalType)
+		sg.cache[key] = bySeries
+	}
+
+	result := make([][]internal.SignalType, len(members))
+	for i, name := range members {
+		signals, ok := bySeries[name]
+		if !ok {
+			var err error
+			if signals, err = runMember(name, candles); err != nil {
+				return nil, err
+			}
+			bySeries[name] = signals
+		}
+		result[i] = signals
+	}
+	return result, nil
+}
+
+func (sg *EnsembleSignalGeneratorV2) GenerateSignals(candles []internal.Candle, config internal.StrategyConfigV2) []internal.SignalType {
+	signals := make([]internal.SignalType, len(candles))
+
+	ensembleConfig, ok := config.(*EnsembleConfigV2)
+	if !ok || len(candles) == 0 {
+		return signals
+	}
+	if err := ensembleConfig.Validate(); err != nil {
+		return signals
+	}
+
+	memberSignals, err := sg.memberSignals(candles, ensembleConfig.Members)
+	if err != nil {
+		return signals
+	}
+
+	return vote(memberSignals, len(candles), ensembleConfig.votesNeeded())
+}
+
+// vote — сигналы ансамбля по позициям участников; сигналы за пределами среза участника считаются HOLD
+func vote(memberSignals [][]internal.SignalType, n int, needed int) []internal.SignalType {
+	signals := make([]internal.SignalType, n)
+	inMarket := make([]bool, len(memberSignals))
+	inPosition := false
+
+	for i := 0; i < n; i++ {
+		long := 0
+		for m, ms := range memberSignals {
+			if i < len(ms) {
+				switch ms[i] {
+				case internal.BUY:
+					inMarket[m] = true
+				case internal.SELL:
+					inMarket[m] = false
+				}
+			}
+			if inMarket[m] {
+				long++
+			}
+		}
+
+		switch {
+		case !inPosition && long >= needed:
+			signals[i] = internal.BUY
+			inPosition = true
+		case inPosition && len(memberSignals)-long >= needed:
+			signals[i] = internal.SELL
+			inPosition = false
+		default:
+			signals[i] = internal.HOLD
+		}
+	}
+
+	return signals
+}
+
+type EnsembleConfigGeneratorV2 struct{}
+
+func NewEnsembleConfigGeneratorV2() *EnsembleConfigGeneratorV2 {
+	return &EnsembleConfigGeneratorV2{}
+}
+
+// Generate — все подмножества пула из двух и более участников с каждым порогом;
+// пороги, дающие одинаковое число нужных голосов, не дублируются
+func (g *EnsembleConfigGeneratorV2) Generate() []internal.StrategyConfigV2 {
+	var configs []internal.StrategyConfigV2
+	for mask := 1; mask < 1<<len(ensemblePool); mask++ {
+		var members []string
+		for i, name := range ensemblePool {
+			if mask&(1<<i) != 0 {
+				members = append(members, name)
+			}
+		}
+		if len(members) < 2 {
+			continue
+		}
+
+		seen := make(map[int]bool)
+		for _, threshold := range ensembleThresholds {
+			config := &EnsembleConfigV2{Members: members, Threshold: threshold}
+			if needed := config.votesNeeded(); !seen[needed] {
+				seen[needed] = true
+				configs = append(configs, config)
+			}
+		}
+	}
+	return configs
+}
+
+func NewEnsembleStrategyV2(slippage float64) internal.TradingStrategy {
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
+	signalGenerator := NewEnsembleSignalGeneratorV2()
+
+	configManager := internal.NewConfigManager(
+		&EnsembleConfigV2{
+			Members:   []string{"rsi_oscillator", "golden_cross", "bollinger_bands"},
+			Threshold: 0.6,
+		},
+		func() internal.StrategyConfigV2 {
+			return &EnsembleConfigV2{}
+		},
+	)
+
+	configGenerator := NewEnsembleConfigGeneratorV2()
+	optimizer := internal.NewGridSearchOptimizer(
+		slippageProvider,
+		configGenerator.Generate,
+	)
+
+	strategy := internal.NewStrategyBase(
+		ensembleName,
+		signalGenerator,
+		configManager,
+		optimizer,
+		slippageProvider,
+	)
+	strategy.SetCategory(internal.CategoryEnsemble)
+
+	return strategy
+}
+
+func init() {
+	strategy := NewEnsembleStrategyV2(0.01)
+	internal.RegisterStrategyV2(strategy)
+}
@@ -0,0 +1,57 @@
+package meta
+
+import (
+	"bt/internal"
+	"reflect"
+	"testing"
+)
+
+func TestVote_ThresholdAndShortMemberSlices(t *testing.T) {
+	B, S, H := internal.BUY, internal.SELL, internal.HOLD
+	members := [][]internal.SignalType{
+		{B, H, H, H, S, H},
+		{H, B, H, S, H, H},
+		// Короткий срез: после него участник держит последнюю позицию (вне рынка)
+		{H, H, B, S},
+	}
+
+	// 2 из 3: вход, когда в рынке двое, выход, когда двое вне рынка
+	got := vote(members, 6, 2)
+	want := []internal.SignalType{H, B, H, S, H, H}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("2 of 3: expected %v, got %v", want, got)
+	}
+
+	// Единогласие: вход только на свече, где в рынке все трое
+	got = vote(members, 6, 3)
+	want = []internal.SignalType{H, H, B, H, S, H}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("3 of 3: expected %v, got %v", want, got)
+	}
+}
+
+func TestEnsembleConfigGenerator_DeduplicatesVoteCounts(t *testing.T) {
+	configs := NewEnsembleConfigGeneratorV2().Generate()
+	seen := make(map[string]bool)
+	for _, c := range configs {
+		config := c.(*EnsembleConfigV2)
+		if err := config.Validate(); err != nil {
+			t.Fatalf("%s: %v", config, err)
+		}
+		key := config.String()
+		if seen[key] {
+			t.Fatalf("duplicate config %s", key)
+		}
+		seen[key] = true
+	}
+	// Пары: 0.6/0.75/1.0 требуют одинаково 2 голоса — остается одна конфигурация на пару
+	pairs := 0
+	for _, c := range configs {
+		if len(c.(*EnsembleConfigV2).Members) == 2 {
+			pairs++
+		}
+	}
+	if pairs != 10 {
+		t.Fatalf("expected 10 pair configs, got %d", pairs)
+	}
+}
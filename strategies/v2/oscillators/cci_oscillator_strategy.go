@@ -16,7 +16,6 @@ import (
 	"bt/internal"
 	"errors"
 	"fmt"
-	"math"
 
 	"github.com/samber/lo"
 )
@@ -54,55 +53,6 @@ func NewCCISignalGeneratorV2() *CCISignalGeneratorV2 {
 	return &CCISignalGeneratorV2{}
 }
 
-// calculateTypicalPrice — (High + Low + Close) / 3
-func calculateTypicalPrice(c internal.Candle) float64 {
-	h := c.High.ToFloat64()
-	l := c.Low.ToFloat64()
-	clo := c.Close.ToFloat64()
-	return (h + l + clo) / 3.0
-}
-
-// calculateCCI — возвращает массив значений CCI
-func calculateCCI(candles []internal.Candle, period int) []float64 {
-	if len(candles) < period {
-		return nil
-	}
-
-	cci := make([]float64, len(candles))
-
-	for i := 0; i < period-1; i++ {
-		cci[i] = 0
-	}
-
-	for i := period - 1; i < len(candles); i++ {
-		var tpSum float64
-		typicalPrices := make([]float64, 0, period)
-
-		for j := i - period + 1; j <= i; j++ {
-			tp := calculateTypicalPrice(candles[j])
-			typicalPrices = append(typicalPrices, tp)
-			tpSum += tp
-		}
-
-		ma := tpSum / float64(period)
-
-		var mdSum float64
-		for _, tp := range typicalPrices {
-			mdSum += math.Abs(tp - ma)
-		}
-		meanDeviation := mdSum / float64(period)
-
-		currentTp := calculateTypicalPrice(candles[i])
-		if meanDeviation == 0 {
-			cci[i] = 0
-		} else {
-			cci[i] = (currentTp - ma) / (0.015 * meanDeviation)
-		}
-	}
-
-	return cci
-}
-
 func (sg *CCISignalGeneratorV2) GenerateSignals(candles []internal.Candle, config internal.StrategyConfigV2) []internal.SignalType {
 	cciConfig, ok := config.(*CCIConfigV2)
 	if !ok {
@@ -113,7 +63,7 @@ func (sg *CCISignalGeneratorV2) GenerateSignals(candles []internal.Candle, confi
 		return make([]internal.SignalType, len(candles))
 	}
 
-	cciValues := calculateCCI(candles, cciConfig.Period)
+	cciValues := internal.CalculateCCI(candles, cciConfig.Period)
 	if cciValues == nil {
 		return make([]internal.SignalType, len(candles))
 	}
@@ -161,7 +111,7 @@ func (sg *CCISignalGeneratorV2) PredictNextSignal(candles []internal.Candle, con
 		return nil
 	}
 
-	cciValues := calculateCCI(candles, cciConfig.Period)
+	cciValues := internal.CalculateCCI(candles, cciConfig.Period)
 	if cciValues == nil {
 		return nil
 	}
@@ -327,7 +277,7 @@ func (g *CCIConfigGeneratorV2) Generate() []internal.StrategyConfigV2 {
 }
 
 func NewCCIOscillatorStrategyV2(slippage float64) internal.TradingStrategy {
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 	signalGenerator := NewCCISignalGeneratorV2()
 
 	configManager := internal.NewConfigManager(
@@ -347,13 +297,16 @@ func NewCCIOscillatorStrategyV2(slippage float64) internal.TradingStrategy {
 		configGenerator.Generate,
 	)
 
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"cci_oscillator_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryOscillators)
+
+	return strategy
 }
 
 func init() {
@@ -0,0 +1,240 @@
+// MACD Strategy V2
+//
+// Описание стратегии:
+// MACD - разность быстрой и медленной EMA цены закрытия, сигнальная линия - EMA от MACD,
+// гистограмма - разность MACD и сигнальной линии.
+// Покупка при пересечении MACD сигнальной линии снизу вверх (гистограмма становится положительной),
+// продажа - при пересечении сверху вниз.
+//
+// Параметры:
+// - FastPeriod: период быстрой EMA (обычно 12)
+// - SlowPeriod: период медленной EMA (обычно 26)
+// - SignalPeriod: период сигнальной линии (обычно 9)
+
+package oscillators
+
+import (
+	"bt/internal"
+	"errors"
+	"fmt"
+	"math"
+
+	"github.com/samber/lo"
+)
+
+type MACDConfigV2 struct {
+	FastPeriod   int `json:"fast_period"`
+	SlowPeriod   int `json:"slow_period"`
+	SignalPeriod int `json:"signal_period"`
+}
+
+func (c *MACDConfigV2) Validate() error {
+	if c.FastPeriod <= 0 || c.SlowPeriod <= 0 || c.SignalPeriod <= 0 {
+		return errors.New("periods must be positive")
+	}
+	if c.FastPeriod >= c.SlowPeriod {
+		return errors.New("fast period must be less than slow period")
+	}
+	return nil
+}
+
+func (c *MACDConfigV2) String() string {
+	return fmt.Sprintf("MACD(fast=%d, slow=%d, signal=%d)",
+		c.FastPeriod, c.SlowPeriod, c.SignalPeriod)
+}
+
+// warmup — первая свеча, на которой определены и MACD, и сигнальная линия
+func (c *MACDConfigV2) warmup() int {
+	return c.SlowPeriod + c.SignalPeriod - 1
+}
+
+type MACDSignalGeneratorV2 struct{}
+
+func NewMACDSignalGeneratorV2() *MACDSignalGeneratorV2 {
+	return &MACDSignalGeneratorV2{}
+}
+
+func (sg *MACDSignalGeneratorV2) GenerateSignals(candles []internal.Candle, config internal.StrategyConfigV2) []internal.SignalType {
+	macdConfig, ok := config.(*MACDConfigV2)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := macdConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	_, _, histogram := internal.CalculateMACDWithSignal(candles, macdConfig.FastPeriod, macdConfig.SlowPeriod, macdConfig.SignalPeriod)
+	if histogram == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	for i := macdConfig.warmup() + 1; i < len(candles); i++ {
+		prev, curr := histogram[i-1], histogram[i]
+
+		if !inPosition && prev <= 0 && curr > 0 {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		if inPosition && prev >= 0 && curr < 0 {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+// PredictNextSignal предсказывает следующее пересечение MACD и сигнальной линии,
+// экстраполируя гистограмму по ее текущему наклону
+func (sg *MACDSignalGeneratorV2) PredictNextSignal(candles []internal.Candle, config internal.StrategyConfigV2) *internal.FutureSignal {
+	macdConfig, ok := config.(*MACDConfigV2)
+	if !ok {
+		return nil
+	}
+
+	if err := macdConfig.Validate(); err != nil {
+		return nil
+	}
+
+	lookback := 3
+	if len(candles) < macdConfig.warmup()+lookback+1 {
+		return nil
+	}
+
+	_, _, histogram := internal.CalculateMACDWithSignal(candles, macdConfig.FastPeriod, macdConfig.SlowPeriod, macdConfig.SignalPeriod)
+	if histogram == nil {
+		return nil
+	}
+
+	currentIdx := len(candles) - 1
+	currentHist := histogram[currentIdx]
+	currentPrice := candles[currentIdx].Close.ToFloat64()
+
+	// Наклон гистограммы: пересечение ожидается, только если она движется к нулю
+	slope := (currentHist - histogram[currentIdx-lookback]) / float64(lookback)
+
+	var signalType internal.SignalType
+	switch {
+	case currentHist < 0 && slope > 0:
+		signalType = internal.BUY
+	case currentHist > 0 && slope < 0:
+		signalType = internal.SELL
+	default:
+		return nil
+	}
+
+	predictedCandles := int(math.Ceil(math.Abs(currentHist) / math.Abs(slope)))
+	if predictedCandles > macdConfig.SignalPeriod*3 {
+		return nil // Слишком далеко
+	}
+	if predictedCandles < 1 {
+		predictedCandles = 1
+	}
+
+	// Экстраполируем цену
+	priceVelocity := (currentPrice - candles[currentIdx-lookback].Close.ToFloat64()) / float64(lookback)
+	predictedPrice := currentPrice + priceVelocity*float64(predictedCandles)
+
+	// Уверенность зависит от крутизны гистограммы относительно ее типичного размаха
+	// за период сигнальной линии: пологий наклон легко сменится до пересечения
+	scale := 0.0
+	for i := currentIdx - macdConfig.SignalPeriod + 1; i <= currentIdx; i++ {
+		scale += math.Abs(histogram[i])
+	}
+	scale /= float64(macdConfig.SignalPeriod)
+
+	confidence := 0.3
+	if scale > 0 {
+		confidence += internal.Min(math.Abs(slope)/scale, 1.0) * 0.5
+	}
+	if predictedCandles <= 2 {
+		confidence += 0.1
+	}
+
+	// Ограничиваем уверенность
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+	if confidence < 0.1 {
+		confidence = 0.1
+	}
+
+	timeInterval := (candles[currentIdx].ToTime().Unix() - candles[0].ToTime().Unix()) / int64(currentIdx)
+	futureTimestamp := candles[currentIdx].ToTime().Unix() + timeInterval*int64(predictedCandles)
+
+	return &internal.FutureSignal{
+		SignalType: signalType,
+		Date:       futureTimestamp,
+		Price:      predictedPrice,
+		Confidence: confidence,
+	}
+}
+
+type MACDConfigGeneratorV2 struct{}
+
+func NewMACDConfigGeneratorV2() *MACDConfigGeneratorV2 {
+	return &MACDConfigGeneratorV2{}
+}
+
+func (g *MACDConfigGeneratorV2) Generate() []internal.StrategyConfigV2 {
+	configs := lo.CrossJoinBy3(
+		lo.RangeWithSteps[int](6, 16, 2),
+		lo.RangeWithSteps[int](18, 40, 4),
+		lo.RangeWithSteps[int](5, 13, 2),
+		func(fast int, slow int, signal int) internal.StrategyConfigV2 {
+			return &MACDConfigV2{
+				FastPeriod:   fast,
+				SlowPeriod:   slow,
+				SignalPeriod: signal,
+			}
+		})
+
+	return configs
+}
+
+func NewMACDStrategyV2(slippage float64) internal.TradingStrategy {
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
+	signalGenerator := NewMACDSignalGeneratorV2()
+
+	configManager := internal.NewConfigManager(
+		&MACDConfigV2{
+			FastPeriod:   12,
+			SlowPeriod:   26,
+			SignalPeriod: 9,
+		},
+		func() internal.StrategyConfigV2 {
+			return &MACDConfigV2{}
+		},
+	)
+
+	configGenerator := NewMACDConfigGeneratorV2()
+	optimizer := internal.NewGridSearchOptimizer(
+		slippageProvider,
+		configGenerator.Generate,
+	)
+
+	strategy := internal.NewStrategyBase(
+		"macd_v2",
+		signalGenerator,
+		configManager,
+		optimizer,
+		slippageProvider,
+	)
+	strategy.SetCategory(internal.CategoryMomentum)
+
+	return strategy
+}
+
+func init() {
+	strategy := NewMACDStrategyV2(0.01)
+	internal.RegisterStrategyV2(strategy)
+}
@@ -107,6 +107,22 @@ func (s *QStickSignalGenerator) GenerateSignals(candles []internal.Candle, confi
 		return make([]internal.SignalType, len(candles))
 	}
 
+	return s.generate(candles, qstickConfig, nil)
+}
+
+// ExplainSignals — сигналы Qstick и условие (волатильность, тренд, импульс), заблокировавшее каждую сделку
+func (s *QStickSignalGenerator) ExplainSignals(candles []internal.Candle, config internal.StrategyConfigV2) ([]internal.SignalType, internal.HoldReasons) {
+	qstickConfig, ok := config.(*QStickConfig)
+	if !ok || qstickConfig.Validate() != nil {
+		return make([]internal.SignalType, len(candles)), nil
+	}
+
+	reasons := make(internal.HoldReasons, len(candles))
+	return s.generate(candles, qstickConfig, reasons), reasons
+}
+
+// generate — основной цикл стратегии; если reasons не nil, в него пишутся причины HOLD
+func (s *QStickSignalGenerator) generate(candles []internal.Candle, qstickConfig *QStickConfig, reasons internal.HoldReasons) []internal.SignalType {
 	qstickValues := calculateQstickValues(candles, qstickConfig.Period)
 	if qstickValues == nil {
 		return make([]internal.SignalType, len(candles))
@@ -153,6 +169,11 @@ func (s *QStickSignalGenerator) GenerateSignals(candles []internal.Candle, confi
 
 		// Пропускаем если волатильность слишком низкая
 		if volatilityValues[i] < qstickConfig.VolatilityFilter {
+			if !inPosition && qstick > qstickConfig.BuyThreshold {
+				reasons.Record(i, internal.BUY, internal.HoldVolatility, "волатильность %.5f < %.5f", volatilityValues[i], qstickConfig.VolatilityFilter)
+			} else if inPosition && qstick < qstickConfig.SellThreshold {
+				reasons.Record(i, internal.SELL, internal.HoldVolatility, "волатильность %.5f < %.5f", volatilityValues[i], qstickConfig.VolatilityFilter)
+			}
 			signals[i] = internal.HOLD
 			continue
 		}
@@ -173,6 +194,12 @@ func (s *QStickSignalGenerator) GenerateSignals(candles []internal.Candle, confi
 				takeProfitPrice = currentPrice * (1.0 + qstickConfig.TakeProfitPercent/100.0)
 				continue
 			}
+
+			if !trendConfirmed {
+				reasons.Record(i, internal.BUY, internal.HoldTrendGate, "наклон тренда %.5f ≤ 0", trendValues[i])
+			} else {
+				reasons.Record(i, internal.BUY, internal.HoldMomentum, "цена растет: %t, qstick растет: %t", priceGrowing, qstickGrowing)
+			}
 		}
 
 		// SELL: Улучшенная логика с фильтрами
@@ -188,6 +215,12 @@ func (s *QStickSignalGenerator) GenerateSignals(candles []internal.Candle, confi
 				inPosition = false
 				continue
 			}
+
+			if !trendConfirmed {
+				reasons.Record(i, internal.SELL, internal.HoldTrendGate, "наклон тренда %.5f ≥ 0", trendValues[i])
+			} else {
+				reasons.Record(i, internal.SELL, internal.HoldMomentum, "цена падает: %t, qstick падает: %t", priceFalling, qstickFalling)
+			}
 		}
 
 		signals[i] = internal.HOLD
@@ -227,7 +260,7 @@ func (s *QstickConfigGenerator) Generate() []internal.StrategyConfigV2 {
 
 func NewQstickStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewQStickSignalGenerator()
@@ -257,13 +290,16 @@ func NewQstickStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"qstick_oscillator_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryOscillators)
+
+	return strategy
 }
 
 func init() {
@@ -311,36 +347,15 @@ func calculateTrendDirection(candles []internal.Candle, period int) []float64 {
 		return nil
 	}
 
-	trend := make([]float64, len(candles))
-
-	// Первые period-1 значений — не определены
-	for i := 0; i < period-1; i++ {
-		trend[i] = 0
+	closes := make([]float64, len(candles))
+	for i, c := range candles {
+		closes[i] = c.Close.ToFloat64()
 	}
 
+	// Первые period-1 значений — не определены (0)
+	trend := make([]float64, len(candles))
 	for i := period - 1; i < len(candles); i++ {
-		var sumX, sumY, sumXY, sumXX float64
-		n := float64(period)
-
-		// Рассчитываем линейную регрессию
-		for j := i - period + 1; j <= i; j++ {
-			x := float64(j - (i - period + 1))
-			y := candles[j].Close.ToFloat64()
-
-			sumX += x
-			sumY += y
-			sumXY += x * y
-			sumXX += x * x
-		}
-
-		// Наклон линии тренда (slope)
-		denominator := n*sumXX - sumX*sumX
-		if denominator == 0 {
-			trend[i] = 0
-		} else {
-			slope := (n*sumXY - sumX*sumY) / denominator
-			trend[i] = slope
-		}
+		trend[i], _, _ = internal.LinearRegression(closes[i-period+1 : i+1])
 	}
 
 	return trend
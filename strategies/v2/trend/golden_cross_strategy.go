@@ -300,7 +300,7 @@ func (cg *GoldenCrossConfigGenerator) Generate() []internal.StrategyConfigV2 {
 
 func NewGoldenCrossStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewGoldenCrossSignalGenerator()
@@ -324,13 +324,16 @@ func NewGoldenCrossStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"golden_cross_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryTrend)
+
+	return strategy
 }
 
 func init() {
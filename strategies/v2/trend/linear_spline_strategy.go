@@ -40,8 +40,6 @@ import (
 	"bt/internal"
 	"errors"
 	"fmt"
-	"log"
-	"math"
 )
 
 type LinearSplineConfig struct {
@@ -67,6 +65,10 @@ func (c *LinearSplineConfig) Validate() error {
 	return nil
 }
 
+func (c *LinearSplineConfig) MinCandles() int {
+	return c.MinSegmentLength * 2
+}
+
 func (c *LinearSplineConfig) String() string {
 	return fmt.Sprintf("LinearSpline(min_len=%d, max_len=%d, r2=%.2f, slope=%.4f)",
 		c.MinSegmentLength, c.MaxSegmentLength, c.MinR2Threshold, c.MinSlopeThreshold)
@@ -105,62 +107,6 @@ func NewLinearSplineAnalyzer(config *LinearSplineConfig) *LinearSplineAnalyzer {
 	}
 }
 
-// linearRegression выполняет линейную регрессию для данных
-func (la *LinearSplineAnalyzer) linearRegression(y []float64) (slope, intercept float64) {
-	n := float64(len(y))
-	if n < 2 {
-		return 0, y[0]
-	}
-
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, yi := range y {
-		xi := float64(i)
-		sumX += xi
-		sumY += yi
-		sumXY += xi * yi
-		sumXX += xi * xi
-	}
-
-	denominator := n*sumXX - sumX*sumX
-	if math.Abs(denominator) < 1e-10 {
-		return 0, sumY / n
-	}
-
-	slope = (n*sumXY - sumX*sumY) / denominator
-	intercept = (sumY - slope*sumX) / n
-
-	return slope, intercept
-}
-
-// calculateR2 вычисляет коэффициент детерминации
-func (la *LinearSplineAnalyzer) calculateR2(y []float64, slope, intercept float64) float64 {
-	if len(y) < 2 {
-		return 0
-	}
-
-	mean := 0.0
-	for _, yi := range y {
-		mean += yi
-	}
-	mean /= float64(len(y))
-
-	ssRes := 0.0
-	ssTot := 0.0
-
-	for i, yi := range y {
-		predicted := slope*float64(i) + intercept
-		ssRes += (yi - predicted) * (yi - predicted)
-		ssTot += (yi - mean) * (yi - mean)
-	}
-
-	if ssTot == 0 {
-		return 0
-	}
-
-	return 1 - ssRes/ssTot
-}
-
 // fitSegment подбирает оптимальный линейный сегмент заданного направления
 func (la *LinearSplineAnalyzer) fitSegment(prices []float64, startIdx int, isAscending bool) *LinearSegment {
 	if startIdx >= len(prices)-la.minSegmentLength {
@@ -178,8 +124,7 @@ func (la *LinearSplineAnalyzer) fitSegment(prices []float64, startIdx int, isAsc
 
 	for endIdx := startIdx + la.minSegmentLength; endIdx <= maxEnd; endIdx++ {
 		segment := prices[startIdx:endIdx]
-		slope, intercept := la.linearRegression(segment)
-		r2 := la.calculateR2(segment, slope, intercept)
+		slope, intercept, r2 := internal.LinearRegression(segment)
 
 		// Проверяем направление тренда
 		slopeMatches := (isAscending && slope > la.minSlopeThreshold) ||
@@ -211,7 +156,7 @@ func (la *LinearSplineAnalyzer) fitAlternatingSplines(prices []float64) []*Linea
 
 	// Определяем начальное направление по первым свечам
 	firstSegmentPrices := prices[:la.minSegmentLength]
-	slope, _ := la.linearRegression(firstSegmentPrices)
+	slope, _, _ := internal.LinearRegression(firstSegmentPrices)
 	isAscending := slope > 0
 
 	currentIdx := 0
@@ -246,13 +191,13 @@ func (sg *LinearSplineSignalGenerator) GenerateSignals(candles []internal.Candle
 	}
 
 	if err := lsConfig.Validate(); err != nil {
-		log.Printf("⚠️ Ошибка валидации конфигурации: %v", err)
+		internal.Log.Warnf("⚠️ Ошибка валидации конфигурации: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < lsConfig.MinSegmentLength*2 {
-		log.Printf("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d", 
-			len(candles), lsConfig.MinSegmentLength*2)
+	if len(candles) < lsConfig.MinCandles() {
+		internal.Log.Infof("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d",
+			len(candles), lsConfig.MinCandles())
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -339,7 +284,7 @@ func (s *LinearSplineStrategy) Name() string {
 
 func NewLinearSplineStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewLinearSplineSignalGenerator()
@@ -367,13 +312,16 @@ func NewLinearSplineStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"linear_spline_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryTrend)
+
+	return strategy
 }
 
 func init() {
@@ -50,7 +50,6 @@ import (
 	"bt/internal"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -63,6 +62,8 @@ type PredictiveLinearSplineConfig struct {
 	MinSlopeThreshold     float64 `json:"min_slope_threshold"`
 	TrendExhaustionFactor float64 `json:"trend_exhaustion_factor"`
 	MinPriceChange        float64 `json:"min_price_change"`
+	// ConfidenceThreshold — минимальная уверенность предсказания для сигнала; 0 — адаптивный порог по длине истории
+	ConfidenceThreshold float64 `json:"confidence_threshold"`
 }
 
 func (c *PredictiveLinearSplineConfig) Validate() error {
@@ -90,13 +91,30 @@ func (c *PredictiveLinearSplineConfig) Validate() error {
 	if c.MinPriceChange <= 0 {
 		c.MinPriceChange = 0.003 // 0.3% по умолчанию (более мягкий фильтр)
 	}
+	if c.ConfidenceThreshold < 0 || c.ConfidenceThreshold > 1 {
+		return errors.New("confidence threshold must be between 0 and 1")
+	}
 	return nil
 }
 
+// confidenceThreshold — заданный порог уверенности или адаптивный: чем длиннее история, тем строже
+func (c *PredictiveLinearSplineConfig) confidenceThreshold(candles int) float64 {
+	if c.ConfidenceThreshold > 0 {
+		return c.ConfidenceThreshold
+	}
+	if candles > 10000 {
+		return 0.40 // Более строгий порог для больших данных
+	} else if candles > 5000 {
+		return 0.35
+	}
+	return 0.30
+}
+
 func (c *PredictiveLinearSplineConfig) String() string {
-	return fmt.Sprintf("PredictiveLinearSpline(min_len=%d, max_len=%d, horizon=%d, r2=%.2f, advance=%d, slope=%.5f, exhaust=%.2f, price_chg=%.2f%%)",
+	return fmt.Sprintf("PredictiveLinearSpline(min_len=%d, max_len=%d, horizon=%d, r2=%.2f, advance=%d, slope=%.5f, exhaust=%.2f, price_chg=%.2f%%, confidence=%s)",
 		c.MinSegmentLength, c.MaxSegmentLength, c.PredictionHorizon, c.MinR2Threshold,
-		c.SignalAdvance, c.MinSlopeThreshold, c.TrendExhaustionFactor, c.MinPriceChange*100)
+		c.SignalAdvance, c.MinSlopeThreshold, c.TrendExhaustionFactor, c.MinPriceChange*100,
+		formatConfidenceThreshold(c.ConfidenceThreshold))
 }
 
 // PredictiveLinearSegment представляет линейный сегмент с предсказанием
@@ -134,12 +152,12 @@ func (sg *PredictiveLinearSplineSignalGenerator) PredictNextSignal(candles []int
 	}
 
 	if err := plsConfig.Validate(); err != nil {
-		log.Printf("⚠️ Ошибка валидации конфигурации: %v", err)
+		internal.Log.Warnf("⚠️ Ошибка валидации конфигурации: %v", err)
 		return nil
 	}
 
 	if len(candles) < plsConfig.MinSegmentLength*2 {
-		log.Printf("⚠️ Недостаточно данных для предсказания: получено %d свечей, требуется минимум %d", len(candles), plsConfig.MinSegmentLength*2)
+		internal.Log.Infof("⚠️ Недостаточно данных для предсказания: получено %d свечей, требуется минимум %d", len(candles), plsConfig.MinSegmentLength*2)
 		return nil
 	}
 
@@ -155,32 +173,26 @@ func (sg *PredictiveLinearSplineSignalGenerator) PredictNextSignal(candles []int
 	// Анализируем текущий тренд
 	segment := analyzer.analyzeCurrentTrend(prices, currentIdx)
 	if segment == nil {
-		log.Printf("⚠️ Не удалось определить текущий тренд")
+		internal.Log.Debugf("⚠️ Не удалось определить текущий тренд")
 		return nil
 	}
 
 	if segment.R2 < plsConfig.MinR2Threshold {
-		log.Printf("⚠️ Недостаточная уверенность в тренде (R²=%.3f < %.3f)", segment.R2, plsConfig.MinR2Threshold)
+		internal.Log.Debugf("⚠️ Недостаточная уверенность в тренде (R²=%.3f < %.3f)", segment.R2, plsConfig.MinR2Threshold)
 		return nil
 	}
 
 	// Предсказываем разворот
 	prediction := analyzer.predictReversal(segment, currentIdx, prices)
 	if prediction == nil {
-		log.Printf("⚠️ Не удалось предсказать разворот")
+		internal.Log.Debugf("⚠️ Не удалось предсказать разворот")
 		return nil
 	}
 
-	// Адаптивный порог уверенности
-	confidenceThreshold := 0.30
-	if len(candles) > 10000 {
-		confidenceThreshold = 0.40
-	} else if len(candles) > 5000 {
-		confidenceThreshold = 0.35
-	}
+	confidenceThreshold := plsConfig.confidenceThreshold(len(candles))
 
 	if prediction.Confidence < confidenceThreshold {
-		log.Printf("⚠️ Недостаточная уверенность в предсказании (%.3f < %.3f)", prediction.Confidence, confidenceThreshold)
+		internal.Log.Debugf("⚠️ Недостаточная уверенность в предсказании (%.3f < %.3f)", prediction.Confidence, confidenceThreshold)
 		return nil
 	}
 
@@ -245,62 +257,6 @@ func NewPredictiveLinearAnalyzer(config *PredictiveLinearSplineConfig) *Predicti
 	}
 }
 
-// linearRegression выполняет линейную регрессию для данных
-func (pla *PredictiveLinearAnalyzer) linearRegression(y []float64) (slope, intercept float64) {
-	n := float64(len(y))
-	if n < 2 {
-		return 0, y[0]
-	}
-
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, yi := range y {
-		xi := float64(i)
-		sumX += xi
-		sumY += yi
-		sumXY += xi * yi
-		sumXX += xi * xi
-	}
-
-	denominator := n*sumXX - sumX*sumX
-	if math.Abs(denominator) < 1e-10 {
-		return 0, sumY / n
-	}
-
-	slope = (n*sumXY - sumX*sumY) / denominator
-	intercept = (sumY - slope*sumX) / n
-
-	return slope, intercept
-}
-
-// calculateR2 вычисляет коэффициент детерминации
-func (pla *PredictiveLinearAnalyzer) calculateR2(y []float64, slope, intercept float64) float64 {
-	if len(y) < 2 {
-		return 0
-	}
-
-	mean := 0.0
-	for _, yi := range y {
-		mean += yi
-	}
-	mean /= float64(len(y))
-
-	ssRes := 0.0
-	ssTot := 0.0
-
-	for i, yi := range y {
-		predicted := slope*float64(i) + intercept
-		ssRes += (yi - predicted) * (yi - predicted)
-		ssTot += (yi - mean) * (yi - mean)
-	}
-
-	if ssTot == 0 {
-		return 0
-	}
-
-	return 1 - ssRes/ssTot
-}
-
 // analyzeCurrentTrend анализирует текущий тренд и строит модель
 func (pla *PredictiveLinearAnalyzer) analyzeCurrentTrend(prices []float64, currentIdx int) *PredictiveLinearSegment {
 	// Кэш: если анализировали недавно, используем кэшированный результат
@@ -334,8 +290,7 @@ func (pla *PredictiveLinearAnalyzer) analyzeCurrentTrend(prices []float64, curre
 		segmentStart := len(window) - length
 		segment := window[segmentStart:]
 
-		slope, intercept := pla.linearRegression(segment)
-		r2 := pla.calculateR2(segment, slope, intercept)
+		slope, intercept, r2 := internal.LinearRegression(segment)
 
 		// Проверяем минимальный наклон
 		if math.Abs(slope) < pla.minSlopeThreshold {
@@ -398,8 +353,8 @@ func (pla *PredictiveLinearAnalyzer) calculateTrendMomentum(prices []float64, se
 	firstHalf := prices[segment.StartIdx:midPoint]
 	secondHalf := prices[midPoint : segment.EndIdx+1]
 
-	slope1, _ := pla.linearRegression(firstHalf)
-	slope2, _ := pla.linearRegression(secondHalf)
+	slope1, _, _ := internal.LinearRegression(firstHalf)
+	slope2, _, _ := internal.LinearRegression(secondHalf)
 
 	// Если наклоны одного знака, вычисляем отношение
 	if (slope1 > 0 && slope2 > 0) || (slope1 < 0 && slope2 < 0) {
@@ -573,12 +528,12 @@ func (sg *PredictiveLinearSplineSignalGenerator) GenerateSignals(candles []inter
 	}
 
 	if err := plsConfig.Validate(); err != nil {
-		log.Printf("⚠️ Ошибка валидации конфигурации: %v", err)
+		internal.Log.Warnf("⚠️ Ошибка валидации конфигурации: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
 	if len(candles) < plsConfig.MinSegmentLength*2 {
-		log.Printf("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d", len(candles), plsConfig.MinSegmentLength*2)
+		internal.Log.Infof("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d", len(candles), plsConfig.MinSegmentLength*2)
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -602,13 +557,7 @@ func (sg *PredictiveLinearSplineSignalGenerator) GenerateSignals(candles []inter
 		minSignalDistance = plsConfig.MinSegmentLength * 3 // Больше расстояние для больших данных
 	}
 
-	// Адаптивный порог уверенности в зависимости от размера данных
-	confidenceThreshold := 0.30
-	if len(candles) > 10000 {
-		confidenceThreshold = 0.40 // Более строгий порог для больших данных
-	} else if len(candles) > 5000 {
-		confidenceThreshold = 0.35
-	}
+	confidenceThreshold := plsConfig.confidenceThreshold(len(candles))
 
 	// Начинаем анализ после накопления достаточных данных
 	startIdx := plsConfig.MaxSegmentLength
@@ -687,6 +636,7 @@ func (g *PredictiveLinearSplineConfigGenerator) Generate() []internal.StrategyCo
 	slopeThresholds := []float64{0.00045, 0.00055, 0.00065, 0.00075}
 	exhaustionFactors := []float64{0.40, 0.50, 0.60, 0.70}
 	priceChanges := []float64{0.008 /*, 0.0085, 0.015*/}
+	confidenceThresholds := []float64{0, 0.45} // адаптивный и строгий
 
 	// Генерируем комбинации с фокусом на качество, а не количество
 	for _, minLen := range minLengths {
@@ -708,16 +658,19 @@ func (g *PredictiveLinearSplineConfigGenerator) Generate() []internal.StrategyCo
 									// Высокий R² + высокое изменение цены
 									// Низкий R² + низкое изменение цены
 									if (r2 >= 0.75 && priceChg >= 0.008) || (r2 <= 0.70 && priceChg <= 0.010) {
-										configs = append(configs, &PredictiveLinearSplineConfig{
-											MinSegmentLength:      minLen,
-											MaxSegmentLength:      maxLen,
-											PredictionHorizon:     horizon,
-											MinR2Threshold:        r2,
-											SignalAdvance:         advance,
-											MinSlopeThreshold:     slope,
-											TrendExhaustionFactor: exhaust,
-											MinPriceChange:        priceChg,
-										})
+										for _, confidence := range confidenceThresholds {
+											configs = append(configs, &PredictiveLinearSplineConfig{
+												MinSegmentLength:      minLen,
+												MaxSegmentLength:      maxLen,
+												PredictionHorizon:     horizon,
+												MinR2Threshold:        r2,
+												SignalAdvance:         advance,
+												MinSlopeThreshold:     slope,
+												TrendExhaustionFactor: exhaust,
+												MinPriceChange:        priceChg,
+												ConfidenceThreshold:   confidence,
+											})
+										}
 									}
 								}
 							}
@@ -738,7 +691,7 @@ func (s *PredictiveLinearSplineStrategy) Name() string {
 }
 
 func NewPredictiveLinearSplineStrategyV2(slippage float64) internal.TradingStrategy {
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 	signalGenerator := NewPredictiveLinearSplineSignalGenerator()
 
 	configManager := internal.NewConfigManager(
@@ -766,13 +719,16 @@ func NewPredictiveLinearSplineStrategyV2(slippage float64) internal.TradingStrat
 		configGenerator.Generate,
 	)
 
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"predictive_linear_spline_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryTrend)
+
+	return strategy
 }
 
 func init() {
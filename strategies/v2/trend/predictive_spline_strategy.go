@@ -47,7 +47,6 @@ import (
 	"bt/internal"
 	"errors"
 	"fmt"
-	"log"
 	"math"
 )
 
@@ -57,8 +56,10 @@ type PredictiveSplineConfig struct {
 	PredictionHorizon int     `json:"prediction_horizon"`
 	MinR2Threshold    float64 `json:"min_r2_threshold"`
 	SignalAdvance     int     `json:"signal_advance"`
-	MinPriceChange    float64 `json:"min_price_change"`     // Минимальное изменение цены для сигнала (%)
-	MinTrendStrength  float64 `json:"min_trend_strength"`   // Минимальная сила тренда
+	MinPriceChange    float64 `json:"min_price_change"`   // Минимальное изменение цены для сигнала (%)
+	MinTrendStrength  float64 `json:"min_trend_strength"` // Минимальная сила тренда
+	// ConfidenceThreshold — минимальная уверенность предсказания для сигнала; 0 — адаптивный порог по длине истории
+	ConfidenceThreshold float64 `json:"confidence_threshold"`
 }
 
 func (c *PredictiveSplineConfig) Validate() error {
@@ -83,13 +84,41 @@ func (c *PredictiveSplineConfig) Validate() error {
 	if c.MinTrendStrength < 0 {
 		c.MinTrendStrength = 0.5 // по умолчанию
 	}
+	if c.ConfidenceThreshold < 0 || c.ConfidenceThreshold > 1 {
+		return errors.New("confidence threshold must be between 0 and 1")
+	}
 	return nil
 }
 
+// confidenceThreshold — заданный порог уверенности или адаптивный: чем длиннее история, тем строже
+func (c *PredictiveSplineConfig) confidenceThreshold(candles int) float64 {
+	if c.ConfidenceThreshold > 0 {
+		return c.ConfidenceThreshold
+	}
+	if candles > 10000 {
+		return 0.30 // Для очень больших данных немного повышаем
+	} else if candles > 5000 {
+		return 0.28
+	}
+	return 0.25 // Более низкий базовый порог
+}
+
+// formatConfidenceThreshold — порог уверенности для String(): число или adaptive
+func formatConfidenceThreshold(threshold float64) string {
+	if threshold > 0 {
+		return fmt.Sprintf("%.2f", threshold)
+	}
+	return "adaptive"
+}
+
+func (c *PredictiveSplineConfig) MinCandles() int {
+	return c.MinSegmentLength * 2
+}
+
 func (c *PredictiveSplineConfig) String() string {
-	return fmt.Sprintf("PredictiveSpline(min_len=%d, max_len=%d, horizon=%d, r2=%.2f, advance=%d, price_chg=%.2f%%, trend_str=%.2f)",
-		c.MinSegmentLength, c.MaxSegmentLength, c.PredictionHorizon, c.MinR2Threshold, c.SignalAdvance, 
-		c.MinPriceChange*100, c.MinTrendStrength)
+	return fmt.Sprintf("PredictiveSpline(min_len=%d, max_len=%d, horizon=%d, r2=%.2f, advance=%d, price_chg=%.2f%%, trend_str=%.2f, confidence=%s)",
+		c.MinSegmentLength, c.MaxSegmentLength, c.PredictionHorizon, c.MinR2Threshold, c.SignalAdvance,
+		c.MinPriceChange*100, c.MinTrendStrength, formatConfidenceThreshold(c.ConfidenceThreshold))
 }
 
 // SplineSegment представляет квадратичный сегмент сплайна
@@ -174,9 +203,8 @@ func (sa *SplineAnalyzer) fitQuadraticRegression(y []float64) (a, b, c, r2 float
 
 	if math.Abs(detA) < 1e-10 {
 		// Вырожденный случай, используем линейную регрессию
-		slope, intercept := sa.linearRegression(y)
-		r2 = sa.calculateLinearR2(y, slope, intercept)
-		return 0, slope, intercept, r2
+		slope, intercept, linearR2 := internal.LinearRegression(y)
+		return 0, slope, intercept, linearR2
 	}
 
 	detAa := sumX2Y*(sumX2*n-sumX*sumX) - sumXY*(sumX3*n-sumX*sumX2) + sumY*(sumX3*sumX-sumX2*sumX2)
@@ -194,28 +222,6 @@ func (sa *SplineAnalyzer) fitQuadraticRegression(y []float64) (a, b, c, r2 float
 	return a, b, c, r2
 }
 
-func (sa *SplineAnalyzer) linearRegression(y []float64) (slope, intercept float64) {
-	n := float64(len(y))
-	if n < 2 {
-		return 0, y[0]
-	}
-
-	sumX, sumY, sumXY, sumXX := 0.0, 0.0, 0.0, 0.0
-
-	for i, yi := range y {
-		xi := float64(i)
-		sumX += xi
-		sumY += yi
-		sumXY += xi * yi
-		sumXX += xi * xi
-	}
-
-	slope = (n*sumXY - sumX*sumY) / (n*sumXX - sumX*sumX)
-	intercept = (sumY - slope*sumX) / n
-
-	return slope, intercept
-}
-
 func (sa *SplineAnalyzer) calculateQuadraticR2(y []float64, a, b, c float64) float64 {
 	if len(y) < 3 {
 		return 0
@@ -243,33 +249,6 @@ func (sa *SplineAnalyzer) calculateQuadraticR2(y []float64, a, b, c float64) flo
 	return 1 - ssRes/ssTot
 }
 
-func (sa *SplineAnalyzer) calculateLinearR2(y []float64, slope, intercept float64) float64 {
-	if len(y) < 2 {
-		return 0
-	}
-
-	mean := 0.0
-	for _, yi := range y {
-		mean += yi
-	}
-	mean /= float64(len(y))
-
-	ssRes := 0.0
-	ssTot := 0.0
-
-	for i, yi := range y {
-		predicted := slope*float64(i) + intercept
-		ssRes += (yi - predicted) * (yi - predicted)
-		ssTot += (yi - mean) * (yi - mean)
-	}
-
-	if ssTot == 0 {
-		return 0
-	}
-
-	return 1 - ssRes/ssTot
-}
-
 // analyzeCurrentTrend анализирует текущий тренд и строит модель
 func (sa *SplineAnalyzer) analyzeCurrentTrend(prices []float64, currentIdx int) *SplineSegment {
 	// Кэш: если анализировали недавно, используем кэшированный результат
@@ -355,7 +334,7 @@ func (sa *SplineAnalyzer) predictReversal(segment *SplineSegment, currentIdx int
 	startPrice := prices[segment.StartIdx]
 	currentPrice := prices[currentIdx]
 	priceChangePercent := math.Abs(currentPrice-startPrice) / startPrice
-	
+
 	// Фильтр 1: Минимальное изменение цены
 	if priceChangePercent < sa.minPriceChange {
 		return nil // Тренд слишком слабый
@@ -365,18 +344,18 @@ func (sa *SplineAnalyzer) predictReversal(segment *SplineSegment, currentIdx int
 	localX := float64(segmentLength - 1)
 	slope := 2*segment.A*localX + segment.B
 	trendStrength := math.Abs(slope) / currentPrice * 100 // Нормализованная сила тренда в %
-	
+
 	if trendStrength < sa.minTrendStrength {
 		return nil // Тренд недостаточно сильный
 	}
 
 	// Предсказываем точку разворота на основе нескольких факторов:
-	
+
 	// 1. Если есть точка перегиба в будущем, используем её
 	inflectionDistance := segment.InflectionX - localX
 
 	var predictedDistance int
-	
+
 	if !math.IsInf(segment.InflectionX, 0) && inflectionDistance > 0 && inflectionDistance < float64(sa.predictionHorizon*3) {
 		// Точка перегиба близко - используем её
 		predictedDistance = int(math.Ceil(inflectionDistance))
@@ -424,7 +403,7 @@ func (sa *SplineAnalyzer) predictReversal(segment *SplineSegment, currentIdx int
 	if distanceFactor < 0 {
 		distanceFactor = 0
 	}
-	
+
 	confidence := segment.R2 * distanceFactor * (priceChangePercent / 0.1) // Нормализуем к 10%
 	if confidence > 1.0 {
 		confidence = 1.0
@@ -448,21 +427,35 @@ func (sg *PredictiveSplineSignalGenerator) GenerateSignals(candles []internal.Ca
 	}
 
 	if err := psConfig.Validate(); err != nil {
-		log.Printf("⚠️ Ошибка валидации конфигурации: %v", err)
+		internal.Log.Warnf("⚠️ Ошибка валидации конфигурации: %v", err)
 		return make([]internal.SignalType, len(candles))
 	}
 
-	if len(candles) < psConfig.MinSegmentLength*2 {
-		log.Printf("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d", len(candles), psConfig.MinSegmentLength*2)
+	if len(candles) < psConfig.MinCandles() {
+		internal.Log.Infof("⚠️ Недостаточно данных: получено %d свечей, требуется минимум %d", len(candles), psConfig.MinCandles())
 		return make([]internal.SignalType, len(candles))
 	}
 
-	// Извлекаем цены закрытия
-	prices := make([]float64, len(candles))
-	for i, candle := range candles {
-		prices[i] = candle.Close.ToFloat64()
+	return sg.generate(candles, psConfig, nil)
+}
+
+// ExplainSignals — сигналы сплайна и причина, по которой предсказанный разворот не стал сделкой
+// (порог уверенности, минимальное расстояние между сигналами, чередование BUY/SELL)
+func (sg *PredictiveSplineSignalGenerator) ExplainSignals(candles []internal.Candle, config internal.StrategyConfigV2) ([]internal.SignalType, internal.HoldReasons) {
+	psConfig, ok := config.(*PredictiveSplineConfig)
+	if !ok || psConfig.Validate() != nil || len(candles) < psConfig.MinCandles() {
+		return sg.GenerateSignals(candles, config), nil
 	}
 
+	reasons := make(internal.HoldReasons, len(candles))
+	return sg.generate(candles, psConfig, reasons), reasons
+}
+
+// generate — основной цикл стратегии; если reasons не nil, в него пишутся причины HOLD
+func (sg *PredictiveSplineSignalGenerator) generate(candles []internal.Candle, psConfig *PredictiveSplineConfig, reasons internal.HoldReasons) []internal.SignalType {
+	// Извлекаем цены (по умолчанию закрытия)
+	prices := internal.ExtractPrices(candles, internal.DefaultPriceSource())
+
 	analyzer := NewSplineAnalyzer(psConfig)
 	signals := make([]internal.SignalType, len(candles))
 
@@ -470,20 +463,14 @@ func (sg *PredictiveSplineSignalGenerator) GenerateSignals(candles []internal.Ca
 	var activePrediction *PredictedReversal
 	lastSignalIdx := -1
 	lastSignalType := internal.HOLD // Отслеживаем тип последнего сигнала
-	
+
 	// Адаптивное минимальное расстояние между сигналами
 	minSignalDistance := psConfig.MinSegmentLength
 	if len(candles) > 10000 {
 		minSignalDistance = int(float64(psConfig.MinSegmentLength) * 1.5)
 	}
-	
-	// Адаптивный порог уверенности в зависимости от длины истории
-	confidenceThreshold := 0.25 // Более низкий базовый порог
-	if len(candles) > 10000 {
-		confidenceThreshold = 0.30 // Для очень больших данных немного повышаем
-	} else if len(candles) > 5000 {
-		confidenceThreshold = 0.28
-	}
+
+	confidenceThreshold := psConfig.confidenceThreshold(len(candles))
 
 	// Начинаем анализ после накопления достаточных данных
 	startIdx := psConfig.MaxSegmentLength
@@ -495,7 +482,7 @@ func (sg *PredictiveSplineSignalGenerator) GenerateSignals(candles []internal.Ca
 		// Проверяем, не пора ли выставить сигнал по активному предсказанию
 		if activePrediction != nil {
 			signalIdx := activePrediction.PredictedIndex - psConfig.SignalAdvance
-			
+
 			if i == signalIdx && activePrediction.Confidence >= confidenceThreshold {
 				// Проверяем минимальное расстояние от последнего сигнала
 				if lastSignalIdx < 0 || i-lastSignalIdx >= minSignalDistance {
@@ -507,6 +494,9 @@ func (sg *PredictiveSplineSignalGenerator) GenerateSignals(candles []internal.Ca
 						activePrediction = nil // Сбрасываем предсказание после выставления сигнала
 						continue
 					}
+					reasons.Record(i, activePrediction.SignalType, internal.HoldAlternation, "предыдущий сигнал тоже %v", lastSignalType)
+				} else {
+					reasons.Record(i, activePrediction.SignalType, internal.HoldCooldown, "после сигнала прошло %d баров < %d", i-lastSignalIdx, minSignalDistance)
 				}
 			}
 
@@ -525,13 +515,15 @@ func (sg *PredictiveSplineSignalGenerator) GenerateSignals(candles []internal.Ca
 		if len(candles) > 10000 {
 			analysisInterval = psConfig.MinSegmentLength
 		}
-		
+
 		if activePrediction == nil && (lastSignalIdx < 0 || i-lastSignalIdx >= analysisInterval) {
 			segment := analyzer.analyzeCurrentTrend(prices, i)
 			if segment != nil && segment.R2 >= psConfig.MinR2Threshold {
 				prediction := analyzer.predictReversal(segment, i, prices)
 				if prediction != nil && prediction.Confidence >= confidenceThreshold {
 					activePrediction = prediction
+				} else if prediction != nil {
+					reasons.Record(i, prediction.SignalType, internal.HoldConfidence, "уверенность %.2f < %.2f", prediction.Confidence, confidenceThreshold)
 				}
 			}
 		}
@@ -548,17 +540,18 @@ func NewPredictiveSplineConfigGenerator() *PredictiveSplineConfigGenerator {
 
 func (g *PredictiveSplineConfigGenerator) Generate() []internal.StrategyConfigV2 {
 	configs := []internal.StrategyConfigV2{}
-	
+
 	// Оптимизированный набор с акцентом на разнообразие количества сделок
 	minLengths := []int{8, 12, 16}
 	maxLengths := []int{50, 70, 90}
 	horizons := []int{5, 7, 10}
 	r2Thresholds := []float64{0.65, 0.70, 0.75}
 	advances := []int{3, 5}
-	
+	confidenceThresholds := []float64{0, 0.35} // адаптивный и строгий
+
 	// Специально подобранные комбинации фильтров для разного количества сделок
 	filterCombos := []struct {
-		priceChange  float64
+		priceChange   float64
 		trendStrength float64
 	}{
 		{0.003, 0.12}, // Очень мягкие - много сделок
@@ -568,7 +561,7 @@ func (g *PredictiveSplineConfigGenerator) Generate() []internal.StrategyConfigV2
 		{0.012, 0.30}, // Строгие - мало сделок
 		{0.015, 0.35}, // Очень строгие - очень мало сделок
 	}
-	
+
 	// Генерируем комбинации
 	for _, minLen := range minLengths {
 		for _, maxLen := range maxLengths {
@@ -579,15 +572,18 @@ func (g *PredictiveSplineConfigGenerator) Generate() []internal.StrategyConfigV2
 				for _, r2 := range r2Thresholds {
 					for _, advance := range advances {
 						for _, combo := range filterCombos {
-							configs = append(configs, &PredictiveSplineConfig{
-								MinSegmentLength:  minLen,
-								MaxSegmentLength:  maxLen,
-								PredictionHorizon: horizon,
-								MinR2Threshold:    r2,
-								SignalAdvance:     advance,
-								MinPriceChange:    combo.priceChange,
-								MinTrendStrength:  combo.trendStrength,
-							})
+							for _, confidence := range confidenceThresholds {
+								configs = append(configs, &PredictiveSplineConfig{
+									MinSegmentLength:    minLen,
+									MaxSegmentLength:    maxLen,
+									PredictionHorizon:   horizon,
+									MinR2Threshold:      r2,
+									SignalAdvance:       advance,
+									MinPriceChange:      combo.priceChange,
+									MinTrendStrength:    combo.trendStrength,
+									ConfidenceThreshold: confidence,
+								})
+							}
 						}
 					}
 				}
@@ -609,7 +605,7 @@ func (s *PredictiveSplineStrategy) Name() string {
 
 func NewPredictiveSplineStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewPredictiveSplineSignalGenerator()
@@ -622,7 +618,7 @@ func NewPredictiveSplineStrategyV2(slippage float64) internal.TradingStrategy {
 			PredictionHorizon: 7,
 			MinR2Threshold:    0.70,
 			SignalAdvance:     3,
-			MinPriceChange:    0.008,  // 0.8%
+			MinPriceChange:    0.008, // 0.8%
 			MinTrendStrength:  0.25,
 		},
 		func() internal.StrategyConfigV2 {
@@ -640,13 +636,16 @@ func NewPredictiveSplineStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"predictive_spline_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryTrend)
+
+	return strategy
 }
 
 func init() {
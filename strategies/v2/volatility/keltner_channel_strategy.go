@@ -0,0 +1,260 @@
+// Keltner Channel Strategy V2
+//
+// Описание стратегии:
+// Каналы Кельтнера - EMA цены закрытия и полосы на расстоянии multiplier × ATR от нее.
+// В отличие от полос Боллинджера ширина канала зависит от истинного диапазона свечей,
+// а не от разброса закрытий, поэтому канал меньше реагирует на отдельные выбросы.
+//
+// Как работает:
+// - Покупка: минимум свечи касается нижней полосы, а средняя линия до этой свечи
+//   выросла за последние EMAPeriod/2 свечей (откат в восходящем тренде)
+// - Продажа: максимум свечи касается верхней полосы
+//
+// Параметры:
+// - EMAPeriod: период EMA средней линии (обычно 20)
+// - ATRPeriod: период ATR (обычно 10-14)
+// - Multiplier: ширина канала в ATR (обычно 1.5-2.5)
+
+package volatility
+
+import (
+	"bt/internal"
+	"errors"
+	"fmt"
+
+	"github.com/samber/lo"
+)
+
+type KeltnerChannelConfigV2 struct {
+	EMAPeriod  int     `json:"ema_period"`
+	ATRPeriod  int     `json:"atr_period"`
+	Multiplier float64 `json:"multiplier"`
+}
+
+func (c *KeltnerChannelConfigV2) Validate() error {
+	if c.EMAPeriod <= 1 {
+		return errors.New("ema period must be greater than 1")
+	}
+	if c.ATRPeriod <= 0 {
+		return errors.New("atr period must be positive")
+	}
+	if c.Multiplier <= 0 {
+		return errors.New("multiplier must be positive")
+	}
+	return nil
+}
+
+func (c *KeltnerChannelConfigV2) String() string {
+	return fmt.Sprintf("Keltner(ema=%d, atr=%d, mult=%.2f)",
+		c.EMAPeriod, c.ATRPeriod, c.Multiplier)
+}
+
+// trendWindow — число свечей, за которое оценивается тренд средней линии
+func (c *KeltnerChannelConfigV2) trendWindow() int {
+	return c.EMAPeriod / 2
+}
+
+// warmup — первая свеча, на которой определены канал и тренд средней линии
+func (c *KeltnerChannelConfigV2) warmup() int {
+	return max(c.EMAPeriod-1, c.ATRPeriod) + c.trendWindow() + 1
+}
+
+// uptrend — средняя линия на предыдущей свече выше, чем trendWindow свечей до нее.
+// Наклон на самой свече касания не подходит: резкое падение к нижней полосе
+// почти всегда разворачивает EMA вниз на этой же свече
+func (c *KeltnerChannelConfigV2) uptrend(middle []float64, i int) bool {
+	return middle[i-1] > middle[i-1-c.trendWindow()]
+}
+
+// candleRange — максимум и минимум свечи (без High/Low — закрытие)
+func candleRange(c internal.Candle) (high, low float64) {
+	high, low = c.High.ToFloat64(), c.Low.ToFloat64()
+	if high <= 0 || low <= 0 {
+		return c.Close.ToFloat64(), c.Close.ToFloat64()
+	}
+	return high, low
+}
+
+type KeltnerChannelSignalGeneratorV2 struct{}
+
+func NewKeltnerChannelSignalGeneratorV2() *KeltnerChannelSignalGeneratorV2 {
+	return &KeltnerChannelSignalGeneratorV2{}
+}
+
+func (sg *KeltnerChannelSignalGeneratorV2) GenerateSignals(candles []internal.Candle, config internal.StrategyConfigV2) []internal.SignalType {
+	keltnerConfig, ok := config.(*KeltnerChannelConfigV2)
+	if !ok {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	if err := keltnerConfig.Validate(); err != nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	upper, middle, lower := internal.CalculateKeltnerChannels(candles, keltnerConfig.EMAPeriod, keltnerConfig.ATRPeriod, keltnerConfig.Multiplier)
+	if upper == nil {
+		return make([]internal.SignalType, len(candles))
+	}
+
+	signals := make([]internal.SignalType, len(candles))
+	inPosition := false
+
+	for i := keltnerConfig.warmup(); i < len(candles); i++ {
+		high, low := candleRange(candles[i])
+
+		if !inPosition && low <= lower[i] && keltnerConfig.uptrend(middle, i) {
+			signals[i] = internal.BUY
+			inPosition = true
+			continue
+		}
+
+		if inPosition && high >= upper[i] {
+			signals[i] = internal.SELL
+			inPosition = false
+			continue
+		}
+
+		signals[i] = internal.HOLD
+	}
+
+	return signals
+}
+
+// PredictNextSignal предсказывает касание полосы канала, экстраполируя движение цены:
+// снижение к нижней полосе при растущей средней - BUY, рост к верхней полосе - SELL
+func (sg *KeltnerChannelSignalGeneratorV2) PredictNextSignal(candles []internal.Candle, config internal.StrategyConfigV2) *internal.FutureSignal {
+	keltnerConfig, ok := config.(*KeltnerChannelConfigV2)
+	if !ok {
+		return nil
+	}
+
+	if err := keltnerConfig.Validate(); err != nil {
+		return nil
+	}
+
+	lookback := 3
+	if len(candles) < keltnerConfig.warmup()+lookback {
+		return nil
+	}
+
+	upper, middle, lower := internal.CalculateKeltnerChannels(candles, keltnerConfig.EMAPeriod, keltnerConfig.ATRPeriod, keltnerConfig.Multiplier)
+	if upper == nil {
+		return nil
+	}
+
+	currentIdx := len(candles) - 1
+	currentPrice := candles[currentIdx].Close.ToFloat64()
+	priceVelocity := (currentPrice - candles[currentIdx-lookback].Close.ToFloat64()) / float64(lookback)
+	width := upper[currentIdx] - lower[currentIdx]
+	uptrend := keltnerConfig.uptrend(middle, currentIdx)
+	if width <= 0 || priceVelocity == 0 {
+		return nil
+	}
+
+	var signalType internal.SignalType
+	var distance float64
+	var confidence float64
+	if priceVelocity < 0 && uptrend {
+		signalType = internal.BUY
+		distance = currentPrice - lower[currentIdx]
+		confidence = 0.4
+	} else if priceVelocity > 0 {
+		signalType = internal.SELL
+		distance = upper[currentIdx] - currentPrice
+		confidence = 0.3
+	} else {
+		return nil
+	}
+
+	// Цена уже за полосой - касание на следующей свече
+	predictedCandles := 1
+	if distance > 0 {
+		predictedCandles = int(distance/internal.Abs(priceVelocity)) + 1
+	}
+	if predictedCandles > keltnerConfig.EMAPeriod {
+		return nil // Слишком далеко
+	}
+
+	// Уверенность выше, чем ближе цена к полосе относительно ширины канала
+	confidence += 0.4 * (1 - internal.Min(internal.Abs(distance)/width, 1.0))
+
+	// Ограничиваем уверенность
+	if confidence > 1.0 {
+		confidence = 1.0
+	}
+	if confidence < 0.1 {
+		confidence = 0.1
+	}
+
+	predictedPrice := currentPrice + priceVelocity*float64(predictedCandles)
+
+	timeInterval := (candles[currentIdx].ToTime().Unix() - candles[0].ToTime().Unix()) / int64(currentIdx)
+	futureTimestamp := candles[currentIdx].ToTime().Unix() + timeInterval*int64(predictedCandles)
+
+	return &internal.FutureSignal{
+		SignalType: signalType,
+		Date:       futureTimestamp,
+		Price:      predictedPrice,
+		Confidence: confidence,
+	}
+}
+
+type KeltnerChannelConfigGeneratorV2 struct{}
+
+func NewKeltnerChannelConfigGeneratorV2() *KeltnerChannelConfigGeneratorV2 {
+	return &KeltnerChannelConfigGeneratorV2{}
+}
+
+func (g *KeltnerChannelConfigGeneratorV2) Generate() []internal.StrategyConfigV2 {
+	configs := lo.CrossJoinBy3(
+		lo.RangeWithSteps[int](10, 40, 5),
+		lo.RangeWithSteps[int](7, 22, 3),
+		lo.RangeWithSteps[float64](1.0, 3.25, 0.25),
+		func(emaPeriod int, atrPeriod int, multiplier float64) internal.StrategyConfigV2 {
+			return &KeltnerChannelConfigV2{
+				EMAPeriod:  emaPeriod,
+				ATRPeriod:  atrPeriod,
+				Multiplier: multiplier,
+			}
+		})
+
+	return configs
+}
+
+func NewKeltnerChannelStrategyV2(slippage float64) internal.TradingStrategy {
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
+	signalGenerator := NewKeltnerChannelSignalGeneratorV2()
+
+	configManager := internal.NewConfigManager(
+		&KeltnerChannelConfigV2{
+			EMAPeriod:  20,
+			ATRPeriod:  10,
+			Multiplier: 2.0,
+		},
+		func() internal.StrategyConfigV2 {
+			return &KeltnerChannelConfigV2{}
+		},
+	)
+
+	configGenerator := NewKeltnerChannelConfigGeneratorV2()
+	optimizer := internal.NewGridSearchOptimizer(
+		slippageProvider,
+		configGenerator.Generate,
+	)
+
+	strategy := internal.NewStrategyBase(
+		"keltner_channel",
+		signalGenerator,
+		configManager,
+		optimizer,
+		slippageProvider,
+	)
+	strategy.SetCategory(internal.CategoryVolatility)
+
+	return strategy
+}
+
+func init() {
+	strategy := NewKeltnerChannelStrategyV2(0.01)
+	internal.RegisterStrategyV2(strategy)
+}
@@ -0,0 +1,26 @@
+package volatility
+
+import (
+	"bt/internal"
+	"testing"
+)
+
+func TestKeltnerChannel_DefaultConfigTrades(t *testing.T) {
+	candles := internal.GenerateSyntheticCandlesWithVolatility(1000, 1, 0.015)
+	config := &KeltnerChannelConfigV2{EMAPeriod: 20, ATRPeriod: 10, Multiplier: 2.0}
+
+	signals := NewKeltnerChannelSignalGeneratorV2().GenerateSignals(candles, config)
+
+	buys, sells := 0, 0
+	for _, s := range signals {
+		switch s {
+		case internal.BUY:
+			buys++
+		case internal.SELL:
+			sells++
+		}
+	}
+	if buys == 0 || sells == 0 {
+		t.Fatalf("default config should produce a BUY/SELL pair, got %d BUY and %d SELL", buys, sells)
+	}
+}
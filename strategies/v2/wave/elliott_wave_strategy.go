@@ -42,7 +42,6 @@ import (
 	"bt/internal"
 	"errors"
 	"fmt"
-	"log"
 
 	"github.com/samber/lo"
 )
@@ -497,7 +496,7 @@ func (s *ElliottWaveSignalGenerator) GenerateSignals(candles []internal.Candle,
 	}
 
 	if len(candles) < 20 {
-		log.Printf("⚠️ Недостаточно данных для волнового анализа Эллиотта: получено %d свечей, требуется минимум 20", len(candles))
+		internal.Log.Infof("⚠️ Недостаточно данных для волнового анализа Эллиотта: получено %d свечей, требуется минимум 20", len(candles))
 		return make([]internal.SignalType, len(candles))
 	}
 
@@ -573,14 +572,19 @@ func (s *ElliottWaveConfigGenerator) Generate() []internal.StrategyConfigV2 {
 
 func NewElliottWaveStrategyV2(slippage float64) internal.TradingStrategy {
 	// 1. Создаем провайдер проскальзывания
-	slippageProvider := internal.NewSlippageProvider(slippage)
+	slippageProvider := internal.NewSlippageProvider(internal.SlippageAbsolute(slippage))
 
 	// 2. Создаем генератор сигналов
 	signalGenerator := NewElliottWaveSignalGenerator()
 
 	// 3. Создаем менеджер конфигурации
 	configManager := internal.NewConfigManager(
-		&ElliottWaveConfig{},
+		&ElliottWaveConfig{
+			MinWaveLength:      5,
+			MaxWaveLength:      50,
+			FibonacciThreshold: 0.618,
+			TrendStrength:      0.3,
+		},
 		func() internal.StrategyConfigV2 {
 			return &ElliottWaveConfig{}
 		},
@@ -596,13 +600,16 @@ func NewElliottWaveStrategyV2(slippage float64) internal.TradingStrategy {
 	)
 
 	// 6. Собираем всё вместе через композицию
-	return internal.NewStrategyBase(
+	strategy := internal.NewStrategyBase(
 		"elliott_wave_v2",
 		signalGenerator,
 		configManager,
 		optimizer,
 		slippageProvider,
 	)
+	strategy.SetCategory(internal.CategoryWave)
+
+	return strategy
 }
 
 func init() {